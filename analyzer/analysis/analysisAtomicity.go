@@ -0,0 +1,194 @@
+package analysis
+
+import (
+	"analyzer/clock"
+	"analyzer/logging"
+	"analyzer/utils"
+	"sort"
+	"strings"
+)
+
+// maximum number of check-then-act sequences and of writes, that are stored
+// for each atomic variable. Variables used in loops would otherwise create a
+// quadratic number of comparisons.
+const maxAtomicOpsPerVariable = 200
+
+/*
+ * Store an operation on an atomic variable for the detection of atomicity
+ * violations. A load, that is followed by a store, add or swap on the same
+ * variable in the same routine, is stored as a check-then-act sequence, if
+ * the write depends on the loaded value in the program code.
+ * The vector clocks are not copied, they must not be changed afterwards.
+ * Args:
+ *   routine (int): The routine id
+ *   id (int): The id of the atomic variable
+ *   tID (string): The id of the trace element, contains the position and the tpre
+ *   objType (string): The type of the operation, e.g. AL
+ *   vcPre (VectorClock): The vector clock of the routine before the operation, only needed for writes
+ *   vcPost (VectorClock): The vector clock of the routine after the operation
+ */
+func (s *State) UpdateAtomicCheckThenAct(routine int, id int, tID string, objType string,
+	vcPre clock.VectorClock, vcPost clock.VectorClock) {
+	// without the position, the operation cannot be reported
+	if !atomicHasPosition(tID) {
+		return
+	}
+
+	op := atomicOp{routine, tID, objType, vcPre, vcPost}
+
+	if _, ok := s.atomicLastLoad[id]; !ok {
+		s.atomicLastLoad[id] = make(map[int]atomicOp)
+	}
+
+	switch objType {
+	case "AL":
+		s.atomicLastLoad[id][routine] = op
+		return
+	case "AS", "AA", "AW":
+		if load, ok := s.atomicLastLoad[id][routine]; ok && s.atomicWriteDependsOnLoad(load, op) &&
+			len(s.atomicCheckThenAct[id]) < maxAtomicOpsPerVariable {
+			s.atomicCheckThenAct[id] = append(s.atomicCheckThenAct[id], checkThenAct{load, op})
+		}
+	case "AC":
+		// a compare and swap checks the value itself
+	default:
+		return
+	}
+
+	delete(s.atomicLastLoad[id], routine)
+	if len(s.atomicWrites[id]) < maxAtomicOpsPerVariable {
+		s.atomicWrites[id] = append(s.atomicWrites[id], op)
+	}
+}
+
+/*
+ * Check if a write depends on the value of a load of the same routine in
+ * the program code. If the code cannot be read, the write is assumed to be
+ * independent.
+ * Args:
+ *   load (atomicOp): The load
+ *   write (atomicOp): The write
+ * Returns:
+ *   bool: true if the write depends on the load
+ */
+func (s *State) atomicWriteDependsOnLoad(load atomicOp, write atomicOp) bool {
+	key := positionFromTID(load.tID) + "|" + positionFromTID(write.tID)
+	if res, ok := s.atomicDependent[key]; ok {
+		return res
+	}
+
+	res := false
+	fileLoad, lineLoad, _, err1 := infoFromTID(load.tID)
+	fileWrite, lineWrite, _, err2 := infoFromTID(write.tID)
+	if err1 == nil && err2 == nil && fileLoad == fileWrite {
+		var err error
+		res, err = utils.AtomicWriteDependsOnLoad(fileLoad, lineLoad, lineWrite)
+		if err != nil {
+			logging.Debug("Could not check dependency of atomic operations: "+err.Error(), logging.INFO)
+		}
+	}
+
+	s.atomicDependent[key] = res
+	return res
+}
+
+/*
+ * Check for atomicity violations on atomic variables. Call when all elements
+ * have been processed.
+ * A check-then-act sequence, i.e. a load followed by a store, add or swap of
+ * the same routine on the same atomic variable, that depends on the loaded
+ * value, is violated, if a write w of another routine can be executed between
+ * the load l and the write s. This is possible, if w does not happen before l
+ * and s does not happen before w.
+ * A load synchronizes with the vector clock, the last write had before it was
+ * executed. We therefore use the vector clock before w for the comparisons.
+ * For each combination of positions, only the first violation is reported.
+ */
+func (s *State) CheckForAtomicityViolation() {
+	reported := make(map[string]struct{})
+
+	ids := make([]int, 0, len(s.atomicCheckThenAct))
+	for id := range s.atomicCheckThenAct {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		for _, seq := range s.atomicCheckThenAct[id] {
+			for _, write := range s.atomicWrites[id] {
+				if write.routine == seq.check.routine {
+					continue
+				}
+
+				if clock.GetHappensBefore(write.vcPre, seq.check.vcPost) == clock.Before ||
+					clock.GetHappensBefore(seq.act.vcPost, write.vcPre) == clock.Before {
+					continue
+				}
+
+				key := positionFromTID(seq.check.tID) + "|" + positionFromTID(write.tID) +
+					"|" + positionFromTID(seq.act.tID)
+				if _, ok := reported[key]; ok {
+					continue
+				}
+				reported[key] = struct{}{}
+
+				s.logAtomicityViolation(id, seq, write)
+			}
+		}
+	}
+}
+
+/*
+ * Get the position of an element from its tID
+ * Args:
+ *   tID (string): The tID
+ * Returns:
+ *   string: The position
+ */
+func positionFromTID(tID string) string {
+	return splitAtLast(tID, "@")[0]
+}
+
+/*
+ * Log an atomicity violation. The arg contains the interleaving, that breaks
+ * the check-then-act sequence, i.e. the load, the write of the other routine
+ * and the write of the check-then-act sequence.
+ * Args:
+ *   id (int): The id of the atomic variable
+ *   seq (checkThenAct): The check-then-act sequence
+ *   write (atomicOp): The write of the other routine
+ */
+func (s *State) logAtomicityViolation(id int, seq checkThenAct, write atomicOp) {
+	args := make([]logging.ResultElem, 0, 3)
+
+	for _, op := range []atomicOp{seq.check, write, seq.act} {
+		file, line, tPre, err := infoFromTID(op.tID)
+		if err != nil {
+			logging.Debug(err.Error(), logging.ERROR)
+			return
+		}
+
+		args = append(args, logging.TraceElementResult{
+			RoutineID: op.routine,
+			ObjID:     id,
+			TPre:      tPre,
+			ObjType:   op.objType,
+			File:      file,
+			Line:      line,
+		})
+	}
+
+	s.results.Result(logging.CRITICAL, logging.PAtomicityViolation,
+		"interleaving", args, "", []logging.ResultElem{})
+}
+
+/*
+ * Check if the tID of an atomic operation contains the position of the operation
+ * Args:
+ *   tID (string): The tID
+ * Returns:
+ *   bool: true if the tID contains the position, false otherwise
+ */
+func atomicHasPosition(tID string) bool {
+	return !strings.HasPrefix(tID, "A@")
+}
@@ -3,6 +3,7 @@ package analysis
 import (
 	"analyzer/clock"
 	"analyzer/logging"
+	"analyzer/utils"
 	"strconv"
 )
 
@@ -14,17 +15,17 @@ Args:
 	id (int): the id of the channel
 	pos (string): the position of the close in the program
 */
-func checkForCommunicationOnClosedChannel(id int, pos string) {
+func (s *State) checkForCommunicationOnClosedChannel(id int, pos string) {
 	// check if there is an earlier send, that could happen concurrently to close
 	// println("Check for possible send on closed channel ", analysisCases["sendOnClosed"], hasSend[id])
-	if analysisCases["sendOnClosed"] && hasSend[id] {
-		for routine, mrs := range mostRecentSend {
+	if s.analysisCases["sendOnClosed"] && s.hasSend[id] {
+		for routine, mrs := range s.mostRecentSend {
 			logging.Debug("Check for possible send on closed channel "+
 				strconv.Itoa(id)+" with "+
-				mrs[id].Vc.ToString()+" and "+closeData[id].Vc.ToString(),
+				mrs[id].Vc.ToString()+" and "+s.closeData[id].Vc.ToString(),
 				logging.DEBUG)
 
-			happensBefore := clock.GetHappensBefore(closeData[id].Vc, mrs[id].Vc)
+			happensBefore := clock.GetHappensBefore(s.closeData[id].Vc, mrs[id].Vc)
 			if mrs[id].TID != "" && happensBefore == clock.Concurrent {
 
 				file1, line1, tPre1, err := infoFromTID(mrs[id].TID) // send
@@ -49,7 +50,7 @@ func checkForCommunicationOnClosedChannel(id int, pos string) {
 				}
 
 				arg2 := logging.TraceElementResult{ // close
-					RoutineID: closeData[id].Routine,
+					RoutineID: s.closeData[id].Routine,
 					ObjID:     id,
 					TPre:      tPre2,
 					ObjType:   "CC",
@@ -57,20 +58,20 @@ func checkForCommunicationOnClosedChannel(id int, pos string) {
 					Line:      line2,
 				}
 
-				logging.Result(logging.CRITICAL, logging.PSendOnClosed,
+				s.results.Result(logging.CRITICAL, logging.PSendOnClosed,
 					"send", []logging.ResultElem{arg1}, "close", []logging.ResultElem{arg2})
 			}
 		}
 	}
 	// check if there is an earlier receive, that could happen concurrently to close
-	if analysisCases["receiveOnClosed"] && hasReceived[id] {
-		for routine, mrr := range mostRecentReceive {
+	if s.analysisCases["receiveOnClosed"] && s.hasReceived[id] {
+		for routine, mrr := range s.mostRecentReceive {
 			logging.Debug("Check for possible receive on closed channel "+
 				strconv.Itoa(id)+" with "+
-				mrr[id].Vc.ToString()+" and "+closeData[id].Vc.ToString(),
+				mrr[id].Vc.ToString()+" and "+s.closeData[id].Vc.ToString(),
 				logging.DEBUG)
 
-			happensBefore := clock.GetHappensBefore(closeData[id].Vc, mrr[id].Vc)
+			happensBefore := clock.GetHappensBefore(s.closeData[id].Vc, mrr[id].Vc)
 			if mrr[id].TID != "" && (happensBefore == clock.Concurrent || happensBefore == clock.Before) {
 
 				file1, line1, tPre1, err := infoFromTID(mrr[id].TID) // recv
@@ -95,7 +96,7 @@ func checkForCommunicationOnClosedChannel(id int, pos string) {
 				}
 
 				arg2 := logging.TraceElementResult{ // close
-					RoutineID: closeData[id].Routine,
+					RoutineID: s.closeData[id].Routine,
 					ObjID:     id,
 					TPre:      tPre2,
 					ObjType:   "CC",
@@ -103,7 +104,7 @@ func checkForCommunicationOnClosedChannel(id int, pos string) {
 					Line:      line2,
 				}
 
-				logging.Result(logging.WARNING, logging.PRecvOnClosed,
+				s.results.Result(logging.WARNING, logging.PRecvOnClosed,
 					"recv", []logging.ResultElem{arg1}, "close", []logging.ResultElem{arg2})
 			}
 		}
@@ -111,12 +112,12 @@ func checkForCommunicationOnClosedChannel(id int, pos string) {
 
 }
 
-func foundSendOnClosedChannel(routineID int, id int, posSend string) {
-	if _, ok := closeData[id]; !ok {
+func (s *State) foundSendOnClosedChannel(routineID int, id int, posSend string) {
+	if _, ok := s.closeData[id]; !ok {
 		return
 	}
 
-	posClose := closeData[id].TID
+	posClose := s.closeData[id].TID
 	if posClose == "" || posSend == "" || posClose == "\n" || posSend == "\n" {
 		return
 	}
@@ -143,7 +144,7 @@ func foundSendOnClosedChannel(routineID int, id int, posSend string) {
 	}
 
 	arg2 := logging.TraceElementResult{ // close
-		RoutineID: closeData[id].Routine,
+		RoutineID: s.closeData[id].Routine,
 		ObjID:     id,
 		TPre:      tPre2,
 		ObjType:   "CC",
@@ -151,17 +152,17 @@ func foundSendOnClosedChannel(routineID int, id int, posSend string) {
 		Line:      line2,
 	}
 
-	logging.Result(logging.CRITICAL, logging.ASendOnClosed,
+	s.results.Result(logging.CRITICAL, logging.ASendOnClosed,
 		"send", []logging.ResultElem{arg1}, "close", []logging.ResultElem{arg2})
 
 }
 
-func foundReceiveOnClosedChannel(routineID int, id int, posRecv string) {
-	if _, ok := closeData[id]; !ok {
+func (s *State) foundReceiveOnClosedChannel(routineID int, id int, posRecv string) {
+	if _, ok := s.closeData[id]; !ok {
 		return
 	}
 
-	posClose := closeData[id].TID
+	posClose := s.closeData[id].TID
 	if posClose == "" || posRecv == "" || posClose == "\n" || posRecv == "\n" {
 		return
 	}
@@ -188,7 +189,7 @@ func foundReceiveOnClosedChannel(routineID int, id int, posRecv string) {
 	}
 
 	arg2 := logging.TraceElementResult{ // close
-		RoutineID: closeData[id].Routine,
+		RoutineID: s.closeData[id].Routine,
 		ObjID:     id,
 		TPre:      tPre2,
 		ObjType:   "CC",
@@ -196,7 +197,7 @@ func foundReceiveOnClosedChannel(routineID int, id int, posRecv string) {
 		Line:      line2,
 	}
 
-	logging.Result(logging.WARNING, logging.ARecvOnClosed,
+	s.results.Result(logging.WARNING, logging.ARecvOnClosed,
 		"recv", []logging.ResultElem{arg1}, "close", []logging.ResultElem{arg2})
 }
 
@@ -208,8 +209,8 @@ func foundReceiveOnClosedChannel(routineID int, id int, posRecv string) {
  * 	id (int): the id of the channel
  * 	pos (string): the position of the close in the program
  */
-func checkForClosedOnClosed(routineID int, id int, pos string) {
-	if oldClose, ok := closeData[id]; ok {
+func (s *State) checkForClosedOnClosed(routineID int, id int, pos string) {
+	if oldClose, ok := s.closeData[id]; ok {
 		if oldClose.TID == "" || oldClose.TID == "\n" || pos == "" || pos == "\n" {
 			return
 		}
@@ -244,7 +245,105 @@ func checkForClosedOnClosed(routineID int, id int, pos string) {
 			Line:      line2,
 		}
 
-		logging.Result(logging.CRITICAL, logging.ACloseOnClosed,
+		s.results.Result(logging.CRITICAL, logging.ACloseOnClosed,
 			"close", []logging.ResultElem{arg1}, "close", []logging.ResultElem{arg2})
 	}
 }
+
+/*
+ * Store a select with a default case, whose default case was chosen. A
+ * select like this is often used to check if a channel has already been
+ * closed, before it is closed.
+ * Args:
+ *   routineID (int): the id of the routine
+ *   ids ([]int): the ids of the channels of the receive cases
+ *   tID (string): the position of the select in the program
+ */
+func (s *State) SelectDefault(routineID int, ids []int, tID string) {
+	if _, ok := s.defaultSelect[routineID]; !ok {
+		s.defaultSelect[routineID] = make(map[int]string)
+	}
+
+	for _, id := range ids {
+		s.defaultSelect[routineID][id] = tID
+	}
+}
+
+/*
+ * Check for a possible close on a closed channel. A select with a default
+ * case in one routine received from a channel, because the channel was
+ * already closed by another routine. If the select is concurrent to the
+ * close and its default case closes the received channel, it could have
+ * chosen the default case and closed the channel again. The default case is
+ * found in the program code. If the code is not available, the select must be at the
+ * same position as the select, whose default case was chosen before the
+ * close, so that both routines run the same close.
+ * Must be called for a select, whose chosen receive case received because
+ * the channel was closed.
+ * Args:
+ *   routineID (int): the id of the routine of the select
+ *   id (int): the id of the channel
+ *   tID (string): the position of the select in the program
+ *   vc (VectorClock): the vector clock of the select before the receive
+ */
+func (s *State) CheckForPossibleCloseOnClosed(routineID int, id int, tID string, vc clock.VectorClock) {
+	closeData, ok := s.closeData[id]
+	if !ok || closeData.Routine == routineID {
+		return
+	}
+
+	if clock.GetHappensBefore(closeData.Vc, vc) != clock.Concurrent {
+		return
+	}
+
+	file1, line1, tPre1, err := infoFromTID(tID) // select
+	if err != nil {
+		logging.Debug(err.Error(), logging.ERROR)
+		return
+	}
+
+	if _, err := utils.FindCloseInDefaultCase(file1, line1); err == utils.ErrNoCloseInDefaultCase {
+		// the default case does not close the received channel
+		return
+	} else if err != nil {
+		logging.Debug(err.Error(), logging.INFO)
+
+		// without the code, the select must be the same check, that was
+		// done before the close
+		guard, ok := s.closeGuard[id]
+		if !ok {
+			return
+		}
+		fileGuard, lineGuard, _, err := infoFromTID(guard)
+		if err != nil || file1 != fileGuard || line1 != lineGuard {
+			return
+		}
+	}
+
+	file2, line2, tPre2, err := infoFromTID(closeData.TID) // close
+	if err != nil {
+		logging.Debug(err.Error(), logging.ERROR)
+		return
+	}
+
+	arg1 := logging.TraceElementResult{ // select
+		RoutineID: routineID,
+		ObjID:     id,
+		TPre:      tPre1,
+		ObjType:   "SS",
+		File:      file1,
+		Line:      line1,
+	}
+
+	arg2 := logging.TraceElementResult{ // close
+		RoutineID: closeData.Routine,
+		ObjID:     id,
+		TPre:      tPre2,
+		ObjType:   "CC",
+		File:      file2,
+		Line:      line2,
+	}
+
+	s.results.Result(logging.CRITICAL, logging.PCloseOnClosed,
+		"select", []logging.ResultElem{arg1}, "close", []logging.ResultElem{arg2})
+}
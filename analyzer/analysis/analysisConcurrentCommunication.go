@@ -5,13 +5,13 @@ import (
 	"analyzer/logging"
 )
 
-func checkForConcurrentRecv(routine int, id int, tID string, vc map[int]clock.VectorClock, tPost int) {
-	for r, elem := range lastRecvRoutine {
+func (s *State) checkForConcurrentRecv(routine int, id int, tID string, vc map[int]clock.VectorClock, tPost int) {
+	for r, elem := range s.lastRecvRoutine {
 		if r == routine {
 			continue
 		}
 
-		if elem[id].Vc.GetClock() == nil {
+		if elem[id].Vc.IsNil() {
 			continue
 		}
 
@@ -24,7 +24,7 @@ func checkForConcurrentRecv(routine int, id int, tID string, vc map[int]clock.Ve
 				return
 			}
 
-			file2, line2, tPre2, err := infoFromTID(lastRecvRoutine[r][id].TID)
+			file2, line2, tPre2, err := infoFromTID(s.lastRecvRoutine[r][id].TID)
 
 			arg1 := logging.TraceElementResult{
 				RoutineID: routine,
@@ -44,16 +44,16 @@ func checkForConcurrentRecv(routine int, id int, tID string, vc map[int]clock.Ve
 				Line:      line2,
 			}
 
-			logging.Result(logging.WARNING, logging.AConcurrentRecv,
+			s.results.Result(logging.WARNING, logging.AConcurrentRecv,
 				"recv", []logging.ResultElem{arg1}, "recv", []logging.ResultElem{arg2})
 		}
 	}
 
 	if tPost != 0 {
-		if _, ok := lastRecvRoutine[routine]; !ok {
-			lastRecvRoutine[routine] = make(map[int]VectorClockTID)
+		if _, ok := s.lastRecvRoutine[routine]; !ok {
+			s.lastRecvRoutine[routine] = make(map[int]VectorClockTID)
 		}
 
-		lastRecvRoutine[routine][id] = VectorClockTID{vc[routine].Copy(), tID, routine}
+		s.lastRecvRoutine[routine][id] = VectorClockTID{vc[routine].Copy(), tID, routine}
 	}
 }
@@ -0,0 +1,95 @@
+package analysis
+
+import (
+	"analyzer/clock"
+	"analyzer/logging"
+)
+
+/*
+ * Check for lost wakeups on conditional variables. Call when all elements have
+ * been processed.
+ * A lost wakeup is possible, if a signal or broadcast was executed while no
+ * wait on the same conditional variable was waiting and a later wait is
+ * concurrent to the signal or broadcast. If the wait had started before the
+ * signal, it would have been woken up.
+ */
+func (s *State) CheckForLostWakeup() {
+	for id, releases := range s.condRelease {
+		for _, release := range releases {
+			if s.condHasWaiter(id, release.tPre) {
+				continue
+			}
+
+			for _, wait := range s.condWait[id] {
+				if wait.tPre < release.tPre {
+					continue
+				}
+
+				if clock.GetHappensBefore(release.vc, wait.vc) != clock.Concurrent {
+					continue
+				}
+
+				s.logLostWakeup(id, release, wait)
+			}
+		}
+	}
+}
+
+/*
+ * Check if a wait on the conditional variable was waiting at the given time
+ * Args:
+ *   id (int): The id of the conditional variable
+ *   time (int): The time
+ * Returns:
+ *   bool: true if a wait was waiting, false otherwise
+ */
+func (s *State) condHasWaiter(id int, time int) bool {
+	for _, wait := range s.condWait[id] {
+		if wait.tPre < time && (wait.tPost == 0 || wait.tPost > time) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Log a lost wakeup
+ * Args:
+ *   id (int): The id of the conditional variable
+ *   release (condOp): The signal or broadcast
+ *   wait (condOp): The wait
+ */
+func (s *State) logLostWakeup(id int, release condOp, wait condOp) {
+	file1, line1, tPre1, err := infoFromTID(release.tID) // signal/broadcast
+	if err != nil {
+		logging.Debug(err.Error(), logging.ERROR)
+		return
+	}
+
+	file2, line2, tPre2, err := infoFromTID(wait.tID) // wait
+	if err != nil {
+		logging.Debug(err.Error(), logging.ERROR)
+		return
+	}
+
+	arg1 := logging.TraceElementResult{ // signal/broadcast
+		RoutineID: release.routine,
+		ObjID:     id,
+		TPre:      tPre1,
+		ObjType:   release.objType,
+		File:      file1,
+		Line:      line1,
+	}
+
+	arg2 := logging.TraceElementResult{ // wait
+		RoutineID: wait.routine,
+		ObjID:     id,
+		TPre:      tPre2,
+		ObjType:   "NW",
+		File:      file2,
+		Line:      line2,
+	}
+
+	s.results.Result(logging.CRITICAL, logging.PLostWakeup,
+		"signal", []logging.ResultElem{arg1}, "wait", []logging.ResultElem{arg2})
+}
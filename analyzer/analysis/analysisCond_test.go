@@ -0,0 +1,54 @@
+package analysis_test
+
+import (
+	"testing"
+
+	"analyzer/logging"
+	"analyzer/trace"
+)
+
+/*
+ * The signal in the new routine is executed before the wait in the main
+ * routine starts, but is concurrent to it, so the wakeup is lost
+ */
+func TestLostWakeup(t *testing.T) {
+	found := runAnalysis(t, 2, []string{"lostWakeup"}, func(tr *trace.Trace) []error {
+		return []error{
+			tr.AddTraceElementFork(1, "1", "2", "/a/main.go:20"),
+			tr.AddTraceElementCond(2, "3", "3", "5", "S", "/a/main.go:10"),
+			tr.AddTraceElementCond(1, "5", "0", "5", "W", "/a/main.go:22"),
+		}
+	})
+
+	expectResult(t, found, logging.PLostWakeup, "/a/main.go:10", "/a/main.go:22")
+}
+
+/*
+ * The signal happens before the wait, it can therefore not wake up the wait
+ * in any execution
+ */
+func TestLostWakeupOrdered(t *testing.T) {
+	found := runAnalysis(t, 1, []string{"lostWakeup"}, func(tr *trace.Trace) []error {
+		return []error{
+			tr.AddTraceElementCond(1, "1", "1", "5", "S", "/a/main.go:10"),
+			tr.AddTraceElementCond(1, "3", "0", "5", "W", "/a/main.go:11"),
+		}
+	})
+
+	expectNoResult(t, found)
+}
+
+/*
+ * The wait is already waiting when the signal is executed and is woken up by it
+ */
+func TestLostWakeupWithWaiter(t *testing.T) {
+	found := runAnalysis(t, 2, []string{"lostWakeup"}, func(tr *trace.Trace) []error {
+		return []error{
+			tr.AddTraceElementFork(1, "1", "2", "/a/main.go:20"),
+			tr.AddTraceElementCond(1, "3", "6", "5", "W", "/a/main.go:21"),
+			tr.AddTraceElementCond(2, "4", "4", "5", "S", "/a/main.go:10"),
+		}
+	})
+
+	expectNoResult(t, found)
+}
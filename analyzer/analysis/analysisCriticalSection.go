@@ -0,0 +1,137 @@
+package analysis
+
+import (
+	"analyzer/logging"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+ * Check if a blocking operation is executed while the routine holds locks.
+ * For each combination of the position of the operation and the positions of
+ * the held locks, only the operation with the longest blocking time is stored.
+ * The results are logged with LogBlockingInCriticalSection.
+ * The wait of a conditional variable releases and reacquires its lock
+ * inside of sync/cond.go. This lock is not counted for the wait.
+ * Args:
+ *   routine (int): The routine id
+ *   id (int): The id of the object of the operation
+ *   tID (string): The id of the trace element, contains the position and the tpre
+ *   objType (string): The type of the operation, e.g. CS
+ *   tPre (int): The tpre of the operation
+ *   tPost (int): The tpost of the operation, 0 if the operation did not return
+ */
+func (s *State) CheckForBlockingInCriticalSection(routine int, id int, tID string, objType string, tPre int, tPost int) {
+	if len(s.lockSet[routine]) == 0 {
+		return
+	}
+
+	blocked := -1
+	if tPost != 0 {
+		blocked = tPost - tPre
+	}
+
+	lockIDs := make([]int, 0, len(s.lockSet[routine]))
+	for lockID := range s.lockSet[routine] {
+		lockIDs = append(lockIDs, lockID)
+	}
+	sort.Ints(lockIDs)
+
+	locks := make([]logging.ResultElem, 0, len(lockIDs))
+	lockPos := make([]string, 0, len(lockIDs))
+	for _, lockID := range lockIDs {
+		lockTID := s.lockSet[routine][lockID]
+		file, line, lockTPre, err := infoFromTID(lockTID)
+		if err != nil {
+			logging.Debug(err.Error(), logging.ERROR)
+			return
+		}
+
+		if objType == "NW" && isCondRelock(file, lockTPre, tPre, tPost) {
+			continue
+		}
+
+		lockType := "ML"
+		if s.lockSetRLock[routine][lockID] {
+			lockType = "MR"
+		}
+
+		locks = append(locks, logging.TraceElementResult{
+			RoutineID: routine,
+			ObjID:     lockID,
+			TPre:      lockTPre,
+			ObjType:   lockType,
+			File:      file,
+			Line:      line,
+		})
+		lockPos = append(lockPos, file+":"+strconv.Itoa(line))
+	}
+
+	if len(locks) == 0 {
+		return
+	}
+
+	pos := strings.Split(tID, "@")[0]
+	key := pos + "|" + strings.Join(lockPos, ";")
+
+	if old, ok := s.blockingInCS[key]; ok && (old.blocked == -1 || (blocked != -1 && old.blocked >= blocked)) {
+		return
+	}
+
+	s.blockingInCS[key] = blockingOp{routine, id, tID, objType, blocked, locks}
+}
+
+/*
+ * Check if a lock is the reacquire of the lock of a conditional variable by
+ * its wait, i.e. a lock in sync/cond.go between the start and end of the wait
+ * Args:
+ *   file (string): The file of the lock
+ *   lockTPre (int): The tpre of the lock
+ *   tPre (int): The tpre of the wait
+ *   tPost (int): The tpost of the wait, 0 if the wait did not return
+ * Returns:
+ *   bool: true if the lock was acquired by the wait
+ */
+func isCondRelock(file string, lockTPre int, tPre int, tPost int) bool {
+	if !strings.HasSuffix(file, "sync/cond.go") {
+		return false
+	}
+	return lockTPre > tPre && (tPost == 0 || lockTPre < tPost)
+}
+
+/*
+ * Log the blocking operations in critical sections as warnings. Call when
+ * all elements have been processed.
+ */
+func (s *State) LogBlockingInCriticalSection() {
+	keys := make([]string, 0, len(s.blockingInCS))
+	for key := range s.blockingInCS {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		op := s.blockingInCS[key]
+
+		file, line, tPre, err := infoFromTID(op.tID)
+		if err != nil {
+			logging.Debug(err.Error(), logging.ERROR)
+			continue
+		}
+
+		arg1 := logging.TraceElementResult{
+			RoutineID: op.routine,
+			ObjID:     op.id,
+			TPre:      tPre,
+			ObjType:   op.objType,
+			File:      file,
+			Line:      line,
+		}
+
+		arg2 := logging.BlockedTimeResult{Time: op.blocked}
+
+		s.results.Result(logging.WARNING, logging.WBlockInCriticalSection,
+			"operation", []logging.ResultElem{arg1, arg2}, "locks", op.locks)
+	}
+}
@@ -0,0 +1,42 @@
+package analysis_test
+
+import (
+	"testing"
+
+	"analyzer/logging"
+	"analyzer/trace"
+)
+
+/*
+ * The send on the channel blocks while the mutex is held
+ */
+func TestBlockingInCriticalSection(t *testing.T) {
+	found := runAnalysis(t, 2, []string{"blockingInCriticalSection"}, func(tr *trace.Trace) []error {
+		return []error{
+			tr.AddTraceElementFork(1, "1", "2", "/a/main.go:20"),
+			tr.AddTraceElementMutex(1, "2", "2", "3", "-", "L", "t", "/a/main.go:21"),
+			tr.AddTraceElementChannel(1, "3", "8", "4", "S", "f", "1", "0", "/a/main.go:22"),
+			tr.AddTraceElementMutex(1, "9", "9", "3", "-", "U", "t", "/a/main.go:23"),
+			tr.AddTraceElementChannel(2, "7", "8", "4", "R", "f", "1", "0", "/a/main.go:10"),
+		}
+	})
+
+	expectResult(t, found, logging.WBlockInCriticalSection, "/a/main.go:22", "/a/main.go:21", "B:5")
+}
+
+/*
+ * The send on the channel is executed after the mutex was released
+ */
+func TestBlockingOutsideCriticalSection(t *testing.T) {
+	found := runAnalysis(t, 2, []string{"blockingInCriticalSection"}, func(tr *trace.Trace) []error {
+		return []error{
+			tr.AddTraceElementFork(1, "1", "2", "/a/main.go:20"),
+			tr.AddTraceElementMutex(1, "2", "2", "3", "-", "L", "t", "/a/main.go:21"),
+			tr.AddTraceElementMutex(1, "3", "3", "3", "-", "U", "t", "/a/main.go:22"),
+			tr.AddTraceElementChannel(1, "4", "8", "4", "S", "f", "1", "0", "/a/main.go:23"),
+			tr.AddTraceElementChannel(2, "7", "8", "4", "R", "f", "1", "0", "/a/main.go:10"),
+		}
+	})
+
+	expectNoResult(t, found)
+}
@@ -82,21 +82,14 @@ func (node *lockGraphNode) toStringTraverse(depth int) string {
 	return result
 }
 
-func printTrees() {
-	for routine, node := range lockGraphs {
+func (s *State) printTrees() {
+	for routine, node := range s.lockGraphs {
 		println("Routine " + strconv.Itoa(routine))
 		node.print()
 	}
 
 }
 
-// currend node for each routine
-var currentNode = make(map[int][]*lockGraphNode) // routine -> []*lockGraphNode
-// lock graph for each routine
-var lockGraphs = make(map[int]*lockGraphNode) // routine -> lockGraphNode
-// all nodes for each id
-var nodesPerID = make(map[int]map[int][]*lockGraphNode) // id -> routine -> []*lockGraphNode
-
 /*
  * Add the lock to the currently hold locks
  * Add the node to the lock tree
@@ -108,30 +101,30 @@ var nodesPerID = make(map[int]map[int][]*lockGraphNode) // id -> routine -> []*l
  *   vc (VectorClock): The vector clock of the lock event
  *   tPre (int): The timestamp at the end of the event
  */
-func AnalysisCyclickDeadlockMutexLock(id int, tID string, routine int, rw bool, rLock bool, vc clock.VectorClock, tPost int) {
+func (s *State) AnalysisCyclickDeadlockMutexLock(id int, tID string, routine int, rw bool, rLock bool, vc clock.VectorClock, tPost int) {
 	if tPost == 0 {
 		return
 	}
 
 	// create new lock tree if it does not exist yet
-	if _, ok := lockGraphs[routine]; !ok {
-		lockGraphs[routine] = newLockGraph(routine)
-		currentNode[routine] = []*lockGraphNode{lockGraphs[routine]}
+	if _, ok := s.lockGraphs[routine]; !ok {
+		s.lockGraphs[routine] = newLockGraph(routine)
+		s.currentNode[routine] = []*lockGraphNode{s.lockGraphs[routine]}
 	}
 
 	// create empty map for nodesPerID if it does not exist yet
-	if _, ok := nodesPerID[id]; !ok {
-		nodesPerID[id] = make(map[int][]*lockGraphNode)
+	if _, ok := s.nodesPerID[id]; !ok {
+		s.nodesPerID[id] = make(map[int][]*lockGraphNode)
 	}
-	if _, ok := nodesPerID[id][routine]; !ok {
-		nodesPerID[id][routine] = []*lockGraphNode{}
+	if _, ok := s.nodesPerID[id][routine]; !ok {
+		s.nodesPerID[id][routine] = []*lockGraphNode{}
 	}
 
 	// add the lock element to the lock tree
 	// update the current lock
-	node := currentNode[routine][len(currentNode[routine])-1].addChild(id, tID, rw, rLock, vc.Copy(), getCurrentLockSet(routine))
-	currentNode[routine] = append(currentNode[routine], node)
-	nodesPerID[id][routine] = append(nodesPerID[id][routine], node)
+	node := s.currentNode[routine][len(s.currentNode[routine])-1].addChild(id, tID, rw, rLock, vc.Copy(), s.getCurrentLockSet(routine))
+	s.currentNode[routine] = append(s.currentNode[routine], node)
+	s.nodesPerID[id][routine] = append(s.nodesPerID[id][routine], node)
 }
 
 /*
@@ -141,14 +134,14 @@ func AnalysisCyclickDeadlockMutexLock(id int, tID string, routine int, rw bool,
  *   routine (int): The id of the routine
  *   tPost (int): The timestamp at the end of the event
  */
-func AnalysisCyclicDeadlockMutexUnLock(id int, routine int, tPost int) {
+func (s *State) AnalysisCyclicDeadlockMutexUnLock(id int, routine int, tPost int) {
 	if tPost == 0 {
 		return
 	}
 
-	for i := len(currentNode[routine]) - 1; i >= 0; i-- {
-		if currentNode[routine][i].id == id {
-			currentNode[routine] = currentNode[routine][:i]
+	for i := len(s.currentNode[routine]) - 1; i >= 0; i-- {
+		if s.currentNode[routine][i].id == id {
+			s.currentNode[routine] = s.currentNode[routine][:i]
 			return
 		}
 	}
@@ -158,9 +151,9 @@ func AnalysisCyclicDeadlockMutexUnLock(id int, routine int, tPost int) {
  * Check if the lock graph created by connecting all lock trees is cyclic
  * If there are cycles, log the results
  */
-func CheckForCyclicDeadlock() {
-	findOutsideConnections()
-	found, cycles := findCycles() // find all cycles in the lock graph
+func (s *State) CheckForCyclicDeadlock() {
+	s.findOutsideConnections()
+	found, cycles := s.findCycles() // find all cycles in the lock graph
 
 	if !found { // no cycles
 		return
@@ -189,9 +182,9 @@ func CheckForCyclicDeadlock() {
  * Find all connections between lock trees for different routines
  * A connection exists iff both nodes have the same id but different routines
  */
-func findOutsideConnections() {
-	for _, tree := range lockGraphs { // for each lock tree
-		traverseTreeAndAddOutsideConnections(tree)
+func (s *State) findOutsideConnections() {
+	for _, tree := range s.lockGraphs { // for each lock tree
+		s.traverseTreeAndAddOutsideConnections(tree)
 	}
 }
 
@@ -201,12 +194,12 @@ func findOutsideConnections() {
  * Args:
  *   node (*lockGraphNode): The node to start the traversal
  */
-func traverseTreeAndAddOutsideConnections(node *lockGraphNode) {
+func (s *State) traverseTreeAndAddOutsideConnections(node *lockGraphNode) {
 	if node == nil {
 		return
 	}
 
-	for routine, outsideNodes := range nodesPerID[node.id] {
+	for routine, outsideNodes := range s.nodesPerID[node.id] {
 		if routine == node.routine {
 			continue
 		}
@@ -217,7 +210,7 @@ func traverseTreeAndAddOutsideConnections(node *lockGraphNode) {
 	}
 
 	for _, child := range node.children {
-		traverseTreeAndAddOutsideConnections(child)
+		s.traverseTreeAndAddOutsideConnections(child)
 	}
 }
 
@@ -229,9 +222,9 @@ func traverseTreeAndAddOutsideConnections(node *lockGraphNode) {
  *  (bool): True if there are cycles
  *  ([][]*lockGraphNode): A list of cycles, where each cycle is a list of nodes
  */
-func findCycles() (bool, [][]*lockGraphNode) {
+func (s *State) findCycles() (bool, [][]*lockGraphNode) {
 	cycles := [][]*lockGraphNode{}
-	for routine, tree := range lockGraphs { // for each lock tree
+	for routine, tree := range s.lockGraphs { // for each lock tree
 		findCyclesDFS(tree, &([]*lockGraphNode{}), &cycles, routine, nil)
 	}
 
@@ -479,9 +472,9 @@ func isCycleValidGate(cycle []*lockGraphNode) bool {
  * Returns:
  *   ([]int): The current lock set of the routine
  */
-func getCurrentLockSet(routine int) []int {
-	ls := make([]int, len(currentNode[routine]))
-	for id, _ := range lockSet[routine] {
+func (s *State) getCurrentLockSet(routine int) []int {
+	ls := make([]int, len(s.currentNode[routine]))
+	for id, _ := range s.lockSet[routine] {
 		ls = append(ls, id)
 	}
 	return ls
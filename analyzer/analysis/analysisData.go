@@ -1,6 +1,9 @@
 package analysis
 
-import "analyzer/clock"
+import (
+	"analyzer/clock"
+	"analyzer/logging"
+)
 
 type VectorClockTID struct {
 	Vc      clock.VectorClock
@@ -27,6 +30,66 @@ type VectorClockTID3 struct {
 	Val     int
 }
 
+type waitVC struct {
+	routine int               // routine of the wait
+	tID     string            // position and tpre of the wait
+	vcPre   clock.VectorClock // vector clock when the wait is called
+	vcPost  clock.VectorClock // vector clock after the wait returned
+	done    bool              // true: the wait returned, false: the wait blocked
+}
+
+type wgCount struct {
+	clock int // value of the routine in its own vector clock after the add
+	sum   int // sum of the deltas of all adds of the routine up to the add
+}
+
+type wgSummary struct {
+	adds     map[int][]wgCount       // routine -> adds of the routine in program order
+	dones    int                     // sum of the deltas of the processed dones (positive)
+	lastAdd  map[int]VectorClockTID3 // routine -> latest add, before which the counter may be zero
+	lastWait map[int]waitVC          // routine -> latest wait
+}
+
+type condOp struct {
+	routine int               // routine of the operation
+	tID     string            // position and tpre of the operation
+	tPre    int               // tpre of the operation
+	tPost   int               // tpost of the operation, 0 if a wait did not return
+	objType string            // NW for wait, NS for signal, NB for broadcast
+	vc      clock.VectorClock // vector clock at the start of the operation
+}
+
+type blockingOp struct {
+	routine int                  // routine of the operation
+	id      int                  // id of the object of the operation
+	tID     string               // position and tpre of the operation
+	objType string               // type of the operation, e.g. CS
+	blocked int                  // tpost - tpre, -1 if the operation did not return
+	locks   []logging.ResultElem // acquires of the locks held by the routine
+}
+
+type atomicOp struct {
+	routine int               // routine of the operation
+	tID     string            // position and tpre of the operation
+	objType string            // type of the operation, e.g. AL
+	vcPre   clock.VectorClock // vector clock before the operation, only for writes
+	vcPost  clock.VectorClock // vector clock after the operation
+}
+
+type checkThenAct struct {
+	check atomicOp // load of the atomic variable
+	act   atomicOp // store, add or swap of the same routine, that follows the load
+}
+
+type memoryOp struct {
+	routine  int               // routine of the access
+	tID      string            // position and tpost of the access
+	objType  string            // type of the access, DR or DW
+	tPost    int               // tpost of the access
+	segStart int               // time of the last synchronization of the routine before the access
+	vc       clock.VectorClock // vector clock after the access
+}
+
 type allSelectCase struct {
 	selectID int            // select id
 	chanID   int            // channel id
@@ -36,57 +99,169 @@ type allSelectCase struct {
 	partner  bool           // true: partner found, false: no partner found
 }
 
-var (
+/*
+ * State contains the state of the analysis of one trace. Each trace is
+ * analyzed with its own state, so that multiple traces can be analyzed at
+ * the same time.
+ */
+type State struct {
 	// analysis cases to run
-	analysisCases = make(map[string]bool)
+	analysisCases map[string]bool
+
+	// results of the analysis
+	results *logging.Results
 
 	// vc of close on channel
-	closeData = make(map[int]VectorClockTID3) // id -> vcTID3 val = objID
+	closeData map[int]VectorClockTID3 // id -> vcTID3 val = objID
+
+	// selects with default, whose default case was chosen, used to check
+	// if another routine could also close the channel
+	defaultSelect map[int]map[int]string // routine -> id -> tID
+	closeGuard    map[int]string         // id -> tID of the select before the close
 
 	// last receive for each routine and each channel
-	lastRecvRoutine = make(map[int]map[int]VectorClockTID) // routine -> id -> vcTID
+	lastRecvRoutine map[int]map[int]VectorClockTID // routine -> id -> vcTID
 
 	// most recent send, used for detection of send on closed
-	hasSend        = make(map[int]bool)                    // id -> bool
-	mostRecentSend = make(map[int]map[int]VectorClockTID3) // routine -> id -> vcTID
+	hasSend        map[int]bool                    // id -> bool
+	mostRecentSend map[int]map[int]VectorClockTID3 // routine -> id -> vcTID
 
 	// most recent send, used for detection of received on closed
-	hasReceived       = make(map[int]bool)                    // id -> bool
-	mostRecentReceive = make(map[int]map[int]VectorClockTID3) // routine -> id -> vcTID3, val = objID
+	hasReceived       map[int]bool                    // id -> bool
+	mostRecentReceive map[int]map[int]VectorClockTID3 // routine -> id -> vcTID3, val = objID
 
 	// vector clock for each buffer place in vector clock
 	// the map key is the channel id. The slice is used for the buffer positions
-	bufferedVCs = make(map[int]([]bufferedVC))
+	bufferedVCs map[int]([]bufferedVC)
 	// the current buffer position
-	bufferedVCsCount = make(map[int]int)
+	bufferedVCsCount map[int]int
+
+	// held back buffered channel operations
+	holdSend []holdObj
+	holdRecv []holdObj
 
 	// add on waitGroup
-	wgAdd = make(map[int]map[int][]VectorClockTID) // id -> routine -> []vcTID
+	wgAdd map[int]map[int][]VectorClockTID // id -> routine -> []vcTID
 
 	// done on waitGroup
-	wgDone = make(map[int]map[int][]VectorClockTID) // id -> routine -> []vcTID
+	wgDone map[int]map[int][]VectorClockTID // id -> routine -> []vcTID
 
 	// wait on waitGroup
-	// wgWait = make(map[int]map[int][]VectorClockTID) // id -> routine -> []vcTID
+	// wgWait map[int]map[int][]VectorClockTID // id -> routine -> []vcTID
+
+	// summary of the adds, dones and waits on a waitGroup, used for the detection of misuse
+	wgMisuse map[int]*wgSummary // id -> summary
+
+	// longest blocking operation in a critical section
+	blockingInCS map[string]blockingOp // position of the operation and the held locks -> operation
+
+	// vector clock for each wait group
+	wg map[int]clock.VectorClock
 
 	// last acquire on mutex for each routine
-	lockSet                = make(map[int]map[int]string)         // routine -> id -> string
-	mostRecentAcquire      = make(map[int]map[int]VectorClockTID) // routine -> id -> vcTID  // TODO: do we need to store the operation?
-	mostRecentAcquireTotal = make(map[int]VectorClockTID3)        // id -> vcTID
+	lockSet                map[int]map[int]string         // routine -> id -> string
+	lockSetRLock           map[int]map[int]bool           // routine -> id -> true if the lock in lockSet was acquired by rlock
+	mostRecentAcquire      map[int]map[int]VectorClockTID // routine -> id -> vcTID  // TODO: do we need to store the operation?
+	mostRecentAcquireTotal map[int]VectorClockTID3        // id -> vcTID
 
 	// vector clocks for last release times
-	relW = make(map[int]clock.VectorClock) // id -> vc
-	relR = make(map[int]clock.VectorClock) // id -> vc
+	relW map[int]clock.VectorClock // id -> vc
+	relR map[int]clock.VectorClock // id -> vc
+
+	// vector clocks for last write times
+	lw map[int]clock.VectorClock
+
+	// vector clocks for the successful do
+	oSuc map[int]clock.VectorClock
+
+	// last routine that signaled or broadcasted a conditional variable
+	lastCondRelease map[int]int // -> id -> routine
+
+	// signal, broadcast and wait on conditional variables, used for the detection of lost wakeups
+	condRelease map[int][]condOp // id -> signals and broadcasts
+	condWait    map[int][]condOp // id -> waits
+
+	// operations on atomic variables, used for the detection of atomicity violations
+	atomicLastLoad     map[int]map[int]atomicOp // id -> routine -> last load, that was not followed by a write of the routine
+	atomicCheckThenAct map[int][]checkThenAct   // id -> load followed by a write of the same routine
+	atomicWrites       map[int][]atomicOp       // id -> store, add, swap and compare and swap
+	atomicDependent    map[string]bool          // position of load|position of write -> write depends on the load
+
+	// last reads and writes on memory, used for the detection of data races
+	memoryLastRead  map[int]map[int]memoryOp // addr -> routine -> last read
+	memoryLastWrite map[int]map[int]memoryOp // addr -> routine -> last write
+	memoryGC        map[int]int              // addr -> gc cycle of the stored accesses
+	memorySyncTimes map[int][]int            // routine -> times of the synchronizations of the routine
+	dataRaceFound   map[string]struct{}      // positions of the reported data races
 
 	// for leak check
-	leakingChannels = make(map[int][]VectorClockTID2) // id -> vcTID
+	leakingChannels map[int][]VectorClockTID2 // id -> vcTID
 
 	// for check of select without partner
 	// store all select cases
-	selectCases = make([]allSelectCase, 0)
-)
+	selectCases []allSelectCase
+
+	// currend node for each routine
+	currentNode map[int][]*lockGraphNode // routine -> []*lockGraphNode
+	// lock graph for each routine
+	lockGraphs map[int]*lockGraphNode // routine -> lockGraphNode
+	// all nodes for each id
+	nodesPerID map[int]map[int][]*lockGraphNode // id -> routine -> []*lockGraphNode
+}
 
-// InitAnalysis initializes the analysis cases
-func InitAnalysis(analysisCasesMap map[string]bool) {
-	analysisCases = analysisCasesMap
+/*
+ * Create a new analysis state
+ * Args:
+ *   analysisCases (map[string]bool): The analysis cases to run
+ *   results (*logging.Results): The results, the found bugs are added to
+ * Returns:
+ *   *State: The new state
+ */
+func NewState(analysisCases map[string]bool, results *logging.Results) *State {
+	return &State{
+		analysisCases:          analysisCases,
+		results:                results,
+		closeData:              make(map[int]VectorClockTID3),
+		defaultSelect:          make(map[int]map[int]string),
+		closeGuard:             make(map[int]string),
+		lastRecvRoutine:        make(map[int]map[int]VectorClockTID),
+		hasSend:                make(map[int]bool),
+		mostRecentSend:         make(map[int]map[int]VectorClockTID3),
+		hasReceived:            make(map[int]bool),
+		mostRecentReceive:      make(map[int]map[int]VectorClockTID3),
+		bufferedVCs:            make(map[int]([]bufferedVC)),
+		bufferedVCsCount:       make(map[int]int),
+		holdSend:               make([]holdObj, 0),
+		holdRecv:               make([]holdObj, 0),
+		wgAdd:                  make(map[int]map[int][]VectorClockTID),
+		wgDone:                 make(map[int]map[int][]VectorClockTID),
+		wgMisuse:               make(map[int]*wgSummary),
+		blockingInCS:           make(map[string]blockingOp),
+		wg:                     make(map[int]clock.VectorClock),
+		lockSet:                make(map[int]map[int]string),
+		lockSetRLock:           make(map[int]map[int]bool),
+		mostRecentAcquire:      make(map[int]map[int]VectorClockTID),
+		mostRecentAcquireTotal: make(map[int]VectorClockTID3),
+		relW:                   make(map[int]clock.VectorClock),
+		relR:                   make(map[int]clock.VectorClock),
+		lw:                     make(map[int]clock.VectorClock),
+		oSuc:                   make(map[int]clock.VectorClock),
+		lastCondRelease:        make(map[int]int),
+		condRelease:            make(map[int][]condOp),
+		condWait:               make(map[int][]condOp),
+		atomicLastLoad:         make(map[int]map[int]atomicOp),
+		atomicCheckThenAct:     make(map[int][]checkThenAct),
+		atomicWrites:           make(map[int][]atomicOp),
+		atomicDependent:        make(map[string]bool),
+		memoryLastRead:         make(map[int]map[int]memoryOp),
+		memoryLastWrite:        make(map[int]map[int]memoryOp),
+		memoryGC:               make(map[int]int),
+		memorySyncTimes:        make(map[int][]int),
+		dataRaceFound:          make(map[string]struct{}),
+		leakingChannels:        make(map[int][]VectorClockTID2),
+		selectCases:            make([]allSelectCase, 0),
+		currentNode:            make(map[int][]*lockGraphNode),
+		lockGraphs:             make(map[int]*lockGraphNode),
+		nodesPerID:             make(map[int]map[int][]*lockGraphNode),
+	}
 }
@@ -0,0 +1,196 @@
+package analysis
+
+import (
+	"analyzer/clock"
+	"analyzer/logging"
+	"sort"
+)
+
+// maximum number of routines, whose last read and last write are stored
+// for each address. If more routines access the address, the oldest access
+// is removed.
+const maxMemoryRoutinesPerAddress = 64
+
+/*
+ * Store the time of a synchronization of a routine. The synchronizations
+ * separate the memory accesses of the routine into segments. Call for each
+ * executed element, that is not a memory access, in the order of the analysis.
+ * Args:
+ *   routine (int): The routine id
+ *   time (int): The time of the synchronization
+ */
+func (s *State) UpdateSyncTime(routine int, time int) {
+	s.memorySyncTimes[routine] = append(s.memorySyncTimes[routine], time)
+}
+
+/*
+ * Check if a read or write on memory is part of a data race and store it for
+ * the following accesses.
+ * Two accesses on the same address by different routines, at least one of
+ * them a write, are a data race, if they are concurrent by the happens
+ * before relation. Races, where both accesses were executed in overlapping
+ * segments of their routines, i.e. between the same synchronizations, can
+ * already be observed in the recorded run, e.g. by the race detector of Go.
+ * Only the other races, where the accesses were executed far apart in the
+ * recorded run, are reported.
+ * For each routine, it is enough to compare with the last read and the last
+ * write of the routine, because an earlier access of the same routine, that
+ * is concurrent to the new access, implies that the last one is concurrent
+ * as well.
+ * For each combination of positions, only the first data race is reported.
+ * The address is the only identity of the memory. Heap memory can be reused
+ * for a new object after the garbage collector freed it. The stored accesses
+ * of an address are therefore discarded, if an access is executed in a later
+ * gc cycle. Accesses on the stack are not recorded.
+ * Args:
+ *   routine (int): The routine id
+ *   addr (int): The address of the access
+ *   gc (int): The number of completed gc cycles at the access
+ *   tID (string): The id of the trace element, contains the position and the tpost
+ *   objType (string): The type of the access, DR or DW
+ *   tPost (int): The time of the access
+ *   vc (VectorClock): The vector clock of the routine after the access
+ */
+func (s *State) CheckForDataRace(routine int, addr int, gc int, tID string, objType string,
+	tPost int, vc clock.VectorClock) {
+	op := memoryOp{routine, tID, objType, tPost, s.lastSyncTime(routine), vc.Copy()}
+	write := objType == "DW"
+
+	if prevGC, ok := s.memoryGC[addr]; ok && prevGC != gc {
+		delete(s.memoryLastRead, addr)
+		delete(s.memoryLastWrite, addr)
+	}
+	s.memoryGC[addr] = gc
+
+	others := make([]memoryOp, 0)
+	for _, prev := range s.memoryLastWrite[addr] {
+		others = append(others, prev)
+	}
+	if write {
+		for _, prev := range s.memoryLastRead[addr] {
+			others = append(others, prev)
+		}
+	}
+	sort.Slice(others, func(i, j int) bool {
+		return others[i].tID < others[j].tID
+	})
+
+	for _, prev := range others {
+		if prev.routine == routine {
+			continue
+		}
+
+		if clock.GetHappensBefore(prev.vc, op.vc) == clock.Before {
+			continue
+		}
+
+		if s.segmentsOverlap(prev, op) {
+			continue
+		}
+
+		key := positionFromTID(prev.tID) + "|" + positionFromTID(op.tID)
+		if positionFromTID(op.tID) < positionFromTID(prev.tID) {
+			key = positionFromTID(op.tID) + "|" + positionFromTID(prev.tID)
+		}
+		if _, ok := s.dataRaceFound[key]; ok {
+			continue
+		}
+		s.dataRaceFound[key] = struct{}{}
+
+		s.logDataRace(addr, prev, op)
+	}
+
+	lastAccess := s.memoryLastRead
+	if write {
+		lastAccess = s.memoryLastWrite
+	}
+	if _, ok := lastAccess[addr]; !ok {
+		lastAccess[addr] = make(map[int]memoryOp)
+	}
+	if _, ok := lastAccess[addr][routine]; !ok && len(lastAccess[addr]) >= maxMemoryRoutinesPerAddress {
+		removeOldestMemoryOp(lastAccess[addr])
+	}
+	lastAccess[addr][routine] = op
+}
+
+/*
+ * Get the time of the last synchronization of a routine
+ * Args:
+ *   routine (int): The routine id
+ * Returns:
+ *   int: The time, 0 if the routine did not synchronize yet
+ */
+func (s *State) lastSyncTime(routine int) int {
+	times := s.memorySyncTimes[routine]
+	if len(times) == 0 {
+		return 0
+	}
+	return times[len(times)-1]
+}
+
+/*
+ * Check if the segments of two accesses overlapped in the recorded run. The
+ * segment of an access lasts from the last synchronization of its routine
+ * before the access until the first synchronization after it. Must be called
+ * when the later access is processed.
+ * Args:
+ *   prev (memoryOp): The earlier access
+ *   op (memoryOp): The later access
+ * Returns:
+ *   bool: true if the segments overlapped
+ */
+func (s *State) segmentsOverlap(prev memoryOp, op memoryOp) bool {
+	times := s.memorySyncTimes[prev.routine]
+	next := sort.SearchInts(times, prev.tPost+1)
+	if next == len(times) {
+		// the routine of prev did not synchronize since prev
+		return true
+	}
+	return op.segStart < times[next]
+}
+
+/*
+ * Remove the access with the smallest time from the accesses of an address
+ * Args:
+ *   accesses (map[int]memoryOp): routine -> last access
+ */
+func removeOldestMemoryOp(accesses map[int]memoryOp) {
+	oldest := -1
+	for routine, op := range accesses {
+		if oldest == -1 || op.tPost < accesses[oldest].tPost {
+			oldest = routine
+		}
+	}
+	delete(accesses, oldest)
+}
+
+/*
+ * Log a data race
+ * Args:
+ *   addr (int): The address of the accesses
+ *   first (memoryOp): The access, that was executed first
+ *   second (memoryOp): The access, that was executed second
+ */
+func (s *State) logDataRace(addr int, first memoryOp, second memoryOp) {
+	args := make([]logging.TraceElementResult, 0, 2)
+
+	for _, op := range []memoryOp{first, second} {
+		file, line, tPost, err := infoFromTID(op.tID)
+		if err != nil {
+			logging.Debug(err.Error(), logging.ERROR)
+			return
+		}
+
+		args = append(args, logging.TraceElementResult{
+			RoutineID: op.routine,
+			ObjID:     addr,
+			TPre:      tPost,
+			ObjType:   op.objType,
+			File:      file,
+			Line:      line,
+		})
+	}
+
+	s.results.Result(logging.CRITICAL, logging.PDataRace,
+		"access", []logging.ResultElem{args[0]}, "access", []logging.ResultElem{args[1]})
+}
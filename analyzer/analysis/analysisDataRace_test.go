@@ -0,0 +1,61 @@
+package analysis_test
+
+import (
+	"testing"
+
+	"analyzer/logging"
+	"analyzer/trace"
+)
+
+/*
+ * Build a trace, where routine 1 writes and routine 2 reads the same
+ * address. If separated is set, both routines synchronize on their own mutex
+ * between the accesses, so that the accesses do not overlap in the recorded run.
+ * Args:
+ *   gcRead (string): The gc cycle of the read
+ *   separated (bool): If the routines synchronize between the accesses
+ * Returns:
+ *   func(*trace.Trace) []error: The function, that builds the trace
+ */
+func dataRaceTrace(gcRead string, separated bool) func(tr *trace.Trace) []error {
+	return func(tr *trace.Trace) []error {
+		errs := []error{
+			tr.AddTraceElementFork(1, "2", "2", "/a/main.go:20"),
+			tr.AddTraceElementMemory(1, "3", "100", "W", "0", "/a/main.go:21"),
+			tr.AddTraceElementMemory(2, "8", "100", "R", gcRead, "/a/main.go:11"),
+		}
+		if separated {
+			errs = append(errs,
+				tr.AddTraceElementMutex(1, "4", "4", "5", "-", "L", "t", "/a/main.go:22"),
+				tr.AddTraceElementMutex(1, "5", "5", "5", "-", "U", "t", "/a/main.go:23"),
+				tr.AddTraceElementMutex(2, "6", "6", "6", "-", "L", "t", "/a/main.go:9"),
+				tr.AddTraceElementMutex(2, "7", "7", "6", "-", "U", "t", "/a/main.go:10"))
+		}
+		return errs
+	}
+}
+
+/*
+ * The accesses are concurrent and were separated by unrelated
+ * synchronizations in the recorded run
+ */
+func TestDataRacePredicted(t *testing.T) {
+	found := runAnalysis(t, 2, []string{"dataRace"}, dataRaceTrace("0", true))
+	expectResult(t, found, logging.PDataRace, "/a/main.go:21", "/a/main.go:11")
+}
+
+/*
+ * The accesses overlapped in the recorded run, the race is not predicted
+ */
+func TestDataRaceOverlapping(t *testing.T) {
+	found := runAnalysis(t, 2, []string{"dataRace"}, dataRaceTrace("0", false))
+	expectNoResult(t, found)
+}
+
+/*
+ * The read is executed in a later gc cycle, the address may have been reused
+ */
+func TestDataRaceAddressReuse(t *testing.T) {
+	found := runAnalysis(t, 2, []string{"dataRace"}, dataRaceTrace("1", true))
+	expectNoResult(t, found)
+}
@@ -19,7 +19,7 @@ import (
  *   opType (int): An identifier for the type of the operation (send = 0, recv = 1)
  *   buffered (bool): If the channel is buffered
  */
-func CheckForLeakChannelStuck(routineID int, objID int, vc clock.VectorClock, tID string, opType int,
+func (s *State) CheckForLeakChannelStuck(routineID int, objID int, vc clock.VectorClock, tID string, opType int,
 	buffered bool) {
 	logging.Debug("Checking channel for for leak channel", logging.INFO)
 
@@ -40,7 +40,7 @@ func CheckForLeakChannelStuck(routineID int, objID int, vc clock.VectorClock, tI
 		arg1 := logging.TraceElementResult{
 			RoutineID: routineID, ObjID: objID, TPre: tPre, ObjType: objType, File: file, Line: line}
 
-		logging.Result(logging.CRITICAL, logging.LNilChan,
+		s.results.Result(logging.CRITICAL, logging.LNilChan,
 			"Channel", []logging.ResultElem{arg1}, "", []logging.ResultElem{})
 
 		return
@@ -50,7 +50,7 @@ func CheckForLeakChannelStuck(routineID int, objID int, vc clock.VectorClock, tI
 	foundPartner := false
 
 	if opType == 0 { // send
-		for partnerRout, mrr := range mostRecentReceive {
+		for partnerRout, mrr := range s.mostRecentReceive {
 			if _, ok := mrr[objID]; ok {
 				if clock.GetHappensBefore(mrr[objID].Vc, vc) == clock.Concurrent {
 
@@ -75,7 +75,7 @@ func CheckForLeakChannelStuck(routineID int, objID int, vc clock.VectorClock, tI
 					arg2 := logging.TraceElementResult{
 						RoutineID: partnerRout, ObjID: objID, TPre: tPre2, ObjType: "CR", File: file2, Line: line2}
 
-					logging.Result(logging.CRITICAL, bugType,
+					s.results.Result(logging.CRITICAL, bugType,
 						"channel", []logging.ResultElem{arg1}, "partner", []logging.ResultElem{arg2})
 
 					foundPartner = true
@@ -83,7 +83,7 @@ func CheckForLeakChannelStuck(routineID int, objID int, vc clock.VectorClock, tI
 			}
 		}
 	} else if opType == 1 { // recv
-		for partnerRout, mrs := range mostRecentSend {
+		for partnerRout, mrs := range s.mostRecentSend {
 			if _, ok := mrs[objID]; ok {
 				if clock.GetHappensBefore(mrs[objID].Vc, vc) == clock.Concurrent {
 
@@ -108,7 +108,7 @@ func CheckForLeakChannelStuck(routineID int, objID int, vc clock.VectorClock, tI
 					arg2 := logging.TraceElementResult{
 						RoutineID: partnerRout, ObjID: objID, TPre: tPre2, ObjType: "CS", File: file2, Line: line2}
 
-					logging.Result(logging.CRITICAL, bugType,
+					s.results.Result(logging.CRITICAL, bugType,
 						"channel", []logging.ResultElem{arg1}, "partner", []logging.ResultElem{arg2})
 
 					foundPartner = true
@@ -119,7 +119,7 @@ func CheckForLeakChannelStuck(routineID int, objID int, vc clock.VectorClock, tI
 	}
 
 	if !foundPartner {
-		leakingChannels[objID] = append(leakingChannels[objID], VectorClockTID2{routineID, objID, vc, tID, opType, -1, buffered, false, 0})
+		s.leakingChannels[objID] = append(s.leakingChannels[objID], VectorClockTID2{routineID, objID, vc, tID, opType, -1, buffered, false, 0})
 	}
 }
 
@@ -136,11 +136,11 @@ func CheckForLeakChannelStuck(routineID int, objID int, vc clock.VectorClock, tI
  *   opType (int): An identifier for the type of the operation (send = 0, recv = 1, close = 2)
  *   buffered (bool): If the channel is buffered
  */
-func CheckForLeakChannelRun(routineID int, objID int, vcTID VectorClockTID, opType int, buffered bool) bool {
+func (s *State) CheckForLeakChannelRun(routineID int, objID int, vcTID VectorClockTID, opType int, buffered bool) bool {
 	logging.Debug("Checking channel for for leak channels", logging.INFO)
 	res := false
 	if opType == 0 || opType == 2 { // send or close
-		for i, vcTID2 := range leakingChannels[objID] {
+		for i, vcTID2 := range s.leakingChannels[objID] {
 			if vcTID2.val != 1 {
 				continue
 			}
@@ -174,25 +174,25 @@ func CheckForLeakChannelRun(routineID int, objID int, vcTID VectorClockTID, opTy
 				arg2 := logging.TraceElementResult{
 					RoutineID: vcTID2.routine, ObjID: objID, TPre: tPre2, ObjType: objType, File: file2, Line: line2}
 
-				logging.Result(logging.CRITICAL, bugType,
+				s.results.Result(logging.CRITICAL, bugType,
 					"channel", []logging.ResultElem{arg1}, "partner", []logging.ResultElem{arg2})
 
 				res = true
 
 				// remove the stuck operation from the list. If it is a select, remove all operations with the same val
 				if vcTID2.val == -1 {
-					leakingChannels[objID] = append(leakingChannels[objID][:i], leakingChannels[objID][i+1:]...)
+					s.leakingChannels[objID] = append(s.leakingChannels[objID][:i], s.leakingChannels[objID][i+1:]...)
 				} else {
-					for j, vcTID3 := range leakingChannels[objID] {
+					for j, vcTID3 := range s.leakingChannels[objID] {
 						if vcTID3.val == vcTID2.val {
-							leakingChannels[objID] = append(leakingChannels[objID][:j], leakingChannels[objID][j+1:]...)
+							s.leakingChannels[objID] = append(s.leakingChannels[objID][:j], s.leakingChannels[objID][j+1:]...)
 						}
 					}
 				}
 			}
 		}
 	} else if opType == 1 { // recv
-		for i, vcTID2 := range leakingChannels[objID] {
+		for i, vcTID2 := range s.leakingChannels[objID] {
 			objType := "C"
 			if vcTID2.val == 0 {
 				objType += "S"
@@ -225,18 +225,18 @@ func CheckForLeakChannelRun(routineID int, objID int, vcTID VectorClockTID, opTy
 				arg2 := logging.TraceElementResult{
 					RoutineID: vcTID2.routine, ObjID: objID, TPre: tPre2, ObjType: "CR", File: file2, Line: line2}
 
-				logging.Result(logging.CRITICAL, bugType,
+				s.results.Result(logging.CRITICAL, bugType,
 					"channel", []logging.ResultElem{arg1}, "partner", []logging.ResultElem{arg2})
 
 				res = true
 
 				// remove the stuck operation from the list. If it is a select, remove all operations with the same val
 				if vcTID2.val == -1 {
-					leakingChannels[objID] = append(leakingChannels[objID][:i], leakingChannels[objID][i+1:]...)
+					s.leakingChannels[objID] = append(s.leakingChannels[objID][:i], s.leakingChannels[objID][i+1:]...)
 				} else {
-					for j, vcTID3 := range leakingChannels[objID] {
+					for j, vcTID3 := range s.leakingChannels[objID] {
 						if vcTID3.val == vcTID2.val {
-							leakingChannels[objID] = append(leakingChannels[objID][:j], leakingChannels[objID][j+1:]...)
+							s.leakingChannels[objID] = append(s.leakingChannels[objID][:j], s.leakingChannels[objID][j+1:]...)
 						}
 					}
 				}
@@ -250,9 +250,9 @@ func CheckForLeakChannelRun(routineID int, objID int, vcTID VectorClockTID, opTy
  * After all operations have been analyzed, check if there are still leaking
  * operations without a possible partner.
  */
-func CheckForLeak() {
+func (s *State) CheckForLeak() {
 	// channel
-	for _, vcTIDs := range leakingChannels {
+	for _, vcTIDs := range s.leakingChannels {
 		buffered := false
 		for _, vcTID := range vcTIDs {
 			if vcTID.tID == "" {
@@ -261,7 +261,7 @@ func CheckForLeak() {
 
 			found := false
 			var partner allSelectCase
-			for _, c := range selectCases {
+			for _, c := range s.selectCases {
 				if c.chanID != vcTID.id {
 					continue
 				}
@@ -311,7 +311,7 @@ func CheckForLeak() {
 					arg2 := logging.TraceElementResult{ // select
 						RoutineID: partner.vcTID.Routine, ObjID: partner.selectID, TPre: tPre2, ObjType: "SS", File: file2, Line: line2}
 
-					logging.Result(logging.CRITICAL, logging.LSelectWith,
+					s.results.Result(logging.CRITICAL, logging.LSelectWith,
 						"select", []logging.ResultElem{arg1}, "partner", []logging.ResultElem{arg2})
 				} else {
 					obType := "C"
@@ -332,7 +332,7 @@ func CheckForLeak() {
 					arg2 := logging.TraceElementResult{ // select
 						RoutineID: partner.vcTID.Routine, ObjID: partner.selectID, TPre: tPre2, ObjType: "SS", File: file2, Line: line2}
 
-					logging.Result(logging.CRITICAL, bugType,
+					s.results.Result(logging.CRITICAL, bugType,
 						"channel", []logging.ResultElem{arg1}, "partner", []logging.ResultElem{arg2})
 				}
 
@@ -348,7 +348,7 @@ func CheckForLeak() {
 					arg1 := logging.TraceElementResult{
 						RoutineID: vcTID.routine, ObjID: vcTID.selID, TPre: tPre, ObjType: "SS", File: file, Line: line}
 
-					logging.Result(logging.CRITICAL, logging.LSelectWithout,
+					s.results.Result(logging.CRITICAL, logging.LSelectWithout,
 						"select", []logging.ResultElem{arg1}, "", []logging.ResultElem{})
 
 				} else {
@@ -373,7 +373,7 @@ func CheckForLeak() {
 						bugType = logging.LBufferedWithout
 					}
 
-					logging.Result(logging.CRITICAL, bugType,
+					s.results.Result(logging.CRITICAL, bugType,
 						"channel", []logging.ResultElem{arg1}, "", []logging.ResultElem{})
 				}
 			}
@@ -399,7 +399,7 @@ func CheckForLeak() {
  *     same select statement in leakingChannels.
  *   objId (int): The id of the select
  */
-func CheckForLeakSelectStuck(routineID int, ids []int, buffered []bool, vc clock.VectorClock, tID string, opTypes []int, tPre int, objID int) {
+func (s *State) CheckForLeakSelectStuck(routineID int, ids []int, buffered []bool, vc clock.VectorClock, tID string, opTypes []int, tPre int, objID int) {
 	foundPartner := false
 
 	if len(ids) == 0 {
@@ -412,7 +412,7 @@ func CheckForLeakSelectStuck(routineID int, ids []int, buffered []bool, vc clock
 		arg1 := logging.TraceElementResult{
 			RoutineID: routineID, ObjID: objID, TPre: tPre, ObjType: "SS", File: file, Line: line}
 
-		logging.Result(logging.CRITICAL, logging.LSelectWithout,
+		s.results.Result(logging.CRITICAL, logging.LSelectWithout,
 			"select", []logging.ResultElem{arg1}, "", []logging.ResultElem{})
 
 		return
@@ -420,7 +420,7 @@ func CheckForLeakSelectStuck(routineID int, ids []int, buffered []bool, vc clock
 
 	for i, id := range ids {
 		if opTypes[i] == 0 { // send
-			for routinePartner, mrr := range mostRecentReceive {
+			for routinePartner, mrr := range s.mostRecentReceive {
 				if recv, ok := mrr[id]; ok {
 					if clock.GetHappensBefore(vc, mrr[id].Vc) == clock.Concurrent {
 						file1, line1, _, err1 := infoFromTID(tID) // select
@@ -439,14 +439,14 @@ func CheckForLeakSelectStuck(routineID int, ids []int, buffered []bool, vc clock
 						arg2 := logging.TraceElementResult{
 							RoutineID: routinePartner, ObjID: id, TPre: tPre2, ObjType: "CR", File: file2, Line: line2}
 
-						logging.Result(logging.CRITICAL, logging.LSelectWith,
+						s.results.Result(logging.CRITICAL, logging.LSelectWith,
 							"select", []logging.ResultElem{arg1}, "partner", []logging.ResultElem{arg2})
 						foundPartner = true
 					}
 				}
 			}
 		} else if opTypes[i] == 1 { // recv
-			for routinePartner, mrs := range mostRecentSend {
+			for routinePartner, mrs := range s.mostRecentSend {
 				if send, ok := mrs[id]; ok {
 					if clock.GetHappensBefore(vc, mrs[id].Vc) == clock.Concurrent {
 						file1, line1, _, err1 := infoFromTID(tID) // select
@@ -465,14 +465,14 @@ func CheckForLeakSelectStuck(routineID int, ids []int, buffered []bool, vc clock
 						arg2 := logging.TraceElementResult{
 							RoutineID: routinePartner, ObjID: id, TPre: tPre2, ObjType: "CS", File: file2, Line: line2}
 
-						logging.Result(logging.CRITICAL, logging.LSelectWith,
+						s.results.Result(logging.CRITICAL, logging.LSelectWith,
 							"select", []logging.ResultElem{arg1}, "partner", []logging.ResultElem{arg2})
 
 						foundPartner = true
 					}
 				}
 			}
-			if cl, ok := closeData[id]; ok {
+			if cl, ok := s.closeData[id]; ok {
 				file1, line1, _, err1 := infoFromTID(tID) // select
 				if err1 != nil {
 					logging.Debug("Error in infoFromTID", logging.ERROR)
@@ -489,7 +489,7 @@ func CheckForLeakSelectStuck(routineID int, ids []int, buffered []bool, vc clock
 				arg2 := logging.TraceElementResult{
 					RoutineID: cl.Routine, ObjID: id, TPre: tPre2, ObjType: "CS", File: file2, Line: line2}
 
-				logging.Result(logging.CRITICAL, logging.LSelectWith,
+				s.results.Result(logging.CRITICAL, logging.LSelectWith,
 					"select", []logging.ResultElem{arg1}, "partner", []logging.ResultElem{arg2})
 
 				foundPartner = true
@@ -500,7 +500,7 @@ func CheckForLeakSelectStuck(routineID int, ids []int, buffered []bool, vc clock
 	if !foundPartner {
 		for i, id := range ids {
 			// add all select operations to leaking Channels,
-			leakingChannels[id] = append(leakingChannels[id], VectorClockTID2{routineID, id, vc, tID, opTypes[i], tPre, buffered[i], true, objID})
+			s.leakingChannels[id] = append(s.leakingChannels[id], VectorClockTID2{routineID, id, vc, tID, opTypes[i], tPre, buffered[i], true, objID})
 		}
 	}
 }
@@ -514,14 +514,14 @@ func CheckForLeakSelectStuck(routineID int, ids []int, buffered []bool, vc clock
  *   tID (string): The trace id
  *   op (int): The operation on the mutex
  */
-func CheckForLeakMutex(routineID int, id int, tID string, op int) {
+func (s *State) CheckForLeakMutex(routineID int, id int, tID string, op int) {
 	file1, line1, tPre1, err := infoFromTID(tID)
 	if err != nil {
 		logging.Debug("Error in infoFromTID", logging.ERROR)
 		return
 	}
 
-	file2, line2, tPre2, err := infoFromTID(mostRecentAcquireTotal[id].TID)
+	file2, line2, tPre2, err := infoFromTID(s.mostRecentAcquireTotal[id].TID)
 	if err != nil {
 		logging.Debug("Error in infoFromTID", logging.ERROR)
 		return
@@ -537,13 +537,13 @@ func CheckForLeakMutex(routineID int, id int, tID string, op int) {
 	}
 
 	objType2 := "M"
-	if mostRecentAcquireTotal[id].Val == 0 { // lock
+	if s.mostRecentAcquireTotal[id].Val == 0 { // lock
 		objType2 += "L"
-	} else if mostRecentAcquireTotal[id].Val == 1 { // rlock
+	} else if s.mostRecentAcquireTotal[id].Val == 1 { // rlock
 		objType2 += "R"
-	} else if mostRecentAcquireTotal[id].Val == 2 { // TryLock
+	} else if s.mostRecentAcquireTotal[id].Val == 2 { // TryLock
 		objType2 += "T"
-	} else if mostRecentAcquireTotal[id].Val == 3 { // TryRLock
+	} else if s.mostRecentAcquireTotal[id].Val == 3 { // TryRLock
 		objType2 += "Y"
 	} else { // only lock and rlock can lead to leak
 		return
@@ -553,9 +553,9 @@ func CheckForLeakMutex(routineID int, id int, tID string, op int) {
 		RoutineID: routineID, ObjID: id, TPre: tPre1, ObjType: objType1, File: file1, Line: line1}
 
 	arg2 := logging.TraceElementResult{
-		RoutineID: mostRecentAcquireTotal[id].Routine, ObjID: id, TPre: tPre2, ObjType: objType2, File: file2, Line: line2}
+		RoutineID: s.mostRecentAcquireTotal[id].Routine, ObjID: id, TPre: tPre2, ObjType: objType2, File: file2, Line: line2}
 
-	logging.Result(logging.CRITICAL, logging.LMutex,
+	s.results.Result(logging.CRITICAL, logging.LMutex,
 		"mutex", []logging.ResultElem{arg1}, "last", []logging.ResultElem{arg2})
 
 }
@@ -569,8 +569,8 @@ func CheckForLeakMutex(routineID int, id int, tID string, op int) {
  *   vc (VectorClock): The vector clock of the operation
  *   op (int): The operation on the mutex
  */
-func addMostRecentAcquireTotal(routine int, id int, tID string, vc clock.VectorClock, op int) {
-	mostRecentAcquireTotal[id] = VectorClockTID3{Routine: routine, Vc: vc, TID: tID, Val: op}
+func (s *State) addMostRecentAcquireTotal(routine int, id int, tID string, vc clock.VectorClock, op int) {
+	s.mostRecentAcquireTotal[id] = VectorClockTID3{Routine: routine, Vc: vc, TID: tID, Val: op}
 }
 
 /*
@@ -581,7 +581,7 @@ func addMostRecentAcquireTotal(routine int, id int, tID string, vc clock.VectorC
  *   id (int): The wait group id
  *   tID (string): The trace id
  */
-func CheckForLeakWait(routine int, id int, tID string) {
+func (s *State) CheckForLeakWait(routine int, id int, tID string) {
 	file, line, tPre, err := infoFromTID(tID)
 	if err != nil {
 		logging.Debug("Error in infoFromTID", logging.ERROR)
@@ -591,7 +591,7 @@ func CheckForLeakWait(routine int, id int, tID string) {
 	arg := logging.TraceElementResult{
 		RoutineID: routine, ObjID: id, TPre: tPre, ObjType: "WW", File: file, Line: line}
 
-	logging.Result(logging.CRITICAL, logging.LWaitGroup,
+	s.results.Result(logging.CRITICAL, logging.LWaitGroup,
 		"wait", []logging.ResultElem{arg}, "", []logging.ResultElem{})
 }
 
@@ -603,7 +603,7 @@ func CheckForLeakWait(routine int, id int, tID string) {
  *   id (int): The conditional variable id
  *   tID (string): The trace id
  */
-func CheckForLeakCond(routine int, id int, tID string) {
+func (s *State) CheckForLeakCond(routine int, id int, tID string) {
 	file, line, tPre, err := infoFromTID(tID)
 	if err != nil {
 		logging.Debug("Error in infoFromTID", logging.ERROR)
@@ -613,6 +613,6 @@ func CheckForLeakCond(routine int, id int, tID string) {
 	arg := logging.TraceElementResult{
 		RoutineID: routine, ObjID: id, TPre: tPre, ObjType: "NW", File: file, Line: line}
 
-	logging.Result(logging.CRITICAL, logging.LCond,
+	s.results.Result(logging.CRITICAL, logging.LCond,
 		"cond", []logging.ResultElem{arg}, "", []logging.ResultElem{})
 }
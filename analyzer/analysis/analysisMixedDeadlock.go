@@ -13,16 +13,18 @@ import (
  *   lock (int): The id of the mutex
  *   tId (string): The trace id of the mutex operation
  *   vc (VectorClock): The current vector clock
+ *   rLock (bool): true if the lock was acquired by rlock
  */
-func lockSetAddLock(routine int, lock int, tID string, vc clock.VectorClock) {
-	if _, ok := lockSet[routine]; !ok {
-		lockSet[routine] = make(map[int]string)
+func (s *State) lockSetAddLock(routine int, lock int, tID string, vc clock.VectorClock, rLock bool) {
+	if _, ok := s.lockSet[routine]; !ok {
+		s.lockSet[routine] = make(map[int]string)
+		s.lockSetRLock[routine] = make(map[int]bool)
 	}
-	if _, ok := mostRecentAcquire[routine]; !ok {
-		mostRecentAcquire[routine] = make(map[int]VectorClockTID)
+	if _, ok := s.mostRecentAcquire[routine]; !ok {
+		s.mostRecentAcquire[routine] = make(map[int]VectorClockTID)
 	}
 
-	if _, ok := lockSet[routine][lock]; ok {
+	if _, ok := s.lockSet[routine][lock]; ok {
 		// TODO: TODO: add a result. Deadlock detection is currently disabled
 		// errorMsg := "Lock " + strconv.Itoa(lock) +
 		// 	" already in lockSet for routine " + strconv.Itoa(routine)
@@ -35,8 +37,9 @@ func lockSetAddLock(routine int, lock int, tID string, vc clock.VectorClock) {
 		// logging.Result(found, logging.CRITICAL)
 	}
 
-	lockSet[routine][lock] = tID
-	mostRecentAcquire[routine][lock] = VectorClockTID{vc, tID, routine}
+	s.lockSet[routine][lock] = tID
+	s.lockSetRLock[routine][lock] = rLock
+	s.mostRecentAcquire[routine][lock] = VectorClockTID{vc, tID, routine}
 }
 
 /*
@@ -45,14 +48,15 @@ func lockSetAddLock(routine int, lock int, tID string, vc clock.VectorClock) {
  *   routine (int): The routine id
  *   lock (int): The id of the mutex
  */
-func lockSetRemoveLock(routine int, lock int) {
-	if _, ok := lockSet[routine][lock]; !ok {
+func (s *State) lockSetRemoveLock(routine int, lock int) {
+	if _, ok := s.lockSet[routine][lock]; !ok {
 		errorMsg := "Lock " + strconv.Itoa(lock) +
 			" not in lockSet for routine " + strconv.Itoa(routine)
 		logging.Debug(errorMsg, logging.ERROR)
 		return
 	}
-	delete(lockSet[routine], lock)
+	delete(s.lockSet[routine], lock)
+	delete(s.lockSetRLock[routine], lock)
 }
 
 /*
@@ -63,11 +67,11 @@ func lockSetRemoveLock(routine int, lock int) {
  *   tIDSend (string): The trace id of the channel send
  *   tIDSend (string): The trace id of the channel recv
  */
-func checkForMixedDeadlock(routineSend int, routineRevc int, tIDSend string, tIDRecv string) {
-	for m := range lockSet[routineSend] {
-		_, ok1 := mostRecentAcquire[routineRevc][m]
-		_, ok2 := mostRecentAcquire[routineSend][m]
-		if ok1 && ok2 && mostRecentAcquire[routineSend][m].TID != mostRecentAcquire[routineRevc][m].TID {
+func (s *State) checkForMixedDeadlock(routineSend int, routineRevc int, tIDSend string, tIDRecv string) {
+	for m := range s.lockSet[routineSend] {
+		_, ok1 := s.mostRecentAcquire[routineRevc][m]
+		_, ok2 := s.mostRecentAcquire[routineSend][m]
+		if ok1 && ok2 && s.mostRecentAcquire[routineSend][m].TID != s.mostRecentAcquire[routineRevc][m].TID {
 			// found possible mixed deadlock
 			// TODO: add a result. Deadlock detection is currently disabled
 			// found := "Possible mixed deadlock:\n"
@@ -78,10 +82,10 @@ func checkForMixedDeadlock(routineSend int, routineRevc int, tIDSend string, tID
 		}
 	}
 
-	for m := range lockSet[routineRevc] {
-		_, ok1 := mostRecentAcquire[routineRevc][m]
-		_, ok2 := mostRecentAcquire[routineSend][m]
-		if ok1 && ok2 && mostRecentAcquire[routineSend][m].TID != mostRecentAcquire[routineRevc][m].TID {
+	for m := range s.lockSet[routineRevc] {
+		_, ok1 := s.mostRecentAcquire[routineRevc][m]
+		_, ok2 := s.mostRecentAcquire[routineSend][m]
+		if ok1 && ok2 && s.mostRecentAcquire[routineSend][m].TID != s.mostRecentAcquire[routineRevc][m].TID {
 			// found possible mixed deadlock
 			// TODO: add a result. Deadlock detection is currently disabled
 			// found := "Possible mixed deadlock:\n"
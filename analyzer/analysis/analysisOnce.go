@@ -0,0 +1,91 @@
+package analysis
+
+import "analyzer/logging"
+
+/*
+ * OnceElem is an element, that is part of a misuse of a once
+ * Fields:
+ *   Routine (int): The routine of the element
+ *   ID (int): The id of the once, 0 for a panic
+ *   TID (string): The id of the trace element, contains the position and the tpre
+ *   ObjType (string): The type of the element, e.g. OE
+ */
+type OnceElem struct {
+	Routine int
+	ID      int
+	TID     string
+	ObjType string
+}
+
+/*
+ * Log a recursive call of Do, i.e. a call of Do in the function of a Do on
+ * the same once
+ * Args:
+ *   outer (OnceElem): The Do, whose function called Do again
+ *   inner (OnceElem): The Do, that was called in the function
+ */
+func (s *State) LogRecursiveDo(outer OnceElem, inner OnceElem) {
+	args, err := onceResults([]OnceElem{outer, inner})
+	if err != nil {
+		logging.Debug(err.Error(), logging.ERROR)
+		return
+	}
+
+	s.results.Result(logging.CRITICAL, logging.ARecursiveDo,
+		"do", args, "", []logging.ResultElem{})
+}
+
+/*
+ * Log a Do, whose function panicked
+ * Args:
+ *   do (OnceElem): The Do
+ *   pa (OnceElem): The panic in the function of the Do
+ *   later ([]OnceElem): The calls of Do on the same once after the panic,
+ *     that returned without executing their function
+ */
+func (s *State) LogDoPanic(do OnceElem, pa OnceElem, later []OnceElem) {
+	arg1, err := onceResults([]OnceElem{do, pa})
+	if err != nil {
+		logging.Debug(err.Error(), logging.ERROR)
+		return
+	}
+
+	arg2, err := onceResults(later)
+	if err != nil {
+		logging.Debug(err.Error(), logging.ERROR)
+		return
+	}
+
+	s.results.Result(logging.CRITICAL, logging.ADoPanic,
+		"do", arg1, "later", arg2)
+}
+
+/*
+ * Create the result elements for elements of a misuse of a once
+ * Args:
+ *   elems ([]OnceElem): The elements
+ * Returns:
+ *   []logging.ResultElem: The result elements
+ *   error: An error if the tID of an element could not be parsed
+ */
+func onceResults(elems []OnceElem) ([]logging.ResultElem, error) {
+	res := make([]logging.ResultElem, 0, len(elems))
+
+	for _, elem := range elems {
+		file, line, tPre, err := infoFromTID(elem.TID)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, logging.TraceElementResult{
+			RoutineID: elem.Routine,
+			ObjID:     elem.ID,
+			TPre:      tPre,
+			ObjType:   elem.ObjType,
+			File:      file,
+			Line:      line,
+		})
+	}
+
+	return res, nil
+}
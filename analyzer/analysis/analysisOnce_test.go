@@ -0,0 +1,54 @@
+package analysis_test
+
+import (
+	"testing"
+
+	"analyzer/logging"
+	"analyzer/trace"
+)
+
+/*
+ * The function of the Do calls Do on the same once, neither returns
+ */
+func TestRecursiveDo(t *testing.T) {
+	found := runAnalysis(t, 1, []string{"onceMisuse"}, func(tr *trace.Trace) []error {
+		return []error{
+			tr.AddTraceElementOnce(1, "1", "0", "5", "f", "/a/main.go:10"),
+			tr.AddTraceElementOnce(1, "2", "0", "5", "f", "/a/main.go:11"),
+		}
+	})
+
+	expectResult(t, found, logging.ARecursiveDo, "/a/main.go:10", "/a/main.go:11")
+}
+
+/*
+ * The function of the Do panics. The panic is recovered by a deferred
+ * function in the same routine, which then calls Do again. The post of the
+ * first Do is not deferred in sync/once.go, so its tpost stays 0 even though
+ * the panic is recovered.
+ */
+func TestDoPanicRecovered(t *testing.T) {
+	found := runAnalysis(t, 1, []string{"onceMisuse"}, func(tr *trace.Trace) []error {
+		return []error{
+			tr.AddTraceElementOnce(1, "1", "0", "5", "f", "/a/main.go:10"),
+			tr.AddTraceElementPanic(1, "2", "/a/main.go:11"),
+			tr.AddTraceElementOnce(1, "3", "4", "5", "f", "/a/main.go:20"),
+		}
+	})
+
+	expectResult(t, found, logging.ADoPanic, "/a/main.go:10", "/a/main.go:11", "/a/main.go:20")
+}
+
+/*
+ * The function of the Do returns, the later Do does not execute its function
+ */
+func TestDoWithoutMisuse(t *testing.T) {
+	found := runAnalysis(t, 1, []string{"onceMisuse"}, func(tr *trace.Trace) []error {
+		return []error{
+			tr.AddTraceElementOnce(1, "1", "2", "5", "t", "/a/main.go:10"),
+			tr.AddTraceElementOnce(1, "3", "4", "5", "f", "/a/main.go:20"),
+		}
+	})
+
+	expectNoResult(t, found)
+}
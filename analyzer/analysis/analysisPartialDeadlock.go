@@ -0,0 +1,58 @@
+package analysis
+
+import "analyzer/logging"
+
+/*
+ * StuckElem is an element, that is part of a partial deadlock
+ * Fields:
+ *   Routine (int): The routine of the element
+ *   ID (int): The id of the object of the element
+ *   TID (string): The id of the trace element, contains the position and the tpre
+ *   ObjType (string): The type of the element, e.g. CS
+ */
+type StuckElem struct {
+	Routine int
+	ID      int
+	TID     string
+	ObjType string
+}
+
+var leakResultTypes = []logging.ResultType{
+	logging.LUnbufferedWith, logging.LUnbufferedWithout, logging.LBufferedWith,
+	logging.LBufferedWithout, logging.LNilChan, logging.LSelectWith,
+	logging.LSelectWithout, logging.LMutex, logging.LWaitGroup, logging.LCond,
+}
+
+/*
+ * Log a partial deadlock, i.e. a set of stuck routines, that block each other.
+ * The leak results of the stuck elements are replaced by the partial deadlock.
+ * Args:
+ *   elems ([]StuckElem): The stuck elements of the routines in the deadlock
+ */
+func (s *State) LogPartialDeadlock(elems []StuckElem) {
+	args := make([]logging.ResultElem, 0, len(elems))
+
+	for _, elem := range elems {
+		file, line, tPre, err := infoFromTID(elem.TID)
+		if err != nil {
+			logging.Debug(err.Error(), logging.ERROR)
+			return
+		}
+
+		args = append(args, logging.TraceElementResult{
+			RoutineID: elem.Routine,
+			ObjID:     elem.ID,
+			TPre:      tPre,
+			ObjType:   elem.ObjType,
+			File:      file,
+			Line:      line,
+		})
+	}
+
+	for _, arg := range args {
+		s.results.RemoveResults(leakResultTypes, arg.(logging.TraceElementResult))
+	}
+
+	s.results.Result(logging.CRITICAL, logging.APartialDeadlock,
+		"stuck", args, "", []logging.ResultElem{})
+}
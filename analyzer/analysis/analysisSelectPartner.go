@@ -9,11 +9,11 @@ import (
 * CheckForSelectCaseWithoutPartner checks for select cases without a valid
 * partner. Call when all elements have been processed.
  */
-func CheckForSelectCaseWithoutPartner() {
+func (s *State) CheckForSelectCaseWithoutPartner() {
 	// check if not selected cases could be partners
-	for i, c1 := range selectCases {
-		for j := i + 1; j < len(selectCases); j++ {
-			c2 := selectCases[j]
+	for i, c1 := range s.selectCases {
+		for j := i + 1; j < len(s.selectCases); j++ {
+			c2 := s.selectCases[j]
 
 			if c1.partner && c2.partner {
 				continue
@@ -36,13 +36,13 @@ func CheckForSelectCaseWithoutPartner() {
 			}
 
 			if found {
-				selectCases[i].partner = true
-				selectCases[j].partner = true
+				s.selectCases[i].partner = true
+				s.selectCases[j].partner = true
 			}
 		}
 	}
 
-	if len(selectCases) == 0 {
+	if len(s.selectCases) == 0 {
 		return
 	}
 
@@ -50,7 +50,7 @@ func CheckForSelectCaseWithoutPartner() {
 	casesWithoutPartner := make(map[string][]logging.ResultElem) // tID -> cases
 	casesWithoutPartnerInfo := make(map[string][]int)            // tID -> [routine, selectID]
 
-	for _, c := range selectCases {
+	for _, c := range s.selectCases {
 		if c.partner {
 			continue
 		}
@@ -103,7 +103,7 @@ func CheckForSelectCaseWithoutPartner() {
 			Line:      line,
 		}
 
-		logging.Result(logging.WARNING, logging.ASelCaseWithoutPartner,
+		s.results.Result(logging.WARNING, logging.ASelCaseWithoutPartner,
 			"select", []logging.ResultElem{arg1}, "case", cases)
 	}
 }
@@ -120,7 +120,7 @@ func CheckForSelectCaseWithoutPartner() {
 *   vc (VectorClock): The vector clock
 *   tID (string): The position of the select in the program
  */
-func CheckForSelectCaseWithoutPartnerSelect(routine int, selectID int, caseChanIds []int, bufferedInfo []bool,
+func (s *State) CheckForSelectCaseWithoutPartnerSelect(routine int, selectID int, caseChanIds []int, bufferedInfo []bool,
 	sendInfo []bool, vc clock.VectorClock, tID string, chosenIndex int) {
 	for i, id := range caseChanIds {
 		buffered := bufferedInfo[i]
@@ -134,7 +134,7 @@ func CheckForSelectCaseWithoutPartnerSelect(routine int, selectID int, caseChanI
 		} else {
 			// not select cases
 			if send {
-				for _, mrr := range mostRecentReceive {
+				for _, mrr := range s.mostRecentReceive {
 					if possiblePartner, ok := mrr[id]; ok {
 						hb := clock.GetHappensBefore(vc, possiblePartner.Vc)
 						if buffered && (hb == clock.Concurrent || hb == clock.Before) {
@@ -147,7 +147,7 @@ func CheckForSelectCaseWithoutPartnerSelect(routine int, selectID int, caseChanI
 					}
 				}
 			} else { // recv
-				for _, mrs := range mostRecentSend {
+				for _, mrs := range s.mostRecentSend {
 					if possiblePartner, ok := mrs[id]; ok {
 						hb := clock.GetHappensBefore(vc, possiblePartner.Vc)
 						if buffered && (hb == clock.Concurrent || hb == clock.After) {
@@ -160,7 +160,7 @@ func CheckForSelectCaseWithoutPartnerSelect(routine int, selectID int, caseChanI
 			}
 		}
 
-		selectCases = append(selectCases,
+		s.selectCases = append(s.selectCases,
 			allSelectCase{selectID, id, VectorClockTID{vc, tID, routine}, send, buffered, found})
 
 	}
@@ -177,10 +177,10 @@ func CheckForSelectCaseWithoutPartnerSelect(routine int, selectID int, caseChanI
 *   buffered (bool): True if the channel is buffered
 *   sel (bool): True if the operation is part of a select statement
  */
-func CheckForSelectCaseWithoutPartnerChannel(id int, vc clock.VectorClock, tID string,
+func (s *State) CheckForSelectCaseWithoutPartnerChannel(id int, vc clock.VectorClock, tID string,
 	send bool, buffered bool) {
 
-	for i, c := range selectCases {
+	for i, c := range s.selectCases {
 		if c.partner || c.chanID != id || c.send == send || c.vcTID.TID == tID {
 			continue
 		}
@@ -202,7 +202,7 @@ func CheckForSelectCaseWithoutPartnerChannel(id int, vc clock.VectorClock, tID s
 		}
 
 		if found {
-			selectCases[i].partner = true
+			s.selectCases[i].partner = true
 		}
 	}
 }
@@ -214,8 +214,8 @@ func CheckForSelectCaseWithoutPartnerChannel(id int, vc clock.VectorClock, tID s
 *   id (int): The id of the channel
 *   vc (VectorClock): The vector clock
  */
-func CheckForSelectCaseWithoutPartnerClose(id int, vc clock.VectorClock) {
-	for i, c := range selectCases {
+func (s *State) CheckForSelectCaseWithoutPartnerClose(id int, vc clock.VectorClock) {
+	for i, c := range s.selectCases {
 		if c.partner || c.chanID != id || c.send {
 			continue
 		}
@@ -229,7 +229,7 @@ func CheckForSelectCaseWithoutPartnerClose(id int, vc clock.VectorClock) {
 		}
 
 		if found {
-			selectCases[i].partner = true
+			s.selectCases[i].partner = true
 		}
 	}
 }
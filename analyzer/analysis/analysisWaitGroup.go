@@ -8,23 +8,23 @@ import (
 	"strconv"
 )
 
-func checkForDoneBeforeAddChange(routine int, id int, delta int, pos string, vc clock.VectorClock) {
+func (s *State) checkForDoneBeforeAddChange(routine int, id int, delta int, pos string, vc clock.VectorClock) {
 	if delta > 0 {
-		checkForDoneBeforeAddAdd(routine, id, pos, vc, delta)
+		s.checkForDoneBeforeAddAdd(routine, id, pos, vc, delta)
 	} else if delta < 0 {
-		checkForDoneBeforeAddDone(routine, id, pos, vc)
+		s.checkForDoneBeforeAddDone(routine, id, pos, vc)
 	} else {
 		// checkForImpossibleWait(routine, id, pos, vc)
 	}
 }
 
-func checkForDoneBeforeAddAdd(routine int, id int, pos string, vc clock.VectorClock, delta int) {
+func (s *State) checkForDoneBeforeAddAdd(routine int, id int, pos string, vc clock.VectorClock, delta int) {
 	// if necessary, create maps and lists
-	if _, ok := wgAdd[id]; !ok {
-		wgAdd[id] = make(map[int][]VectorClockTID)
+	if _, ok := s.wgAdd[id]; !ok {
+		s.wgAdd[id] = make(map[int][]VectorClockTID)
 	}
-	if _, ok := wgAdd[id][routine]; !ok {
-		wgAdd[id][routine] = make([]VectorClockTID, 0)
+	if _, ok := s.wgAdd[id][routine]; !ok {
+		s.wgAdd[id][routine] = make([]VectorClockTID, 0)
 	}
 
 	// add the vector clock and position to the list
@@ -32,22 +32,22 @@ func checkForDoneBeforeAddAdd(routine int, id int, pos string, vc clock.VectorCl
 		if delta > 1 {
 			pos = pos + "+" + strconv.Itoa(i) // add a unique identifier to the position
 		}
-		wgAdd[id][routine] = append(wgAdd[id][routine], VectorClockTID{vc.Copy(), pos, routine})
+		s.wgAdd[id][routine] = append(s.wgAdd[id][routine], VectorClockTID{vc.Copy(), pos, routine})
 	}
 }
 
-func checkForDoneBeforeAddDone(routine int, id int, pos string, vc clock.VectorClock) {
+func (s *State) checkForDoneBeforeAddDone(routine int, id int, pos string, vc clock.VectorClock) {
 	// if necessary, create maps and lists
-	if _, ok := wgDone[id]; !ok {
-		wgDone[id] = make(map[int][]VectorClockTID)
+	if _, ok := s.wgDone[id]; !ok {
+		s.wgDone[id] = make(map[int][]VectorClockTID)
 
 	}
-	if _, ok := wgDone[id][routine]; !ok {
-		wgDone[id][routine] = make([]VectorClockTID, 0)
+	if _, ok := s.wgDone[id][routine]; !ok {
+		s.wgDone[id][routine] = make([]VectorClockTID, 0)
 	}
 
 	// add the vector clock and position to the list
-	wgDone[id][routine] = append(wgDone[id][routine], VectorClockTID{vc.Copy(), pos, routine})
+	s.wgDone[id][routine] = append(s.wgDone[id][routine], VectorClockTID{vc.Copy(), pos, routine})
 }
 
 /*
@@ -185,9 +185,9 @@ func remove(list []string, element string) []string {
 	return list
 }
 
-func numberDone(id int) int {
+func (s *State) numberDone(id int) int {
 	res := 0
-	for _, dones := range wgDone[id] {
+	for _, dones := range s.wgDone[id] {
 		res += len(dones)
 	}
 	return res
@@ -199,12 +199,12 @@ func numberDone(id int) int {
 - Use the Ford-Fulkerson algorithm to find the maximum flow.
 - If the maximum flow is smaller than the number of done operations, a negative wait group counter is possible.
 */
-func CheckForDoneBeforeAdd() {
-	for id := range wgAdd { // for all waitgroups
-		graph := buildResidualGraph(wgAdd[id], wgDone[id])
+func (s *State) CheckForDoneBeforeAdd() {
+	for id := range s.wgAdd { // for all waitgroups
+		graph := buildResidualGraph(s.wgAdd[id], s.wgDone[id])
 
 		maxFlow, graph := calculateMaxFlow(graph)
-		nrDone := numberDone(id)
+		nrDone := s.numberDone(id)
 
 		addsVcTIDs := []VectorClockTID{}
 		donesVcTIDs := []VectorClockTID{}
@@ -214,7 +214,7 @@ func CheckForDoneBeforeAdd() {
 			// that the i-th add in the result message is concurrent with the
 			// i-th done in the result message
 
-			for _, adds := range wgAdd[id] {
+			for _, adds := range s.wgAdd[id] {
 				for _, add := range adds {
 					if !utils.Contains(graph["t"], add.TID) {
 						addsVcTIDs = append(addsVcTIDs, add)
@@ -222,7 +222,7 @@ func CheckForDoneBeforeAdd() {
 				}
 			}
 			for _, dones := range graph["s"] {
-				doneVcTID, err := getDoneVcTIDFromTID(id, dones)
+				doneVcTID, err := s.getDoneVcTIDFromTID(id, dones)
 				if err != nil {
 					logging.Debug(err.Error(), logging.ERROR)
 				} else {
@@ -292,14 +292,14 @@ func CheckForDoneBeforeAdd() {
 				})
 			}
 
-			logging.Result(logging.CRITICAL, logging.PNegWG,
+			s.results.Result(logging.CRITICAL, logging.PNegWG,
 				"add", args1, "done", args2)
 		}
 	}
 }
 
-func getDoneVcTIDFromTID(id int, tID string) (VectorClockTID, error) {
-	for _, dones := range wgDone[id] {
+func (s *State) getDoneVcTIDFromTID(id int, tID string) (VectorClockTID, error) {
+	for _, dones := range s.wgDone[id] {
 		for _, done := range dones {
 			if done.TID == tID {
 				return done, nil
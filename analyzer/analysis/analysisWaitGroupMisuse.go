@@ -0,0 +1,198 @@
+package analysis
+
+import (
+	"analyzer/clock"
+	"analyzer/logging"
+	"sort"
+)
+
+/*
+ * Get the summary of a wait group for the detection of misuse
+ * Args:
+ *   id (int): The id of the wait group
+ * Returns:
+ *   *wgSummary: The summary
+ */
+func (s *State) getWaitGroupSummary(id int) *wgSummary {
+	if _, ok := s.wgMisuse[id]; !ok {
+		s.wgMisuse[id] = &wgSummary{
+			adds:     make(map[int][]wgCount),
+			lastAdd:  make(map[int]VectorClockTID3),
+			lastWait: make(map[int]waitVC),
+		}
+	}
+	return s.wgMisuse[id]
+}
+
+/*
+ * Update the summary of a wait group with an add or done and check an add
+ * with a positive delta against the latest wait of each routine.
+ * The following misuses are detected:
+ *   - an add with a positive delta, that is concurrent with a wait, while the
+ *     counter may be zero. The wait may return before the add.
+ *   - an add with a positive delta, that starts a new round of the wait group,
+ *     while an earlier wait may not have returned.
+ * Each pair of an add and a wait is checked, when the later one of them is
+ * processed. Only the latest add and wait of each routine is kept, earlier
+ * ones of the same routine happen before them.
+ * Args:
+ *   routine (int): The routine of the operation
+ *   id (int): The id of the wait group
+ *   delta (int): The delta of the operation
+ *   tID (string): The id of the trace element, contains the position and the tpre
+ *   vc (VectorClock): The vector clock of the routine after the operation
+ */
+func (s *State) updateWaitGroupMisuseChange(routine int, id int, delta int, tID string, vc clock.VectorClock) {
+	summary := s.getWaitGroupSummary(id)
+
+	if delta <= 0 {
+		summary.dones -= delta
+		return
+	}
+
+	mayBeZero := summary.counterMayBeZero(vc)
+
+	sum := delta
+	if adds := summary.adds[routine]; len(adds) > 0 {
+		sum += adds[len(adds)-1].sum
+	}
+	summary.adds[routine] = append(summary.adds[routine], wgCount{vc.Get(routine), sum})
+
+	if !mayBeZero {
+		return
+	}
+
+	add := VectorClockTID3{routine, tID, vc.Copy(), delta}
+	summary.lastAdd[routine] = add
+
+	for _, r := range sortedRoutines(summary.lastWait) {
+		s.checkWaitGroupMisuse(id, add, summary.lastWait[r])
+	}
+}
+
+/*
+ * Update the summary of a wait group with a wait and check it against the
+ * latest add of each routine, before which the counter may be zero
+ * Args:
+ *   id (int): The id of the wait group
+ *   wait (waitVC): The wait
+ */
+func (s *State) updateWaitGroupMisuseWait(id int, wait waitVC) {
+	summary := s.getWaitGroupSummary(id)
+	summary.lastWait[wait.routine] = wait
+
+	for _, r := range sortedRoutines(summary.lastAdd) {
+		s.checkWaitGroupMisuse(id, summary.lastAdd[r], wait)
+	}
+}
+
+/*
+ * Check an add with a positive delta, before which the counter may be zero,
+ * and a wait for a misuse of the wait group
+ * Args:
+ *   id (int): The id of the wait group
+ *   add (VectorClockTID3): The add, val = delta
+ *   wait (waitVC): The wait
+ */
+func (s *State) checkWaitGroupMisuse(id int, add VectorClockTID3, wait waitVC) {
+	if add.Routine == wait.routine {
+		return
+	}
+
+	hbPre := clock.GetHappensBefore(add.Vc, wait.vcPre)
+	hbPost := clock.GetHappensBefore(add.Vc, wait.vcPost)
+
+	if s.analysisCases["addConcurrentWait"] && hbPre == clock.Concurrent &&
+		(!wait.done || hbPost == clock.Before) {
+		s.logWaitGroupMisuse(logging.PAddConcurrentWait, id, add, wait)
+	} else if s.analysisCases["waitGroupReuse"] && wait.done && hbPost == clock.Concurrent {
+		s.logWaitGroupMisuse(logging.PWaitGroupReuse, id, add, wait)
+	}
+}
+
+/*
+ * Check if the counter of a wait group may be zero directly before an add.
+ * This is the case, if the adds that happen before the add can be balanced
+ * by the dones that have been processed before the add. The adds of each
+ * routine, that happen before the add, are a prefix of the adds of the
+ * routine and are found by the value of the routine in the clock of the add.
+ * Dones, that are concurrent with the add but processed after it, are not
+ * counted.
+ * Args:
+ *   vc (VectorClock): The vector clock of the add
+ * Returns:
+ *   bool: true if the counter may be zero before the add
+ */
+func (summary *wgSummary) counterMayBeZero(vc clock.VectorClock) bool {
+	adds := 0
+
+	for r, counts := range summary.adds {
+		// the add itself has not been stored yet
+		value := vc.Get(r)
+		n := sort.Search(len(counts), func(i int) bool { return counts[i].clock > value })
+		if n > 0 {
+			adds += counts[n-1].sum
+		}
+	}
+
+	return adds <= summary.dones
+}
+
+/*
+ * Get the routines of a map in sorted order
+ * Args:
+ *   m (map[int]T): The map with the routines as keys
+ * Returns:
+ *   []int: The sorted routines
+ */
+func sortedRoutines[T any](m map[int]T) []int {
+	res := make([]int, 0, len(m))
+	for routine := range m {
+		res = append(res, routine)
+	}
+	sort.Ints(res)
+	return res
+}
+
+/*
+ * Log a misuse of a wait group
+ * Args:
+ *   resType (ResultType): The type of the misuse
+ *   id (int): The id of the wait group
+ *   add (VectorClockTID3): The add
+ *   wait (waitVC): The wait
+ */
+func (s *State) logWaitGroupMisuse(resType logging.ResultType, id int, add VectorClockTID3, wait waitVC) {
+	file1, line1, tPre1, err := infoFromTID(add.TID) // add
+	if err != nil {
+		logging.Debug(err.Error(), logging.ERROR)
+		return
+	}
+
+	file2, line2, tPre2, err := infoFromTID(wait.tID) // wait
+	if err != nil {
+		logging.Debug(err.Error(), logging.ERROR)
+		return
+	}
+
+	arg1 := logging.TraceElementResult{ // add
+		RoutineID: add.Routine,
+		ObjID:     id,
+		TPre:      tPre1,
+		ObjType:   "WA",
+		File:      file1,
+		Line:      line1,
+	}
+
+	arg2 := logging.TraceElementResult{ // wait
+		RoutineID: wait.routine,
+		ObjID:     id,
+		TPre:      tPre2,
+		ObjType:   "WW",
+		File:      file2,
+		Line:      line2,
+	}
+
+	s.results.Result(logging.CRITICAL, resType,
+		"add", []logging.ResultElem{arg1}, "wait", []logging.ResultElem{arg2})
+}
@@ -0,0 +1,62 @@
+package analysis_test
+
+import (
+	"testing"
+
+	"analyzer/logging"
+	"analyzer/trace"
+)
+
+var waitGroupMisuseCases = []string{"addConcurrentWait", "waitGroupReuse"}
+
+/*
+ * The add is executed in the new routine, the wait in the main routine may
+ * therefore return before the add
+ */
+func TestAddConcurrentWait(t *testing.T) {
+	found := runAnalysis(t, 2, waitGroupMisuseCases, func(tr *trace.Trace) []error {
+		return []error{
+			tr.AddTraceElementFork(1, "2", "2", "/a/main.go:20"),
+			tr.AddTraceElementWait(2, "3", "3", "5", "A", "1", "1", "/a/main.go:10"),
+			tr.AddTraceElementWait(1, "4", "7", "5", "W", "0", "0", "/a/main.go:21"),
+			tr.AddTraceElementWait(2, "6", "6", "5", "A", "-1", "0", "/a/main.go:11"),
+		}
+	})
+
+	expectResult(t, found, logging.PAddConcurrentWait, "/a/main.go:10", "/a/main.go:21")
+}
+
+/*
+ * The add is executed before the new routine is started, so the wait cannot
+ * return before the add
+ */
+func TestAddBeforeWait(t *testing.T) {
+	found := runAnalysis(t, 2, waitGroupMisuseCases, func(tr *trace.Trace) []error {
+		return []error{
+			tr.AddTraceElementWait(1, "1", "1", "5", "A", "1", "1", "/a/main.go:19"),
+			tr.AddTraceElementFork(1, "2", "2", "/a/main.go:20"),
+			tr.AddTraceElementWait(1, "4", "7", "5", "W", "0", "0", "/a/main.go:21"),
+			tr.AddTraceElementWait(2, "6", "6", "5", "A", "-1", "0", "/a/main.go:11"),
+		}
+	})
+
+	expectNoResult(t, found)
+}
+
+/*
+ * The first round of the wait group has finished, but the add of the second
+ * round in the other routine is concurrent with the return of the wait
+ */
+func TestWaitGroupReuse(t *testing.T) {
+	found := runAnalysis(t, 2, waitGroupMisuseCases, func(tr *trace.Trace) []error {
+		return []error{
+			tr.AddTraceElementWait(1, "1", "1", "5", "A", "1", "1", "/a/main.go:19"),
+			tr.AddTraceElementFork(1, "2", "2", "/a/main.go:20"),
+			tr.AddTraceElementWait(2, "3", "3", "5", "A", "-1", "0", "/a/main.go:10"),
+			tr.AddTraceElementWait(1, "4", "5", "5", "W", "0", "0", "/a/main.go:21"),
+			tr.AddTraceElementWait(2, "6", "6", "5", "A", "1", "1", "/a/main.go:11"),
+		}
+	})
+
+	expectResult(t, found, logging.PWaitGroupReuse, "/a/main.go:11", "/a/main.go:21")
+}
@@ -0,0 +1,78 @@
+package analysis_test
+
+import (
+	"strings"
+	"testing"
+
+	"analyzer/logging"
+	"analyzer/trace"
+)
+
+/*
+ * Build a trace, run the given analysis cases on it and return the results
+ * in the machine readable format
+ * Args:
+ *   t (*testing.T): The test
+ *   nRoutines (int): The number of routines in the trace
+ *   cases ([]string): The analysis cases to run
+ *   build (func(*trace.Trace) []error): Adds the elements to the trace
+ * Returns:
+ *   []string: The results
+ */
+func runAnalysis(t *testing.T, nRoutines int, cases []string, build func(tr *trace.Trace) []error) []string {
+	t.Helper()
+
+	tr := trace.NewTrace()
+	for _, err := range build(tr) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	tr.Sort()
+	tr.SetNumberOfRoutines(nRoutines)
+
+	analysisCases := make(map[string]bool)
+	for _, c := range cases {
+		analysisCases[c] = true
+	}
+
+	results := logging.NewResults()
+	tr.RunAnalysis(false, false, analysisCases, results, nil)
+	return results.GetResultsMachine(false)
+}
+
+/*
+ * Check that exactly one result of the given type was found, that contains
+ * all given positions
+ * Args:
+ *   t (*testing.T): The test
+ *   found ([]string): The results
+ *   resType (logging.ResultType): The expected type
+ *   positions ([]string): The positions, that must be contained in the result
+ */
+func expectResult(t *testing.T, found []string, resType logging.ResultType, positions ...string) {
+	t.Helper()
+
+	if len(found) != 1 || !strings.HasPrefix(found[0], string(resType)+",") {
+		t.Fatalf("expected one result of type %s, got %v", resType, found)
+	}
+	for _, pos := range positions {
+		if !strings.Contains(found[0], pos) {
+			t.Errorf("result does not contain %s: %s", pos, found[0])
+		}
+	}
+}
+
+/*
+ * Check that no result was found
+ * Args:
+ *   t (*testing.T): The test
+ *   found ([]string): The results
+ */
+func expectNoResult(t *testing.T, found []string) {
+	t.Helper()
+
+	if len(found) != 0 {
+		t.Errorf("expected no result, got %v", found)
+	}
+}
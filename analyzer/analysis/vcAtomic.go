@@ -4,18 +4,15 @@ import (
 	"analyzer/clock"
 )
 
-// vector clocks for last write times
-var lw map[int]clock.VectorClock = make(map[int]clock.VectorClock)
-
 /*
  * Create a new lw if needed
  * Args:
  *   index (int): The id of the atomic variable
  *   nRout (int): The number of routines in the trace
  */
-func newLw(index int, nRout int) {
-	if _, ok := lw[index]; !ok {
-		lw[index] = clock.NewVectorClock(nRout)
+func (s *State) newLw(index int, nRout int) {
+	if _, ok := s.lw[index]; !ok {
+		s.lw[index] = clock.NewVectorClock(nRout)
 	}
 }
 
@@ -26,9 +23,9 @@ func newLw(index int, nRout int) {
  *   id (int): The id of the atomic variable
  *   vc (*map[int]VectorClock): The vector clocks
  */
-func Write(routine int, id int, vc map[int]clock.VectorClock) {
-	newLw(id, vc[id].GetSize())
-	lw[id] = vc[routine].Copy()
+func (s *State) Write(routine int, id int, vc map[int]clock.VectorClock) {
+	s.newLw(id, vc[id].GetSize())
+	s.lw[id] = vc[routine].Copy()
 	vc[routine] = vc[routine].Inc(routine)
 }
 
@@ -41,10 +38,10 @@ func Write(routine int, id int, vc map[int]clock.VectorClock) {
  *   vc (map[int]VectorClock): The vector clocks
  *   sync bool: sync reader with last writer
  */
-func Read(routine int, id int, vc map[int]clock.VectorClock, sync bool) {
-	newLw(id, vc[id].GetSize())
+func (s *State) Read(routine int, id int, vc map[int]clock.VectorClock, sync bool) {
+	s.newLw(id, vc[id].GetSize())
 	if sync {
-		vc[routine] = vc[routine].Sync(lw[id])
+		vc[routine] = vc[routine].Sync(s.lw[id])
 	}
 	vc[routine] = vc[routine].Inc(routine)
 }
@@ -59,7 +56,7 @@ func Read(routine int, id int, vc map[int]clock.VectorClock, sync bool) {
  *   cv (map[int]VectorClock): The vector clocks
  *   sync bool: sync reader with last writer
  */
-func Swap(routine int, id int, cv map[int]clock.VectorClock, sync bool) {
-	Read(routine, id, cv, sync)
-	Write(routine, id, cv)
+func (s *State) Swap(routine int, id int, cv map[int]clock.VectorClock, sync bool) {
+	s.Read(routine, id, cv, sync)
+	s.Write(routine, id, cv)
 }
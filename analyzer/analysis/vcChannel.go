@@ -27,19 +27,19 @@ type bufferedVC struct {
  * 	vc (map[int]VectorClock): the current vector clocks
  *  tPost (int): the timestamp at the end of the event
  */
-func Unbuffered(routSend int, routRecv int, id int, tIDSend string,
+func (s *State) Unbuffered(routSend int, routRecv int, id int, tIDSend string,
 	tIDRecv string, vc map[int]clock.VectorClock, tPost int) {
-	if analysisCases["concurrentRecv"] {
-		checkForConcurrentRecv(routRecv, id, tIDRecv, vc, tPost)
+	if s.analysisCases["concurrentRecv"] {
+		s.checkForConcurrentRecv(routRecv, id, tIDRecv, vc, tPost)
 	}
 
 	if tPost != 0 {
 
-		if mostRecentReceive[routRecv] == nil {
-			mostRecentReceive[routRecv] = make(map[int]VectorClockTID3)
+		if s.mostRecentReceive[routRecv] == nil {
+			s.mostRecentReceive[routRecv] = make(map[int]VectorClockTID3)
 		}
-		if mostRecentSend[routSend] == nil {
-			mostRecentSend[routSend] = make(map[int]VectorClockTID3)
+		if s.mostRecentSend[routSend] == nil {
+			s.mostRecentSend[routSend] = make(map[int]VectorClockTID3)
 		}
 
 		vc[routRecv] = vc[routRecv].Sync(vc[routSend])
@@ -48,40 +48,40 @@ func Unbuffered(routSend int, routRecv int, id int, tIDSend string,
 		vc[routRecv] = vc[routRecv].Inc(routRecv)
 
 		// for detection of send on closed
-		hasSend[id] = true
-		mostRecentSend[routSend][id] = VectorClockTID3{routSend, tIDSend, mostRecentSend[routSend][id].Vc.Sync(vc[routSend]).Copy(), id}
+		s.hasSend[id] = true
+		s.mostRecentSend[routSend][id] = VectorClockTID3{routSend, tIDSend, s.mostRecentSend[routSend][id].Vc.Sync(vc[routSend]).Copy(), id}
 
 		// for detection of receive on closed
-		hasReceived[id] = true
-		mostRecentReceive[routRecv][id] = VectorClockTID3{routRecv, tIDRecv, mostRecentReceive[routRecv][id].Vc.Sync(vc[routRecv]).Copy(), id}
+		s.hasReceived[id] = true
+		s.mostRecentReceive[routRecv][id] = VectorClockTID3{routRecv, tIDRecv, s.mostRecentReceive[routRecv][id].Vc.Sync(vc[routRecv]).Copy(), id}
 
-		logging.Debug("Set most recent send of "+strconv.Itoa(id)+" to "+mostRecentSend[routSend][id].Vc.ToString(), logging.DEBUG)
-		logging.Debug("Set most recent recv of "+strconv.Itoa(id)+" to "+mostRecentReceive[routRecv][id].Vc.ToString(), logging.DEBUG)
+		logging.Debug("Set most recent send of "+strconv.Itoa(id)+" to "+s.mostRecentSend[routSend][id].Vc.ToString(), logging.DEBUG)
+		logging.Debug("Set most recent recv of "+strconv.Itoa(id)+" to "+s.mostRecentReceive[routRecv][id].Vc.ToString(), logging.DEBUG)
 
 	} else {
 		vc[routSend] = vc[routSend].Inc(routSend)
 	}
 
-	if analysisCases["sendOnClosed"] {
-		if _, ok := closeData[id]; ok {
-			foundSendOnClosedChannel(routSend, id, tIDSend)
+	if s.analysisCases["sendOnClosed"] {
+		if _, ok := s.closeData[id]; ok {
+			s.foundSendOnClosedChannel(routSend, id, tIDSend)
 		}
 	}
 
-	if analysisCases["mixedDeadlock"] {
-		CheckForSelectCaseWithoutPartnerChannel(id, vc[routSend], tIDSend, true, false)
-		CheckForSelectCaseWithoutPartnerChannel(id, vc[routRecv], tIDRecv, false, false)
-		checkForMixedDeadlock(routSend, routRecv, tIDSend, tIDRecv)
+	if s.analysisCases["mixedDeadlock"] {
+		s.CheckForSelectCaseWithoutPartnerChannel(id, vc[routSend], tIDSend, true, false)
+		s.CheckForSelectCaseWithoutPartnerChannel(id, vc[routRecv], tIDRecv, false, false)
+		s.checkForMixedDeadlock(routSend, routRecv, tIDSend, tIDRecv)
 	}
 
-	if analysisCases["selectWithoutPartner"] {
-		CheckForSelectCaseWithoutPartnerChannel(id, vc[routSend], tIDSend, true, false)
-		CheckForSelectCaseWithoutPartnerChannel(id, vc[routRecv], tIDRecv, false, false)
+	if s.analysisCases["selectWithoutPartner"] {
+		s.CheckForSelectCaseWithoutPartnerChannel(id, vc[routSend], tIDSend, true, false)
+		s.CheckForSelectCaseWithoutPartnerChannel(id, vc[routRecv], tIDRecv, false, false)
 	}
 
-	if analysisCases["leak"] {
-		CheckForLeakChannelRun(routSend, id, VectorClockTID{vc[routSend].Copy(), tIDSend, routSend}, 0, false)
-		CheckForLeakChannelRun(routRecv, id, VectorClockTID{vc[routRecv].Copy(), tIDRecv, routRecv}, 1, false)
+	if s.analysisCases["leak"] {
+		s.CheckForLeakChannelRun(routSend, id, VectorClockTID{vc[routSend].Copy(), tIDSend, routSend}, 0, false)
+		s.CheckForLeakChannelRun(routRecv, id, VectorClockTID{vc[routRecv].Copy(), tIDRecv, routRecv}, 1, false)
 	}
 
 }
@@ -97,9 +97,6 @@ type holdObj struct {
 	tPost int
 }
 
-var holdSend = make([]holdObj, 0)
-var holdRecv = make([]holdObj, 0)
-
 /*
  * Update and calculate the vector clocks given a send on a buffered channel.
  * Args:
@@ -112,7 +109,7 @@ var holdRecv = make([]holdObj, 0)
  *  fifo (bool): true if the channel buffer is assumed to be fifo
  *  tPost (int): the timestamp at the end of the event
  */
-func Send(rout int, id int, oID int, size int, tID string,
+func (s *State) Send(rout int, id int, oID int, size int, tID string,
 	vc map[int]clock.VectorClock, fifo bool, tPost int) {
 
 	if tPost == 0 {
@@ -120,59 +117,59 @@ func Send(rout int, id int, oID int, size int, tID string,
 		return
 	}
 
-	if mostRecentSend[rout] == nil {
-		mostRecentSend[rout] = make(map[int]VectorClockTID3)
+	if s.mostRecentSend[rout] == nil {
+		s.mostRecentSend[rout] = make(map[int]VectorClockTID3)
 	}
 
-	newBufferedVCs(id, size, vc[rout].GetSize())
+	s.newBufferedVCs(id, size, vc[rout].GetSize())
 
-	count := bufferedVCsCount[id]
+	count := s.bufferedVCsCount[id]
 
-	if len(bufferedVCs[id]) <= count {
-		holdSend = append(holdSend, holdObj{rout, id, oID, size, tID, vc, fifo, tPost})
+	if len(s.bufferedVCs[id]) <= count {
+		s.holdSend = append(s.holdSend, holdObj{rout, id, oID, size, tID, vc, fifo, tPost})
 		return
 		// panic("BufferedVCsCount is bigger than the buffer size for chan " + strconv.Itoa(id) + " with count " + strconv.Itoa(count) + " and size " + strconv.Itoa(size) + "\n\tand tID " + tID)
 	}
 
-	if count > size || bufferedVCs[id][count].occupied {
+	if count > size || s.bufferedVCs[id][count].occupied {
 		logging.Debug("Write to occupied buffer position or to big count", logging.ERROR)
 	}
 
-	v := bufferedVCs[id][count].vc
+	v := s.bufferedVCs[id][count].vc
 	vc[rout] = vc[rout].Sync(v)
 
 	if fifo {
-		vc[rout] = vc[rout].Sync(mostRecentSend[rout][id].Vc)
+		vc[rout] = vc[rout].Sync(s.mostRecentSend[rout][id].Vc)
 	}
 
-	bufferedVCs[id][count] = bufferedVC{true, oID, vc[rout].Copy(), rout, tID}
+	s.bufferedVCs[id][count] = bufferedVC{true, oID, vc[rout].Copy(), rout, tID}
 
-	bufferedVCsCount[id]++
+	s.bufferedVCsCount[id]++
 
 	// for detection of send on closed
-	hasSend[id] = true
-	mostRecentSend[rout][id] = VectorClockTID3{rout, tID, mostRecentSend[rout][id].Vc.Sync(vc[rout]), id}
+	s.hasSend[id] = true
+	s.mostRecentSend[rout][id] = VectorClockTID3{rout, tID, s.mostRecentSend[rout][id].Vc.Sync(vc[rout]), id}
 
 	vc[rout] = vc[rout].Inc(rout)
 
-	if analysisCases["sendOnClosed"] {
-		if _, ok := closeData[id]; ok {
-			foundSendOnClosedChannel(rout, id, tID)
+	if s.analysisCases["sendOnClosed"] {
+		if _, ok := s.closeData[id]; ok {
+			s.foundSendOnClosedChannel(rout, id, tID)
 		}
 	}
 
-	if analysisCases["selectWithoutPartner"] {
-		CheckForSelectCaseWithoutPartnerChannel(id, vc[rout], tID, true, true)
+	if s.analysisCases["selectWithoutPartner"] {
+		s.CheckForSelectCaseWithoutPartnerChannel(id, vc[rout], tID, true, true)
 	}
 
-	if analysisCases["leak"] {
-		CheckForLeakChannelRun(rout, id, VectorClockTID{vc[rout].Copy(), tID, rout}, 0, true)
+	if s.analysisCases["leak"] {
+		s.CheckForLeakChannelRun(rout, id, VectorClockTID{vc[rout].Copy(), tID, rout}, 0, true)
 	}
 
-	for i, hold := range holdRecv {
+	for i, hold := range s.holdRecv {
 		if hold.id == id {
-			Recv(hold.rout, hold.id, hold.oID, hold.size, hold.tID, hold.vc, hold.fifo, hold.tPost)
-			holdRecv = append(holdRecv[:i], holdRecv[i+1:]...)
+			s.Recv(hold.rout, hold.id, hold.oID, hold.size, hold.tID, hold.vc, hold.fifo, hold.tPost)
+			s.holdRecv = append(s.holdRecv[:i], s.holdRecv[i+1:]...)
 			break
 		}
 	}
@@ -191,11 +188,11 @@ func Send(rout int, id int, oID int, size int, tID string,
  *  fifo (bool): true if the channel buffer is assumed to be fifo
  *  tPost (int): the timestamp at the end of the event
  */
-func Recv(rout int, id int, oID, size int, tID string, vc map[int]clock.VectorClock,
+func (s *State) Recv(rout int, id int, oID, size int, tID string, vc map[int]clock.VectorClock,
 	fifo bool, tPost int) {
 
-	if analysisCases["concurrentRecv"] {
-		checkForConcurrentRecv(rout, id, tID, vc, tPost)
+	if s.analysisCases["concurrentRecv"] {
+		s.checkForConcurrentRecv(rout, id, tID, vc, tPost)
 	}
 
 	if tPost == 0 {
@@ -203,26 +200,26 @@ func Recv(rout int, id int, oID, size int, tID string, vc map[int]clock.VectorCl
 		return
 	}
 
-	if mostRecentReceive[rout] == nil {
-		mostRecentReceive[rout] = make(map[int]VectorClockTID3)
+	if s.mostRecentReceive[rout] == nil {
+		s.mostRecentReceive[rout] = make(map[int]VectorClockTID3)
 	}
 
-	newBufferedVCs(id, size, vc[rout].GetSize())
+	s.newBufferedVCs(id, size, vc[rout].GetSize())
 
-	if bufferedVCsCount[id] == 0 {
-		holdSend = append(holdSend, holdObj{rout, id, oID, size, tID, vc, fifo, tPost})
+	if s.bufferedVCsCount[id] == 0 {
+		s.holdSend = append(s.holdSend, holdObj{rout, id, oID, size, tID, vc, fifo, tPost})
 		return
 		// logging.Debug("Read operation on empty buffer position", logging.ERROR)
 	}
-	bufferedVCsCount[id]--
+	s.bufferedVCsCount[id]--
 
-	if bufferedVCs[id][0].oID != oID {
+	if s.bufferedVCs[id][0].oID != oID {
 		found := false
 		for i := 1; i < size; i++ {
-			if bufferedVCs[id][i].oID == oID {
+			if s.bufferedVCs[id][i].oID == oID {
 				found = true
-				bufferedVCs[id][0] = bufferedVCs[id][i]
-				bufferedVCs[id][i] = bufferedVC{false, 0, vc[rout].Copy(), 0, ""}
+				s.bufferedVCs[id][0] = s.bufferedVCs[id][i]
+				s.bufferedVCs[id][i] = bufferedVC{false, 0, vc[rout].Copy(), 0, ""}
 				break
 			}
 		}
@@ -231,40 +228,40 @@ func Recv(rout int, id int, oID, size int, tID string, vc map[int]clock.VectorCl
 			logging.Debug(err, logging.INFO)
 		}
 	}
-	v := bufferedVCs[id][0].vc
-	routSend := bufferedVCs[id][0].routineSend
-	tIDSend := bufferedVCs[id][0].tID
+	v := s.bufferedVCs[id][0].vc
+	routSend := s.bufferedVCs[id][0].routineSend
+	tIDSend := s.bufferedVCs[id][0].tID
 
 	vc[rout] = vc[rout].Sync(v)
 
 	if fifo {
-		vc[rout] = vc[rout].Sync(mostRecentReceive[rout][id].Vc)
+		vc[rout] = vc[rout].Sync(s.mostRecentReceive[rout][id].Vc)
 	}
 
-	bufferedVCs[id] = bufferedVCs[id][1:]
-	bufferedVCs[id] = append(bufferedVCs[id], bufferedVC{false, 0, vc[rout].Copy(), 0, ""})
+	s.bufferedVCs[id] = s.bufferedVCs[id][1:]
+	s.bufferedVCs[id] = append(s.bufferedVCs[id], bufferedVC{false, 0, vc[rout].Copy(), 0, ""})
 
 	// for detection of receive on closed
-	hasReceived[id] = true
-	mostRecentReceive[rout][id] = VectorClockTID3{rout, tID, mostRecentReceive[rout][id].Vc.Sync(vc[rout]), id}
+	s.hasReceived[id] = true
+	s.mostRecentReceive[rout][id] = VectorClockTID3{rout, tID, s.mostRecentReceive[rout][id].Vc.Sync(vc[rout]), id}
 
 	vc[rout] = vc[rout].Inc(rout)
 
-	if analysisCases["selectWithoutPartner"] {
-		CheckForSelectCaseWithoutPartnerChannel(id, vc[rout], tID, true, true)
+	if s.analysisCases["selectWithoutPartner"] {
+		s.CheckForSelectCaseWithoutPartnerChannel(id, vc[rout], tID, true, true)
 	}
 
-	if analysisCases["mixedDeadlock"] {
-		checkForMixedDeadlock(routSend, rout, tIDSend, tID)
+	if s.analysisCases["mixedDeadlock"] {
+		s.checkForMixedDeadlock(routSend, rout, tIDSend, tID)
 	}
-	if analysisCases["leak"] {
-		CheckForLeakChannelRun(rout, id, VectorClockTID{vc[rout].Copy(), tID, rout}, 1, true)
+	if s.analysisCases["leak"] {
+		s.CheckForLeakChannelRun(rout, id, VectorClockTID{vc[rout].Copy(), tID, rout}, 1, true)
 	}
 
-	for i, hold := range holdSend {
+	for i, hold := range s.holdSend {
 		if hold.id == id {
-			Send(hold.rout, hold.id, hold.oID, hold.size, hold.tID, hold.vc, hold.fifo, hold.tPost)
-			holdSend = append(holdSend[:i], holdSend[i+1:]...)
+			s.Send(hold.rout, hold.id, hold.oID, hold.size, hold.tID, hold.vc, hold.fifo, hold.tPost)
+			s.holdSend = append(s.holdSend[:i], s.holdSend[i+1:]...)
 			break
 		}
 	}
@@ -290,35 +287,38 @@ func StuckChan(routine int, vc map[int]clock.VectorClock) {
  *  tPost (int): the timestamp at the end of the event
  *  buffered (bool): true if the channel is buffered
  */
-func Close(rout int, id int, tID string, vc map[int]clock.VectorClock, tPost int, buffered bool) {
+func (s *State) Close(rout int, id int, tID string, vc map[int]clock.VectorClock, tPost int, buffered bool) {
 	if tPost == 0 {
 		return
 	}
 
-	if analysisCases["closeOnClosed"] {
-		checkForClosedOnClosed(rout, id, tID) // must be called before closePos is updated
+	if s.analysisCases["closeOnClosed"] {
+		s.checkForClosedOnClosed(rout, id, tID) // must be called before closePos is updated
 	}
 
 	vc[rout] = vc[rout].Inc(rout)
 
-	closeData[id] = VectorClockTID3{Routine: rout, TID: tID, Vc: vc[rout].Copy(), Val: id}
+	s.closeData[id] = VectorClockTID3{Routine: rout, TID: tID, Vc: vc[rout].Copy(), Val: id}
+	if guard, ok := s.defaultSelect[rout][id]; ok {
+		s.closeGuard[id] = guard
+	}
 
-	if analysisCases["sendOnClosed"] || analysisCases["receiveOnClosed"] {
-		checkForCommunicationOnClosedChannel(id, tID)
+	if s.analysisCases["sendOnClosed"] || s.analysisCases["receiveOnClosed"] {
+		s.checkForCommunicationOnClosedChannel(id, tID)
 	}
 
-	if analysisCases["selectWithoutPartner"] {
-		CheckForSelectCaseWithoutPartnerClose(id, vc[rout])
+	if s.analysisCases["selectWithoutPartner"] {
+		s.CheckForSelectCaseWithoutPartnerClose(id, vc[rout])
 	}
 
-	if analysisCases["leak"] {
-		CheckForLeakChannelRun(rout, id, VectorClockTID{vc[rout].Copy(), tID, rout}, 2, true)
+	if s.analysisCases["leak"] {
+		s.CheckForLeakChannelRun(rout, id, VectorClockTID{vc[rout].Copy(), tID, rout}, 2, true)
 	}
 }
 
-func SendC(rout int, id int, tID string) {
-	if analysisCases["sendOnClosed"] {
-		foundSendOnClosedChannel(rout, id, tID)
+func (s *State) SendC(rout int, id int, tID string) {
+	if s.analysisCases["sendOnClosed"] {
+		s.foundSendOnClosedChannel(rout, id, tID)
 	}
 }
 
@@ -332,28 +332,28 @@ func SendC(rout int, id int, tID string) {
  *  tPost (int): the timestamp at the end of the event
  *  buffered (bool): true if the channel is buffered
  */
-func RecvC(rout int, id int, tID string, vc map[int]clock.VectorClock, tPost int,
+func (s *State) RecvC(rout int, id int, tID string, vc map[int]clock.VectorClock, tPost int,
 	buffered bool) {
 	if tPost == 0 {
 		return
 	}
 
-	if analysisCases["receiveOnClosed"] {
-		foundReceiveOnClosedChannel(rout, id, tID)
+	if s.analysisCases["receiveOnClosed"] {
+		s.foundReceiveOnClosedChannel(rout, id, tID)
 	}
 
-	vc[rout] = vc[rout].Sync(closeData[id].Vc)
+	vc[rout] = vc[rout].Sync(s.closeData[id].Vc)
 	vc[rout] = vc[rout].Inc(rout)
 
-	if analysisCases["selectWithoutPartner"] {
-		CheckForSelectCaseWithoutPartnerChannel(id, vc[rout], tID, false, buffered)
+	if s.analysisCases["selectWithoutPartner"] {
+		s.CheckForSelectCaseWithoutPartnerChannel(id, vc[rout], tID, false, buffered)
 	}
 
-	if analysisCases["mixedDeadlock"] {
-		checkForMixedDeadlock(closeData[id].Routine, rout, closeData[id].TID, tID)
+	if s.analysisCases["mixedDeadlock"] {
+		s.checkForMixedDeadlock(s.closeData[id].Routine, rout, s.closeData[id].TID, tID)
 	}
-	if analysisCases["leak"] {
-		CheckForLeakChannelRun(rout, id, VectorClockTID{vc[rout].Copy(), tID, rout}, 1, buffered)
+	if s.analysisCases["leak"] {
+		s.CheckForLeakChannelRun(rout, id, VectorClockTID{vc[rout].Copy(), tID, rout}, 1, buffered)
 	}
 }
 
@@ -365,12 +365,12 @@ func RecvC(rout int, id int, tID string, vc map[int]clock.VectorClock, tPost int
  * 	size (int): the buffer size of the channel
  * 	numRout (int): the number of routines
  */
-func newBufferedVCs(id int, size int, numRout int) {
-	if _, ok := bufferedVCs[id]; !ok {
-		bufferedVCs[id] = make([]bufferedVC, size)
+func (s *State) newBufferedVCs(id int, size int, numRout int) {
+	if _, ok := s.bufferedVCs[id]; !ok {
+		s.bufferedVCs[id] = make([]bufferedVC, size)
 		for i := 0; i < size; i++ {
-			bufferedVCsCount[id] = 0
-			bufferedVCs[id][i] = bufferedVC{false, 0, clock.NewVectorClock(numRout), 0, ""}
+			s.bufferedVCsCount[id] = 0
+			s.bufferedVCs[id][i] = bufferedVC{false, 0, clock.NewVectorClock(numRout), 0, ""}
 		}
 	}
 }
@@ -384,12 +384,12 @@ func newBufferedVCs(id int, size int, numRout int) {
  *  vc (VectorClock): the vector clock of the operation
  *  tID (string): the position of the send in the program
  */
-func SetChannelAsLastSend(id int, rout int, vc clock.VectorClock, tID string) {
-	if mostRecentSend[rout] == nil {
-		mostRecentSend[rout] = make(map[int]VectorClockTID3)
+func (s *State) SetChannelAsLastSend(id int, rout int, vc clock.VectorClock, tID string) {
+	if s.mostRecentSend[rout] == nil {
+		s.mostRecentSend[rout] = make(map[int]VectorClockTID3)
 	}
-	mostRecentSend[rout][id] = VectorClockTID3{rout, tID, vc, id}
-	hasSend[id] = true
+	s.mostRecentSend[rout][id] = VectorClockTID3{rout, tID, vc, id}
+	s.hasSend[id] = true
 }
 
 /*
@@ -401,10 +401,10 @@ func SetChannelAsLastSend(id int, rout int, vc clock.VectorClock, tID string) {
  *  vc (VectorClock): the vector clock of the operation
  *  tID (string): the position of the recv in the program
  */
-func SetChannelAsLastReceive(id int, rout int, vc clock.VectorClock, tID string) {
-	if mostRecentReceive[rout] == nil {
-		mostRecentReceive[rout] = make(map[int]VectorClockTID3)
+func (s *State) SetChannelAsLastReceive(id int, rout int, vc clock.VectorClock, tID string) {
+	if s.mostRecentReceive[rout] == nil {
+		s.mostRecentReceive[rout] = make(map[int]VectorClockTID3)
 	}
-	mostRecentReceive[rout][id] = VectorClockTID3{rout, tID, vc, id}
-	hasReceived[id] = true
+	s.mostRecentReceive[rout][id] = VectorClockTID3{rout, tID, vc, id}
+	s.hasReceived[id] = true
 }
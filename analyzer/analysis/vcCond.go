@@ -2,19 +2,24 @@ package analysis
 
 import "analyzer/clock"
 
-var lastCondRelease = make(map[int]int) // -> id -> routine
-
 /*
  * Update and calculate the vector clocks given a wait operation
  * Args:
  *   id (int): The id of the condition variable
  *   routine (int): The routine id
+ *   tID (string): The id of the trace element, contains the position and the tpre
+ *   tPre (int): The tpre of the operation
+ *   tPost (int): The tpost of the operation, 0 if the operation is a leak
  *   vc (map[int]VectorClock): The current vector clocks
- *   leak (bool): If the operation is a leak (tPost = 0)
  */
-func CondWait(id int, routine int, vc map[int]clock.VectorClock, leak bool) {
-	if !leak {
-		vc[routine].Sync(vc[lastCondRelease[id]])
+func (s *State) CondWait(id int, routine int, tID string, tPre int, tPost int, vc map[int]clock.VectorClock) {
+	if s.analysisCases["lostWakeup"] {
+		s.condWait[id] = append(s.condWait[id],
+			condOp{routine, tID, tPre, tPost, "NW", vc[routine].Copy().Inc(routine)})
+	}
+
+	if tPost != 0 {
+		vc[routine].Sync(vc[s.lastCondRelease[id]])
 	}
 	vc[routine].Inc(routine)
 }
@@ -24,12 +29,18 @@ func CondWait(id int, routine int, vc map[int]clock.VectorClock, leak bool) {
  * Args:
  *   id (int): The id of the condition variable
  *   routine (int): The routine id
+ *   tID (string): The id of the trace element, contains the position and the tpre
+ *   tPre (int): The tpre of the operation
  *   vc (map[int]VectorClock): The current vector clocks
  */
-func CondSignal(id int, routine int, vc map[int]clock.VectorClock) {
+func (s *State) CondSignal(id int, routine int, tID string, tPre int, vc map[int]clock.VectorClock) {
 	vc[routine].Inc(routine)
 
-	lastCondRelease[id] = routine
+	s.lastCondRelease[id] = routine
+
+	if s.analysisCases["lostWakeup"] {
+		s.condRelease[id] = append(s.condRelease[id], condOp{routine, tID, tPre, tPre, "NS", vc[routine].Copy()})
+	}
 }
 
 /*
@@ -37,9 +48,15 @@ func CondSignal(id int, routine int, vc map[int]clock.VectorClock) {
  * Args:
  *   id (int): The id of the condition variable
  *   routine (int): The routine id
+ *   tID (string): The id of the trace element, contains the position and the tpre
+ *   tPre (int): The tpre of the operation
  *   vc (map[int]VectorClock): The current vector clocks
  */
-func CondBroadcast(id int, routine int, vc map[int]clock.VectorClock) {
+func (s *State) CondBroadcast(id int, routine int, tID string, tPre int, vc map[int]clock.VectorClock) {
 	vc[routine].Inc(routine)
-	lastCondRelease[id] = routine
+	s.lastCondRelease[id] = routine
+
+	if s.analysisCases["lostWakeup"] {
+		s.condRelease[id] = append(s.condRelease[id], condOp{routine, tID, tPre, tPre, "NB", vc[routine].Copy()})
+	}
 }
@@ -8,12 +8,12 @@ import "analyzer/clock"
  *   index (int): The id of the atomic variable
  *   nRout (int): The number of routines in the trace
  */
-func newRel(index int, nRout int) {
-	if _, ok := relW[index]; !ok {
-		relW[index] = clock.NewVectorClock(nRout)
+func (s *State) newRel(index int, nRout int) {
+	if _, ok := s.relW[index]; !ok {
+		s.relW[index] = clock.NewVectorClock(nRout)
 	}
-	if _, ok := relR[index]; !ok {
-		relR[index] = clock.NewVectorClock(nRout)
+	if _, ok := s.relR[index]; !ok {
+		s.relR[index] = clock.NewVectorClock(nRout)
 	}
 }
 
@@ -27,23 +27,23 @@ func newRel(index int, nRout int) {
  *   tID (string): The trace id of the lock operation
  *   tPost (int): The timestamp at the end of the event
  */
-func Lock(routine int, id int, vc map[int]clock.VectorClock, wVc map[int]clock.VectorClock, tID string, tPost int) {
+func (s *State) Lock(routine int, id int, vc map[int]clock.VectorClock, wVc map[int]clock.VectorClock, tID string, tPost int) {
 	if tPost == 0 {
 		vc[routine] = vc[routine].Inc(routine)
 		return
 	}
 
-	newRel(id, vc[routine].GetSize())
-	vc[routine] = vc[routine].Sync(relW[id])
-	vc[routine] = vc[routine].Sync(relR[id])
+	s.newRel(id, vc[routine].GetSize())
+	vc[routine] = vc[routine].Sync(s.relW[id])
+	vc[routine] = vc[routine].Sync(s.relR[id])
 	vc[routine] = vc[routine].Inc(routine)
 
-	if analysisCases["leak"] {
-		addMostRecentAcquireTotal(routine, id, tID, vc[routine], 0)
+	if s.analysisCases["leak"] {
+		s.addMostRecentAcquireTotal(routine, id, tID, vc[routine], 0)
 	}
 
-	if analysisCases["mixedDeadlock"] {
-		lockSetAddLock(routine, id, tID, wVc[routine])
+	if s.analysisCases["mixedDeadlock"] || s.analysisCases["blockingInCriticalSection"] {
+		s.lockSetAddLock(routine, id, tID, wVc[routine], false)
 	}
 }
 
@@ -54,18 +54,18 @@ func Lock(routine int, id int, vc map[int]clock.VectorClock, wVc map[int]clock.V
  *   id (int): The id of the mutex
  *   vc (map[int]VectorClock): The current vector clocks
  */
-func Unlock(routine int, id int, vc map[int]clock.VectorClock, tPost int) {
+func (s *State) Unlock(routine int, id int, vc map[int]clock.VectorClock, tPost int) {
 	if tPost == 0 {
 		return
 	}
 
-	newRel(id, vc[routine].GetSize())
-	relW[id] = vc[routine].Copy()
-	relR[id] = vc[routine].Copy()
+	s.newRel(id, vc[routine].GetSize())
+	s.relW[id] = vc[routine].Copy()
+	s.relR[id] = vc[routine].Copy()
 	vc[routine] = vc[routine].Inc(routine)
 
-	if analysisCases["mixedDeadlock"] {
-		lockSetRemoveLock(routine, id)
+	if s.analysisCases["mixedDeadlock"] || s.analysisCases["blockingInCriticalSection"] {
+		s.lockSetRemoveLock(routine, id)
 	}
 }
 
@@ -80,7 +80,7 @@ func Unlock(routine int, id int, vc map[int]clock.VectorClock, tPost int) {
  * Returns:
  *   (vectorClock): The new vector clock
  */
-func RLock(routine int, id int, vc map[int]clock.VectorClock, wVc map[int]clock.VectorClock,
+func (s *State) RLock(routine int, id int, vc map[int]clock.VectorClock, wVc map[int]clock.VectorClock,
 	tID string, tPost int) {
 
 	if tPost == 0 {
@@ -88,16 +88,16 @@ func RLock(routine int, id int, vc map[int]clock.VectorClock, wVc map[int]clock.
 		return
 	}
 
-	newRel(id, vc[routine].GetSize())
-	vc[routine] = vc[routine].Sync(relW[id])
+	s.newRel(id, vc[routine].GetSize())
+	vc[routine] = vc[routine].Sync(s.relW[id])
 	vc[routine] = vc[routine].Inc(routine)
 
-	if analysisCases["leak"] {
-		addMostRecentAcquireTotal(routine, id, tID, vc[routine], 1)
+	if s.analysisCases["leak"] {
+		s.addMostRecentAcquireTotal(routine, id, tID, vc[routine], 1)
 	}
 
-	if analysisCases["mixedDeadlock"] {
-		lockSetAddLock(routine, id, tID, wVc[routine])
+	if s.analysisCases["mixedDeadlock"] || s.analysisCases["blockingInCriticalSection"] {
+		s.lockSetAddLock(routine, id, tID, wVc[routine], true)
 	}
 }
 
@@ -109,17 +109,17 @@ func RLock(routine int, id int, vc map[int]clock.VectorClock, wVc map[int]clock.
  *   vc (map[int]VectorClock): The current vector clocks
  *   tPost (int): The timestamp at the end of the event
  */
-func RUnlock(routine int, id int, vc map[int]clock.VectorClock, tPost int) {
+func (s *State) RUnlock(routine int, id int, vc map[int]clock.VectorClock, tPost int) {
 	if tPost == 0 {
 		vc[routine] = vc[routine].Inc(routine)
 		return
 	}
 
-	newRel(id, vc[routine].GetSize())
-	relR[id] = relR[id].Sync(vc[routine])
+	s.newRel(id, vc[routine].GetSize())
+	s.relR[id] = s.relR[id].Sync(vc[routine])
 	vc[routine] = vc[routine].Inc(routine)
 
-	if analysisCases["mixedDeadlock"] {
-		lockSetRemoveLock(routine, id)
+	if s.analysisCases["mixedDeadlock"] || s.analysisCases["blockingInCriticalSection"] {
+		s.lockSetRemoveLock(routine, id)
 	}
 }
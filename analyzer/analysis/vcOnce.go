@@ -2,18 +2,15 @@ package analysis
 
 import "analyzer/clock"
 
-// vector clocks for the successful do
-var oSuc map[int]clock.VectorClock = make(map[int]clock.VectorClock)
-
 /*
  * Create a new oSuc if needed
  * Args:
  *   index (int): The id of the atomic variable
  *   nRout (int): The number of routines in the trace
  */
-func newOSuc(index int, nRout int) {
-	if _, ok := oSuc[index]; !ok {
-		oSuc[index] = clock.NewVectorClock(nRout)
+func (s *State) newOSuc(index int, nRout int) {
+	if _, ok := s.oSuc[index]; !ok {
+		s.oSuc[index] = clock.NewVectorClock(nRout)
 	}
 }
 
@@ -24,9 +21,9 @@ func newOSuc(index int, nRout int) {
  *   id (int): The id of the atomic variable
  *   vc (map[int]VectorClock): The current vector clocks
  */
-func DoSuc(routine int, id int, vc map[int]clock.VectorClock) {
-	newOSuc(id, vc[id].GetSize())
-	oSuc[id] = vc[routine]
+func (s *State) DoSuc(routine int, id int, vc map[int]clock.VectorClock) {
+	s.newOSuc(id, vc[id].GetSize())
+	s.oSuc[id] = vc[routine]
 	vc[routine] = vc[routine].Inc(routine)
 }
 
@@ -37,8 +34,8 @@ func DoSuc(routine int, id int, vc map[int]clock.VectorClock) {
  *   id (int): The id of the atomic variable
  *   vc (map[int]VectorClock): The current vector clocks
  */
-func DoFail(routine int, id int, vc map[int]clock.VectorClock) {
-	newOSuc(id, vc[id].GetSize())
-	vc[routine] = vc[routine].Sync(oSuc[id])
+func (s *State) DoFail(routine int, id int, vc map[int]clock.VectorClock) {
+	s.newOSuc(id, vc[id].GetSize())
+	vc[routine] = vc[routine].Sync(s.oSuc[id])
 	vc[routine] = vc[routine].Inc(routine)
 }
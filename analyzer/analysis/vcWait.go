@@ -2,18 +2,15 @@ package analysis
 
 import "analyzer/clock"
 
-// vector clock for each wait group
-var wg map[int]clock.VectorClock = make(map[int]clock.VectorClock)
-
 /*
  * Create a new wg if needed
  * Args:
  *   index (int): The id of the wait group
  *   nRout (int): The number of routines in the trace
  */
-func newWg(index int, nRout int) {
-	if _, ok := wg[index]; !ok {
-		wg[index] = clock.NewVectorClock(nRout)
+func (s *State) newWg(index int, nRout int) {
+	if _, ok := s.wg[index]; !ok {
+		s.wg[index] = clock.NewVectorClock(nRout)
 	}
 }
 
@@ -26,13 +23,17 @@ func newWg(index int, nRout int) {
  *   tID (string): The id of the trace element, contains the position and the tpre
  *   vc (map[int]VectorClock): The vector clocks
  */
-func Change(routine int, id int, delta int, tID string, vc map[int]clock.VectorClock) {
-	newWg(id, vc[id].GetSize())
-	wg[id] = wg[id].Sync(vc[routine])
+func (s *State) Change(routine int, id int, delta int, tID string, vc map[int]clock.VectorClock) {
+	s.newWg(id, vc[id].GetSize())
+	s.wg[id] = s.wg[id].Sync(vc[routine])
 	vc[routine] = vc[routine].Inc(routine)
 
-	if analysisCases["doneBeforeAdd"] {
-		checkForDoneBeforeAddChange(routine, id, delta, tID, vc[routine])
+	if s.analysisCases["doneBeforeAdd"] {
+		s.checkForDoneBeforeAddChange(routine, id, delta, tID, vc[routine])
+	}
+
+	if s.analysisCases["addConcurrentWait"] || s.analysisCases["waitGroupReuse"] {
+		s.updateWaitGroupMisuseChange(routine, id, delta, tID, vc[routine])
 	}
 }
 
@@ -41,13 +42,19 @@ func Change(routine int, id int, delta int, tID string, vc map[int]clock.VectorC
  * Args:
  *   routine (int): The routine id
  *   id (int): The id of the wait group
+ *   tID (string): The id of the trace element, contains the position and the tpre
  *   vc (*map[int]VectorClock): The vector clocks
  *   notLeak (bool): If the wait group is not leaked (tpost = 0)
  */
-func Wait(routine int, id int, tID string, vc map[int]clock.VectorClock, notLeak bool) {
-	newWg(id, vc[id].GetSize())
+func (s *State) Wait(routine int, id int, tID string, vc map[int]clock.VectorClock, notLeak bool) {
+	s.newWg(id, vc[id].GetSize())
+	vcPre := vc[routine].Copy().Inc(routine) // clock of the start of the wait
 	if notLeak {
-		vc[routine] = vc[routine].Sync(wg[id])
+		vc[routine] = vc[routine].Sync(s.wg[id])
 		vc[routine] = vc[routine].Inc(routine)
 	}
+
+	if s.analysisCases["addConcurrentWait"] || s.analysisCases["waitGroupReuse"] {
+		s.updateWaitGroupMisuseWait(id, waitVC{routine, tID, vcPre, vc[routine].Copy(), notLeak})
+	}
 }
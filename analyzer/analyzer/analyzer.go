@@ -0,0 +1,243 @@
+// Package analyzer provides the analysis of ADVOCATE traces as a library.
+// All state of an analysis is stored in an Analyzer, so that multiple traces
+// can be analyzed in the same process, also at the same time.
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+
+	"analyzer/io"
+	"analyzer/logging"
+	"analyzer/trace"
+)
+
+/*
+ * Options for an analysis run
+ * Fields:
+ *   Fifo (bool): Assume a FIFO ordering for buffered channels
+ *   IgnoreCriticalSections (bool): Ignore happens before relations of critical sections
+ *   AnalysisCases (map[string]bool): The analysis cases to run, see ParseAnalysisCases.
+ *     If nil, all analysis cases are run
+ *   Detectors ([]trace.Detector): Additional detectors, that are run independent
+ *     of the analysis cases
+ */
+type Options struct {
+	Fifo                   bool
+	IgnoreCriticalSections bool
+	AnalysisCases          map[string]bool
+	Detectors              []trace.Detector
+}
+
+/*
+ * Analyzer contains a trace and the results of its analysis
+ * Fields:
+ *   trace (*trace.Trace): The loaded trace
+ *   numberOfRoutines (int): The number of routines in the trace
+ *   results (*logging.Results): The results of the last run
+ */
+type Analyzer struct {
+	trace            *trace.Trace
+	numberOfRoutines int
+	results          *logging.Results
+}
+
+/*
+ * Create a new analyzer
+ * Returns:
+ *   *Analyzer: The new analyzer
+ */
+func New() *Analyzer {
+	return &Analyzer{
+		results: logging.NewResults(),
+	}
+}
+
+/*
+ * Load the trace that should be analyzed
+ * Args:
+ *   path (string): The path to the trace folder
+ *   ignoreAtomics (bool): If atomic operations should be ignored
+ * Returns:
+ *   error: An error if the trace could not be read
+ */
+func (a *Analyzer) LoadTrace(path string, ignoreAtomics bool) error {
+	t, numberOfRoutines, err := io.CreateTraceFromFiles(path, ignoreAtomics)
+	if err != nil {
+		return err
+	}
+
+	a.trace = t
+	a.numberOfRoutines = numberOfRoutines
+	a.results = logging.NewResults()
+	return nil
+}
+
+/*
+ * Run the analysis on the loaded trace. The results of a previous run are
+ * discarded.
+ * Args:
+ *   options (Options): The options of the analysis
+ * Returns:
+ *   error: An error if no trace has been loaded
+ */
+func (a *Analyzer) Run(options Options) error {
+	if a.trace == nil {
+		return errors.New("No trace loaded")
+	}
+
+	analysisCases := options.AnalysisCases
+	if analysisCases == nil {
+		analysisCases, _ = ParseAnalysisCases("")
+	}
+
+	detectors := createDetectors(analysisCases)
+	detectors = append(detectors, options.Detectors...)
+
+	a.results = logging.NewResults()
+	a.trace.RunAnalysis(options.Fifo, options.IgnoreCriticalSections, analysisCases, a.results, detectors)
+	return nil
+}
+
+/*
+ * Get the results of the last run
+ * Returns:
+ *   *logging.Results: The results
+ */
+func (a *Analyzer) Results() *logging.Results {
+	return a.results
+}
+
+/*
+ * Get the loaded trace
+ * Returns:
+ *   *trace.Trace: The trace, nil if no trace has been loaded
+ */
+func (a *Analyzer) Trace() *trace.Trace {
+	return a.trace
+}
+
+/*
+ * Get the number of routines in the loaded trace
+ * Returns:
+ *   int: The number of routines
+ */
+func (a *Analyzer) NumberOfRoutines() int {
+	return a.numberOfRoutines
+}
+
+/*
+ * Get the built-in analysis cases, all disabled
+ * Returns:
+ *   map[string]bool: A map of the analysis cases
+ */
+func builtinAnalysisCases() map[string]bool {
+	return map[string]bool{
+		"all":                       false, // all cases enabled
+		"sendOnClosed":              false,
+		"receiveOnClosed":           false,
+		"doneBeforeAdd":             false,
+		"addConcurrentWait":         false,
+		"waitGroupReuse":            false,
+		"lostWakeup":                false,
+		"atomicityViolation":        false,
+		"dataRace":                  false,
+		"blockingInCriticalSection": false,
+		"closeOnClosed":             false,
+		"concurrentRecv":            false,
+		"leak":                      false,
+		"partialDeadlock":           false,
+		"onceMisuse":                false,
+		"selectWithoutPartner":      false,
+		"cyclicDeadlock":            false,
+		"mixedDeadlock":             false,
+	}
+}
+
+/*
+ * Parse the given analysis cases. Registered detectors are selected with
+ * their letter and are contained in the map with their name.
+ * Args:
+ *   cases (string): The string of analysis cases to parse
+ * Returns:
+ *   map[string]bool: A map of the analysis cases and if they are set
+ *   error: An error if the cases could not be parsed
+ */
+func ParseAnalysisCases(cases string) (map[string]bool, error) {
+	analysisCases := builtinAnalysisCases()
+	for _, name := range GetRegisteredDetectors() {
+		analysisCases[name] = false
+	}
+
+	if cases == "" {
+		analysisCases["all"] = true
+		analysisCases["sendOnClosed"] = true
+		analysisCases["receiveOnClosed"] = true
+		analysisCases["doneBeforeAdd"] = true
+		analysisCases["addConcurrentWait"] = true
+		analysisCases["waitGroupReuse"] = true
+		analysisCases["lostWakeup"] = true
+		analysisCases["atomicityViolation"] = true
+		analysisCases["dataRace"] = true
+		analysisCases["blockingInCriticalSection"] = true
+		analysisCases["closeOnClosed"] = true
+		analysisCases["concurrentRecv"] = true
+		analysisCases["leak"] = true
+		analysisCases["partialDeadlock"] = true
+		analysisCases["onceMisuse"] = true
+		analysisCases["selectWithoutPartner"] = true
+		// analysisCases["cyclicDeadlock"] = true
+		// analysisCases["mixedDeadlock"] = true
+		for _, name := range GetRegisteredDetectors() {
+			analysisCases[name] = true
+		}
+
+		return analysisCases, nil
+	}
+
+	for _, c := range cases {
+		switch c {
+		case 's':
+			analysisCases["sendOnClosed"] = true
+		case 'r':
+			analysisCases["receiveOnClosed"] = true
+		case 'w':
+			analysisCases["doneBeforeAdd"] = true
+		case 'a':
+			analysisCases["addConcurrentWait"] = true
+		case 'g':
+			analysisCases["waitGroupReuse"] = true
+		case 'k':
+			analysisCases["lostWakeup"] = true
+		case 'v':
+			analysisCases["atomicityViolation"] = true
+		case 'd':
+			analysisCases["dataRace"] = true
+		case 'i':
+			analysisCases["blockingInCriticalSection"] = true
+		case 'n':
+			analysisCases["closeOnClosed"] = true
+		case 'b':
+			analysisCases["concurrentRecv"] = true
+		case 'l':
+			analysisCases["leak"] = true
+		case 'p':
+			analysisCases["partialDeadlock"] = true
+		case 'o':
+			analysisCases["onceMisuse"] = true
+		case 'u':
+			analysisCases["selectWithoutPartner"] = true
+		// case 'c':
+		// 	analysisCases["cyclicDeadlock"] = true
+		// case 'm':
+		// analysisCases["mixedDeadlock"] = true
+		default:
+			name, ok := getDetectorForLetter(c)
+			if !ok {
+				return nil, fmt.Errorf("Invalid analysis case: %c", c)
+			}
+			analysisCases[name] = true
+		}
+	}
+	return analysisCases, nil
+}
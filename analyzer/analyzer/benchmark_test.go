@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+/*
+ * Get the recorded and rewritten traces in the examples folder
+ * Args:
+ *   b (*testing.B): The benchmark
+ * Returns:
+ *   []string: The paths to the trace folders
+ */
+func exampleTraces(b *testing.B) []string {
+	paths, err := filepath.Glob("../../examples/*/advocateTrace")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	rewritten, err := filepath.Glob("../../examples/*/rewritten_trace_*")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	paths = append(paths, rewritten...)
+	if len(paths) == 0 {
+		b.Skip("no example traces found")
+	}
+
+	return paths
+}
+
+/*
+ * Benchmark loading and analyzing the example traces with all analysis cases.
+ * The analysis is dominated by the vector clock operations.
+ */
+func BenchmarkAnalysis(b *testing.B) {
+	for _, path := range exampleTraces(b) {
+		name, _ := filepath.Rel("../../examples", path)
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				a := New()
+				if err := a.LoadTrace(path, false); err != nil {
+					b.Fatal(err)
+				}
+				if err := a.Run(Options{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+/*
+ * Benchmark only the analysis of the example traces, without reading the files
+ */
+func BenchmarkAnalysisRun(b *testing.B) {
+	for _, path := range exampleTraces(b) {
+		name, _ := filepath.Rel("../../examples", path)
+		b.Run(name, func(b *testing.B) {
+			a := New()
+			if err := a.LoadTrace(path, false); err != nil {
+				b.Fatal(err)
+			}
+			original := a.Trace().CopyCurrentTrace()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				a.Trace().SetTrace(original)
+				b.StartTimer()
+
+				if err := a.Run(Options{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
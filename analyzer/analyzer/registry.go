@@ -0,0 +1,142 @@
+package analyzer
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"analyzer/trace"
+)
+
+/*
+ * Information about a registered detector
+ * Fields:
+ *   name (string): The name of the detector, used as key in the analysis cases
+ *   letter (rune): The letter to select the detector with the -s flag
+ *   newDetector (func() trace.Detector): Function to create a new instance of the detector
+ */
+type registeredDetector struct {
+	name        string
+	letter      rune
+	newDetector func() trace.Detector
+}
+
+var (
+	detectorsLock sync.Mutex
+	detectors     = make(map[string]registeredDetector) // name -> detector
+)
+
+// letters used by the built-in analysis scenarios
+const builtinLetters = "srwagkvdinblpoucm"
+
+/*
+ * Register a custom detector. The detector is run if all analysis scenarios
+ * are selected or if its letter is given with the -s flag. The function is
+ * typically called in an init function of the package implementing the detector.
+ * For each analysis, a new instance of the detector is created with newDetector.
+ * Args:
+ *   name (string): The name of the detector, must be unique
+ *   letter (rune): The letter to select the detector, must be unique and not
+ *     be used by a built-in scenario. If 0, the detector can only be run with all scenarios
+ *   newDetector (func() trace.Detector): Function to create a new instance of the detector
+ * Returns:
+ *   error: An error if the name or the letter is already used
+ */
+func RegisterDetector(name string, letter rune, newDetector func() trace.Detector) error {
+	detectorsLock.Lock()
+	defer detectorsLock.Unlock()
+
+	if name == "" || newDetector == nil {
+		return errors.New("A detector needs a name and a constructor")
+	}
+
+	if _, ok := detectors[name]; ok {
+		return errors.New("A detector with the name " + name + " is already registered")
+	}
+
+	if _, ok := builtinAnalysisCases()[name]; ok {
+		return errors.New("The name " + name + " is used by a built-in analysis scenario")
+	}
+
+	if letter != 0 {
+		for _, l := range builtinLetters {
+			if l == letter {
+				return errors.New("The letter " + string(letter) + " is used by a built-in analysis scenario")
+			}
+		}
+		for _, d := range detectors {
+			if d.letter == letter {
+				return errors.New("The letter " + string(letter) + " is already used by detector " + d.name)
+			}
+		}
+	}
+
+	detectors[name] = registeredDetector{
+		name:        name,
+		letter:      letter,
+		newDetector: newDetector,
+	}
+
+	return nil
+}
+
+/*
+ * Get the names of all registered detectors
+ * Returns:
+ *   []string: The names, sorted alphabetically
+ */
+func GetRegisteredDetectors() []string {
+	detectorsLock.Lock()
+	defer detectorsLock.Unlock()
+
+	res := make([]string, 0, len(detectors))
+	for name := range detectors {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+/*
+ * Get the name of the registered detector with the given letter
+ * Args:
+ *   letter (rune): The letter
+ * Returns:
+ *   string: The name of the detector
+ *   bool: true if a detector with the letter exists
+ */
+func getDetectorForLetter(letter rune) (string, bool) {
+	detectorsLock.Lock()
+	defer detectorsLock.Unlock()
+
+	for _, d := range detectors {
+		if d.letter == letter {
+			return d.name, true
+		}
+	}
+	return "", false
+}
+
+/*
+ * Create new instances of all registered detectors, that are enabled in the
+ * analysis cases. The detectors are sorted by name, so that the order of the
+ * results does not depend on the order of registration.
+ * Args:
+ *   analysisCases (map[string]bool): The analysis cases
+ * Returns:
+ *   []trace.Detector: The new detectors
+ */
+func createDetectors(analysisCases map[string]bool) []trace.Detector {
+	names := GetRegisteredDetectors()
+
+	detectorsLock.Lock()
+	defer detectorsLock.Unlock()
+
+	res := make([]trace.Detector, 0)
+	for _, name := range names {
+		if analysisCases[name] {
+			res = append(res, detectors[name].newDetector())
+		}
+	}
+	return res
+}
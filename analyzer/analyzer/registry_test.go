@@ -0,0 +1,182 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"analyzer/bugs"
+	"analyzer/clock"
+	"analyzer/logging"
+	"analyzer/trace"
+)
+
+/*
+ * Detector for the tests, reports each close of a channel
+ * Fields:
+ *   resType (logging.ResultType): The type of the reported results
+ */
+type closeDetector struct {
+	trace.DetectorBase
+	resType logging.ResultType
+}
+
+func (d *closeDetector) Name() string { return "closeDetector" }
+
+func (d *closeDetector) Channel(ch *trace.TraceElementChannel, vc map[int]clock.VectorClock) {
+	if ch.Operation() != trace.Close {
+		return
+	}
+
+	pos := ch.GetPos()
+	line, _ := strconv.Atoi(pos[strings.LastIndex(pos, ":")+1:])
+	d.Results.Result(logging.CRITICAL, d.resType, "close", []logging.ResultElem{
+		logging.TraceElementResult{
+			RoutineID: ch.GetRoutine(),
+			ObjID:     ch.GetID(),
+			TPre:      ch.GetTPre(),
+			ObjType:   "CC",
+			File:      pos[:strings.LastIndex(pos, ":")],
+			Line:      line,
+		}}, "", []logging.ResultElem{})
+}
+
+// result types can not be unregistered, they are therefore registered once
+// for all runs of the tests
+func init() {
+	logging.RegisterResultType("X1", "Found close:", true)
+	logging.RegisterResultType("X2", "Found close:", true)
+	logging.RegisterResultType("X3", "Found possible close:", false)
+}
+
+/*
+ * Register a detector for the duration of a test
+ * Args:
+ *   t (*testing.T): The test
+ *   name (string): The name of the detector
+ *   letter (rune): The letter of the detector
+ *   resType (logging.ResultType): The type of the reported results
+ * Returns:
+ *   error: The error of RegisterDetector
+ */
+func registerForTest(t *testing.T, name string, letter rune, resType logging.ResultType) error {
+	t.Helper()
+
+	err := RegisterDetector(name, letter, func() trace.Detector { return &closeDetector{resType: resType} })
+	if err == nil {
+		t.Cleanup(func() {
+			detectorsLock.Lock()
+			delete(detectors, name)
+			detectorsLock.Unlock()
+		})
+	}
+	return err
+}
+
+/*
+ * Check the name and letter checks of RegisterDetector
+ */
+func TestRegisterDetector(t *testing.T) {
+	if err := registerForTest(t, "closeDetector", 'x', "X1"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		letter rune
+		err    string
+	}{
+		{"", 'y', "A detector needs a name and a constructor"},
+		{"closeDetector", 'y', "A detector with the name closeDetector is already registered"},
+		{"leak", 'y', "The name leak is used by a built-in analysis scenario"},
+		{"other", 'l', "The letter l is used by a built-in analysis scenario"},
+		{"other", 'x', "The letter x is already used by detector closeDetector"},
+	}
+
+	for _, test := range tests {
+		err := registerForTest(t, test.name, test.letter, "X1")
+		if err == nil || err.Error() != test.err {
+			t.Errorf("RegisterDetector(%q, %c) = %v, expected %s", test.name, test.letter, err, test.err)
+		}
+	}
+
+	if err := registerForTest(t, "other", 0, "X1"); err != nil {
+		t.Errorf("RegisterDetector without letter: %v", err)
+	}
+
+	names := GetRegisteredDetectors()
+	if len(names) != 2 || names[0] != "closeDetector" || names[1] != "other" {
+		t.Errorf("GetRegisteredDetectors() = %v", names)
+	}
+
+	cases, err := ParseAnalysisCases("x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cases["closeDetector"] || cases["other"] || cases["leak"] {
+		t.Errorf("ParseAnalysisCases(\"x\") = %v", cases)
+	}
+
+	if _, err := ParseAnalysisCases("y"); err == nil {
+		t.Errorf("ParseAnalysisCases(\"y\") did not fail")
+	}
+}
+
+/*
+ * Run a registered detector on a trace and check that its results are
+ * classified with the severity of their registered result type
+ */
+func TestCustomDetectorSeverity(t *testing.T) {
+	if err := logging.RegisterResultType("X2", "Found close:", true); err == nil {
+		t.Errorf("RegisterResultType accepted a registered type")
+	}
+	if err := logging.RegisterResultType(logging.ASendOnClosed, "Found close:", true); err == nil {
+		t.Errorf("RegisterResultType accepted a built-in type")
+	}
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "trace_1.log"), []byte("C,2,2,4,C,f,0,0,/a/main.go:5"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		resType logging.ResultType
+		actual  bool
+	}{{"X2", true}, {"X3", false}} {
+		if err := registerForTest(t, "closeDetector", 'x', test.resType); err != nil {
+			t.Fatal(err)
+		}
+
+		a := New()
+		if err := a.LoadTrace(dir, false); err != nil {
+			t.Fatal(err)
+		}
+		cases, err := ParseAnalysisCases("x")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := a.Run(Options{AnalysisCases: cases}); err != nil {
+			t.Fatal(err)
+		}
+
+		found := a.Results().GetResultsMachine(false)
+		if len(found) != 1 || !strings.HasPrefix(found[0], string(test.resType)+",") {
+			t.Fatalf("expected one result of type %s, got %v", test.resType, found)
+		}
+
+		actual, bug, err := bugs.ProcessBug(a.Trace(), strings.TrimSpace(found[0]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != test.actual || string(bug.Type) != string(test.resType) {
+			t.Errorf("ProcessBug(%s) = %v, %s, expected %v", found[0], actual, bug.Type, test.actual)
+		}
+
+		detectorsLock.Lock()
+		delete(detectors, "closeDetector")
+		detectorsLock.Unlock()
+	}
+}
@@ -1,8 +1,10 @@
 package bugs
 
 import (
+	"analyzer/logging"
 	"analyzer/trace"
 	"errors"
+	"strconv"
 	"strings"
 )
 
@@ -17,11 +19,23 @@ const (
 	ACloseOnClosed         ResultType = "A3"
 	AConcurrentRecv        ResultType = "A4"
 	ASelCaseWithoutPartner ResultType = "A5"
+	APartialDeadlock       ResultType = "A6"
+	ARecursiveDo           ResultType = "A7"
+	ADoPanic               ResultType = "A8"
 
 	// possible
-	PSendOnClosed ResultType = "P1"
-	PRecvOnClosed ResultType = "P2"
-	PNegWG        ResultType = "P3"
+	PSendOnClosed       ResultType = "P1"
+	PRecvOnClosed       ResultType = "P2"
+	PNegWG              ResultType = "P3"
+	PCloseOnClosed      ResultType = "P6"
+	PAddConcurrentWait  ResultType = "P7"
+	PWaitGroupReuse     ResultType = "P8"
+	PLostWakeup         ResultType = "P9"
+	PAtomicityViolation ResultType = "P10"
+	PDataRace           ResultType = "P11"
+
+	// warnings
+	WBlockInCriticalSection ResultType = "W1"
 
 	// leaks
 	LUnbufferedWith    = "L1"
@@ -40,6 +54,19 @@ type Bug struct {
 	Type          ResultType
 	TraceElement1 []*trace.TraceElement
 	TraceElement2 []*trace.TraceElement
+	SelectCases   []SelectCase
+	BlockedTime   int // time an operation was blocked, -1 if it did not return
+}
+
+/*
+ * A case of a select, that is part of a bug, e.g. a case without partner
+ * Fields:
+ *   ObjID (int): The id of the channel, -1 for a nil channel
+ *   Send (bool): true for a send case, false for a receive case
+ */
+type SelectCase struct {
+	ObjID int
+	Send  bool
 }
 
 /*
@@ -72,6 +99,16 @@ func (b Bug) ToString() string {
 		typeStr = "Found select case without partner or nil case:"
 		arg1Str = "select: "
 		arg2Str = "case: "
+	case APartialDeadlock:
+		typeStr = "Found partial deadlock:"
+		arg1Str = "stuck: "
+	case ARecursiveDo:
+		typeStr = "Found recursive Do on once:"
+		arg1Str = "do: "
+	case ADoPanic:
+		typeStr = "Found panic in Do on once:"
+		arg1Str = "do: "
+		arg2Str = "later: "
 
 	case PSendOnClosed:
 		typeStr = "Possible send on closed channel:"
@@ -85,6 +122,34 @@ func (b Bug) ToString() string {
 		typeStr = "Possible negative waitgroup counter:"
 		arg1Str = "add: "
 		arg2Str = "done: "
+	case PCloseOnClosed:
+		typeStr = "Possible close on closed channel:"
+		arg1Str = "select: "
+		arg2Str = "close: "
+	case PAddConcurrentWait:
+		typeStr = "Possible add concurrent with wait:"
+		arg1Str = "add: "
+		arg2Str = "wait: "
+	case PWaitGroupReuse:
+		typeStr = "Possible reuse of wait group before wait returned:"
+		arg1Str = "add: "
+		arg2Str = "wait: "
+	case PLostWakeup:
+		typeStr = "Possible lost wakeup on conditional variable:"
+		arg1Str = "signal: "
+		arg2Str = "wait: "
+	case PAtomicityViolation:
+		typeStr = "Possible atomicity violation on atomic variable:"
+		arg1Str = "interleaving: "
+	case PDataRace:
+		typeStr = "Possible data race:"
+		arg1Str = "access: "
+		arg2Str = "access: "
+
+	case WBlockInCriticalSection:
+		typeStr = "Blocking operation in critical section:"
+		arg1Str = "operation: "
+		arg2Str = "locks: "
 
 	case LUnbufferedWith:
 		typeStr = "Leak on unbuffered channel with possible partner:"
@@ -128,7 +193,13 @@ func (b Bug) ToString() string {
 		arg2Str = ""
 
 	default:
-		panic("Unknown bug type: " + string(b.Type))
+		description, ok := logging.GetCustomResultType(logging.ResultType(b.Type))
+		if !ok {
+			panic("Unknown bug type: " + string(b.Type))
+		}
+		typeStr = description
+		arg1Str = "elements: "
+		arg2Str = "elements: "
 	}
 
 	res := typeStr + "\n\t" + arg1Str
@@ -139,10 +210,18 @@ func (b Bug) ToString() string {
 		res += (*elem).GetTID()
 	}
 
+	if b.Type == WBlockInCriticalSection {
+		if b.BlockedTime == -1 {
+			res += ";blocked until the end"
+		} else {
+			res += ";blocked for " + strconv.Itoa(b.BlockedTime)
+		}
+	}
+
 	if arg2Str != "" {
 		res += "\n\t" + arg2Str
 
-		if len(b.TraceElement2) == 0 {
+		if len(b.TraceElement2) == 0 && len(b.SelectCases) == 0 {
 			res += "-"
 		}
 
@@ -152,6 +231,17 @@ func (b Bug) ToString() string {
 			}
 			res += (*elem).GetTID()
 		}
+
+		for i, c := range b.SelectCases {
+			if i != 0 || len(b.TraceElement2) != 0 {
+				res += ";"
+			}
+			if c.Send {
+				res += "send " + strconv.Itoa(c.ObjID)
+			} else {
+				res += "recv " + strconv.Itoa(c.ObjID)
+			}
+		}
 	}
 
 	return res
@@ -167,13 +257,14 @@ func (b Bug) Println() {
 /*
  * Process the bug that was selected from the analysis results
  * Args:
+ *   t: The trace the bug was found in
  *   bugStr: The bug that was selected
  * Returns:
  *   bool: true, if the bug was not a possible, but a actually occuring bug
  *   Bug: The bug that was selected
  *   error: An error if the bug could not be processed
  */
-func ProcessBug(bugStr string) (bool, Bug, error) {
+func ProcessBug(t *trace.Trace, bugStr string) (bool, Bug, error) {
 	bug := Bug{}
 
 	bugSplit := strings.Split(bugStr, ",")
@@ -198,10 +289,20 @@ func ProcessBug(bugStr string) (bool, Bug, error) {
 		actual = true
 	case "A4":
 		bug.Type = AConcurrentRecv
-		actual = true
 	case "A5":
 		bug.Type = ASelCaseWithoutPartner
+	case "A6":
+		bug.Type = APartialDeadlock
+		actual = true
+		containsArg2 = false
+	case "A7":
+		bug.Type = ARecursiveDo
+		actual = true
+		containsArg2 = false
+	case "A8":
+		bug.Type = ADoPanic
 		actual = true
+		containsArg2 = len(bugSplit) == 3
 	case "P1":
 		bug.Type = PSendOnClosed
 	case "P2":
@@ -212,6 +313,22 @@ func ProcessBug(bugStr string) (bool, Bug, error) {
 	// 	bug.Type = CyclicDeadlock
 	// case "P5":
 	// 	bug.Type = MixedDeadlock
+	case "P6":
+		bug.Type = PCloseOnClosed
+	case "P7":
+		bug.Type = PAddConcurrentWait
+	case "P8":
+		bug.Type = PWaitGroupReuse
+	case "P9":
+		bug.Type = PLostWakeup
+	case "P10":
+		bug.Type = PAtomicityViolation
+		containsArg2 = false
+	case "P11":
+		bug.Type = PDataRace
+	case "W1":
+		bug.Type = WBlockInCriticalSection
+		actual = true
 	case "L1":
 		bug.Type = LUnbufferedWith
 	case "L2":
@@ -239,7 +356,13 @@ func ProcessBug(bugStr string) (bool, Bug, error) {
 		bug.Type = LCond
 		containsArg2 = false
 	default:
-		return actual, bug, errors.New("Unknown bug type: " + bugStr)
+		// results of custom detectors can not be rewritten
+		if _, ok := logging.GetCustomResultType(logging.ResultType(bugType)); !ok {
+			return actual, bug, errors.New("Unknown bug type: " + bugStr)
+		}
+		bug.Type = ResultType(bugType)
+		actual = logging.IsActualCustomResultType(logging.ResultType(bugType))
+		containsArg2 = len(bugSplit) == 3
 	}
 
 	bugArg1 := bugSplit[1]
@@ -255,15 +378,25 @@ func ProcessBug(bugStr string) (bool, Bug, error) {
 			continue
 		}
 
-		elem, err := trace.GetTraceElementFromBugArg(bugArg)
+		if bugArg[0] == 'B' {
+			blockedTime, err := strconv.Atoi(strings.TrimPrefix(bugArg, "B:"))
+			if err != nil {
+				return actual, bug, errors.New("Invalid blocked time: " + bugArg)
+			}
+			bug.BlockedTime = blockedTime
+			continue
+		}
+
+		elem, err := t.GetTraceElementFromBugArg(bugArg)
 		if err != nil {
-			println("Could not find: " + bugArg + " in trace")
+			t.Println("Could not find: " + bugArg + " in trace")
 			return actual, bug, err
 		}
 		bug.TraceElement1 = append(bug.TraceElement1, elem)
 	}
 
 	bug.TraceElement2 = make([]*trace.TraceElement, 0)
+	bug.SelectCases = make([]SelectCase, 0)
 
 	if !containsArg2 {
 		return actual, bug, nil
@@ -275,14 +408,43 @@ func ProcessBug(bugStr string) (bool, Bug, error) {
 		}
 
 		if bugArg[0] == 'T' {
-			elem, err := trace.GetTraceElementFromBugArg(bugArg)
+			elem, err := t.GetTraceElementFromBugArg(bugArg)
 			if err != nil {
 				return actual, bug, err
 			}
 
 			bug.TraceElement2 = append(bug.TraceElement2, elem)
+		} else if bugArg[0] == 'S' {
+			selectCase, err := processSelectCase(bugArg)
+			if err != nil {
+				return actual, bug, err
+			}
+
+			bug.SelectCases = append(bug.SelectCases, selectCase)
 		}
 	}
 
 	return actual, bug, nil
 }
+
+/*
+ * Process a select case arg of a bug, e.g. S:3:CR
+ * Args:
+ *   bugArg: The select case arg
+ * Returns:
+ *   SelectCase: The select case
+ *   error: An error if the arg could not be processed
+ */
+func processSelectCase(bugArg string) (SelectCase, error) {
+	fields := strings.Split(bugArg, ":")
+	if len(fields) != 3 {
+		return SelectCase{}, errors.New("Invalid select case: " + bugArg)
+	}
+
+	objID, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return SelectCase{}, errors.New("Invalid id of select case: " + bugArg)
+	}
+
+	return SelectCase{ObjID: objID, Send: fields[2] == "CS"}, nil
+}
@@ -2,20 +2,21 @@ package clock
 
 import (
 	"analyzer/logging"
-	"fmt"
 	"runtime"
 	"strconv"
 )
 
 /*
  * vectorClock is a vector clock
+ * The clock is stored as a dense slice, the value of routine i is stored
+ * at index i-1.
  * Fields:
  *   size (int): The size of the vector clock
  *   clock ([]int): The vector clock
  */
 type VectorClock struct {
 	size  int
-	clock map[int]int
+	clock []int
 }
 
 /*
@@ -26,14 +27,9 @@ type VectorClock struct {
  *   (vectorClock): The new vector clock
  */
 func NewVectorClock(size int) VectorClock {
-	c := make(map[int]int)
-	for i := 1; i <= size; i++ {
-		c[i] = 0
-	}
-
 	return VectorClock{
 		size:  size,
-		clock: c,
+		clock: make([]int, size),
 	}
 }
 
@@ -49,10 +45,43 @@ func (vc VectorClock) GetSize() int {
 /*
  * Get the vector clock
  * Returns:
- *   (map[int]int): The vector clock
+ *   (map[int]int): The vector clock, the value of each routine by its id,
+ *     nil if the clock has not been created
  */
 func (vc VectorClock) GetClock() map[int]int {
-	return vc.clock
+	if vc.clock == nil {
+		return nil
+	}
+
+	res := make(map[int]int, len(vc.clock))
+	for i, value := range vc.clock {
+		res[i+1] = value
+	}
+	return res
+}
+
+/*
+ * Check if the vector clock has not been created
+ * Returns:
+ *   (bool): true if the clock has not been created
+ */
+func (vc VectorClock) IsNil() bool {
+	return vc.clock == nil
+}
+
+/*
+ * Get the value of the vector clock for the given routine without copying
+ * the clock
+ * Args:
+ *   routine (int): The routine
+ * Returns:
+ *   (int): The value of the routine, 0 if the routine is not in the clock
+ */
+func (vc VectorClock) Get(routine int) int {
+	if routine < 1 || routine > len(vc.clock) {
+		return 0
+	}
+	return vc.clock[routine-1]
 }
 
 /*
@@ -63,7 +92,7 @@ func (vc VectorClock) GetClock() map[int]int {
 func (vc VectorClock) ToString() string {
 	str := "["
 	for i := 1; i <= vc.size; i++ {
-		str += fmt.Sprint(vc.clock[i])
+		str += strconv.Itoa(vc.Get(i))
 		if i <= vc.size-1 {
 			str += ", "
 		}
@@ -74,16 +103,21 @@ func (vc VectorClock) ToString() string {
 
 /*
  * Increment the vector clock at the given position
+ * The increment is done in place, all copies of the struct share the clock.
  * Args:
  *   routine (int): The routine to increment
  * Returns:
  *   (vectorClock): The vector clock
  */
 func (vc VectorClock) Inc(routine int) VectorClock {
-	if vc.clock == nil {
-		vc.clock = make(map[int]int)
+	if routine > len(vc.clock) {
+		// should not happen, all clocks are created with the number of routines
+		clock := make([]int, routine)
+		copy(clock, vc.clock)
+		vc.clock = clock
+		vc.size = max(vc.size, routine)
 	}
-	vc.clock[routine]++
+	vc.clock[routine-1]++
 	return vc
 }
 
@@ -107,14 +141,16 @@ func (vc VectorClock) Sync(rec VectorClock) VectorClock {
 	if rec.size == 0 {
 		return vc.Copy()
 	}
-	copy := rec.Copy()
-	for i := 1; i <= vc.size; i++ {
-		if vc.clock[i] > copy.clock[i] {
-			copy.clock[i] = vc.clock[i]
+
+	res := NewVectorClock(max(vc.size, rec.size))
+	copy(res.clock, rec.clock)
+	for i, v := range vc.clock {
+		if v > res.clock[i] {
+			res.clock[i] = v
 		}
 	}
 
-	return copy
+	return res
 }
 
 /*
@@ -124,9 +160,7 @@ func (vc VectorClock) Sync(rec VectorClock) VectorClock {
  */
 func (vc VectorClock) Copy() VectorClock {
 	newVc := NewVectorClock(vc.size)
-	for i := 1; i <= vc.size; i++ {
-		newVc.clock[i] = vc.clock[i]
-	}
+	copy(newVc.clock, vc.clock)
 	return newVc
 }
 
@@ -160,9 +194,9 @@ func GetHappensBefore(vc1 VectorClock, vc2 VectorClock) HappensBefore {
 func isCause(vc1 VectorClock, vc2 VectorClock) bool {
 	atLeastOneSmaller := false
 	for i := 1; i <= vc1.size; i++ {
-		if vc1.clock[i] > vc2.clock[i] {
+		if vc1.Get(i) > vc2.Get(i) {
 			return false
-		} else if vc1.clock[i] < vc2.clock[i] {
+		} else if vc1.Get(i) < vc2.Get(i) {
 			atLeastOneSmaller = true
 		}
 	}
@@ -0,0 +1,52 @@
+package clock
+
+import "testing"
+
+/*
+ * Benchmark the operations of the analysis on a vector clock with the given
+ * number of routines: increment, sync with another clock and copy
+ */
+func benchmarkVectorClock(b *testing.B, size int) {
+	vc := NewVectorClock(size)
+	other := NewVectorClock(size)
+	for i := 1; i <= size; i++ {
+		other = other.Inc(i)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		vc = vc.Inc(i%size + 1)
+		vc = vc.Sync(other)
+		other = vc.Copy()
+	}
+}
+
+func BenchmarkVectorClock10(b *testing.B)   { benchmarkVectorClock(b, 10) }
+func BenchmarkVectorClock100(b *testing.B)  { benchmarkVectorClock(b, 100) }
+func BenchmarkVectorClock1000(b *testing.B) { benchmarkVectorClock(b, 1000) }
+
+/*
+ * Get and IsNil must give the same values as GetClock
+ */
+func TestVectorClockGet(t *testing.T) {
+	var empty VectorClock
+	if !empty.IsNil() || empty.Get(1) != 0 {
+		t.Errorf("expected an empty clock, got %s", empty.ToString())
+	}
+
+	vc := NewVectorClock(3).Inc(1).Inc(3).Inc(3)
+	if vc.IsNil() {
+		t.Fatal("created clock is nil")
+	}
+
+	for routine, value := range vc.GetClock() {
+		if vc.Get(routine) != value {
+			t.Errorf("Get(%d) = %d, expected %d", routine, vc.Get(routine), value)
+		}
+	}
+	for _, routine := range []int{0, 4} {
+		if vc.Get(routine) != 0 {
+			t.Errorf("Get(%d) = %d, expected 0", routine, vc.Get(routine))
+		}
+	}
+}
@@ -65,7 +65,7 @@ func getTraceElements(resultFolderPath string) (map[string][]int, error) {
 					continue
 				}
 
-				if field[0] == "A" || field[0] == "X" {
+				if field[0] == "A" || field[0] == "D" || field[0] == "P" || field[0] == "X" {
 					continue
 				}
 
@@ -4,24 +4,34 @@ import "fmt"
 
 // type (bug / diagnostics)
 var bugCrit = map[string]string{
-	"A1": "Bug",
-	"A2": "Diagnostics",
-	"A3": "Bug",
-	"A4": "Diagnostics",
-	"A5": "Diagnostics",
-	"P1": "Bug",
-	"P2": "Diagnostic",
-	"P3": "Leak",
-	"L1": "Leak",
-	"L2": "Leak",
-	"L3": "Leak",
-	"L4": "Leak",
-	"L5": "Leak",
-	"L6": "Leak",
-	"L7": "Leak",
-	"L8": "Leak",
-	"L9": "Leak",
-	"L0": "Leak",
+	"A1":  "Bug",
+	"A2":  "Diagnostics",
+	"A3":  "Bug",
+	"A4":  "Diagnostics",
+	"A5":  "Diagnostics",
+	"A6":  "Leak",
+	"A7":  "Bug",
+	"A8":  "Bug",
+	"P1":  "Bug",
+	"P2":  "Diagnostic",
+	"P3":  "Leak",
+	"P6":  "Bug",
+	"P7":  "Bug",
+	"P8":  "Bug",
+	"P9":  "Leak",
+	"P10": "Bug",
+	"P11": "Bug",
+	"W1":  "Diagnostics",
+	"L1":  "Leak",
+	"L2":  "Leak",
+	"L3":  "Leak",
+	"L4":  "Leak",
+	"L5":  "Leak",
+	"L6":  "Leak",
+	"L7":  "Leak",
+	"L8":  "Leak",
+	"L9":  "Leak",
+	"L0":  "Leak",
 }
 
 var bugNames = map[string]string{
@@ -30,10 +40,20 @@ var bugNames = map[string]string{
 	"A3": "Actual Close on Closed Channel",
 	"A4": "Concurrent Receive",
 	"A5": "Select Case without Partner",
+	"A6": "Partial Deadlock",
+	"A7": "Recursive Do on Once",
+	"A8": "Panic in Do on Once",
 
-	"P1": "Possible Send on Closed Channel",
-	"P2": "Possible Receive on Closed Channel",
-	"P3": "Possible Negative WaitGroup cCounter",
+	"P1":  "Possible Send on Closed Channel",
+	"P2":  "Possible Receive on Closed Channel",
+	"P3":  "Possible Negative WaitGroup cCounter",
+	"P6":  "Possible Close on Closed Channel",
+	"P7":  "Possible Add concurrent with Wait",
+	"P8":  "Possible Reuse of WaitGroup before Wait returned",
+	"P9":  "Possible Lost Wakeup on Conditional Variable",
+	"P10": "Possible Atomicity Violation on Atomic Variable",
+	"P11": "Possible Data Race",
+	"W1":  "Blocking Operation in Critical Section",
 
 	"L1": "Leak of unbuffered Channel with possible partner",
 	"L2": "Leak on unbuffered Channel without possible partner",
@@ -62,6 +82,20 @@ var bugExplanations = map[string]string{
 		"on the happens-before relation, at least one case could never be triggered.\n" +
 		"This can be a desired behavior, especially considering, that only executed " +
 		"operations are considered, but it can also be an hint of an unnecessary select case.",
+	"A6": "During the execution of the program, a set of routines got stuck, where each routine " +
+		"waits for another routine of the set.\n" +
+		"A routine waits for another routine, if the other routine has executed operations, " +
+		"that could release it, e.g. a receive for a stuck send, an unlock of a held mutex or a done " +
+		"for a stuck wait. Since all of these routines are blocked, none of them can release the " +
+		"others, which leads to a leak of all routines in the set.\n" +
+		"The partial deadlock replaces the leak results of the stuck operations.",
+	"A7": "During the execution of the program, the function of a Do on a once called Do on the same once.\n" +
+		"The inner Do waits until the outer Do has finished, but the outer Do can only finish after " +
+		"its function, and therefore the inner Do, has returned. This is a guaranteed deadlock of the routine.",
+	"A8": "During the execution of the program, the function of a Do on a once panicked.\n" +
+		"Even if the panic is recovered, the once is marked as done. All later calls of Do on this once " +
+		"return immediately without executing the function and may therefore use a value, " +
+		"that was only partially initialized. The result shows these later calls, if any were recorded.",
 	"P1": "The analyzer detected a possible send on a closed channel.\n" +
 		"Although the send on a closed channel did not occur during the recording, " +
 		"it is possible that it will occur, based on the happens before relation.\n" +
@@ -74,6 +108,51 @@ var bugExplanations = map[string]string{
 		"Although the negative counter did not occur during the recording, " +
 		"it is possible that it will occur, based on the happens before relation.\n" +
 		"A negative counter will lead to a panic.",
+	"P6": "The analyzer detected a possible close on a closed channel.\n" +
+		"A select with a default case was used to check, if the channel has already been closed, " +
+		"before closing it. Another routine executed the same select concurrently to the close " +
+		"and only received, because the channel was already closed.\n" +
+		"Based on the happens before relation, both routines could choose the default case " +
+		"and close the channel. Such a close on a closed channel leads to a panic.",
+	"P7": "The analyzer detected a possible add concurrent with a wait.\n" +
+		"An add with a positive delta must happen before the wait, if the counter may be zero. " +
+		"Based on the happens before relation, the add and the wait are concurrent, while the counter " +
+		"may be zero. The wait may therefore return before the add, so that the routines, that call done " +
+		"for this add, are not waited for.",
+	"P8": "The analyzer detected a possible reuse of a WaitGroup before a previous wait has returned.\n" +
+		"When a WaitGroup is reused for a new round, the add of the new round must happen after all " +
+		"previous waits have returned. Based on the happens before relation, the add is concurrent to the " +
+		"end of a previous wait, while the counter may be zero. " +
+		"If the add is executed after the wait has been woken up, but before it returned, this leads to a panic.",
+	"P9": "The analyzer detected a possible lost wakeup on a conditional variable.\n" +
+		"A signal or broadcast was executed, while no routine was waiting on the conditional variable. " +
+		"The wakeup was therefore lost. A later wait on the same conditional variable is concurrent " +
+		"to the signal or broadcast, based on the happens before relation. If the wait had been executed first, it " +
+		"would have been woken up. If no other signal or broadcast follows, the wait blocks forever, " +
+		"which leads to a leak.",
+	"P10": "The analyzer detected a possible atomicity violation on an atomic variable.\n" +
+		"A routine loaded the value of an atomic variable and later wrote to the same variable " +
+		"with a store, add or swap, that depends on the loaded value in the code (check-then-act). " +
+		"Although each operation is atomic, the sequence is not. Based on the happens before relation, " +
+		"a write of another routine can be executed between the load and the write, so that the " +
+		"write is based on an outdated value. The result shows this interleaving: the load, the write of " +
+		"the other routine and the write of the check-then-act sequence.\n" +
+		"A compare and swap should be used instead.",
+	"P11": "The analyzer detected a possible data race.\n" +
+		"Two routines accessed the same memory location and at least one of the accesses was a write. " +
+		"Based on the happens before relation, the accesses are concurrent, i.e. they are not ordered " +
+		"by any of the recorded synchronizations like channel operations, locks, wait groups or " +
+		"atomic operations. The accesses can therefore be executed at the same time, even if they " +
+		"were executed far apart in the recorded run. In Go, a data race is undefined behavior.\n" +
+		"Memory accesses are only recorded for the packages selected with advocate.EnableMemoryRecording " +
+		"in programs built with -race.",
+	"W1": "The analyzer detected a blocking operation, that was executed while the routine held one or more locks.\n" +
+		"All other routines, that try to acquire one of the locks, have to wait until the operation " +
+		"has finished. This can lead to long waiting times. If the operation waits for a routine, " +
+		"that tries to acquire one of the locks, this leads to a deadlock.\n" +
+		"The result contains the held locks and the time the operation was blocked. " +
+		"For each combination of the position of the operation and the positions of the locks, " +
+		"only the operation with the longest blocking time is shown.",
 	"L1": "The analyzer detected a leak of an unbuffered channel with a possible partner.\n" +
 		"A leak of an unbuffered channel is a situation, where a unbuffered channel is " +
 		"still blocking at the end of the program.\n" +
@@ -150,6 +229,38 @@ var bugExamples map[string]string = map[string]string{
 		"    case d <- 1:      // <-------\n" +
 		"        print(\"d\")\n" +
 		"    }\n",
+	"A6": "func main() {\n" +
+		"    var m sync.Mutex\n" +
+		"    c := make(chan int)\n\n" +
+		"    go func() {\n" +
+		"        for i := 0; i < 2; i++ {\n" +
+		"            m.Lock()    // <-------\n" +
+		"            c <- i\n" +
+		"            m.Unlock()\n" +
+		"        }\n" +
+		"    }()\n\n" +
+		"    <-c\n" +
+		"    m.Lock()\n" +
+		"    <-c                 // <-------\n" +
+		"    m.Unlock()\n}",
+	"A7": "func main() {\n" +
+		"    var o sync.Once\n\n" +
+		"    o.Do(func() {       // <-------\n" +
+		"        o.Do(func() {}) // <-------\n" +
+		"    })\n}",
+	"A8": "func main() {\n" +
+		"    var o sync.Once\n" +
+		"    var m map[string]int\n\n" +
+		"    func() {\n" +
+		"        defer func() { recover() }()\n" +
+		"        o.Do(func() {   // <-------\n" +
+		"            m = load()  // <------- panics\n" +
+		"        })\n" +
+		"    }()\n\n" +
+		"    o.Do(func() {       // <-------\n" +
+		"        m = load()\n" +
+		"    })\n" +
+		"    m[\"a\"] = 1\n}",
 	"P1": "func main() {\n" +
 		"    c := make(chan int)\n\n" +
 		"    go func() {\n" +
@@ -177,6 +288,69 @@ var bugExamples map[string]string = map[string]string{
 		"        wg.Done()       // <-------\n" +
 		"    }()\n\n" +
 		"    wg.Wait()\n}",
+	"P6": "func closeOnce(c chan int) {\n" +
+		"    select {\n" +
+		"    case <-c:           // <-------\n" +
+		"    default:\n" +
+		"        close(c)        // <-------\n" +
+		"    }\n" +
+		"}\n\n" +
+		"func main() {\n" +
+		"    c := make(chan int)\n\n" +
+		"    go closeOnce(c)\n" +
+		"    go closeOnce(c)\n}",
+	"P7": "func main() {\n" +
+		"    var wg sync.WaitGroup\n\n" +
+		"    go func() {\n" +
+		"        wg.Add(1)       // <-------\n" +
+		"        defer wg.Done()\n" +
+		"    }()\n\n" +
+		"    wg.Wait()           // <-------\n}",
+	"P9": "func main() {\n" +
+		"    var m sync.Mutex\n" +
+		"    c := sync.NewCond(&m)\n\n" +
+		"    go func() {\n" +
+		"        c.Signal()      // <-------\n" +
+		"    }()\n\n" +
+		"    m.Lock()\n" +
+		"    c.Wait()            // <-------\n" +
+		"    m.Unlock()\n}",
+	"P10": "func main() {\n" +
+		"    var a atomic.Int32\n\n" +
+		"    go func() {\n" +
+		"        a.Store(2)      // <-------\n" +
+		"    }()\n\n" +
+		"    if a.Load() == 0 {  // <-------\n" +
+		"        a.Store(1)      // <-------\n" +
+		"    }\n}",
+	"P11": "func main() {\n" +
+		"    x := 0\n" +
+		"    c := make(chan int, 1)\n\n" +
+		"    go func() {\n" +
+		"        x = 1           // <-------\n" +
+		"        c <- 1\n" +
+		"    }()\n\n" +
+		"    time.Sleep(time.Second)\n" +
+		"    fmt.Println(x)      // <-------\n" +
+		"    <-c\n}",
+	"W1": "func main() {\n" +
+		"    var m sync.Mutex\n" +
+		"    c := make(chan int)\n\n" +
+		"    go func() {\n" +
+		"        c <- 1\n" +
+		"    }()\n\n" +
+		"    m.Lock()            // <-------\n" +
+		"    <-c                 // <-------\n" +
+		"    m.Unlock()\n}",
+	"P8": "func main() {\n" +
+		"    var wg sync.WaitGroup\n\n" +
+		"    wg.Add(1)\n" +
+		"    go func() {\n" +
+		"        wg.Done()\n" +
+		"        wg.Add(1)       // <-------\n" +
+		"        wg.Done()\n" +
+		"    }()\n\n" +
+		"    wg.Wait()           // <-------\n}",
 	"L1": "func main() {\n" +
 		"    c := make(chan int)\n\n" +
 		"    go func() {\n" +
@@ -259,24 +433,34 @@ var bugExamples map[string]string = map[string]string{
 }
 
 var rewriteType = map[string]string{
-	"A1": "Actual",
-	"A2": "Actual",
-	"A3": "Actual",
-	"A4": "Actual",
-	"A5": "Actual",
-	"P1": "Possible",
-	"P2": "Possible",
-	"P3": "Possible",
-	"L1": "LeakPos",
-	"L2": "Leak",
-	"L3": "LeakPos",
-	"L4": "Leak",
-	"L5": "Leak",
-	"L6": "LeakPos",
-	"L7": "Leak",
-	"L8": "LeakPos",
-	"L9": "LeakPos",
-	"L0": "LeakPos",
+	"A1":  "Actual",
+	"A2":  "Actual",
+	"A3":  "Actual",
+	"A4":  "Replay",
+	"A5":  "Replay",
+	"A6":  "Actual",
+	"A7":  "Actual",
+	"A8":  "Actual",
+	"P1":  "Possible",
+	"P2":  "Possible",
+	"P3":  "Possible",
+	"P6":  "Possible",
+	"P7":  "Possible",
+	"P8":  "Possible",
+	"P9":  "Possible",
+	"P10": "Possible",
+	"P11": "None",
+	"W1":  "Actual",
+	"L1":  "LeakPos",
+	"L2":  "Leak",
+	"L3":  "LeakPos",
+	"L4":  "Leak",
+	"L5":  "Leak",
+	"L6":  "LeakPos",
+	"L7":  "Replay",
+	"L8":  "LeakPos",
+	"L9":  "LeakPos",
+	"L0":  "LeakPos",
 }
 
 // TODO: describe exit codes
@@ -302,7 +486,7 @@ var exitCodeExplanation = map[string]string{
 		"    - The program execution path depends on the order of not tracked operations\n" +
 		"    - The program execution depends on outside input, that was not exactly reproduced",
 	"12": "The replay got stuck during the execution.\n" +
-		"No trace element was executed for a long tim.\n" +
+		"No trace element was executed for a long time.\n" +
 		"This can be caused by a stuck replay.\n" +
 		"Possible causes are:\n" +
 		"    - The program was altered between recording and replay\n" +
@@ -317,6 +501,10 @@ var exitCodeExplanation = map[string]string{
 		"    - The program execution path is not deterministic, e.g. its execution path is determined by a random number\n" +
 		"    - The program execution path depends on the order of not tracked operations\n" +
 		"    - The program execution depends on outside input, that was not exactly reproduced",
+	"14": "The replay got stuck during the execution.\n" +
+		"The replay took longer than the maximum replay time.\n" +
+		"The maximum replay time can be set with the ADVOCATE_REPLAY_TIMEOUT_TOTAL environment variable.\n" +
+		"This can be caused by a stuck replay or a slow machine.",
 	"20": "The replay was able to get the leaking unbuffered channel or select unstuck.",
 	"21": "The replay was able to get the leaking buffered channel unstuck.",
 	"22": "The replay was able to get the leaking mutex unstuck.",
@@ -328,6 +516,21 @@ var exitCodeExplanation = map[string]string{
 		"The replay was therefore able to confirm, that the receive on closed can actually occur.",
 	"32": "The replay resulted in an expected negative wait group triggering a panic. The bug was triggered. " +
 		"The replay was therefore able to confirm, that the negative wait group can actually occur.",
+	"33": "The replay resulted in an expected close on close triggering a panic. The bug was triggered. " +
+		"The replay was therefore able to confirm, that the close on closed can actually occur.",
+	"34": "The replay swapped the order of the concurrent receives. The other routine received the message.",
+	"35": "The replay forced the select to execute the case without partner. The select blocked on the case. " +
+		"The replay was therefore able to confirm, that the select blocks if the case is chosen.",
+	"36": "The replay executed the wait before the add. The wait returned, without waiting for the add. " +
+		"The replay was therefore able to confirm, that the add can be concurrent with the wait.",
+	"37": "The replay executed the add while the previous wait had not returned. This triggered a panic. " +
+		"The replay was therefore able to confirm, that the wait group can be reused before the wait returned.",
+	"38": "The replay executed the signal before the wait. The wait started waiting after the signal, so the wakeup was lost. " +
+		"The replay was therefore able to confirm, that the wakeup can be lost.",
+	"39": "The replay reached the end of the trace rewritten for the atomicity violation. " +
+		"The replay does not enforce the order of atomic operations, so the write of the other routine " +
+		"may not have been executed between the load and the dependent write. " +
+		"The atomicity violation is therefore not confirmed by the replay.",
 	// "41": "cyclic",
 }
 
@@ -350,7 +553,16 @@ var objectTypes = map[string]string{
 	"NS": "Conditional Variable: Signal",
 	"OE": "Once: Done Executed",
 	"ON": "Once: Done Not Executed (because the once was already executed)",
+	"OB": "Once: Done Blocked (called in the function of a Do on the same once)",
 	"GF": "Routine: Fork",
+	"AL": "Atomic: Load",
+	"AS": "Atomic: Store",
+	"AA": "Atomic: Add",
+	"AW": "Atomic: Swap",
+	"AC": "Atomic: CompareAndSwap",
+	"DR": "Memory: Read",
+	"DW": "Memory: Write",
+	"PA": "Panic",
 }
 
 func getBugTypeDescription(bugType string) map[string]string {
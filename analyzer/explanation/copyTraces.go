@@ -53,6 +53,15 @@ func copyDir(src string, dst string) error {
 		srcFile := src + "/" + file.Name()
 		dstFile := dst + "/" + file.Name()
 
+		// e.g. the trace recorded during the replay
+		if file.IsDir() {
+			err := copyDir(srcFile, dstFile)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
 		err := copyFile(srcFile, dstFile)
 		if err != nil {
 			return err
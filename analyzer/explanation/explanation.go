@@ -29,12 +29,14 @@ import (
  * It then writes all this information into a file.
  * Args:
  *    path: the path to the folder, where the results of the analysis and the trace are stored
+ *    tracePath: the path to the analyzed trace folder
  *    index: the index of the bug in the results
  *    preventCopyRewrittenTrace: if the rewritten trace should not be copied
+ *    ignoreAtomics: if atomic operations should be ignored in the happens before graph
  * Returns:
  *    error: if an error occurred
  */
-func CreateOverview(path string, index int, preventCopyRewrittenTrace bool) error {
+func CreateOverview(path string, tracePath string, index int, preventCopyRewrittenTrace bool, ignoreAtomics bool) error {
 	// get the code info (main file, test name, commands)
 	progInfo, err := readProgInfo(path, index)
 	if err != nil {
@@ -63,6 +65,11 @@ func CreateOverview(path string, index int, preventCopyRewrittenTrace bool) erro
 
 	err = writeFile(path, index, bugTypeDescription, bugPos, bugElemType, code,
 		replay, progInfo)
+	if err == nil {
+		if errGraph := addHBGraph(path, tracePath, index, ignoreAtomics); errGraph != nil {
+			fmt.Println("Error creating happens before graph: ", errGraph)
+		}
+	}
 
 	// copyTrace(path, index)
 	if !preventCopyRewrittenTrace {
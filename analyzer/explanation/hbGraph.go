@@ -0,0 +1,68 @@
+package explanation
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"analyzer/analyzer"
+	"analyzer/export"
+)
+
+/*
+ * Create the happens before graph around the elements of the bug and add it
+ * to the README of the bug. The graph is stored as hb.dot and hb.graphml.
+ * If graphviz is installed, the graph is rendered into hb.svg, which is
+ * embedded in the README.
+ * Args:
+ *   path (string): the path to the folder, where the results of the analysis are stored
+ *   tracePath (string): the path to the analyzed trace folder
+ *   index (int): the index of the bug in the results (1 based)
+ *   ignoreAtomics (bool): if atomic operations should be ignored
+ * Returns:
+ *   error: if the graph could not be created
+ */
+func addHBGraph(path string, tracePath string, index int, ignoreAtomics bool) error {
+	folderName := path + "bugs/bug_" + fmt.Sprint(index)
+
+	a := analyzer.New()
+	err := a.LoadTrace(tracePath, ignoreAtomics)
+	if err != nil {
+		return err
+	}
+	t := a.Trace()
+
+	resultsFile := path + "results_machine.log"
+	dotFile := filepath.Join(folderName, "hb.dot")
+
+	err = export.WriteHBGraph(t, resultsFile, index, 5, "dot", dotFile)
+	if err != nil {
+		return err
+	}
+
+	err = export.WriteHBGraph(t, resultsFile, index, 5, "graphml", filepath.Join(folderName, "hb.graphml"))
+	if err != nil {
+		return err
+	}
+
+	res := "## Happens Before Graph\n"
+	res += "The happens before graph around the elements of the bug is stored in `hb.dot` and `hb.graphml`. "
+	res += "The elements of the bug are highlighted. Only elements close to the elements of the bug are shown. Two elements are concurrent, if there is no directed path between them in the full graph.\n\n"
+
+	if _, err := exec.LookPath("dot"); err == nil {
+		cmd := exec.Command("dot", "-Tsvg", "-o", filepath.Join(folderName, "hb.svg"), dotFile)
+		if err := cmd.Run(); err == nil {
+			res += "![Happens before graph](hb.svg)\n\n"
+		}
+	}
+
+	file, err := os.OpenFile(filepath.Join(folderName, "README.md"), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(res)
+	return err
+}
@@ -32,6 +32,15 @@ func getRewriteInfo(bugType string, path string, index int) map[string]string {
 		res["description"] += "The analyzer found a way to resolve the leak, meaning the "
 		res["description"] += "leak should not reappear in the rewritten trace."
 		res["exitCode"], res["exitCodeExplanation"], res["replaySuc"], err = getReplayInfo(path, index)
+	} else if rewPos == "Replay" {
+		res["description"] += "The analyzer found the problem in the recorded trace.\n"
+		res["description"] += "The analyzer has tried to rewrite the trace in such a way, "
+		res["description"] += "that the other possible behavior is shown when replaying the trace."
+		res["exitCode"], res["exitCodeExplanation"], res["replaySuc"], err = getReplayInfo(path, index)
+	} else if rewPos == "None" {
+		res["description"] += "The bug is a potential bug.\n"
+		res["description"] += "The replay cannot enforce the order of the involved operations. "
+		res["description"] += "Therefore no rewritten trace was created."
 	} else if rewPos == "Leak" {
 		res["description"] += "The analyzer found a leak in the recorded trace.\n"
 		res["description"] += "The analyzer could not find a way to resolve the leak."
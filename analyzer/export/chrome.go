@@ -0,0 +1,433 @@
+// Package export converts traces into formats that can be opened by
+// other tools, e.g. to show the recorded execution on a timeline.
+package export
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"analyzer/bugs"
+	"analyzer/trace"
+)
+
+// process id used for all events, the trace only contains one process
+const chromePid = 1
+
+/*
+ * Event in the Chrome Trace Event format
+ * Fields:
+ *   Name (string): name of the event
+ *   Cat (string): category of the event
+ *   Ph (string): phase (type) of the event, e.g. X for a complete slice
+ *   Ts (int): start time of the event
+ *   Dur (int): duration of the event, only for slices
+ *   Pid (int): process id
+ *   Tid (int): thread id, the routine
+ *   ID (int): id of a flow event
+ *   Bp (string): binding point of a flow end event
+ *   S (string): scope of an instant event
+ *   Cname (string): color of the event
+ *   Args (map[string]interface{}): additional information shown for the event
+ */
+type chromeEvent struct {
+	Name  string                 `json:"name"`
+	Cat   string                 `json:"cat,omitempty"`
+	Ph    string                 `json:"ph"`
+	Ts    int                    `json:"ts"`
+	Dur   int                    `json:"dur,omitempty"`
+	Pid   int                    `json:"pid"`
+	Tid   int                    `json:"tid"`
+	ID    int                    `json:"id,omitempty"`
+	Bp    string                 `json:"bp,omitempty"`
+	S     string                 `json:"s,omitempty"`
+	Cname string                 `json:"cname,omitempty"`
+	Args  map[string]interface{} `json:"args,omitempty"`
+}
+
+/*
+ * Trace in the Chrome Trace Event format
+ * Fields:
+ *   TraceEvents ([]chromeEvent): the events
+ *   DisplayTimeUnit (string): the unit in which the times are shown
+ */
+type chromeTrace struct {
+	TraceEvents     []chromeEvent `json:"traceEvents"`
+	DisplayTimeUnit string        `json:"displayTimeUnit"`
+}
+
+/*
+ * Write the trace as Chrome Trace Event JSON, that can be opened with Perfetto
+ * or chrome://tracing. Each routine is shown as its own track, each operation
+ * as a slice from tPre to tPost. Operations that never finished are shown
+ * until the end of the trace. Channel communications and the creation of
+ * routines are shown as arrows. The elements of each finding in the
+ * analysis results are highlighted.
+ * Args:
+ *   t (*trace.Trace): the trace
+ *   resultsFile (string): path to the machine readable analysis results, if empty no findings are highlighted
+ *   outputFile (string): path of the created JSON file
+ * Returns:
+ *   error: error if the results could not be read or the output could not be written
+ */
+func WriteChromeTrace(t *trace.Trace, resultsFile string, outputFile string) error {
+	findings := make(map[trace.TraceElement][]string)
+	if resultsFile != "" {
+		var err error
+		findings, err = readFindings(t, resultsFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	elems := sortedElements(t)
+
+	// end of the trace, used for operations that did not finish
+	end := 0
+	for _, elem := range elems {
+		end = max(end, elem.GetTPre(), getTPost(elem))
+	}
+	end++
+
+	events := make([]chromeEvent, 0)
+
+	routines := make([]int, 0)
+	for routine := range *t.GetTraces() {
+		routines = append(routines, routine)
+	}
+	sort.Ints(routines)
+	for _, routine := range routines {
+		events = append(events, chromeEvent{
+			Name: "thread_name",
+			Ph:   "M",
+			Pid:  chromePid,
+			Tid:  routine,
+			Args: map[string]interface{}{"name": "Routine " + strconv.Itoa(routine)},
+		})
+	}
+
+	for _, elem := range elems {
+		start := elem.GetTPre()
+		dur := getTPost(elem) - start
+		name := elementName(elem)
+		if getTPost(elem) == 0 {
+			dur = end - start
+			name += " (blocked)"
+		}
+
+		event := chromeEvent{
+			Name: name,
+			Cat:  elementCategory(elem),
+			Ph:   "X",
+			Ts:   start,
+			Dur:  max(dur, 1),
+			Pid:  chromePid,
+			Tid:  elem.GetRoutine(),
+			Args: map[string]interface{}{
+				"element": elem.ToString(),
+				"pos":     elem.GetPos(),
+			},
+		}
+
+		if f, ok := findings[elem]; ok {
+			event.Cname = "terrible"
+			event.Args["findings"] = f
+			for _, finding := range f {
+				events = append(events, chromeEvent{
+					Name:  finding,
+					Cat:   "finding",
+					Ph:    "i",
+					Ts:    start,
+					Pid:   chromePid,
+					Tid:   elem.GetRoutine(),
+					S:     "t",
+					Cname: "terrible",
+				})
+			}
+		}
+
+		events = append(events, event)
+	}
+
+	flows := channelFlows(elems)
+	events = append(events, flows...)
+	events = append(events, spawnFlows(elems, len(flows)/2+1)...)
+
+	res, err := json.Marshal(chromeTrace{TraceEvents: events, DisplayTimeUnit: "ns"})
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(outputFile, res, 0644)
+}
+
+/*
+ * Get all elements of the trace sorted by tPre
+ * Args:
+ *   t (*trace.Trace): the trace
+ * Returns:
+ *   []trace.TraceElement: the elements
+ */
+func sortedElements(t *trace.Trace) []trace.TraceElement {
+	elems := make([]trace.TraceElement, 0)
+	for _, tra := range *t.GetTraces() {
+		elems = append(elems, tra...)
+	}
+
+	sort.SliceStable(elems, func(i, j int) bool {
+		if elems[i].GetTPre() != elems[j].GetTPre() {
+			return elems[i].GetTPre() < elems[j].GetTPre()
+		}
+		return elems[i].GetRoutine() < elems[j].GetRoutine()
+	})
+
+	return elems
+}
+
+/*
+ * Get the tPost of an element
+ * Args:
+ *   elem (trace.TraceElement): the element
+ * Returns:
+ *   int: the tPost, 0 if the operation did not finish
+ */
+func getTPost(elem trace.TraceElement) int {
+	if elem.GetTSort() == math.MaxInt {
+		return 0
+	}
+	return elem.GetTSort()
+}
+
+/*
+ * Get the category of an element, used to group and filter the events
+ * Args:
+ *   elem (trace.TraceElement): the element
+ * Returns:
+ *   string: the category
+ */
+func elementCategory(elem trace.TraceElement) string {
+	switch elem.(type) {
+	case *trace.TraceElementAtomic:
+		return "atomic"
+	case *trace.TraceElementMemory:
+		return "memory"
+	case *trace.TraceElementPanic:
+		return "panic"
+	case *trace.TraceElementChannel:
+		return "channel"
+	case *trace.TraceElementMutex:
+		return "mutex"
+	case *trace.TraceElementFork:
+		return "fork"
+	case *trace.TraceElementSelect:
+		return "select"
+	case *trace.TraceElementWait:
+		return "waitgroup"
+	case *trace.TraceElementOnce:
+		return "once"
+	case *trace.TraceElementCond:
+		return "cond"
+	case *trace.TraceElementReplay:
+		return "replay"
+	}
+	return "unknown"
+}
+
+/*
+ * Get the name of an element shown on the slice
+ * Args:
+ *   elem (trace.TraceElement): the element
+ * Returns:
+ *   string: the name
+ */
+func elementName(elem trace.TraceElement) string {
+	id := strconv.Itoa(elem.GetID())
+
+	switch e := elem.(type) {
+	case *trace.TraceElementChannel:
+		switch e.Operation() {
+		case trace.Send:
+			return "Send " + id
+		case trace.Recv:
+			return "Recv " + id
+		case trace.Close:
+			return "Close " + id
+		}
+	case *trace.TraceElementFork:
+		return "Go " + id
+	case *trace.TraceElementReplay:
+		return "Replay stop"
+	}
+
+	category := elementCategory(elem)
+	return strings.ToUpper(category[:1]) + category[1:] + " " + id
+}
+
+/*
+ * Create the flow events from each send to its receive. Send and receive
+ * are matched by the channel id and the oId.
+ * Args:
+ *   elems ([]trace.TraceElement): the elements sorted by tPre
+ * Returns:
+ *   []chromeEvent: the flow events
+ */
+func channelFlows(elems []trace.TraceElement) []chromeEvent {
+	type communication struct {
+		id  int
+		oID int
+	}
+
+	sends := make(map[communication]trace.TraceElement)
+	recvs := make(map[communication]trace.TraceElement)
+	keys := make([]communication, 0)
+
+	add := func(ch *trace.TraceElementChannel, elem trace.TraceElement) {
+		if ch.GetTSort() == math.MaxInt || ch.GetID() == -1 {
+			return
+		}
+		key := communication{ch.GetID(), ch.GetOID()}
+		switch ch.Operation() {
+		case trace.Send:
+			sends[key] = elem
+			keys = append(keys, key)
+		case trace.Recv:
+			recvs[key] = elem
+		}
+	}
+
+	for _, elem := range elems {
+		switch e := elem.(type) {
+		case *trace.TraceElementChannel:
+			add(e, e)
+		case *trace.TraceElementSelect:
+			for _, c := range e.GetCases() {
+				add(&c, e)
+			}
+		}
+	}
+
+	events := make([]chromeEvent, 0)
+	id := 1
+	for _, key := range keys {
+		recv, ok := recvs[key]
+		if !ok {
+			continue
+		}
+		events = append(events, flow(id, "channel", "Channel "+strconv.Itoa(key.id), sends[key], recv)...)
+		id++
+	}
+	return events
+}
+
+/*
+ * Create the flow events from each go statement to the first element of
+ * the created routine
+ * Args:
+ *   elems ([]trace.TraceElement): the elements sorted by tPre
+ *   firstID (int): the id of the first flow
+ * Returns:
+ *   []chromeEvent: the flow events
+ */
+func spawnFlows(elems []trace.TraceElement, firstID int) []chromeEvent {
+	first := make(map[int]trace.TraceElement)
+	for _, elem := range elems {
+		if _, ok := first[elem.GetRoutine()]; !ok {
+			first[elem.GetRoutine()] = elem
+		}
+	}
+
+	events := make([]chromeEvent, 0)
+	id := firstID
+	for _, elem := range elems {
+		fork, ok := elem.(*trace.TraceElementFork)
+		if !ok {
+			continue
+		}
+
+		child, ok := first[fork.GetID()]
+		if !ok {
+			continue
+		}
+		events = append(events, flow(id, "fork", "Go "+strconv.Itoa(fork.GetID()), fork, child)...)
+		id++
+	}
+	return events
+}
+
+/*
+ * Create the start and end event of a flow arrow
+ * Args:
+ *   id (int): the id of the flow
+ *   cat (string): the category of the flow
+ *   name (string): the name of the flow
+ *   from (trace.TraceElement): the element where the arrow starts
+ *   to (trace.TraceElement): the element where the arrow ends
+ * Returns:
+ *   []chromeEvent: the start and end event
+ */
+func flow(id int, cat string, name string, from trace.TraceElement, to trace.TraceElement) []chromeEvent {
+	return []chromeEvent{
+		{
+			Name: name,
+			Cat:  cat,
+			Ph:   "s",
+			Ts:   from.GetTPre(),
+			Pid:  chromePid,
+			Tid:  from.GetRoutine(),
+			ID:   id,
+		},
+		{
+			Name: name,
+			Cat:  cat,
+			Ph:   "f",
+			Bp:   "e",
+			Ts:   to.GetTPre(),
+			Pid:  chromePid,
+			Tid:  to.GetRoutine(),
+			ID:   id,
+		},
+	}
+}
+
+/*
+ * Read the machine readable analysis results and get the findings each
+ * element is involved in
+ * Args:
+ *   t (*trace.Trace): the trace the results belong to
+ *   resultsFile (string): path to the results file
+ * Returns:
+ *   map[trace.TraceElement][]string: for each involved element the findings, e.g. "Finding 1: P1"
+ *   error: error if the file could not be read
+ */
+func readFindings(t *trace.Trace, resultsFile string) (map[trace.TraceElement][]string, error) {
+	res := make(map[trace.TraceElement][]string)
+
+	content, err := os.ReadFile(resultsFile)
+	if err != nil {
+		return res, err
+	}
+
+	index := 0
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		index++
+
+		// results that do not belong to the trace are not highlighted
+		_, bug, err := bugs.ProcessBug(t, line)
+		if err != nil {
+			continue
+		}
+
+		finding := "Finding " + strconv.Itoa(index) + ": " + string(bug.Type)
+		for _, elem := range append(bug.TraceElement1, bug.TraceElement2...) {
+			res[*elem] = append(res[*elem], finding)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,551 @@
+package export
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"analyzer/bugs"
+	"analyzer/trace"
+)
+
+/*
+ * Edge in the happens before graph
+ * Fields:
+ *   from (trace.TraceElement): the element that happens before
+ *   to (trace.TraceElement): the element that happens after
+ *   kind (string): the reason for the edge, e.g. po for program order
+ */
+type hbEdge struct {
+	from trace.TraceElement
+	to   trace.TraceElement
+	kind string
+}
+
+/*
+ * Communication on a channel
+ * Fields:
+ *   id (int): the id of the channel
+ *   oID (int): the id of the communication
+ */
+type hbComm struct {
+	id  int
+	oID int
+}
+
+// style of the edges in the DOT output
+var edgeStyle = map[string]string{
+	"po":      "color=gray50",
+	"fork":    "color=blue, style=dashed",
+	"channel": "color=darkgreen, penwidth=2",
+	"mutex":   "color=orange, penwidth=2",
+	"wait":    "color=purple, penwidth=2",
+	"once":    "color=brown, penwidth=2",
+	"cond":    "color=red, penwidth=2",
+	"atomic":  "color=cyan4, penwidth=2",
+}
+
+/*
+ * Write the happens before subgraph around the elements of one analysis
+ * result. The graph contains all elements, that are connected to the
+ * elements of the result by at most depth edges. The elements of the result
+ * are highlighted.
+ * Args:
+ *   t (*trace.Trace): the trace
+ *   resultsFile (string): path to the machine readable analysis results
+ *   index (int): index of the result (1 based)
+ *   depth (int): maximum distance of the shown elements from the elements of the result
+ *   format (string): dot or graphml
+ *   outputFile (string): path of the created file
+ * Returns:
+ *   error: error if the result could not be read or the output could not be written
+ */
+func WriteHBGraph(t *trace.Trace, resultsFile string, index int, depth int, format string,
+	outputFile string) error {
+
+	bug, err := readResult(t, resultsFile, index)
+	if err != nil {
+		return err
+	}
+
+	highlighted := make(map[trace.TraceElement]bool)
+	targets := make([]trace.TraceElement, 0)
+	for _, elem := range append(bug.TraceElement1, bug.TraceElement2...) {
+		highlighted[*elem] = true
+		targets = append(targets, *elem)
+	}
+
+	nodes, edges := hbSubgraph(buildHBGraph(t), targets, depth)
+
+	var res string
+	switch format {
+	case "dot":
+		res = hbGraphToDot(nodes, edges, highlighted, "Result "+strconv.Itoa(index)+": "+string(bug.Type))
+	case "graphml":
+		res, err = hbGraphToGraphML(nodes, edges, highlighted)
+		if err != nil {
+			return err
+		}
+	default:
+		return errors.New("Unknown graph format: " + format)
+	}
+
+	return os.WriteFile(outputFile, []byte(res), 0644)
+}
+
+/*
+ * Read one result from the machine readable analysis results
+ * Args:
+ *   t (*trace.Trace): the trace the results belong to
+ *   resultsFile (string): path to the results file
+ *   index (int): index of the result (1 based)
+ * Returns:
+ *   bugs.Bug: the result
+ *   error: error if the result does not exist or could not be processed
+ */
+func readResult(t *trace.Trace, resultsFile string, index int) (bugs.Bug, error) {
+	content, err := os.ReadFile(resultsFile)
+	if err != nil {
+		return bugs.Bug{}, err
+	}
+
+	i := 0
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		i++
+		if i == index {
+			_, bug, err := bugs.ProcessBug(t, line)
+			return bug, err
+		}
+	}
+
+	return bugs.Bug{}, errors.New("Result " + strconv.Itoa(index) + " does not exist in " + resultsFile)
+}
+
+/*
+ * Create the edges of the happens before graph. Besides the program order,
+ * the graph contains the synchronizations, with which the analysis computes
+ * the vector clocks (see analysis/vc*.go):
+ *   - fork: the fork and the first element of the new routine
+ *   - channel: an unbuffered send and its receive in both directions, a
+ *     buffered send and the receive of the message, the receive of a message
+ *     and the send, that reuses its buffer slot, and a close and the
+ *     receives on the closed channel
+ *   - mutex: an unlock and the next lock or rlock, and the runlocks since the
+ *     last unlock and the next lock
+ *   - wait: the last add or done of each routine and the wait
+ *   - once: the do, that executed the function, and the other dos
+ *   - cond: the last signal or broadcast and a wait, that returned
+ *   - atomic: the last write and a load, swap or compare and swap
+ * The elements are processed in the order of the analysis. Elements, that
+ * did not finish, do not synchronize.
+ * Args:
+ *   t (*trace.Trace): the trace
+ * Returns:
+ *   []hbEdge: the edges
+ */
+func buildHBGraph(t *trace.Trace) []hbEdge {
+	edges := make([]hbEdge, 0)
+
+	routines := make([]int, 0)
+	for routine := range *t.GetTraces() {
+		routines = append(routines, routine)
+	}
+	sort.Ints(routines)
+
+	elems := make([]trace.TraceElement, 0)
+	for _, routine := range routines {
+		tra := t.GetTraceFromId(routine)
+		for i, elem := range tra {
+			if i > 0 {
+				edges = append(edges, hbEdge{tra[i-1], elem, "po"})
+			}
+			if elem.GetTSort() != math.MaxInt {
+				elems = append(elems, elem)
+			}
+		}
+	}
+	sort.SliceStable(elems, func(i, j int) bool {
+		return elems[i].GetTSort() < elems[j].GetTSort()
+	})
+
+	unbufferedSend := make(map[hbComm]trace.TraceElement)
+	unbufferedRecv := make(map[hbComm]trace.TraceElement)
+	bufferedSend := make(map[hbComm]trace.TraceElement)
+	bufferedRecv := make(map[hbComm]trace.TraceElement)
+	qSize := make(map[int]int)
+	closes := make(map[int]trace.TraceElement)
+
+	lastUnlock := make(map[int]trace.TraceElement)
+	rUnlocks := make(map[int][]trace.TraceElement)
+	wgChanges := make(map[int]map[int]trace.TraceElement) // id -> routine -> last add or done
+	onceSuc := make(map[int]trace.TraceElement)
+	condRelease := make(map[int]trace.TraceElement)
+	lastWrite := make(map[int]trace.TraceElement)
+
+	add := func(from trace.TraceElement, to trace.TraceElement, kind string) {
+		if from != nil && from.GetRoutine() != to.GetRoutine() {
+			edges = append(edges, hbEdge{from, to, kind})
+		}
+	}
+
+	for _, elem := range elems {
+		switch e := elem.(type) {
+		case *trace.TraceElementFork:
+			if tra := t.GetTraceFromId(e.GetID()); len(tra) > 0 {
+				add(e, tra[0], "fork")
+			}
+
+		case *trace.TraceElementChannel, *trace.TraceElementSelect:
+			var ch *trace.TraceElementChannel
+			if sel, ok := e.(*trace.TraceElementSelect); ok {
+				ch = sel.GetChosenCase()
+			} else {
+				ch = e.(*trace.TraceElementChannel)
+			}
+			if ch == nil {
+				continue
+			}
+
+			key := hbComm{ch.GetID(), ch.GetOID()}
+			switch {
+			case ch.Operation() == trace.Close:
+				closes[ch.GetID()] = elem
+			case ch.Operation() == trace.Recv && ch.IsClosed():
+				add(closes[ch.GetID()], elem, "channel")
+			case !ch.IsBuffered() && ch.Operation() == trace.Send:
+				unbufferedSend[key] = elem
+			case !ch.IsBuffered():
+				unbufferedRecv[key] = elem
+			case ch.Operation() == trace.Send:
+				bufferedSend[key] = elem
+				qSize[ch.GetID()] = ch.GetQSize()
+			default:
+				bufferedRecv[key] = elem
+			}
+
+		case *trace.TraceElementMutex:
+			id := e.GetID()
+			switch e.GetOperation() {
+			case trace.LockOp, trace.TryLockOp:
+				if !e.IsSuc() {
+					continue
+				}
+				add(lastUnlock[id], e, "mutex")
+				for _, rUnlock := range rUnlocks[id] {
+					add(rUnlock, e, "mutex")
+				}
+			case trace.RLockOp, trace.TryRLockOp:
+				if e.IsSuc() {
+					add(lastUnlock[id], e, "mutex")
+				}
+			case trace.UnlockOp:
+				lastUnlock[id] = e
+				rUnlocks[id] = nil
+			case trace.RUnlockOp:
+				rUnlocks[id] = append(rUnlocks[id], e)
+			}
+
+		case *trace.TraceElementWait:
+			id := e.GetID()
+			if !e.IsWait() {
+				if _, ok := wgChanges[id]; !ok {
+					wgChanges[id] = make(map[int]trace.TraceElement)
+				}
+				wgChanges[id][e.GetRoutine()] = e
+				continue
+			}
+			for _, routine := range sortedKeys(wgChanges[id]) {
+				add(wgChanges[id][routine], e, "wait")
+			}
+
+		case *trace.TraceElementOnce:
+			if e.IsSuc() {
+				onceSuc[e.GetID()] = e
+			} else {
+				add(onceSuc[e.GetID()], e, "once")
+			}
+
+		case *trace.TraceElementCond:
+			if e.GetOpCond() == trace.WaitCondOp {
+				add(condRelease[e.GetID()], e, "cond")
+			} else {
+				condRelease[e.GetID()] = e
+			}
+
+		case *trace.TraceElementAtomic:
+			switch e.GetObjType() {
+			case "AL":
+				add(lastWrite[e.GetID()], e, "atomic")
+			case "AS", "AA":
+				lastWrite[e.GetID()] = e
+			case "AW", "AC":
+				add(lastWrite[e.GetID()], e, "atomic")
+				lastWrite[e.GetID()] = e
+			}
+		}
+	}
+
+	// communications are matched by their oID, independent of the order
+	// in which the operations were processed
+	for _, key := range sortedComms(unbufferedSend) {
+		if recv, ok := unbufferedRecv[key]; ok {
+			add(unbufferedSend[key], recv, "channel")
+			add(recv, unbufferedSend[key], "channel")
+		}
+	}
+	for _, key := range sortedComms(bufferedSend) {
+		if recv, ok := bufferedRecv[key]; ok {
+			add(bufferedSend[key], recv, "channel")
+		}
+		// the send reuses the buffer slot of the message qSize before it
+		if recv, ok := bufferedRecv[hbComm{key.id, key.oID - qSize[key.id]}]; ok && qSize[key.id] > 0 {
+			add(recv, bufferedSend[key], "channel")
+		}
+	}
+
+	return edges
+}
+
+/*
+ * Get the keys of a map in increasing order
+ * Args:
+ *   m (map[int]trace.TraceElement): the map
+ * Returns:
+ *   []int: the sorted keys
+ */
+func sortedKeys(m map[int]trace.TraceElement) []int {
+	res := make([]int, 0, len(m))
+	for key := range m {
+		res = append(res, key)
+	}
+	sort.Ints(res)
+	return res
+}
+
+/*
+ * Get the communications of a map in increasing order
+ * Args:
+ *   m (map[hbComm]trace.TraceElement): the map
+ * Returns:
+ *   []hbComm: the sorted communications
+ */
+func sortedComms(m map[hbComm]trace.TraceElement) []hbComm {
+	res := make([]hbComm, 0, len(m))
+	for key := range m {
+		res = append(res, key)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].id != res[j].id {
+			return res[i].id < res[j].id
+		}
+		return res[i].oID < res[j].oID
+	})
+	return res
+}
+
+/*
+ * Get the part of the graph around the given elements
+ * Args:
+ *   edges ([]hbEdge): the edges of the graph
+ *   targets ([]trace.TraceElement): the elements the subgraph is created for
+ *   depth (int): maximum number of edges between a shown element and one of the targets
+ * Returns:
+ *   []trace.TraceElement: the nodes of the subgraph, sorted by routine and time
+ *   []hbEdge: the edges between the nodes of the subgraph
+ */
+func hbSubgraph(edges []hbEdge, targets []trace.TraceElement, depth int) ([]trace.TraceElement, []hbEdge) {
+	neighbors := make(map[trace.TraceElement][]trace.TraceElement)
+	for _, edge := range edges {
+		neighbors[edge.from] = append(neighbors[edge.from], edge.to)
+		neighbors[edge.to] = append(neighbors[edge.to], edge.from)
+	}
+
+	distance := make(map[trace.TraceElement]int)
+	queue := make([]trace.TraceElement, 0)
+	for _, target := range targets {
+		distance[target] = 0
+		queue = append(queue, target)
+	}
+
+	for len(queue) > 0 {
+		elem := queue[0]
+		queue = queue[1:]
+		if distance[elem] == depth {
+			continue
+		}
+		for _, n := range neighbors[elem] {
+			if _, ok := distance[n]; !ok {
+				distance[n] = distance[elem] + 1
+				queue = append(queue, n)
+			}
+		}
+	}
+
+	nodes := make([]trace.TraceElement, 0, len(distance))
+	for elem := range distance {
+		nodes = append(nodes, elem)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].GetRoutine() != nodes[j].GetRoutine() {
+			return nodes[i].GetRoutine() < nodes[j].GetRoutine()
+		}
+		return nodes[i].GetTPre() < nodes[j].GetTPre()
+	})
+
+	subEdges := make([]hbEdge, 0)
+	for _, edge := range edges {
+		_, okFrom := distance[edge.from]
+		_, okTo := distance[edge.to]
+		if okFrom && okTo {
+			subEdges = append(subEdges, edge)
+		}
+	}
+
+	return nodes, subEdges
+}
+
+/*
+ * Get the label of a node
+ * Args:
+ *   elem (trace.TraceElement): the element
+ * Returns:
+ *   string: the label
+ */
+func nodeLabel(elem trace.TraceElement) string {
+	return elementName(elem) + "\n" + filepath.Base(elem.GetPos()) + " @ " + strconv.Itoa(elem.GetTPre())
+}
+
+/*
+ * Convert the graph into the DOT format
+ * Args:
+ *   nodes ([]trace.TraceElement): the nodes sorted by routine
+ *   edges ([]hbEdge): the edges
+ *   highlighted (map[trace.TraceElement]bool): the highlighted nodes
+ *   title (string): the title of the graph
+ * Returns:
+ *   string: the graph in the DOT format
+ */
+func hbGraphToDot(nodes []trace.TraceElement, edges []hbEdge,
+	highlighted map[trace.TraceElement]bool, title string) string {
+
+	ids := make(map[trace.TraceElement]string)
+
+	res := "digraph hb {\n"
+	res += "\tlabel=" + strconv.Quote(title) + ";\n"
+	res += "\tlabelloc=t;\n"
+	res += "\tnode [shape=box, fontname=\"monospace\"];\n\n"
+
+	for i := 0; i < len(nodes); {
+		routine := nodes[i].GetRoutine()
+		res += fmt.Sprintf("\tsubgraph cluster_%d {\n", routine)
+		res += fmt.Sprintf("\t\tlabel=\"Routine %d\";\n", routine)
+		for ; i < len(nodes) && nodes[i].GetRoutine() == routine; i++ {
+			id := "n" + strconv.Itoa(i+1)
+			ids[nodes[i]] = id
+			attr := "label=" + strconv.Quote(nodeLabel(nodes[i]))
+			if highlighted[nodes[i]] {
+				attr += ", style=filled, fillcolor=\"#ff9999\", penwidth=2"
+			}
+			res += "\t\t" + id + " [" + attr + "];\n"
+		}
+		res += "\t}\n"
+	}
+
+	res += "\n"
+	for _, edge := range edges {
+		res += "\t" + ids[edge.from] + " -> " + ids[edge.to] + " [" + edgeStyle[edge.kind] +
+			", tooltip=\"" + edge.kind + "\"];\n"
+	}
+	res += "}\n"
+
+	return res
+}
+
+/*
+ * Convert the graph into the GraphML format
+ * Args:
+ *   nodes ([]trace.TraceElement): the nodes
+ *   edges ([]hbEdge): the edges
+ *   highlighted (map[trace.TraceElement]bool): the highlighted nodes
+ * Returns:
+ *   string: the graph in the GraphML format
+ *   error: error if the graph could not be encoded
+ */
+func hbGraphToGraphML(nodes []trace.TraceElement, edges []hbEdge,
+	highlighted map[trace.TraceElement]bool) (string, error) {
+
+	type data struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+	type node struct {
+		ID   string `xml:"id,attr"`
+		Data []data `xml:"data"`
+	}
+	type edge struct {
+		Source string `xml:"source,attr"`
+		Target string `xml:"target,attr"`
+		Data   []data `xml:"data"`
+	}
+	type key struct {
+		ID       string `xml:"id,attr"`
+		For      string `xml:"for,attr"`
+		AttrName string `xml:"attr.name,attr"`
+		AttrType string `xml:"attr.type,attr"`
+	}
+	type graph struct {
+		EdgeDefault string `xml:"edgedefault,attr"`
+		Nodes       []node `xml:"node"`
+		Edges       []edge `xml:"edge"`
+	}
+	type graphML struct {
+		XMLName xml.Name `xml:"graphml"`
+		Xmlns   string   `xml:"xmlns,attr"`
+		Keys    []key    `xml:"key"`
+		Graph   graph    `xml:"graph"`
+	}
+
+	g := graphML{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []key{
+			{"label", "node", "label", "string"},
+			{"routine", "node", "routine", "int"},
+			{"element", "node", "element", "string"},
+			{"highlighted", "node", "highlighted", "boolean"},
+			{"kind", "edge", "kind", "string"},
+		},
+		Graph: graph{EdgeDefault: "directed"},
+	}
+
+	ids := make(map[trace.TraceElement]string)
+	for i, elem := range nodes {
+		id := "n" + strconv.Itoa(i+1)
+		ids[elem] = id
+		g.Graph.Nodes = append(g.Graph.Nodes, node{id, []data{
+			{"label", nodeLabel(elem)},
+			{"routine", strconv.Itoa(elem.GetRoutine())},
+			{"element", elem.ToString()},
+			{"highlighted", strconv.FormatBool(highlighted[elem])},
+		}})
+	}
+
+	for _, e := range edges {
+		g.Graph.Edges = append(g.Graph.Edges, edge{ids[e.from], ids[e.to], []data{{"kind", e.kind}}})
+	}
+
+	res, err := xml.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return xml.Header + string(res) + "\n", nil
+}
@@ -20,19 +20,22 @@ import (
  *   filePath (string): The path to the folder
  *   ignoreAtomics (bool): If atomic operations should be ignored
  * Returns:
+ *   *trace.Trace: The trace
  *   int: The number of routines
  *   error: An error if the trace could not be created
  */
-func CreateTraceFromFiles(filePath string, ignoreAtomics bool) (int, error) {
+func CreateTraceFromFiles(filePath string, ignoreAtomics bool) (*trace.Trace, int, error) {
 	maxTokenSize := 4
 	numberIds := 0
 
 	println("Read trace from " + filePath + "...")
 
+	t := trace.NewTrace()
+
 	// traverse all files in the folder
 	files, err := os.ReadDir(filePath)
 	if err != nil {
-		return 0, err
+		return t, 0, err
 	}
 
 	for _, file := range files {
@@ -44,27 +47,36 @@ func CreateTraceFromFiles(filePath string, ignoreAtomics bool) (int, error) {
 			continue
 		}
 
+		// skip files that are not trace files, e.g. rewrite_info.log
 		routine, err := getRoutineFromFileName(file.Name())
 		if err != nil {
-			return 0, nil
+			continue
 		}
 		numberIds = max(numberIds, routine)
 
-		maxTokenSize, err = CreateTraceFromFile(filePath+"/"+file.Name(), routine, maxTokenSize, ignoreAtomics)
+		maxTokenSize, err = CreateTraceFromFile(t, filePath+"/"+file.Name(), routine, maxTokenSize, ignoreAtomics)
 		if err != nil {
-			return 0, err
+			return t, 0, err
 		}
 
 	}
 
-	trace.Sort()
+	t.Sort()
+	t.SetNumberOfRoutines(numberIds)
 
-	return numberIds, nil
+	// traces imported from runtime/trace contain the information, that
+	// could not be imported
+	if _, err := os.Stat(filePath + "/import_info.log"); err == nil {
+		t.SetImported(true)
+	}
+
+	return t, numberIds, nil
 }
 
 /*
  * Read and build the trace from a file
  * Args:
+ *   t (*trace.Trace): The trace to add the elements to
  *   filePath (string): The path to the log file
  *   routine (int): The routine id
  *   maxTokenSize (int): The max token size
@@ -72,7 +84,7 @@ func CreateTraceFromFiles(filePath string, ignoreAtomics bool) (int, error) {
  * Returns:
  *   int: The max token size
  */
-func CreateTraceFromFile(filePath string, routine int, maxTokenSize int, ignoreAtomics bool) (int, error) {
+func CreateTraceFromFile(t *trace.Trace, filePath string, routine int, maxTokenSize int, ignoreAtomics bool) (int, error) {
 	logging.Debug("Create trace from file "+filePath+"...", logging.INFO)
 	mb := 1048576 // 1 MB
 
@@ -93,7 +105,7 @@ func CreateTraceFromFile(filePath string, routine int, maxTokenSize int, ignoreA
 			}
 
 			line := scanner.Text()
-			processLine(line, routine, ignoreAtomics)
+			processLine(t, line, routine, ignoreAtomics)
 			alreadyRead = true
 		}
 
@@ -117,17 +129,18 @@ func CreateTraceFromFile(filePath string, routine int, maxTokenSize int, ignoreA
 /*
  * Process one line from the log file.
  * Args:
+ *   t (*trace.Trace): The trace to add the elements to
  *   line (string): The line to process
  *   routine (int): The routine id, equal to the line number
  *   ignoreAtomics (bool): If atomic operations should be ignored
  * Returns:
  *   error: An error if the line could not be processed
  */
-func processLine(line string, routine int, ignoreAtomics bool) error {
+func processLine(t *trace.Trace, line string, routine int, ignoreAtomics bool) error {
 	logging.Debug("Read routine "+strconv.Itoa(routine), logging.DEBUG)
 	elements := strings.Split(line, ";")
 	for _, element := range elements {
-		err := processElement(element, routine, ignoreAtomics)
+		err := processElement(t, element, routine, ignoreAtomics)
 		if err != nil {
 			return err
 		}
@@ -138,13 +151,14 @@ func processLine(line string, routine int, ignoreAtomics bool) error {
 /*
  * Process one element from the log file.
  * Args:
+ *   t (*trace.Trace): The trace to add the element to
  *   element (string): The element to process
  *   routine (int): The routine id, equal to the line number
  *   ignoreAtomics (bool): If atomic operations should be ignored
  * Returns:
  *   error: An error if the element could not be processed
  */
-func processElement(element string, routine int, ignoreAtomics bool) error {
+func processElement(t *trace.Trace, element string, routine int, ignoreAtomics bool) error {
 	if element == "" {
 		logging.Debug("Routine "+strconv.Itoa(routine)+" is empty", logging.DEBUG)
 		return errors.New("Element is empty")
@@ -156,27 +170,37 @@ func processElement(element string, routine int, ignoreAtomics bool) error {
 		if ignoreAtomics {
 			return nil
 		}
-		err = trace.AddTraceElementAtomic(routine, fields[1], fields[2], fields[3])
+		pos := ""
+		if len(fields) > 4 {
+			pos = fields[4]
+		}
+		err = t.AddTraceElementAtomic(routine, fields[1], fields[2], fields[3], pos)
 	case "C":
-		err = trace.AddTraceElementChannel(routine, fields[1], fields[2],
+		err = t.AddTraceElementChannel(routine, fields[1], fields[2],
 			fields[3], fields[4], fields[5], fields[6], fields[7], fields[8])
 	case "M":
-		err = trace.AddTraceElementMutex(routine, fields[1], fields[2],
+		err = t.AddTraceElementMutex(routine, fields[1], fields[2],
 			fields[3], fields[4], fields[5], fields[6], fields[7])
 	case "G":
-		err = trace.AddTraceElementFork(routine, fields[1], fields[2], fields[3])
+		err = t.AddTraceElementFork(routine, fields[1], fields[2], fields[3])
 	case "S":
-		err = trace.AddTraceElementSelect(routine, fields[1], fields[2], fields[3],
+		err = t.AddTraceElementSelect(routine, fields[1], fields[2], fields[3],
 			fields[4], fields[5], fields[6])
 	case "W":
-		err = trace.AddTraceElementWait(routine, fields[1], fields[2], fields[3],
+		err = t.AddTraceElementWait(routine, fields[1], fields[2], fields[3],
 			fields[4], fields[5], fields[6], fields[7])
 	case "O":
-		err = trace.AddTraceElementOnce(routine, fields[1], fields[2], fields[3],
+		err = t.AddTraceElementOnce(routine, fields[1], fields[2], fields[3],
 			fields[4], fields[5])
 	case "N":
-		err = trace.AddTraceElementCond(routine, fields[1], fields[2], fields[3],
+		err = t.AddTraceElementCond(routine, fields[1], fields[2], fields[3],
 			fields[4], fields[5])
+	case "D":
+		err = t.AddTraceElementMemory(routine, fields[1], fields[2], fields[3], fields[4], fields[5])
+	case "P":
+		err = t.AddTraceElementPanic(routine, fields[1], fields[2])
+	case "X":
+		err = processElementReplay(t, fields)
 	default:
 		return errors.New("Unknown element type in: " + element)
 	}
@@ -188,6 +212,32 @@ func processElement(element string, routine int, ignoreAtomics bool) error {
 	return nil
 }
 
+/*
+ * Process the replay stop element of a rewritten trace
+ * Args:
+ *   t (*trace.Trace): The trace to add the element to
+ *   fields ([]string): The fields of the element
+ * Returns:
+ *   error: An error if the element could not be processed
+ */
+func processElementReplay(t *trace.Trace, fields []string) error {
+	if len(fields) != 3 {
+		return errors.New("Replay element must have 3 fields")
+	}
+
+	tPost, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return errors.New("Replay time is not an integer")
+	}
+
+	exitCode, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return errors.New("Replay exit code is not an integer")
+	}
+
+	return t.AddTraceElementReplay(tPost, exitCode)
+}
+
 func getRoutineFromFileName(fileName string) (int, error) {
 	// the file name is "trace_routineID.log"
 	// remove the .log at the end
@@ -2,6 +2,7 @@ package io
 
 import (
 	"analyzer/bugs"
+	"analyzer/trace"
 	"bufio"
 	"os"
 	"strconv"
@@ -11,6 +12,7 @@ import (
  * Read the fail containing the output of the analysis
  * Extract the needed information to create a trace to replay the selected error
  * Args:
+ *   t (*trace.Trace): The trace the analysis results belong to
  *   filePath (string): The path to the file containing the analysis results
  *   index (int): The index of the result to create a trace for (0 based)
  * Returns:
@@ -18,8 +20,8 @@ import (
  *   Bug: The bug that was selected
  *   error: An error if the bug could not be processed
  */
-func ReadAnalysisResults(filePath string, index int) (bool, bugs.Bug, error) {
-	println("Read analysis results from " + filePath + " for index " + strconv.Itoa(index) + "...")
+func ReadAnalysisResults(t *trace.Trace, filePath string, index int) (bool, bugs.Bug, error) {
+	t.Println("Read analysis results from " + filePath + " for index " + strconv.Itoa(index) + "...")
 
 	mb := 1048576 // 1 MB
 	maxTokenSize := 1
@@ -29,7 +31,7 @@ func ReadAnalysisResults(filePath string, index int) (bool, bugs.Bug, error) {
 	for {
 		file, err := os.Open(filePath)
 		if err != nil {
-			println("Error opening file: " + filePath)
+			t.Println("Error opening file: " + filePath)
 			panic(err)
 		}
 
@@ -48,9 +50,9 @@ func ReadAnalysisResults(filePath string, index int) (bool, bugs.Bug, error) {
 		if err := scanner.Err(); err != nil {
 			if err == bufio.ErrTooLong {
 				maxTokenSize *= 2 // max buffer was to short, restart
-				println("Increase max file size to " + strconv.Itoa(maxTokenSize) + "MB")
+				t.Println("Increase max file size to " + strconv.Itoa(maxTokenSize) + "MB")
 			} else {
-				println("Error reading file line.")
+				t.Println("Error reading file line.")
 				panic(err)
 			}
 		} else {
@@ -58,20 +60,20 @@ func ReadAnalysisResults(filePath string, index int) (bool, bugs.Bug, error) {
 		}
 	}
 
-	println("Analysis results read")
+	t.Println("Analysis results read")
 
-	actual, bug, err := bugs.ProcessBug(bugStr)
+	actual, bug, err := bugs.ProcessBug(t, bugStr)
 	if err != nil {
-		println("Error processing bug")
-		println(err.Error())
+		t.Println("Error processing bug")
+		t.Println(err.Error())
 		return false, bug, err
 	}
 
-	bug.Println()
+	t.Println(bug.ToString())
 
 	if actual {
-		println("The bug is an actual bug.")
-		println("No rewrite needed.")
+		t.Println("The bug is an actual bug.")
+		t.Println("No rewrite needed.")
 		return true, bug, nil
 	}
 
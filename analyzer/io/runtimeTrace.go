@@ -0,0 +1,703 @@
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"analyzer/trace"
+)
+
+/*
+ * Operations that can be reconstructed from a runtime/trace
+ */
+type runtimeOpKind int
+
+const (
+	rtUnknown runtimeOpKind = iota
+	rtSend
+	rtRecv
+	rtClose
+	rtSelect
+	rtLock
+	rtRLock
+	rtUnlock
+	rtRUnlock
+	rtWait
+	rtDone
+	rtAdd
+	rtCondWait
+	rtSignal
+	rtBroadcast
+	rtFork
+)
+
+// functions of the standard library, that are called directly by the program
+// for an operation, and the operation
+var runtimeOpFunctions = map[string]runtimeOpKind{
+	"runtime.chansend1":       rtSend,
+	"runtime.chanrecv1":       rtRecv,
+	"runtime.chanrecv2":       rtRecv,
+	"runtime.closechan":       rtClose,
+	"runtime.selectgo":        rtSelect,
+	"runtime.block":           rtSelect,
+	"sync.(*Mutex).Lock":      rtLock,
+	"sync.(*Mutex).Unlock":    rtUnlock,
+	"sync.(*RWMutex).Lock":    rtLock,
+	"sync.(*RWMutex).RLock":   rtRLock,
+	"sync.(*RWMutex).Unlock":  rtUnlock,
+	"sync.(*RWMutex).RUnlock": rtRUnlock,
+	"sync.(*WaitGroup).Wait":  rtWait,
+	"sync.(*WaitGroup).Done":  rtDone,
+	"sync.(*WaitGroup).Add":   rtAdd,
+	"sync.(*Cond).Wait":       rtCondWait,
+	"sync.(*Cond).Signal":     rtSignal,
+	"sync.(*Cond).Broadcast":  rtBroadcast,
+}
+
+// packages of the standard library that are skipped to find the position
+// of an operation in the program
+var runtimeStdPrefixes = []string{"runtime.", "runtime/", "internal/", "sync.", "sync/", "time."}
+
+var runtimeEventRegex = regexp.MustCompile(
+	`^M=-?\d+ P=-?\d+ G=(-?\d+) StateTransition Time=(\d+) GoID=(\d+) (\w+)->(\w+) Reason="(.*)"$`)
+
+/*
+ * Frame of a stack in a runtime trace
+ * Fields:
+ *   function (string): the name of the function
+ *   pos (string): the position, file:line
+ */
+type runtimeFrame struct {
+	function string
+	pos      string
+}
+
+/*
+ * State transition of a goroutine in a runtime trace
+ * Fields:
+ *   g (int): the goroutine that caused the transition, -1 if none
+ *   time (int): the time of the transition in ns
+ *   goID (int): the goroutine whose state changed
+ *   from (string): the old state
+ *   to (string): the new state
+ *   stack ([]runtimeFrame): the stack of g
+ *   transitionStack ([]runtimeFrame): the stack of goID
+ */
+type runtimeEvent struct {
+	g               int
+	time            int
+	goID            int
+	from            string
+	to              string
+	stack           []runtimeFrame
+	transitionStack []runtimeFrame
+}
+
+/*
+ * Operation reconstructed from a runtime trace
+ * Fields:
+ *   kind (runtimeOpKind): the operation
+ *   routine (int): the routine that executed the operation
+ *   tPre (int): the time the operation started in ns
+ *   tPost (int): the time the operation finished in ns, 0 if it never finished
+ *   blocked (bool): if the operation blocked its routine
+ *   id (int): the id of the object or the created routine
+ *   rw (bool): if the operation is on a RWMutex
+ *   pos (string): the position of the operation
+ *   oID (int): for channel operations the id of the communication, shared
+ *     with the partner
+ *   partner (*runtimeOp): for a blocked operation the operation that unblocked it,
+ *     otherwise the first operation unblocked by this operation
+ */
+type runtimeOp struct {
+	kind    runtimeOpKind
+	routine int
+	tPre    int
+	tPost   int
+	blocked bool
+	id      int
+	rw      bool
+	pos     string
+	oID     int
+	partner *runtimeOp
+}
+
+/*
+ * Create a trace from a Go execution trace recorded with runtime/trace.
+ * The execution trace only contains operations that blocked a goroutine
+ * and the operation that unblocked it, as well as the creation of the
+ * goroutines. Operations that did not block are not contained.
+ * Args:
+ *   filePath (string): path to the execution trace, either the binary trace
+ *     or the output of go tool trace -d=parsed
+ * Returns:
+ *   *trace.Trace: the trace
+ *   int: the number of routines
+ *   []string: information that could not be imported
+ *   error: error if the trace could not be read or converted
+ */
+func CreateTraceFromRuntimeTrace(filePath string) (*trace.Trace, int, []string, error) {
+	println("Read runtime trace from " + filePath + "...")
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	// binary traces start with a header like "go 1.23 trace"
+	if bytes.HasPrefix(content, []byte("go 1.")) {
+		cmd := exec.Command("go", "tool", "trace", "-d=parsed", filePath)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		content, err = cmd.Output()
+		if err != nil {
+			return nil, 0, nil, errors.New("Could not parse runtime trace with go tool trace: " +
+				strings.TrimSpace(stderr.String()))
+		}
+	}
+
+	events, err := parseRuntimeEvents(bytes.NewReader(content))
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	ops, numberRoutines, notes := convertRuntimeEvents(events)
+
+	t, err := createTraceFromRuntimeOps(ops, numberRoutines)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	return t, numberRoutines, notes, nil
+}
+
+/*
+ * Parse the state transitions of goroutines from the output of
+ * go tool trace -d=parsed
+ * Args:
+ *   r (io.Reader): the output
+ * Returns:
+ *   []runtimeEvent: the state transitions of goroutines
+ *   error: error if the output could not be read
+ */
+func parseRuntimeEvents(r io.Reader) ([]runtimeEvent, error) {
+	events := make([]runtimeEvent, 0)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 64*1024*1024)
+
+	// the event and the stack the following lines belong to
+	current := -1
+	var stack *[]runtimeFrame
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "M="):
+			current = -1
+			stack = nil
+			match := runtimeEventRegex.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			g, _ := strconv.Atoi(match[1])
+			time, _ := strconv.Atoi(match[2])
+			goID, _ := strconv.Atoi(match[3])
+			events = append(events, runtimeEvent{g: g, time: time, goID: goID, from: match[4], to: match[5]})
+			current = len(events) - 1
+		case current == -1:
+			continue
+		case line == "Stack=":
+			stack = &events[current].stack
+		case line == "TransitionStack=":
+			stack = &events[current].transitionStack
+		case stack == nil || line == "":
+			stack = nil
+		case strings.HasPrefix(line, "\t\t"):
+			if len(*stack) > 0 {
+				(*stack)[len(*stack)-1].pos = strings.TrimSpace(line)
+			}
+		case strings.HasPrefix(line, "\t"):
+			function := strings.TrimSpace(line)
+			if i := strings.LastIndex(function, " @ "); i != -1 {
+				function = function[:i]
+			}
+			*stack = append(*stack, runtimeFrame{function: function})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(events) == 0 {
+		return nil, errors.New("No goroutine events found in runtime trace")
+	}
+
+	return events, nil
+}
+
+/*
+ * Check if a frame belongs to the standard library
+ * Args:
+ *   frame (runtimeFrame): the frame
+ * Returns:
+ *   bool: true if the frame belongs to the standard library
+ */
+func isStdFrame(frame runtimeFrame) bool {
+	for _, prefix := range runtimeStdPrefixes {
+		if strings.HasPrefix(frame.function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Get the operation of a stack and its position in the program. The
+ * operation is given by the function of the standard library, that was
+ * called by the program.
+ * Args:
+ *   stack ([]runtimeFrame): the stack
+ * Returns:
+ *   runtimeOpKind: the operation, rtUnknown if it could not be determined
+ *   bool: true if the operation is on a RWMutex
+ *   runtimeFrame: the frame of the program, empty if the stack only contains the standard library
+ */
+func getRuntimeOp(stack []runtimeFrame) (runtimeOpKind, bool, runtimeFrame) {
+	for i, frame := range stack {
+		if isStdFrame(frame) {
+			continue
+		}
+		if i == 0 {
+			return rtUnknown, false, frame
+		}
+		entry := stack[i-1].function
+		return runtimeOpFunctions[entry], strings.HasPrefix(entry, "sync.(*RWMutex)"), frame
+	}
+	return rtUnknown, false, runtimeFrame{}
+}
+
+/*
+ * Check if an operation can unblock a blocked operation
+ * Args:
+ *   blocked (runtimeOpKind): the blocked operation
+ *   unblocker (runtimeOpKind): the unblocking operation
+ * Returns:
+ *   bool: true if the unblocking operation can unblock the blocked operation
+ */
+func canUnblock(blocked runtimeOpKind, unblocker runtimeOpKind) bool {
+	switch blocked {
+	case rtRecv:
+		return unblocker == rtSend || unblocker == rtSelect || unblocker == rtClose
+	case rtSend:
+		return unblocker == rtRecv || unblocker == rtSelect
+	case rtSelect:
+		return unblocker == rtSend || unblocker == rtRecv || unblocker == rtSelect || unblocker == rtClose
+	case rtLock, rtRLock:
+		return unblocker == rtUnlock || unblocker == rtRUnlock
+	case rtWait:
+		return unblocker == rtDone
+	case rtCondWait:
+		return unblocker == rtSignal || unblocker == rtBroadcast
+	}
+	return false
+}
+
+/*
+ * Convert the state transitions of the goroutines into operations.
+ * Only goroutines of the program are converted, goroutines of the runtime
+ * are ignored.
+ * Args:
+ *   events ([]runtimeEvent): the state transitions
+ * Returns:
+ *   []*runtimeOp: the operations
+ *   int: the number of routines
+ *   []string: information that could not be imported
+ */
+func convertRuntimeEvents(events []runtimeEvent) ([]*runtimeOp, int, []string) {
+	// goroutines of the program, either goroutine 1 or goroutines whose
+	// function is not in the standard library
+	routines := map[int]int{1: 1}
+	numberRoutines := 1
+	isProgramGoroutine := func(ev runtimeEvent) bool {
+		if _, ok := routines[ev.goID]; ok {
+			return true
+		}
+		if ev.from != "NotExist" && ev.from != "Undetermined" {
+			return false
+		}
+		for _, frame := range ev.transitionStack {
+			if !isStdFrame(frame) {
+				numberRoutines++
+				routines[ev.goID] = numberRoutines
+				return true
+			}
+		}
+		return false
+	}
+
+	ops := make([]*runtimeOp, 0)
+	pending := make(map[int]*runtimeOp)
+
+	// one operation can unblock multiple goroutines, e.g. a close or a
+	// broadcast, the unblocking is then recorded as consecutive events
+	lastUnblock := make(map[int]*runtimeOp)
+	lastUnblockStack := make(map[int]string)
+
+	numberUnblockedBy := map[runtimeOpKind]int{}
+	numberBlockedBefore := 0
+
+	for _, ev := range events {
+		if ev.from != "Waiting" || ev.to != "Runnable" {
+			delete(lastUnblock, ev.g)
+		}
+
+		if !isProgramGoroutine(ev) {
+			continue
+		}
+		routine := routines[ev.goID]
+
+		switch {
+		case ev.from == "NotExist" && ev.to == "Runnable":
+			parent, ok := routines[ev.g]
+			if !ok {
+				continue
+			}
+			_, _, frame := getRuntimeOp(ev.stack)
+			ops = append(ops, &runtimeOp{kind: rtFork, routine: parent, tPre: ev.time,
+				tPost: ev.time, id: routine, pos: frame.pos})
+
+		case ev.to == "Waiting" && (ev.from == "Running" || ev.from == "Undetermined"):
+			kind, rw, frame := getRuntimeOp(ev.transitionStack)
+			if kind == rtUnknown {
+				continue
+			}
+			if ev.from == "Undetermined" {
+				numberBlockedBefore++
+			}
+			op := &runtimeOp{kind: kind, routine: routine, tPre: ev.time, blocked: true, rw: rw,
+				pos: frame.pos}
+			ops = append(ops, op)
+			pending[ev.goID] = op
+
+		case ev.from == "Waiting" && ev.to == "Runnable":
+			op, ok := pending[ev.goID]
+			if !ok {
+				continue
+			}
+			delete(pending, ev.goID)
+			op.tPost = ev.time
+
+			unblockerRoutine, ok := routines[ev.g]
+			if !ok {
+				numberUnblockedBy[rtUnknown]++
+				continue
+			}
+
+			stackKey := ""
+			for _, frame := range ev.stack {
+				stackKey += frame.function + "@" + frame.pos + ";"
+			}
+
+			unblocker, ok := lastUnblock[ev.g]
+			if !ok || lastUnblockStack[ev.g] != stackKey {
+				kind, rw, frame := getRuntimeOp(ev.stack)
+				// a close does not appear in the stack of the unblocking routine
+				if kind == rtUnknown && (op.kind == rtRecv || op.kind == rtSelect) {
+					kind = rtClose
+				}
+				unblocker = &runtimeOp{kind: kind, routine: unblockerRoutine, tPre: ev.time,
+					tPost: ev.time, rw: rw, pos: frame.pos}
+				ops = append(ops, unblocker)
+				lastUnblock[ev.g] = unblocker
+				lastUnblockStack[ev.g] = stackKey
+			}
+
+			if !canUnblock(op.kind, unblocker.kind) {
+				numberUnblockedBy[rtUnknown]++
+				continue
+			}
+			op.partner = unblocker
+			if unblocker.partner == nil {
+				unblocker.partner = op
+			}
+		}
+	}
+
+	notes := []string{
+		"Imported from a runtime/trace execution trace",
+		"Only operations that blocked a routine, the operations that unblocked them and the creation of routines are contained",
+		"Operations that never finished may also have been unblocked after the end of the recording",
+		"Locks that did not block are not contained, the unlock of a mutex can therefore appear without a lock",
+		"Object ids are not contained in the execution trace. An operation and the operation that unblocked it and channel and wait group operations at the same position are assumed to be on the same object",
+		"Calls of WaitGroup.Add are assumed to increase the counter by one",
+		"The leak analysis of channels and selects and the analysis of select cases without partner are not run on imported traces, because operations that did not block are missing",
+		"The size of the channel buffers is unknown and set to 0",
+		"The not chosen cases of select statements are unknown",
+		"Closing a channel is not contained in the stack of the routine and assumed if a channel or select operation is unblocked by an unknown operation",
+	}
+	if numberBlockedBefore > 0 {
+		notes = append(notes, strconv.Itoa(numberBlockedBefore)+
+			" operations were already blocked at the start of the recording, their tPre is the start of the recording")
+	}
+	if numberUnblockedBy[rtUnknown] > 0 {
+		notes = append(notes, strconv.Itoa(numberUnblockedBy[rtUnknown])+
+			" operations were unblocked by the runtime or by an unknown operation, they have no partner")
+	}
+
+	return ops, numberRoutines, notes
+}
+
+/*
+ * Create the trace from the reconstructed operations. The times are
+ * replaced by a counter. An operation that unblocked another operation
+ * finishes directly before it.
+ * Args:
+ *   ops ([]*runtimeOp): the operations
+ *   numberRoutines (int): the number of routines
+ * Returns:
+ *   *trace.Trace: the trace
+ *   error: error if an element could not be created
+ */
+func createTraceFromRuntimeOps(ops []*runtimeOp, numberRoutines int) (*trace.Trace, error) {
+	times := make([]int, 0)
+	for _, op := range ops {
+		times = append(times, op.tPre)
+		if op.tPost != 0 {
+			times = append(times, op.tPost)
+		}
+	}
+	sort.Ints(times)
+	counter := make(map[int]int)
+	for _, time := range times {
+		if _, ok := counter[time]; !ok {
+			counter[time] = 2 * (len(counter) + 1)
+		}
+	}
+
+	assignRuntimeObjectIDs(ops)
+	assignRuntimeCommunicationIDs(ops)
+
+	t := trace.NewTrace()
+	for routine := 1; routine <= numberRoutines; routine++ {
+		t.AddEmptyRoutine(routine)
+	}
+
+	for _, op := range ops {
+		tPre := strconv.Itoa(counter[op.tPre])
+		tPost := "0"
+		if op.tPost != 0 {
+			if op.blocked {
+				tPost = strconv.Itoa(counter[op.tPost] + 1)
+			} else {
+				tPost = strconv.Itoa(counter[op.tPost])
+			}
+		}
+
+		if err := addRuntimeOp(t, op, tPre, tPost); err != nil {
+			return nil, err
+		}
+	}
+
+	t.Sort()
+	t.SetNumberOfRoutines(numberRoutines)
+	t.SetImported(true)
+
+	return t, nil
+}
+
+/*
+ * Assign the object ids of the reconstructed operations. The execution
+ * trace does not contain the objects. Operations are assumed to be on the
+ * same object, if one unblocked the other or if they have the same object
+ * key. All operations, that are connected by this, get the same id.
+ * Args:
+ *   ops ([]*runtimeOp): the operations
+ */
+func assignRuntimeObjectIDs(ops []*runtimeOp) {
+	parent := make(map[*runtimeOp]*runtimeOp)
+	var find func(op *runtimeOp) *runtimeOp
+	find = func(op *runtimeOp) *runtimeOp {
+		p, ok := parent[op]
+		if !ok || p == op {
+			return op
+		}
+		root := find(p)
+		parent[op] = root
+		return root
+	}
+	union := func(a *runtimeOp, b *runtimeOp) {
+		rootA, rootB := find(a), find(b)
+		if rootA != rootB {
+			parent[rootB] = rootA
+		}
+	}
+
+	byKey := make(map[string]*runtimeOp)
+	for _, op := range ops {
+		if op.kind == rtFork {
+			continue
+		}
+		if op.partner != nil {
+			union(op, op.partner)
+		}
+		key := runtimeObjectKey(op)
+		if key == "" {
+			continue
+		}
+		if other, ok := byKey[key]; ok {
+			union(other, op)
+		} else {
+			byKey[key] = op
+		}
+	}
+
+	ids := make(map[*runtimeOp]int)
+	for _, op := range ops {
+		if op.kind == rtFork {
+			continue
+		}
+		root := find(op)
+		if _, ok := ids[root]; !ok {
+			ids[root] = len(ids) + 1
+		}
+		op.id = ids[root]
+	}
+}
+
+/*
+ * Assign the communication ids (oID) of the channel operations. A send or
+ * receive and the operation it communicated with share an oID, that is not
+ * used by any other operation. Operations without known partner get their
+ * own oID, so that they are never matched with another operation.
+ * Args:
+ *   ops ([]*runtimeOp): the operations
+ */
+func assignRuntimeCommunicationIDs(ops []*runtimeOp) {
+	isComm := func(op *runtimeOp) bool {
+		return op.kind == rtSend || op.kind == rtRecv || op.kind == rtSelect
+	}
+
+	oID := 0
+	for _, op := range ops {
+		if !isComm(op) || op.oID != 0 {
+			continue
+		}
+		oID++
+		op.oID = oID
+		if p := op.partner; p != nil && isComm(p) && p.oID == 0 &&
+			(p.partner == op || p.partner == nil) {
+			p.oID = oID
+		}
+	}
+}
+
+/*
+ * Get the key of the object of an operation. Channel and wait group
+ * operations at the same position, e.g. in a loop, are on the same object.
+ * Mutexes and conditional variables are only connected by their partners,
+ * because different objects are often used in the same function.
+ * Args:
+ *   op (*runtimeOp): the operation
+ * Returns:
+ *   string: the key, empty if the object cannot be determined
+ */
+func runtimeObjectKey(op *runtimeOp) string {
+	switch op.kind {
+	case rtSend, rtRecv, rtClose, rtSelect:
+		if op.pos != "" {
+			return "C:" + op.pos
+		}
+	case rtWait, rtDone, rtAdd:
+		if op.pos != "" {
+			return "W:" + op.pos
+		}
+	}
+	return ""
+}
+
+/*
+ * Add a reconstructed operation to the trace
+ * Args:
+ *   t (*trace.Trace): the trace
+ *   op (*runtimeOp): the operation
+ *   tPre (string): the tPre of the operation
+ *   tPost (string): the tPost of the operation
+ * Returns:
+ *   error: error if the element could not be created
+ */
+func addRuntimeOp(t *trace.Trace, op *runtimeOp, tPre string, tPost string) error {
+	id := strconv.Itoa(op.id)
+	oID := strconv.Itoa(op.oID)
+
+	partnerKind := rtUnknown
+	if op.partner != nil {
+		partnerKind = op.partner.kind
+	}
+
+	rw := "-"
+	if op.rw {
+		rw = "R"
+	}
+
+	switch op.kind {
+	case rtFork:
+		return t.AddTraceElementFork(op.routine, tPost, id, op.pos)
+	case rtSend:
+		return t.AddTraceElementChannel(op.routine, tPre, tPost, id, "S", "f", oID, "0", op.pos)
+	case rtRecv:
+		if partnerKind == rtClose {
+			return t.AddTraceElementChannel(op.routine, tPre, tPost, id, "R", "t", "0", "0", op.pos)
+		}
+		return t.AddTraceElementChannel(op.routine, tPre, tPost, id, "R", "f", oID, "0", op.pos)
+	case rtClose:
+		return t.AddTraceElementChannel(op.routine, tPre, tPost, id, "C", "f", "0", "0", op.pos)
+	case rtSelect:
+		if tPost == "0" {
+			return t.AddTraceElementSelect(op.routine, tPre, tPost, id, "", "-1", op.pos)
+		}
+		// the executed case is the opposite operation of the partner. If two
+		// selects communicate, the blocked select receives.
+		opC, cl := "R", "f"
+		if partnerKind == rtRecv || (partnerKind == rtSelect && !op.blocked) {
+			opC = "S"
+		}
+		if partnerKind == rtClose {
+			cl, oID = "t", "0"
+		}
+		c := "C." + tPre + "." + tPost + "." + id + "." + opC + "." + cl + "." + oID + ".0"
+		return t.AddTraceElementSelect(op.routine, tPre, tPost, id, c, "0", op.pos)
+	case rtLock:
+		return t.AddTraceElementMutex(op.routine, tPre, tPost, id, rw, "L", "t", op.pos)
+	case rtRLock:
+		return t.AddTraceElementMutex(op.routine, tPre, tPost, id, rw, "R", "t", op.pos)
+	case rtUnlock:
+		return t.AddTraceElementMutex(op.routine, tPre, tPost, id, rw, "U", "t", op.pos)
+	case rtRUnlock:
+		return t.AddTraceElementMutex(op.routine, tPre, tPost, id, rw, "N", "t", op.pos)
+	case rtWait:
+		return t.AddTraceElementWait(op.routine, tPre, tPost, id, "W", "0", "0", op.pos)
+	case rtDone:
+		return t.AddTraceElementWait(op.routine, tPre, tPost, id, "A", "-1", "0", op.pos)
+	case rtAdd:
+		return t.AddTraceElementWait(op.routine, tPre, tPost, id, "A", "1", "0", op.pos)
+	case rtCondWait:
+		return t.AddTraceElementCond(op.routine, tPre, tPost, id, "W", op.pos)
+	case rtSignal:
+		return t.AddTraceElementCond(op.routine, tPre, tPost, id, "S", op.pos)
+	case rtBroadcast:
+		return t.AddTraceElementCond(op.routine, tPre, tPost, id, "B", op.pos)
+	}
+
+	// operations of unknown type that unblocked a routine are not added
+	return nil
+}
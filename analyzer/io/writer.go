@@ -42,19 +42,20 @@ func CopyFolder(source string, dest string) {
 /*
  * Write the trace to a file
  * Args:
+ *   t (*trace.Trace): The trace to write
  *   path (string): The path to the file to write to
  *   numberRoutines (int): The number of routines in the trace
  */
-func WriteTrace(path string, numberRoutines int) error {
+func WriteTrace(t *trace.Trace, path string, numberRoutines int) error {
 	// delete folder if exists
 	if _, err := os.Stat(path); err == nil {
-		println(path + " already exists. Delete folder " + path)
+		t.Println(path + " already exists. Delete folder " + path)
 		if err := os.RemoveAll(path); err != nil {
 			return err
 		}
 	}
 
-	println("Create new trace at " + path + "...")
+	t.Println("Create new trace at " + path + "...")
 
 	// create new folder
 	if err := os.Mkdir(path, 0755); err != nil {
@@ -76,7 +77,7 @@ func WriteTrace(path string, numberRoutines int) error {
 
 			// write trace
 			// println("Write trace to " + fileName + "...")
-			trace := trace.GetTraceFromId(i)
+			trace := t.GetTraceFromId(i)
 
 			// sort trace by tPre
 			sort.Slice(trace, func(i, j int) bool {
@@ -101,7 +102,7 @@ func WriteTrace(path string, numberRoutines int) error {
 		}(i)
 	}
 	wg.Wait()
-	println("Trace written")
+	t.Println("Trace written")
 	return nil
 }
 
@@ -1,10 +1,12 @@
 package logging
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 var levelDebug int = 0
@@ -43,11 +45,23 @@ const (
 	ACloseOnClosed         ResultType = "A3"
 	AConcurrentRecv        ResultType = "A4"
 	ASelCaseWithoutPartner ResultType = "A5"
+	APartialDeadlock       ResultType = "A6"
+	ARecursiveDo           ResultType = "A7"
+	ADoPanic               ResultType = "A8"
 
 	// possible
-	PSendOnClosed ResultType = "P1"
-	PRecvOnClosed ResultType = "P2"
-	PNegWG        ResultType = "P3"
+	PSendOnClosed       ResultType = "P1"
+	PRecvOnClosed       ResultType = "P2"
+	PNegWG              ResultType = "P3"
+	PCloseOnClosed      ResultType = "P6"
+	PAddConcurrentWait  ResultType = "P7"
+	PWaitGroupReuse     ResultType = "P8"
+	PLostWakeup         ResultType = "P9"
+	PAtomicityViolation ResultType = "P10"
+	PDataRace           ResultType = "P11"
+
+	// warnings
+	WBlockInCriticalSection ResultType = "W1"
 
 	// leaks
 	LUnbufferedWith    = "L1"
@@ -68,10 +82,21 @@ var resultTypeMap = map[ResultType]string{
 	ACloseOnClosed:         "Found close on closed channel:",
 	AConcurrentRecv:        "Found concurrent Recv on same channel:",
 	ASelCaseWithoutPartner: "Found select case without partner or nil case",
-
-	PSendOnClosed: "Possible send on closed channel:",
-	PRecvOnClosed: "Possible receive on closed channel:",
-	PNegWG:        "Possible negative waitgroup counter:",
+	APartialDeadlock:       "Found partial deadlock:",
+	ARecursiveDo:           "Found recursive Do on once:",
+	ADoPanic:               "Found panic in Do on once:",
+
+	PSendOnClosed:       "Possible send on closed channel:",
+	PRecvOnClosed:       "Possible receive on closed channel:",
+	PNegWG:              "Possible negative waitgroup counter:",
+	PCloseOnClosed:      "Possible close on closed channel:",
+	PAddConcurrentWait:  "Possible add concurrent with wait:",
+	PWaitGroupReuse:     "Possible reuse of wait group before wait returned:",
+	PLostWakeup:         "Possible lost wakeup on conditional variable:",
+	PAtomicityViolation: "Possible atomicity violation on atomic variable:",
+	PDataRace:           "Possible data race:",
+
+	WBlockInCriticalSection: "Blocking operation in critical section:",
 
 	LUnbufferedWith:    "Leak on unbuffered channel with possible partner:",
 	LUnbufferedWithout: "Leak on unbuffered channel without possible partner:",
@@ -85,13 +110,133 @@ var resultTypeMap = map[ResultType]string{
 	LCond:              "Leak on conditional variable:",
 }
 
-var outputReadableFile string
-var outputMachineFile string
-var foundBug = false
-var resultsWarningReadable []string
-var resultsCriticalReadable []string
-var resultsWarningMachine []string
-var resultCriticalMachine []string
+/*
+ * A result type registered by a custom detector
+ * Fields:
+ *   description (string): The description shown in the readable results
+ *   actual (bool): true if the results are actual bugs, false if they are
+ *     possible bugs or warnings
+ */
+type customResultType struct {
+	description string
+	actual      bool
+}
+
+// result types registered by custom detectors
+var customResultTypesLock sync.RWMutex
+var customResultTypes = map[ResultType]customResultType{}
+
+/*
+ * Register a result type for a custom detector. Results of a registered type
+ * are never rewritten. The detector declares, if its results are classified
+ * as actual bugs or as possible bugs and warnings.
+ * Args:
+ *   resType (ResultType): The type, must not be used by a built-in result
+ *   description (string): The description shown in the readable results
+ *   actual (bool): true if the results are bugs, that happened in the
+ *     recorded execution, false if they are possible bugs or warnings
+ * Returns:
+ *   error: An error if the type is already used
+ */
+func RegisterResultType(resType ResultType, description string, actual bool) error {
+	if resType == Empty || strings.ContainsAny(string(resType), ",;\n") {
+		return errors.New("Invalid result type: " + string(resType))
+	}
+
+	if _, ok := resultTypeMap[resType]; ok {
+		return errors.New("The result type " + string(resType) + " is used by a built-in result")
+	}
+
+	customResultTypesLock.Lock()
+	defer customResultTypesLock.Unlock()
+
+	if _, ok := customResultTypes[resType]; ok {
+		return errors.New("The result type " + string(resType) + " is already registered")
+	}
+
+	customResultTypes[resType] = customResultType{description, actual}
+	return nil
+}
+
+/*
+ * Get the description of a registered custom result type
+ * Args:
+ *   resType (ResultType): The type
+ * Returns:
+ *   string: The description
+ *   bool: true if the type is registered
+ */
+func GetCustomResultType(resType ResultType) (string, bool) {
+	customResultTypesLock.RLock()
+	defer customResultTypesLock.RUnlock()
+
+	custom, ok := customResultTypes[resType]
+	return custom.description, ok
+}
+
+/*
+ * Check if the results of a registered custom result type are actual bugs
+ * Args:
+ *   resType (ResultType): The type
+ * Returns:
+ *   bool: true if the type is registered as actual bug, false otherwise
+ */
+func IsActualCustomResultType(resType ResultType) bool {
+	customResultTypesLock.RLock()
+	defer customResultTypesLock.RUnlock()
+
+	return customResultTypes[resType].actual
+}
+
+/*
+ * Get the description of a built-in or custom result type
+ * Args:
+ *   resType (ResultType): The type
+ * Returns:
+ *   string: The description
+ */
+func getResultDescription(resType ResultType) string {
+	if description, ok := resultTypeMap[resType]; ok {
+		return description
+	}
+
+	if description, ok := GetCustomResultType(resType); ok {
+		return description
+	}
+
+	return string(resType)
+}
+
+/*
+ * Results contains the results found in the analysis of one trace
+ * Fields:
+ *   foundBug (bool): true if at least one result was found
+ *   resultsWarningReadable ([]string): readable warnings
+ *   resultsCriticalReadable ([]string): readable critical results
+ *   resultsWarningMachine ([]string): machine readable warnings
+ *   resultCriticalMachine ([]string): machine readable critical results
+ */
+type Results struct {
+	foundBug                bool
+	resultsWarningReadable  []string
+	resultsCriticalReadable []string
+	resultsWarningMachine   []string
+	resultCriticalMachine   []string
+}
+
+/*
+ * Create a new, empty result collection
+ * Returns:
+ *   *Results: The new result collection
+ */
+func NewResults() *Results {
+	return &Results{
+		resultsWarningReadable:  make([]string, 0),
+		resultsCriticalReadable: make([]string, 0),
+		resultsWarningMachine:   make([]string, 0),
+		resultCriticalMachine:   make([]string, 0),
+	}
+}
 
 /*
 * Print a debug log message if the log level is sufficiant
@@ -158,13 +303,32 @@ func (s SelectCaseResult) isInvalid() bool {
 	return s.ObjType == ""
 }
 
+type BlockedTimeResult struct {
+	Time int // time the operation was blocked, -1 if it did not return
+}
+
+func (b BlockedTimeResult) stringMachine() string {
+	return fmt.Sprintf("B:%d", b.Time)
+}
+
+func (b BlockedTimeResult) stringReadable() string {
+	if b.Time == -1 {
+		return "blocked until the end"
+	}
+	return fmt.Sprintf("blocked for %d", b.Time)
+}
+
+func (b BlockedTimeResult) isInvalid() bool {
+	return false
+}
+
 /*
  * Print a result message
  * Args:
  * 	level: level of the message
  *	message: message to print
  */
-func Result(level resultLevel, resType ResultType, argType1 string, arg1 []ResultElem, argType2 string, arg2 []ResultElem) {
+func (r *Results) Result(level resultLevel, resType ResultType, argType1 string, arg1 []ResultElem, argType2 string, arg2 []ResultElem) {
 	if arg1[0].isInvalid() {
 		return
 	}
@@ -174,9 +338,9 @@ func Result(level resultLevel, resType ResultType, argType1 string, arg1 []Resul
 		return
 	}
 
-	foundBug = true
+	r.foundBug = true
 
-	resultReadable := resultTypeMap[resType] + "\n\t" + argType1 + ": "
+	resultReadable := getResultDescription(resType) + "\n\t" + argType1 + ": "
 	resultMachine := string(resType) + ","
 
 	for i, arg := range arg1 {
@@ -207,15 +371,15 @@ func Result(level resultLevel, resType ResultType, argType1 string, arg1 []Resul
 	resultMachine += "\n"
 
 	if level == WARNING {
-		if !stringInSlice(resultMachine, resultsWarningMachine) {
-			resultsWarningReadable = append(resultsWarningReadable, resultReadable)
-			resultsWarningMachine = append(resultsWarningMachine, resultMachine)
+		if !stringInSlice(resultMachine, r.resultsWarningMachine) {
+			r.resultsWarningReadable = append(r.resultsWarningReadable, resultReadable)
+			r.resultsWarningMachine = append(r.resultsWarningMachine, resultMachine)
 		}
 	} else if level == CRITICAL {
 		println(resultReadable)
-		if !stringInSlice(resultMachine, resultCriticalMachine) {
-			resultsCriticalReadable = append(resultsCriticalReadable, resultReadable)
-			resultCriticalMachine = append(resultCriticalMachine, resultMachine)
+		if !stringInSlice(resultMachine, r.resultCriticalMachine) {
+			r.resultsCriticalReadable = append(r.resultsCriticalReadable, resultReadable)
+			r.resultCriticalMachine = append(r.resultCriticalMachine, resultMachine)
 		}
 	}
 }
@@ -224,17 +388,109 @@ func Result(level resultLevel, resType ResultType, argType1 string, arg1 []Resul
 * Initialize the debug
 * Args:
 *   level: level of the debug
-*   outReadable: path to the output file, no output file if empty
-*   outMachine: path to the output file for the reordered trace, no output file if empty
  */
-func InitLogging(level int, outReadable string, outMachine string) {
+func InitLogging(level int) {
 	if level < 0 {
 		level = 0
 	}
 	levelDebug = level
+}
 
-	outputReadableFile = outReadable
-	outputMachineFile = outMachine
+/*
+ * Remove all critical results of the given types, whose first arg starts with
+ * the given trace element. The object id and the object type of the element
+ * are not compared.
+ * Args:
+ *   resTypes ([]ResultType): The types of the results to remove
+ *   elem (TraceElementResult): The trace element
+ */
+func (r *Results) RemoveResults(resTypes []ResultType, elem TraceElementResult) {
+	readable := make([]string, 0, len(r.resultsCriticalReadable))
+	machine := make([]string, 0, len(r.resultCriticalMachine))
+
+	for i, result := range r.resultCriticalMachine {
+		if !resultStartsWith(result, resTypes, elem) {
+			readable = append(readable, r.resultsCriticalReadable[i])
+			machine = append(machine, result)
+		}
+	}
+
+	r.resultsCriticalReadable = readable
+	r.resultCriticalMachine = machine
+}
+
+/*
+ * Check if a machine readable result has one of the given types and its
+ * first arg starts with the given trace element
+ * Args:
+ *   result (string): The machine readable result
+ *   resTypes ([]ResultType): The types
+ *   elem (TraceElementResult): The trace element
+ * Returns:
+ *   bool: true if the result has one of the types and starts with the element
+ */
+func resultStartsWith(result string, resTypes []ResultType, elem TraceElementResult) bool {
+	fields := strings.Split(strings.TrimSuffix(result, "\n"), ",")
+	if len(fields) < 2 {
+		return false
+	}
+
+	typeFound := false
+	for _, resType := range resTypes {
+		if string(resType) == fields[0] {
+			typeFound = true
+			break
+		}
+	}
+	if !typeFound {
+		return false
+	}
+
+	arg := strings.Split(strings.Split(fields[1], ";")[0], ":")
+	if len(arg) != 7 || arg[0] != "T" {
+		return false
+	}
+
+	return arg[1] == strconv.Itoa(elem.RoutineID) && arg[3] == strconv.Itoa(elem.TPre) &&
+		arg[5] == elem.File && arg[6] == strconv.Itoa(elem.Line)
+}
+
+/*
+ * Get the machine readable results in the order in which they are numbered in
+ * the summary, i.e. first the critical results, then the warnings
+ * Args:
+ *   noWarning: if true, the warnings are not included
+ * Returns:
+ *   []string: the machine readable results
+ */
+func (r *Results) GetResultsMachine(noWarning bool) []string {
+	res := make([]string, 0, len(r.resultCriticalMachine)+len(r.resultsWarningMachine))
+	for _, result := range r.resultCriticalMachine {
+		res = append(res, strings.TrimSuffix(result, "\n"))
+	}
+	if !noWarning {
+		for _, result := range r.resultsWarningMachine {
+			res = append(res, strings.TrimSuffix(result, "\n"))
+		}
+	}
+	return res
+}
+
+/*
+ * Get the readable results in the order in which they are numbered in the
+ * summary, i.e. first the critical results, then the warnings
+ * Args:
+ *   noWarning: if true, the warnings are not included
+ * Returns:
+ *   []string: the readable results
+ */
+func (r *Results) GetResultsReadable(noWarning bool) []string {
+	res := make([]string, 0, len(r.resultsCriticalReadable)+len(r.resultsWarningReadable))
+	res = append(res, r.resultsCriticalReadable...)
+	if !noWarning {
+		res = append(res, r.resultsWarningReadable...)
+	}
+	return res
 }
 
 /*
@@ -242,10 +498,12 @@ func InitLogging(level int, outReadable string, outMachine string) {
 * Args:
 *   noWarning: if true, only critical errors will be shown
 *   noPrint: if true, no output will be printed to the terminal
+*   outputReadableFile: path to the output file for the readable results
+*   outputMachineFile: path to the output file for the machine readable results
 * Returns:
 *   int: number of bugs found
  */
-func PrintSummary(noWarning bool, noPrint bool) int {
+func (r *Results) PrintSummary(noWarning bool, noPrint bool, outputReadableFile string, outputMachineFile string) int {
 	counter := 1
 	resMachine := ""
 	resReadable := "```\n==================== Summary ====================\n\n"
@@ -256,7 +514,7 @@ func PrintSummary(noWarning bool, noPrint bool) int {
 
 	found := false
 
-	if len(resultsCriticalReadable) > 0 {
+	if len(r.resultsCriticalReadable) > 0 {
 		found = true
 		resReadable += "-------------------- Critical -------------------\n\n"
 
@@ -264,7 +522,7 @@ func PrintSummary(noWarning bool, noPrint bool) int {
 			fmt.Print("-------------------- Critical -------------------\n\n")
 		}
 
-		for _, result := range resultsCriticalReadable {
+		for _, result := range r.resultsCriticalReadable {
 			resReadable += strconv.Itoa(counter) + " " + result + "\n"
 
 			if !noPrint {
@@ -274,18 +532,18 @@ func PrintSummary(noWarning bool, noPrint bool) int {
 			counter++
 		}
 
-		for _, result := range resultCriticalMachine {
+		for _, result := range r.resultCriticalMachine {
 			resMachine += result
 		}
 	}
-	if len(resultsWarningReadable) > 0 && !noWarning {
+	if len(r.resultsWarningReadable) > 0 && !noWarning {
 		found = true
 		resReadable += "\n-------------------- Warning --------------------\n\n"
 		if !noPrint {
 			fmt.Print("\n-------------------- Warning --------------------\n\n")
 		}
 
-		for _, result := range resultsWarningReadable {
+		for _, result := range r.resultsWarningReadable {
 			resReadable += strconv.Itoa(counter) + " " + result + "\n"
 
 			if !noPrint {
@@ -295,7 +553,7 @@ func PrintSummary(noWarning bool, noPrint bool) int {
 			counter++
 		}
 
-		for _, result := range resultsWarningMachine {
+		for _, result := range r.resultsWarningMachine {
 			resMachine += result
 		}
 	}
@@ -343,7 +601,7 @@ func PrintSummary(noWarning bool, noPrint bool) int {
 		panic(err)
 	}
 
-	return len(resultCriticalMachine) + len(resultsWarningMachine)
+	return len(r.resultCriticalMachine) + len(r.resultsWarningMachine)
 }
 
 func stringInSlice(a string, list []string) bool {
@@ -1,25 +1,77 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"analyzer/analyzer"
 	"analyzer/complete"
 	"analyzer/explanation"
+	"analyzer/export"
 	"analyzer/io"
 	"analyzer/logging"
+	"analyzer/minimize"
+	"analyzer/query"
+	"analyzer/replay"
 	"analyzer/rewriter"
 	"analyzer/stats"
 	"analyzer/trace"
+	"analyzer/validate"
 )
 
 func main() {
+	// subcommand to query elements of a trace
+	if len(os.Args) > 1 && os.Args[1] == "query" {
+		err := runQuery(os.Args[2:])
+		if err != nil {
+			fmt.Println("Error running query: ", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	// subcommand to export a trace into other formats
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		err := runExport(os.Args[2:])
+		if err != nil {
+			fmt.Println("Error exporting trace: ", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	// subcommand to import an execution trace recorded with runtime/trace
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		err := runImport(os.Args[2:])
+		if err != nil {
+			fmt.Println("Error importing trace: ", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	// subcommand to check trace folders for corrupt or truncated traces
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		ok, err := runValidate(os.Args[2:])
+		if err != nil {
+			fmt.Println("Error validating trace: ", err.Error())
+			os.Exit(1)
+		}
+		if !ok {
+			os.Exit(1)
+		}
+		return
+	}
+
 	help := flag.Bool("h", false, "Print this help")
 	pathTrace := flag.String("t", "", "Path to the trace folder to analyze or rewrite")
 	level := flag.Int("d", 1, "Debug Level, 0 = silent, 1 = errors, 2 = info, 3 = debug (default 1)")
@@ -37,15 +89,28 @@ func main() {
 	programPath := flag.String("P", "", "Path to the program folder")
 	createStats := flag.Bool("S", false, "Create statistics for the trace")
 	preventCopyRewrittenTrace := flag.Bool("n", false, "Do not copy the rewritten trace in the explanation")
+	compareReplay := flag.Bool("C", false, "Compare the trace recorded during a replay with the replayed trace")
+	minimizeTrace := flag.Bool("m", false, "Minimize the rewritten trace of a bug")
+	replayCommand := flag.String("E", "", "Shell command to run the replay of the bug for the minimization")
+	rewriteWorkers := flag.Int("j", 1, "Number of traces that are rewritten in parallel (default 1)")
 
 	scenarios := flag.String("s", "", "Select which analysis scenario to run, e.g. -s srd for the option s, r and d. Options:\n"+
 		"\ts: Send on closed channel\n"+
 		"\tr: Receive on closed channel\n"+
 		"\tw: Done before add on waitGroup\n"+
+		"\ta: Add concurrent with wait on waitGroup\n"+
+		"\tg: Reuse of waitGroup before wait returned\n"+
+		"\tk: Lost wakeup on conditional variable\n"+
+		"\tv: Atomicity violation on atomic variable\n"+
+		"\td: Data race on memory accesses recorded with -race\n"+
+		"\ti: Blocking operation in critical section\n"+
 		"\tn: Close of closed channel\n"+
 		"\tb: Concurrent receive on channel\n"+
 		"\tl: Leaking routine\n"+
-		"\tu: Select case without partner\n",
+		"\tp: Partial deadlock of routines blocking each other\n"+
+		"\to: Misuse of once (recursive Do, panic in Do)\n"+
+		"\tu: Select case without partner\n"+
+		"\tCustom detectors registered with analyzer.RegisterDetector are selected with their letter\n",
 	)
 	// "\tc: Cyclic deadlock\n",
 	// "\tm: Mixed deadlock\n"
@@ -66,6 +131,16 @@ func main() {
 		return
 	}
 
+	if *compareReplay && (*explanationFlag || *checkAllElem) {
+		fmt.Println("Please provide only one of the flags -e, -o or -C")
+		return
+	}
+
+	if *minimizeTrace && (*explanationFlag || *checkAllElem || *compareReplay) {
+		fmt.Println("Please provide only one of the flags -e, -o, -C or -m")
+		return
+	}
+
 	folderTrace, err := filepath.Abs(*pathTrace)
 	if err != nil {
 		panic(err)
@@ -99,7 +174,7 @@ func main() {
 			fmt.Println("Please provide a path to the trace file and an index (1 based) for the explanation. Set with -t [file] -i [index]")
 			return
 		}
-		err := explanation.CreateOverview(folderTrace, *explanationIndex, *preventCopyRewrittenTrace)
+		err := explanation.CreateOverview(folderTrace, *pathTrace, *explanationIndex, *preventCopyRewrittenTrace, *ignoreAtomics)
 		if err != nil {
 			fmt.Println("Error creating explanation: ", err.Error())
 		}
@@ -126,6 +201,33 @@ func main() {
 		return
 	}
 
+	// instead of the normal program, check if a replay followed the replayed trace
+	if *compareReplay {
+		if *pathTrace == "" {
+			fmt.Println("Please provide a path to the replayed trace folder. Set with -t [folder]")
+			return
+		}
+
+		_, err := replay.CheckReplay(*pathTrace)
+		if err != nil {
+			fmt.Println("Error comparing replay: ", err.Error())
+		}
+		return
+	}
+
+	// instead of the normal program, minimize the rewritten trace of a bug
+	if *minimizeTrace {
+		if *pathTrace == "" || *explanationIndex == 0 || *replayCommand == "" {
+			fmt.Println("Please provide a path to the trace file, an index (1 based) and the replay command. Set with -t [file] -i [index] -E [command]")
+			return
+		}
+		err := minimize.Minimize(folderTrace, *explanationIndex, *replayCommand)
+		if err != nil {
+			fmt.Println("Error minimizing trace: ", err.Error())
+		}
+		return
+	}
+
 	// ============== Start the normal program ==============
 
 	printHeader()
@@ -139,7 +241,7 @@ func main() {
 		*noRewrite = true
 	}
 
-	analysisCases, err := parseAnalysisCases(*scenarios)
+	analysisCases, err := analyzer.ParseAnalysisCases(*scenarios)
 	if err != nil {
 		panic(err)
 	}
@@ -147,12 +249,13 @@ func main() {
 	// run the analysis and, if requested, create a reordered trace file
 	// based on the analysis results
 
-	logging.InitLogging(*level, outReadable, outMachine)
-	numberOfRoutines, err := io.CreateTraceFromFiles(*pathTrace, *ignoreAtomics)
+	logging.InitLogging(*level)
+	a := analyzer.New()
+	err = a.LoadTrace(*pathTrace, *ignoreAtomics)
 	if err != nil {
 		panic(err)
 	}
-	trace.SetNumberOfRoutines(numberOfRoutines)
+	numberOfRoutines := a.NumberOfRoutines()
 
 	if analysisCases["all"] {
 		fmt.Println("Start Analysis for all scenarios")
@@ -165,11 +268,18 @@ func main() {
 		}
 	}
 
-	trace.RunAnalysis(*fifo, *ignoreCriticalSection, analysisCases)
+	err = a.Run(analyzer.Options{
+		Fifo:                   *fifo,
+		IgnoreCriticalSections: *ignoreCriticalSection,
+		AnalysisCases:          analysisCases,
+	})
+	if err != nil {
+		panic(err)
+	}
 
 	fmt.Print("Analysis finished\n\n")
 
-	numberOfResults := logging.PrintSummary(*noWarning, *noPrint)
+	numberOfResults := a.Results().PrintSummary(*noWarning, *noPrint, outReadable, outMachine)
 
 	analysisFinishedTime := time.Now()
 	err = writeTime(folderTrace, "Analysis", analysisFinishedTime.Sub(startTime).Seconds())
@@ -183,24 +293,22 @@ func main() {
 		notNeededRewrites := 0
 		println("\n\nStart rewriting trace files...")
 		var rewriteTime time.Duration
-		originalTrace := trace.CopyCurrentTrace()
-		for resultIndex := 0; resultIndex < numberOfResults; resultIndex++ {
-			rewriteStartTime := time.Now()
 
-			needed, err := rewriteTrace(outMachine,
-				newTrace+"_"+strconv.Itoa(resultIndex+1)+"/", resultIndex, numberOfRoutines)
+		results := rewriteTraces(a.Trace(), outMachine, newTrace, numberOfResults,
+			numberOfRoutines, *rewriteWorkers)
 
-			if !needed {
+		for resultIndex, res := range results {
+			print(res.output)
+			println("Result " + strconv.Itoa(resultIndex+1) + ":")
+			if !res.needed {
 				println("Trace can not be rewritten.")
 				notNeededRewrites++
-			} else if err != nil {
-				println("Failed to rewrite trace: ", err.Error())
+			} else if res.err != nil {
+				println("Failed to rewrite trace: ", res.err.Error())
 				failedRewrites++
-				trace.SetTrace(originalTrace)
 			} else { // needed && err == nil
 				numberRewrittenTrace++
-				rewriteTime += time.Now().Sub(rewriteStartTime)
-				trace.SetTrace(originalTrace)
+				rewriteTime += res.duration
 			}
 
 			print("\n\n")
@@ -225,6 +333,210 @@ func main() {
 	print("\n\n\n")
 }
 
+/*
+ * Run the query subcommand, that prints the elements of a trace matching
+ * the given filters
+ * Args:
+ *   args ([]string): the arguments after the subcommand
+ * Returns:
+ *   error: error if the arguments are invalid or the trace could not be read
+ */
+func runQuery(args []string) error {
+	flags := flag.NewFlagSet("query", flag.ExitOnError)
+	pathTrace := flags.String("t", "", "Path to the trace folder")
+	objectID := flags.Int("o", -1, "Only print operations on the object with this id (default all)")
+	routine := flags.Int("r", -1, "Only print elements of the routine with this id (default all)")
+	types := flags.String("y", "", "Only print elements of the given types, e.g. -y CM for channel and mutex operations (default all)")
+	filePattern := flags.String("f", "", "Only print elements whose source file matches the glob pattern, e.g. -f 'main.go' (default all)")
+	start := flags.Int("s", 0, "Only print elements with a time (tSort) of at least this value")
+	end := flags.Int("e", math.MaxInt, "Only print elements with a time (tSort) of at most this value")
+	clocks := flags.Bool("c", false, "Print the vector clocks of the elements")
+	jsonOutput := flags.Bool("j", false, "Print the elements as JSON")
+	ignoreAtomics := flags.Bool("a", false, "Ignore atomic operations")
+	flags.Parse(args)
+
+	if *pathTrace == "" {
+		return errors.New("Please provide a path to the trace folder. Set with -t [folder]")
+	}
+
+	a := analyzer.New()
+	err := a.LoadTrace(*pathTrace, *ignoreAtomics)
+	if err != nil {
+		return err
+	}
+
+	// the vector clocks are only calculated by the analysis
+	if *clocks {
+		err = a.Run(analyzer.Options{AnalysisCases: map[string]bool{}})
+		if err != nil {
+			return err
+		}
+	}
+
+	filter := query.NewFilter()
+	filter.ObjectID = *objectID
+	filter.Routine = *routine
+	filter.FilePattern = *filePattern
+	filter.Start = *start
+	filter.End = *end
+	for _, c := range *types {
+		filter.Types = append(filter.Types, string(c))
+	}
+
+	elems := query.Query(a.Trace(), filter)
+
+	if *jsonOutput {
+		return query.WriteJSON(os.Stdout, elems, *clocks)
+	}
+	return query.WriteText(os.Stdout, elems, *clocks)
+}
+
+/*
+ * Run the validate subcommand, that checks trace folders for corrupt or
+ * truncated traces and prints all found problems
+ * Args:
+ *   args ([]string): the arguments after the subcommand
+ * Returns:
+ *   bool: true if no problem was found
+ *   error: error if the arguments are invalid or the folders could not be read
+ */
+func runValidate(args []string) (bool, error) {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	pathTrace := flags.String("t", "", "Path to the trace folder or to a folder containing trace folders")
+	flags.Parse(args)
+
+	if *pathTrace == "" && flags.NArg() > 0 {
+		*pathTrace = flags.Arg(0)
+	}
+
+	if *pathTrace == "" {
+		return false, errors.New("Please provide a path to the trace folder. Use ./analyzer validate [folder]")
+	}
+
+	problems, numberFolders, err := validate.Validate(*pathTrace)
+	if err != nil {
+		return false, err
+	}
+
+	for _, problem := range problems {
+		fmt.Println(problem.String())
+	}
+	fmt.Printf("Checked %d trace folder(s), found %d problem(s)\n", numberFolders, len(problems))
+
+	return len(problems) == 0, nil
+}
+
+/*
+ * Run the export subcommand, that converts a trace into another format
+ * Args:
+ *   args ([]string): the arguments after the subcommand
+ * Returns:
+ *   error: error if the arguments are invalid or the trace could not be exported
+ */
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	pathTrace := flags.String("t", "", "Path to the trace folder")
+	format := flags.String("f", "chrome", "Format of the export. Options: chrome, dot, graphml")
+	output := flags.String("o", "", "Path of the created file (default [format].json or hb_[index].[format] next to the trace folder)")
+	resultIndex := flags.Int("i", 0, "Index of the result (1 based) for the happens before graph (dot, graphml)")
+	depth := flags.Int("d", 5, "Maximum number of edges between the elements of the result and the other elements in the happens before graph")
+	results := flags.String("r", "", "Path to the machine readable analysis results, whose elements are highlighted (default results_machine.log next to the trace folder, if it exists)")
+	ignoreAtomics := flags.Bool("a", false, "Ignore atomic operations")
+	flags.Parse(args)
+
+	if *pathTrace == "" {
+		return errors.New("Please provide a path to the trace folder. Set with -t [folder]")
+	}
+
+	folderTrace, err := filepath.Abs(*pathTrace)
+	if err != nil {
+		return err
+	}
+	folderTrace = filepath.Dir(folderTrace)
+
+	if *results == "" {
+		if _, err := os.Stat(filepath.Join(folderTrace, "results_machine.log")); err == nil {
+			*results = filepath.Join(folderTrace, "results_machine.log")
+		}
+	}
+
+	a := analyzer.New()
+	err = a.LoadTrace(*pathTrace, *ignoreAtomics)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "chrome":
+		if *output == "" {
+			*output = filepath.Join(folderTrace, "chrome.json")
+		}
+		err = export.WriteChromeTrace(a.Trace(), *results, *output)
+	case "dot", "graphml":
+		if *resultIndex == 0 || *results == "" {
+			return errors.New("Please provide the index (1 based) of the result and the analysis results. Set with -i [index] -r [file]")
+		}
+		if *output == "" {
+			*output = filepath.Join(folderTrace, "hb_"+strconv.Itoa(*resultIndex)+"."+*format)
+		}
+		err = export.WriteHBGraph(a.Trace(), *results, *resultIndex, *depth, *format, *output)
+	default:
+		return errors.New("Unknown export format: " + *format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Trace exported to " + *output)
+	return nil
+}
+
+/*
+ * Run the import subcommand, that converts an execution trace recorded
+ * with runtime/trace into a trace folder
+ * Args:
+ *   args ([]string): the arguments after the subcommand
+ * Returns:
+ *   error: error if the arguments are invalid or the trace could not be imported
+ */
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	pathRuntimeTrace := flags.String("t", "", "Path to the execution trace, or to the output of go tool trace -d=parsed")
+	output := flags.String("o", "", "Path of the created trace folder (default advocateTrace next to the execution trace)")
+	flags.Parse(args)
+
+	if *pathRuntimeTrace == "" {
+		return errors.New("Please provide a path to the execution trace. Set with -t [file]")
+	}
+
+	if *output == "" {
+		*output = filepath.Join(filepath.Dir(*pathRuntimeTrace), "advocateTrace")
+	}
+
+	t, numberOfRoutines, notes, err := io.CreateTraceFromRuntimeTrace(*pathRuntimeTrace)
+	if err != nil {
+		return err
+	}
+
+	err = io.WriteTrace(t, *output+string(os.PathSeparator), numberOfRoutines)
+	if err != nil {
+		return err
+	}
+
+	// the information that could not be imported is written next to the trace
+	err = os.WriteFile(filepath.Join(*output, "import_info.log"), []byte(strings.Join(notes, "\n")+"\n"), 0644)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Trace imported to " + *output)
+	for _, note := range notes {
+		fmt.Println("  " + note)
+	}
+	return nil
+}
+
 func memorySupervisor() {
 	var stat syscall.Sysinfo_t
 
@@ -308,9 +620,88 @@ func writeTime(pathTrace string, name string, time float64) error {
 	return err
 }
 
+/*
+ * Result of the rewrite of one analysis result
+ * Fields:
+ *   needed (bool): true, if a rewrite was nessesary
+ *   err (error): An error if the rewrite failed
+ *   duration (time.Duration): The time the rewrite took
+ *   output (string): The messages of the rewrite, only set if the rewrites
+ *     run in parallel
+ */
+type rewriteResult struct {
+	needed   bool
+	err      error
+	duration time.Duration
+	output   string
+}
+
+/*
+ * Rewrite the trace for all analysis results. Each rewrite works on its own
+ * copy of the trace, so that the rewrites can run in parallel. The rewritten
+ * trace for the result with index i is always written into newTrace_[i+1],
+ * independent of the order in which the rewrites finish.
+ * Args:
+ *   t (*trace.Trace): The analyzed trace, is not changed
+ *   outMachine (string): The path to the analysis result file
+ *   newTrace (string): The path prefix of the new traces folders
+ *   numberOfResults (int): The number of analysis results
+ *   numberOfRoutines (int): The number of routines in the trace
+ *   workers (int): The number of rewrites that run in parallel
+ * Returns:
+ *   []rewriteResult: The result of the rewrite for each analysis result
+ */
+func rewriteTraces(t *trace.Trace, outMachine string, newTrace string, numberOfResults int,
+	numberOfRoutines int, workers int) []rewriteResult {
+
+	results := make([]rewriteResult, numberOfResults)
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for resultIndex := range indices {
+				rewriteStartTime := time.Now()
+
+				// the messages of parallel rewrites are collected and
+				// printed together with the result, so that they do not interleave
+				tCopy := t.Copy()
+				var output strings.Builder
+				if workers > 1 {
+					tCopy.SetOutput(&output)
+				}
+
+				needed, err := rewriteTrace(tCopy, outMachine,
+					newTrace+"_"+strconv.Itoa(resultIndex+1)+"/", resultIndex, numberOfRoutines)
+				results[resultIndex] = rewriteResult{
+					needed:   needed,
+					err:      err,
+					duration: time.Now().Sub(rewriteStartTime),
+					output:   output.String(),
+				}
+			}
+		}()
+	}
+
+	for resultIndex := 0; resultIndex < numberOfResults; resultIndex++ {
+		indices <- resultIndex
+	}
+	close(indices)
+	wg.Wait()
+
+	return results
+}
+
 /*
  * Rewrite the trace file based on given analysis results
  * Args:
+ *   t (*trace.Trace): The trace to rewrite, is changed by the rewrite
  *   outMachine (string): The path to the analysis result file
  *   newTrace (string): The path where the new traces folder will be created
  *   resultIndex (int): The index of the result to use for the reordered trace file
@@ -319,10 +710,10 @@ func writeTime(pathTrace string, name string, time float64) error {
  *   bool: true, if a rewrite was nessesary, false if not (e.g. actual bug, warning)
  *   error: An error if the trace file could not be created
  */
-func rewriteTrace(outMachine string, newTrace string, resultIndex int,
+func rewriteTrace(t *trace.Trace, outMachine string, newTrace string, resultIndex int,
 	numberOfRoutines int) (bool, error) {
 
-	actual, bug, err := io.ReadAnalysisResults(outMachine, resultIndex)
+	actual, bug, err := io.ReadAnalysisResults(t, outMachine, resultIndex)
 	if err != nil {
 		return false, err
 	}
@@ -331,13 +722,13 @@ func rewriteTrace(outMachine string, newTrace string, resultIndex int,
 		return false, nil
 	}
 
-	rewriteNeeded, code, err := rewriter.RewriteTrace(bug)
+	rewriteNeeded, code, err := rewriter.RewriteTrace(t, bug)
 
 	if err != nil {
 		return rewriteNeeded, err
 	}
 
-	err = io.WriteTrace(newTrace, numberOfRoutines)
+	err = io.WriteTrace(t, newTrace, numberOfRoutines)
 	if err != nil {
 		return rewriteNeeded, err
 	}
@@ -350,70 +741,6 @@ func rewriteTrace(outMachine string, newTrace string, resultIndex int,
 	return rewriteNeeded, nil
 }
 
-/*
- * Parse the given analysis cases
- * Args:
- *   cases (string): The string of analysis cases to parse
- * Returns:
- *   map[string]bool: A map of the analysis cases and if they are set
- *   error: An error if the cases could not be parsed
- */
-func parseAnalysisCases(cases string) (map[string]bool, error) {
-	analysisCases := map[string]bool{
-		"all":                  false, // all cases enabled
-		"sendOnClosed":         false,
-		"receiveOnClosed":      false,
-		"doneBeforeAdd":        false,
-		"closeOnClosed":        false,
-		"concurrentRecv":       false,
-		"leak":                 false,
-		"selectWithoutPartner": false,
-		"cyclicDeadlock":       false,
-		"mixedDeadlock":        false,
-	}
-
-	if cases == "" {
-		analysisCases["all"] = true
-		analysisCases["sendOnClosed"] = true
-		analysisCases["receiveOnClosed"] = true
-		analysisCases["doneBeforeAdd"] = true
-		analysisCases["closeOnClosed"] = true
-		analysisCases["concurrentRecv"] = true
-		analysisCases["leak"] = true
-		analysisCases["selectWithoutPartner"] = true
-		// analysisCases["cyclicDeadlock"] = true
-		// analysisCases["mixedDeadlock"] = true
-
-		return analysisCases, nil
-	}
-
-	for _, c := range cases {
-		switch c {
-		case 's':
-			analysisCases["sendOnClosed"] = true
-		case 'r':
-			analysisCases["receiveOnClosed"] = true
-		case 'w':
-			analysisCases["doneBeforeAdd"] = true
-		case 'n':
-			analysisCases["closeOnClosed"] = true
-		case 'b':
-			analysisCases["concurrentRecv"] = true
-		case 'l':
-			analysisCases["leak"] = true
-		case 'u':
-			analysisCases["selectWithoutPartner"] = true
-		// case 'c':
-		// 	analysisCases["cyclicDeadlock"] = true
-		// case 'm':
-		// analysisCases["mixedDeadlock"] = true
-		default:
-			return nil, fmt.Errorf("Invalid analysis case: %c", c)
-		}
-	}
-	return analysisCases, nil
-}
-
 func printHeader() {
 	fmt.Print("\n")
 	fmt.Println(" $$$$$$\\  $$$$$$$\\  $$\\    $$\\  $$$$$$\\   $$$$$$\\   $$$$$$\\ $$$$$$$$\\ $$$$$$$$\\ ")
@@ -442,10 +769,16 @@ func printHeader() {
 
 func printHelp() {
 	println("Usage: ./analyzer [options\n")
-	println("There are three modes of operation:")
+	println("There are nine modes of operation:")
 	println("1. Analyze a trace file and create a reordered trace file based on the analysis results (Default)")
 	println("2. Create an explanation for a found bug")
-	println("3. Check if all concurrency elements of the program have been executed at least once\n\n")
+	println("3. Check if all concurrency elements of the program have been executed at least once")
+	println("4. Check if a replay followed the replayed trace")
+	println("5. Minimize the rewritten trace of a bug")
+	println("6. Check trace folders for corrupt or truncated traces")
+	println("7. Query the elements of a trace")
+	println("8. Export a trace into other formats")
+	println("9. Import an execution trace recorded with runtime/trace\n\n")
 	println("1. Analyze a trace file and create a reordered trace file based on the analysis results (Default)")
 	println("This mode is the default mode and analyzes a trace file and creates a reordered trace file based on the analysis results.")
 	println("It has the following options:")
@@ -458,13 +791,22 @@ func printHelp() {
 	println("  -p          Do not print the results to the terminal (default false). Automatically set -x to true")
 	println("  -r [folder] Path to where the result file should be saved. (default parallel to -t)")
 	println("  -a          Ignore atomic operations (default false). Use to reduce memory overhead for large traces.")
+	println("  -j [number] Number of traces that are rewritten in parallel (default 1)")
 	println("  -s [cases]  Select which analysis scenario to run, e.g. -s srd for the option s, r and d. Options:")
 	println("              s: Send on closed channel")
 	println("              r: Receive on closed channel")
 	println("              w: Done before add on waitGroup")
+	println("              a: Add concurrent with wait on waitGroup")
+	println("              g: Reuse of waitGroup before wait returned")
+	println("              k: Lost wakeup on conditional variable")
+	println("              v: Atomicity violation on atomic variable")
+	println("              d: Data race on memory accesses recorded with -race")
+	println("              i: Blocking operation in critical section")
 	println("              n: Close of closed channel")
 	println("              b: Concurrent receive on channel")
 	println("              l: Leaking routine")
+	println("              p: Partial deadlock of routines blocking each other")
+	println("              o: Misuse of once (recursive Do, panic in Do)")
 	println("              u: Select case without partner")
 	// println("              c: Cyclic deadlock")
 	// println("              m: Mixed deadlock")
@@ -483,4 +825,71 @@ func printHelp() {
 	println("  -R [folder] Path where the advocateResult folder created by the pipeline is located (required)")
 	println("  -P [folder] Path to the program folder (required)")
 	println("\n\n")
+	println("4. Check if a replay followed the replayed trace")
+	println("This mode compares the trace recorded during a replay (replay_trace) with the replayed trace")
+	println("and prints the first element at which the replay diverged from the trace.")
+	println("It has the following options:")
+	println("  -C          Compare the trace recorded during a replay with the replayed trace")
+	println("  -t [folder] Path to the replayed trace folder, e.g. rewritten_trace_1 (required)")
+	println("\n\n")
+	println("5. Minimize the rewritten trace of a bug")
+	println("This mode repeatedly replays reduced versions of the rewritten trace of a bug and keeps")
+	println("the smallest trace that still results in the expected exit code. The minimized trace is")
+	println("written into bugs/bug_[index]/minimized_trace.")
+	println("It has the following options:")
+	println("  -m          Minimize the rewritten trace of a bug")
+	println("  -t [file]   Path to the trace file of the bug (required)")
+	println("  -i [index]  Index of the bug (1 based) (required)")
+	println("  -E [cmd]    Shell command to run the replay of the bug (required). The program must")
+	println("              contain the replay header. The path of the trace to replay is given")
+	println("              to the replay in the environment variable ADVOCATE_REPLAY_TRACE")
+	println("\n\n")
+	println("6. Check trace folders for corrupt or truncated traces")
+	println("This mode checks the syntax of all elements, that tpre <= tpost, that all channel")
+	println("operations have matching partners, that all spawned routines have a trace file and")
+	println("that the lock and unlock operations of mutexes are balanced. All found problems are")
+	println("printed. The exit code is 1 if a problem was found.")
+	println("It is started with ./analyzer validate [folder] and has the following options:")
+	println("  [folder]    Path to the trace folder or to a folder containing trace folders (required),")
+	println("              can also be given with -t [folder]")
+	println("\n\n")
+	println("7. Query the elements of a trace")
+	println("This mode prints all elements of a trace, that match all given filters, sorted by time.")
+	println("It is started with ./analyzer query [options] and has the following options:")
+	println("  -t [folder] Path to the trace folder (required)")
+	println("  -o [id]     Only print operations on the object with this id")
+	println("  -r [id]     Only print elements of the routine with this id")
+	println("  -y [types]  Only print elements of the given types, e.g. -y CM for channel and mutex operations")
+	println("  -f [glob]   Only print elements whose source file matches the glob pattern")
+	println("  -s [time]   Only print elements with a time (tSort) of at least this value")
+	println("  -e [time]   Only print elements with a time (tSort) of at most this value")
+	println("  -c          Print the vector clocks of the elements")
+	println("  -j          Print the elements as JSON")
+	println("  -a          Ignore atomic operations")
+	println("\n\n")
+	println("8. Export a trace into other formats")
+	println("This mode converts a trace into a format that can be opened by other tools.")
+	println("It is started with ./analyzer export [options] and has the following options:")
+	println("  -t [folder] Path to the trace folder (required)")
+	println("  -f [format] Format of the export (default chrome). Options:")
+	println("              chrome: Chrome Trace Event JSON for Perfetto or chrome://tracing")
+	println("              dot: Happens before graph around the elements of a result in the DOT format")
+	println("              graphml: Happens before graph around the elements of a result in the GraphML format")
+	println("  -o [file]   Path of the created file (default [format].json or hb_[index].[format] next to the trace folder)")
+	println("  -r [file]   Path to the machine readable analysis results, whose elements are highlighted")
+	println("              (default results_machine.log next to the trace folder, if it exists)")
+	println("  -i [index]  Index of the result (1 based) for the happens before graph (required for dot and graphml)")
+	println("  -d [depth]  Maximum number of edges between the elements of the result and the other")
+	println("              elements in the happens before graph (default 5)")
+	println("  -a          Ignore atomic operations")
+	println("\n\n")
+	println("9. Import an execution trace recorded with runtime/trace")
+	println("This mode converts an execution trace of the standard toolchain into a trace folder,")
+	println("that can be analyzed with the other modes. Only operations that blocked a routine, the")
+	println("operations that unblocked them and the creation of routines are imported. The information")
+	println("that could not be imported is written into import_info.log in the trace folder.")
+	println("It is started with ./analyzer import [options] and has the following options:")
+	println("  -t [file]   Path to the execution trace, or to the output of go tool trace -d=parsed (required)")
+	println("  -o [folder] Path of the created trace folder (default advocateTrace next to the execution trace)")
+	println("\n\n")
 }
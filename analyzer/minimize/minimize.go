@@ -0,0 +1,389 @@
+// Package minimize provides functions to minimize a rewritten trace that reproduces a bug.
+package minimize
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"analyzer/io"
+	"analyzer/trace"
+)
+
+/*
+ * State of the minimization
+ * Fields:
+ *   command (string): shell command that replays the trace given in ADVOCATE_REPLAY_TRACE
+ *   candidatePath (string): folder, where the candidate traces are written to
+ *   expectedCode (int): exit code that the replay must produce to reproduce the bug
+ *   original (map[int][]trace.TraceElement): the rewritten trace without the replay stop element
+ *   numberRoutines (int): number of routines in the trace
+ *   stopTime (int): time of the replay stop element
+ *   runs (int): number of executed replays
+ */
+type minimizer struct {
+	command        string
+	candidatePath  string
+	expectedCode   int
+	original       map[int][]trace.TraceElement
+	numberRoutines int
+	stopTime       int
+	runs           int
+}
+
+/*
+ * Minimize a rewritten trace that reproduces a bug.
+ * The function repeatedly replays reduced versions of the rewritten trace and
+ * keeps a reduction, if the replay still results in the expected exit code.
+ * First, the elements of complete routines are removed. Then, the trace of each
+ * remaining routine is shortened to a short prefix that still reproduces
+ * the bug. Finally, single elements and groups of elements are removed with
+ * the delta debugging algorithm (ddmin), which also finds reductions, that
+ * are not a prefix of a routine. An operation that is not in the trace is only
+ * executed after all elements in the trace, therefore each removed element
+ * removes an ordering constraint from the replay.
+ * The minimized trace is written to bugs/bug_[index]/minimized_trace.
+ * Args:
+ *   path (string): path to the folder containing the results and the rewritten traces
+ *   index (int): index of the bug (1 based)
+ *   command (string): shell command to run the replay of the bug. The trace to
+ *     replay is given to the replay in the environment variable ADVOCATE_REPLAY_TRACE
+ * Returns:
+ *   error: error if the trace could not be minimized
+ */
+func Minimize(path string, index int, command string) error {
+	rewrittenPath := filepath.Join(path, "rewritten_trace_"+strconv.Itoa(index))
+
+	expectedCode, err := readExpectedExitCode(rewrittenPath)
+	if err != nil {
+		return err
+	}
+
+	rewrittenTrace, numberRoutines, err := io.CreateTraceFromFiles(rewrittenPath, false)
+	if err != nil {
+		return err
+	}
+
+	m := minimizer{
+		command:        command,
+		candidatePath:  filepath.Join(path, "minimize_candidate") + string(os.PathSeparator),
+		expectedCode:   expectedCode,
+		original:       make(map[int][]trace.TraceElement),
+		numberRoutines: numberRoutines,
+		stopTime:       -1,
+	}
+
+	for routine, routineTrace := range rewrittenTrace.CopyCurrentTrace() {
+		m.original[routine] = make([]trace.TraceElement, 0, len(routineTrace))
+		for _, elem := range routineTrace {
+			if stop, ok := elem.(*trace.TraceElementReplay); ok {
+				m.stopTime = stop.GetTSort()
+				continue
+			}
+			m.original[routine] = append(m.original[routine], elem)
+		}
+	}
+
+	if m.stopTime == -1 {
+		return errors.New("The trace " + rewrittenPath + " does not contain a replay stop element")
+	}
+
+	defer os.RemoveAll(m.candidatePath)
+
+	keep := make(map[int]int)
+	total := 0
+	for routine, routineTrace := range m.original {
+		keep[routine] = len(routineTrace)
+		total += len(routineTrace)
+	}
+
+	fmt.Printf("Minimize trace with %d elements, expected exit code %d\n", total, expectedCode)
+
+	removed := make(map[string]bool)
+
+	if !m.reproduces(keep, removed) {
+		return errors.New("The rewritten trace does not reproduce the expected exit code " +
+			strconv.Itoa(expectedCode))
+	}
+
+	routines := make([]int, 0, len(keep))
+	for routine := range keep {
+		routines = append(routines, routine)
+	}
+	sort.Ints(routines)
+
+	// remove complete routines
+	for _, routine := range routines {
+		if keep[routine] == 0 {
+			continue
+		}
+
+		old := keep[routine]
+		keep[routine] = 0
+		if !m.reproduces(keep, removed) {
+			keep[routine] = old
+		}
+	}
+
+	// shorten the remaining routines by a binary search for a short prefix.
+	// The replay is not necessarily monotone in the prefix length, the search
+	// only keeps prefixes that were confirmed by a replay, but the result may
+	// not be the shortest prefix. The following ddmin pass removes further
+	// elements.
+	for _, routine := range routines {
+		low, high := 0, keep[routine]
+		for low < high {
+			mid := (low + high) / 2
+			keep[routine] = mid
+			if m.reproduces(keep, removed) {
+				high = mid
+			} else {
+				low = mid + 1
+			}
+		}
+		keep[routine] = high
+	}
+
+	removed = m.ddmin(keep, routines)
+
+	minimized := 0
+	for _, k := range m.keptPerRoutine(keep, removed) {
+		minimized += k
+	}
+
+	fmt.Printf("Minimized trace from %d to %d elements with %d replays\n", total, minimized, m.runs)
+
+	return m.writeResult(path, index, keep, removed, total, minimized)
+}
+
+/*
+ * Remove single elements and groups of elements from the prefixes with the
+ * delta debugging algorithm (ddmin). The remaining elements are split into n
+ * chunks. If the trace without one of the chunks still reproduces the bug,
+ * the chunk is removed. Otherwise, the number of chunks is doubled, until
+ * each chunk contains a single element. The result is 1-minimal, i.e. no
+ * single element can be removed without losing the bug.
+ * Args:
+ *   keep (map[int]int): number of elements to keep for each routine
+ *   routines ([]int): the routines in the trace, sorted
+ * Returns:
+ *   map[string]bool: the tIDs of the removed elements
+ */
+func (m *minimizer) ddmin(keep map[int]int, routines []int) map[string]bool {
+	elems := make([]string, 0)
+	for _, routine := range routines {
+		for _, elem := range m.original[routine][:keep[routine]] {
+			elems = append(elems, elem.GetTID())
+		}
+	}
+
+	removed := make(map[string]bool)
+	n := 2
+	for len(elems) >= 2 {
+		n = min(n, len(elems))
+		chunkSize := (len(elems) + n - 1) / n
+
+		reduced := false
+		for start := 0; start < len(elems); start += chunkSize {
+			end := min(start+chunkSize, len(elems))
+
+			candidate := make(map[string]bool, len(removed)+end-start)
+			for tID := range removed {
+				candidate[tID] = true
+			}
+			for _, tID := range elems[start:end] {
+				candidate[tID] = true
+			}
+
+			if m.reproduces(keep, candidate) {
+				removed = candidate
+				elems = append(elems[:start:start], elems[end:]...)
+				n = max(n-1, 2)
+				reduced = true
+				break
+			}
+		}
+
+		if !reduced {
+			if n >= len(elems) {
+				break
+			}
+			n = min(2*n, len(elems))
+		}
+	}
+
+	return removed
+}
+
+/*
+ * Get the number of elements, that are kept for each routine
+ * Args:
+ *   keep (map[int]int): number of elements to keep for each routine
+ *   removed (map[string]bool): the tIDs of the removed elements
+ * Returns:
+ *   map[int]int: the number of kept elements for each routine
+ */
+func (m *minimizer) keptPerRoutine(keep map[int]int, removed map[string]bool) map[int]int {
+	res := make(map[int]int)
+	for routine, k := range keep {
+		res[routine] = k
+		for _, elem := range m.original[routine][:k] {
+			if removed[elem.GetTID()] {
+				res[routine]--
+			}
+		}
+	}
+	return res
+}
+
+/*
+ * Create the original trace, reduced to the given prefixes and without the
+ * removed elements
+ * Args:
+ *   keep (map[int]int): number of elements to keep for each routine
+ *   removed (map[string]bool): the tIDs of the removed elements
+ * Returns:
+ *   *trace.Trace: the reduced trace
+ */
+func (m *minimizer) reducedTrace(keep map[int]int, removed map[string]bool) *trace.Trace {
+	t := trace.NewTrace()
+	t.SetTrace(m.original)
+	for routine, k := range keep {
+		t.ShortenRoutineIndex(routine, k, false)
+	}
+	for tID := range removed {
+		t.RemoveElementFromTrace(tID)
+	}
+	t.AddTraceElementReplay(m.stopTime, m.expectedCode)
+	return t
+}
+
+/*
+ * Check if the reduced trace still reproduces the bug
+ * Args:
+ *   keep (map[int]int): number of elements to keep for each routine
+ *   removed (map[string]bool): the tIDs of the removed elements
+ * Returns:
+ *   bool: true if the replay of the reduced trace results in the expected exit code
+ */
+func (m *minimizer) reproduces(keep map[int]int, removed map[string]bool) bool {
+	err := io.WriteTrace(m.reducedTrace(keep, removed), m.candidatePath, m.numberRoutines)
+	if err != nil {
+		println("Could not write candidate trace: ", err.Error())
+		return false
+	}
+
+	m.runs++
+
+	cmd := exec.Command("sh", "-c", m.command)
+	cmd.Env = append(os.Environ(), "ADVOCATE_REPLAY_TRACE="+m.candidatePath)
+	// the replay exits with the replay exit code, the result is read from the result file
+	_ = cmd.Run()
+
+	code, err := readReplayExitCode(m.candidatePath)
+	if err != nil {
+		println("Could not read replay result: ", err.Error())
+		return false
+	}
+
+	return code == m.expectedCode
+}
+
+/*
+ * Write the minimized trace and a summary into the bug folder
+ * Args:
+ *   path (string): path to the folder containing the results and the rewritten traces
+ *   index (int): index of the bug (1 based)
+ *   keep (map[int]int): number of elements to keep for each routine
+ *   removed (map[string]bool): the tIDs of the removed elements
+ *   total (int): number of elements in the rewritten trace
+ *   minimized (int): number of elements in the minimized trace
+ * Returns:
+ *   error: error if the result could not be written
+ */
+func (m *minimizer) writeResult(path string, index int, keep map[int]int, removed map[string]bool,
+	total int, minimized int) error {
+	bugFolder := filepath.Join(path, "bugs", "bug_"+strconv.Itoa(index))
+	err := os.MkdirAll(bugFolder, 0755)
+	if err != nil {
+		return err
+	}
+
+	resultPath := filepath.Join(bugFolder, "minimized_trace") + string(os.PathSeparator)
+	err = io.WriteTrace(m.reducedTrace(keep, removed), resultPath, m.numberRoutines)
+	if err != nil {
+		return err
+	}
+
+	info := "Original elements: " + strconv.Itoa(total) + "\n"
+	info += "Minimized elements: " + strconv.Itoa(minimized) + "\n"
+	info += "Expected exit code: " + strconv.Itoa(m.expectedCode) + "\n"
+	info += "Replays: " + strconv.Itoa(m.runs) + "\n"
+	info += "Elements per routine:\n"
+	kept := m.keptPerRoutine(keep, removed)
+	routines := make([]int, 0, len(kept))
+	for routine := range kept {
+		routines = append(routines, routine)
+	}
+	sort.Ints(routines)
+	for _, routine := range routines {
+		info += "\t" + strconv.Itoa(routine) + ": " + strconv.Itoa(kept[routine]) +
+			"/" + strconv.Itoa(len(m.original[routine])) + "\n"
+	}
+
+	return os.WriteFile(filepath.Join(bugFolder, "minimize_info.log"), []byte(info), 0644)
+}
+
+/*
+ * Read the expected exit code from the rewrite info file of a rewritten trace
+ * Args:
+ *   path (string): path to the rewritten trace
+ * Returns:
+ *   int: the expected exit code
+ *   error: error if the file could not be read
+ */
+func readExpectedExitCode(path string) (int, error) {
+	content, err := os.ReadFile(filepath.Join(path, "rewrite_info.log"))
+	if err != nil {
+		return 0, err
+	}
+
+	// format: index#bugType#exitCode
+	fields := strings.Split(strings.TrimSpace(string(content)), "#")
+	if len(fields) != 3 {
+		return 0, errors.New("Invalid rewrite info: " + string(content))
+	}
+
+	return strconv.Atoi(fields[2])
+}
+
+/*
+ * Read the exit code from the replay result file written by the replay
+ * Args:
+ *   path (string): path to the replayed trace
+ * Returns:
+ *   int: the exit code of the replay
+ *   error: error if the file could not be read
+ */
+func readReplayExitCode(path string) (int, error) {
+	content, err := os.ReadFile(filepath.Join(path, "replay_result.json"))
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		ExitCode int `json:"exitCode"`
+	}
+	err = json.Unmarshal(content, &result)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ExitCode, nil
+}
@@ -0,0 +1,262 @@
+// Package query selects elements of a trace by object, routine, type,
+// source file and time, to inspect traces without writing extra programs.
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"analyzer/trace"
+	"analyzer/utils"
+)
+
+/*
+ * Filter for the elements of a trace. An element is selected if it matches
+ * all set fields.
+ * Fields:
+ *   ObjectID (int): id of the object (channel, mutex, ...), -1 for all objects.
+ *     A select matches if its id or the id of one of its cases is equal
+ *   Routine (int): id of the routine, -1 for all routines
+ *   Types ([]string): types of the elements, e.g. "C" or "M", empty for all types
+ *   FilePattern (string): glob pattern for the source file of the element, matched
+ *     against the full path and the file name, empty for all files
+ *   Start (int): smallest time (tSort) of the elements
+ *   End (int): largest time (tSort) of the elements
+ */
+type Filter struct {
+	ObjectID    int
+	Routine     int
+	Types       []string
+	FilePattern string
+	Start       int
+	End         int
+}
+
+/*
+ * Create a filter, that selects all elements
+ * Returns:
+ *   Filter: the filter
+ */
+func NewFilter() Filter {
+	return Filter{
+		ObjectID: -1,
+		Routine:  -1,
+		Types:    make([]string, 0),
+		Start:    0,
+		End:      math.MaxInt,
+	}
+}
+
+/*
+ * An element in the output of the query
+ * Fields:
+ *   Routine (int): the routine of the element
+ *   Type (string): the type of the element
+ *   ID (int): the id of the object
+ *   TPre (int): the tPre of the element
+ *   TSort (int): the time used to sort the element
+ *   Pos (string): the position of the element in the code
+ *   Element (string): the element as it is written in the trace
+ *   VC (map[int]int): the vector clock of the element by routine, only set if requested
+ */
+type Element struct {
+	Routine int         `json:"routine"`
+	Type    string      `json:"type"`
+	ID      int         `json:"id"`
+	TPre    int         `json:"tPre"`
+	TSort   int         `json:"tSort"`
+	Pos     string      `json:"pos"`
+	Element string      `json:"element"`
+	VC      map[int]int `json:"vc,omitempty"`
+}
+
+/*
+ * Get all elements of the trace that match the filter, sorted by time
+ * Args:
+ *   t (*trace.Trace): the trace
+ *   filter (Filter): the filter
+ * Returns:
+ *   []trace.TraceElement: the matching elements
+ */
+func Query(t *trace.Trace, filter Filter) []trace.TraceElement {
+	res := make([]trace.TraceElement, 0)
+	for _, tra := range *t.GetTraces() {
+		for _, elem := range tra {
+			if matches(elem, filter) {
+				res = append(res, elem)
+			}
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].GetTSort() != res[j].GetTSort() {
+			return res[i].GetTSort() < res[j].GetTSort()
+		}
+		return res[i].GetRoutine() < res[j].GetRoutine()
+	})
+
+	return res
+}
+
+/*
+ * Check if an element matches the filter
+ * Args:
+ *   elem (trace.TraceElement): the element
+ *   filter (Filter): the filter
+ * Returns:
+ *   bool: true if the element matches
+ */
+func matches(elem trace.TraceElement, filter Filter) bool {
+	if filter.Routine != -1 && elem.GetRoutine() != filter.Routine {
+		return false
+	}
+
+	if len(filter.Types) != 0 && !utils.Contains(filter.Types, elementType(elem)) {
+		return false
+	}
+
+	if elem.GetTSort() < filter.Start || elem.GetTSort() > filter.End {
+		return false
+	}
+
+	if filter.ObjectID != -1 && !matchesObject(elem, filter.ObjectID) {
+		return false
+	}
+
+	if filter.FilePattern != "" && !matchesFile(elem.GetPos(), filter.FilePattern) {
+		return false
+	}
+
+	return true
+}
+
+/*
+ * Check if an element is an operation on the given object
+ * Args:
+ *   elem (trace.TraceElement): the element
+ *   objectID (int): the id of the object
+ * Returns:
+ *   bool: true if the element is an operation on the object
+ */
+func matchesObject(elem trace.TraceElement, objectID int) bool {
+	if elem.GetID() == objectID {
+		return true
+	}
+
+	if se, ok := elem.(*trace.TraceElementSelect); ok {
+		for _, c := range se.GetCases() {
+			if c.GetID() == objectID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+/*
+ * Check if the file of a position matches a glob pattern
+ * Args:
+ *   pos (string): the position, file:line
+ *   pattern (string): the glob pattern
+ * Returns:
+ *   bool: true if the full path or the file name matches the pattern
+ */
+func matchesFile(pos string, pattern string) bool {
+	file := pos
+	if i := strings.LastIndex(pos, ":"); i != -1 {
+		file = pos[:i]
+	}
+
+	if ok, _ := filepath.Match(pattern, file); ok {
+		return true
+	}
+
+	ok, _ := filepath.Match(pattern, filepath.Base(file))
+	return ok
+}
+
+/*
+ * Get the type of an element, e.g. "C" for a channel operation
+ * Args:
+ *   elem (trace.TraceElement): the element
+ * Returns:
+ *   string: the type of the element
+ */
+func elementType(elem trace.TraceElement) string {
+	elemStr := elem.ToString()
+	if elemStr == "" {
+		return ""
+	}
+	return elemStr[0:1]
+}
+
+/*
+ * Convert the elements into the output format
+ * Args:
+ *   elems ([]trace.TraceElement): the elements
+ *   clocks (bool): if true, the vector clocks are added
+ * Returns:
+ *   []Element: the converted elements
+ */
+func toElements(elems []trace.TraceElement, clocks bool) []Element {
+	res := make([]Element, 0, len(elems))
+	for _, elem := range elems {
+		e := Element{
+			Routine: elem.GetRoutine(),
+			Type:    elementType(elem),
+			ID:      elem.GetID(),
+			TPre:    elem.GetTPre(),
+			TSort:   elem.GetTSort(),
+			Pos:     elem.GetPos(),
+			Element: elem.ToString(),
+		}
+		if clocks {
+			e.VC = elem.GetVC().GetClock()
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+/*
+ * Write the elements as text, one element per line
+ * Args:
+ *   w (io.Writer): where the elements are written to
+ *   elems ([]trace.TraceElement): the elements
+ *   clocks (bool): if true, the vector clocks are written
+ * Returns:
+ *   error: error if the elements could not be written
+ */
+func WriteText(w io.Writer, elems []trace.TraceElement, clocks bool) error {
+	for _, elem := range elems {
+		line := fmt.Sprintf("%d -> %s", elem.GetRoutine(), elem.ToString())
+		if clocks {
+			line += " " + elem.GetVC().ToString()
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+ * Write the elements as a JSON array
+ * Args:
+ *   w (io.Writer): where the elements are written to
+ *   elems ([]trace.TraceElement): the elements
+ *   clocks (bool): if true, the vector clocks are written
+ * Returns:
+ *   error: error if the elements could not be written
+ */
+func WriteJSON(w io.Writer, elems []trace.TraceElement, clocks bool) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(toElements(elems, clocks))
+}
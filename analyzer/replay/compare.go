@@ -0,0 +1,220 @@
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"analyzer/io"
+	"analyzer/trace"
+)
+
+/*
+ * Mismatch describes the first position, where the trace recorded during the
+ * replay differs from the replayed trace
+ * Fields:
+ *   Index (int): index of the element in the global order of the replayed trace
+ *   Expected (trace.TraceElement): the element of the replayed trace
+ *   Observed (trace.TraceElement): the element of the recorded trace, nil if the replay executed fewer elements
+ */
+type Mismatch struct {
+	Index    int
+	Expected trace.TraceElement
+	Observed trace.TraceElement
+}
+
+/*
+ * Compare the trace that was recorded during the replay with the trace that
+ * was replayed. Only the part of the replayed trace before the replay stop
+ * element is compared, because only this part is enforced by the replay.
+ * Args:
+ *   pathTrace (string): path to the replayed (rewritten) trace folder. The
+ *     recorded trace is expected in the subfolder replay_trace
+ * Returns:
+ *   *Mismatch: the first mismatch, nil if the replay followed the trace
+ *   int: number of compared elements
+ *   error: error if one of the traces could not be read
+ */
+func Compare(pathTrace string) (*Mismatch, int, error) {
+	pathReplay := filepath.Join(pathTrace, "replay_trace")
+	if _, err := os.Stat(pathReplay); os.IsNotExist(err) {
+		return nil, 0, errors.New("No trace recorded during the replay found in " + pathReplay)
+	}
+
+	ignored, err := readIgnored(pathReplay)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	expected, err := readOrder(pathTrace, ignored, true)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	observed, err := readOrder(pathReplay, ignored, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i, exp := range expected {
+		if i >= len(observed) {
+			return &Mismatch{Index: i, Expected: exp, Observed: nil}, i, nil
+		}
+
+		if elementKey(exp) != elementKey(observed[i]) {
+			return &Mismatch{Index: i, Expected: exp, Observed: observed[i]}, i, nil
+		}
+	}
+
+	return nil, len(expected), nil
+}
+
+/*
+ * Read a trace and get the executed elements in the order in which they
+ * were executed
+ * Args:
+ *   path (string): path to the trace folder
+ *   ignored ([]string): the positions, whose operations are not enforced by the replay
+ *   stopAtReplayEnd (bool): if true, only elements before the replay stop element are returned
+ * Returns:
+ *   []trace.TraceElement: the elements in global order
+ *   error: error if the trace could not be read
+ */
+func readOrder(path string, ignored []string, stopAtReplayEnd bool) ([]trace.TraceElement, error) {
+	t, _, err := io.CreateTraceFromFiles(path, true)
+	if err != nil {
+		return nil, err
+	}
+
+	replayEnd := math.MaxInt
+	res := make([]trace.TraceElement, 0)
+	for _, routineTrace := range *t.GetTraces() {
+		for _, elem := range routineTrace {
+			if _, ok := elem.(*trace.TraceElementReplay); ok {
+				if stopAtReplayEnd {
+					replayEnd = min(replayEnd, elem.GetTSort())
+				}
+				continue
+			}
+
+			// not executed elements have no position in the order
+			if elem.GetTSort() == math.MaxInt || isIgnored(elem.GetPos(), ignored) {
+				continue
+			}
+
+			res = append(res, elem)
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].GetTSort() < res[j].GetTSort()
+	})
+
+	for i, elem := range res {
+		if elem.GetTSort() >= replayEnd {
+			return res[:i], nil
+		}
+	}
+
+	return res, nil
+}
+
+/*
+ * Get a key to identify an element independent of its timestamps
+ * Args:
+ *   elem (trace.TraceElement): the element
+ * Returns:
+ *   string: the key of the element
+ */
+func elementKey(elem trace.TraceElement) string {
+	return fmt.Sprintf("%T,%d,%s", elem, elem.GetRoutine(), elem.GetPos())
+}
+
+/*
+ * Read the positions, whose operations are not enforced by the replay. They
+ * are written by the runtime into ignored.log in the folder of the trace
+ * recorded during the replay
+ * Args:
+ *   pathReplay (string): path to the trace recorded during the replay
+ * Returns:
+ *   []string: the positions, either a file suffix or file:line
+ *   error: error if the file could not be read
+ */
+func readIgnored(pathReplay string) ([]string, error) {
+	content, err := os.ReadFile(filepath.Join(pathReplay, "ignored.log"))
+	if err != nil {
+		return nil, errors.New("Could not read the positions ignored by the replay. " +
+			"The replay must be run with a runtime, that writes ignored.log: " + err.Error())
+	}
+
+	res := make([]string, 0)
+	for _, line := range strings.Split(string(content), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			res = append(res, line)
+		}
+	}
+
+	return res, nil
+}
+
+/*
+ * Check if an element is not enforced by the replay
+ * Args:
+ *   pos (string): position of the element
+ *   ignored ([]string): the ignored positions, either a file suffix or file:line
+ * Returns:
+ *   bool: true if the order of the element is not enforced by the replay
+ */
+func isIgnored(pos string, ignored []string) bool {
+	file, line, found := strings.Cut(pos, ":")
+	if !found {
+		return false
+	}
+
+	for _, ig := range ignored {
+		igFile, igLine, hasLine := strings.Cut(ig, ":")
+		if !strings.HasSuffix(file, igFile) {
+			continue
+		}
+
+		if !hasLine || igLine == line {
+			return true
+		}
+	}
+
+	return false
+}
+
+/*
+ * Compare the recorded replay with the replayed trace and print the result
+ * Args:
+ *   pathTrace (string): path to the replayed (rewritten) trace folder
+ * Returns:
+ *   bool: true if the replay followed the trace, false otherwise
+ *   error: error if one of the traces could not be read
+ */
+func CheckReplay(pathTrace string) (bool, error) {
+	mismatch, compared, err := Compare(pathTrace)
+	if err != nil {
+		return false, err
+	}
+
+	if mismatch == nil {
+		fmt.Printf("The replay followed the trace (%d elements compared)\n", compared)
+		return true, nil
+	}
+
+	fmt.Printf("The replay diverged from the trace at element %d\n", mismatch.Index+1)
+	fmt.Printf("\tExpected: routine %d, %s\n", mismatch.Expected.GetRoutine(), mismatch.Expected.ToString())
+	if mismatch.Observed != nil {
+		fmt.Printf("\tObserved: routine %d, %s\n", mismatch.Observed.GetRoutine(), mismatch.Observed.ToString())
+	} else {
+		fmt.Println("\tObserved: no further element was executed in the replay")
+	}
+
+	return false, nil
+}
@@ -0,0 +1,83 @@
+package rewriter
+
+import (
+	"analyzer/bugs"
+	"analyzer/trace"
+	"errors"
+)
+
+/*
+ * Create a new trace for an atomicity violation on an atomic variable.
+ * Let l be the load and s the write of the check-then-act sequence, w the
+ * write of the other routine, X' a stop marker and T1, T2, T3 partial traces.
+ * If w was executed after s, the trace looks as follows:
+ * 	T1 ++ [l] ++ T2 ++ [s] ++ T3 ++ [w] ++ T4
+ * We remove T4 and all elements, that are HB-later than s, and add s
+ * directly after w:
+ * 	T1 ++ [l] ++ T2 ++ T3' ++ [w, s, X']
+ * If w was executed before l, we move l before w. For this, all elements,
+ * that are not HB-before l, including w and s, are moved after l without
+ * changing their order. The trace then looks as follows:
+ * 	T1 ++ [l] ++ T2 ++ [w] ++ T3 ++ [s] ++ T4
+ * We remove T4 and all elements, that are HB-later than s:
+ * 	T1 ++ [l] ++ T2 ++ [w] ++ T3' ++ [s, X']
+ * The replay does not enforce the order of atomic operations. The interleaving
+ * is therefore only enforced by the order of the other operations in the trace.
+ * Reaching the stop marker does therefore not confirm the violation.
+ * Args:
+ *   t (*trace.Trace): The trace to rewrite
+ *   bug (Bug): The bug to create a trace for
+ * Returns:
+ *   error: An error if the trace could not be created
+ */
+func rewriteAtomicityViolation(t *trace.Trace, bug bugs.Bug) error {
+	t.Println("Start rewriting trace for atomicity violation...")
+
+	if len(bug.TraceElement1) != 3 {
+		return errors.New("TraceElement1 does not contain the load, the write and the act")
+	}
+	for _, elem := range bug.TraceElement1 {
+		if elem == nil {
+			return errors.New("TraceElement1 contains nil")
+		}
+	}
+
+	load := bug.TraceElement1[0]
+	write := bug.TraceElement1[1]
+	act := bug.TraceElement1[2]
+
+	tLoad := (*load).GetTSort()
+	tWrite := (*write).GetTSort()
+	tAct := (*act).GetTSort()
+
+	if tLoad < tWrite && tWrite < tAct {
+		return errors.New("The write was already executed between the load and the act. Therefore no rewrite is needed.")
+	}
+
+	if tWrite < tLoad {
+		// move l before w -> T1 ++ [l] ++ T2 ++ [w] ++ T3 ++ [s] ++ T4
+		t.ShiftConcurrentOrAfterToAfterStartingFromElement(load, tWrite-1)
+		tWrite = (*write).GetTSort()
+		tAct = (*act).GetTSort()
+	}
+
+	// remove the elements HB-later than s
+	t.RemoveLater(act, 0)
+
+	if tAct < tWrite {
+		// remove T4 and move s after w -> T1 ++ [l] ++ T2 ++ T3' ++ [w, s]
+		t.ShortenTrace(tWrite, true)
+		t.RemoveElementFromTrace((*act).GetTID())
+		(*act).SetT(tWrite + 1)
+		t.AddElementToTrace(*act)
+		tAct = tWrite + 1
+	} else {
+		// remove T4 -> T1 ++ [l] ++ T2 ++ [w] ++ T3' ++ [s]
+		t.ShortenTrace(tAct, true)
+	}
+
+	// add a stop marker
+	t.AddTraceElementReplay(tAct+1, exitAtomicityViolation)
+
+	return nil
+}
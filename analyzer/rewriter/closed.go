@@ -3,7 +3,10 @@ package rewriter
 import (
 	"analyzer/bugs"
 	"analyzer/trace"
+	"analyzer/utils"
 	"errors"
+	"strconv"
+	"strings"
 )
 
 /*
@@ -37,13 +40,14 @@ import (
 * elements T2'. We can therefore rewrite the trace as follows:
 * 	T1 ++ T2' ++ [X, c, a, X']
 * Args:
+*   t (*trace.Trace): The trace to rewrite
 *   bug (Bug): The bug to create a trace for
 *   exitCode (int): The exit code to use for the stop marker
 * Returns:
 *   error: An error if the trace could not be created
  */
-func rewriteClosedChannel(bug bugs.Bug, exitCode int) error {
-	println("Start rewriting trace for receive on closed channel...")
+func rewriteClosedChannel(t *trace.Trace, bug bugs.Bug, exitCode int) error {
+	t.Println("Start rewriting trace for receive on closed channel...")
 
 	if len(bug.TraceElement1) == 0 || bug.TraceElement1[0] == nil { // close
 		return errors.New("TraceElement1 is nil") // send/recv
@@ -60,17 +64,138 @@ func rewriteClosedChannel(bug bugs.Bug, exitCode int) error {
 	}
 
 	// remove T3 -> T1 ++ [a] ++ T2 ++ [c]
-	trace.ShortenTrace(t2, true)
+	t.ShortenTrace(t2, true)
 
 	// transform T2 to T2' -> T1 ++ T2' ++ [c, a]
 	// This is done by removing all elements in T2, that are concurrent to c (including a)
 	// and then adding a after c
-	trace.RemoveConcurrent(bug.TraceElement2[0], t1)
+	t.RemoveConcurrent(bug.TraceElement2[0], t1)
 	(*bug.TraceElement1[0]).SetT(t2 + 1)
-	trace.AddElementToTrace(*bug.TraceElement1[0])
+	t.AddElementToTrace(*bug.TraceElement1[0])
 
 	// add a stop marker -> T1 ++ T2' ++ [c, a, X']
-	trace.AddTraceElementReplay(t2+2, exitCode)
+	t.AddTraceElementReplay(t2+2, exitCode)
 
 	return nil
 }
+
+/*
+ * Create a new trace for a possible close on closed channel
+ * Let c be the close, s the select of the other routine, that received
+ * from the closed channel, X' a stop marker and T1, T2, T3 partial traces.
+ * The trace before the rewrite looks as follows:
+ * 	T1 ++ [c] ++ T2 ++ [s] ++ T3
+ * s is concurrent to c, except for the receive on the closed channel. We
+ * remove T3 and all elements in T2, that are HB-later than c, and let s
+ * choose its default case. The close c' in the default case of s is added
+ * with its position in the program. If the program code is not available,
+ * s must be the same select, that chose its default case before c, and c'
+ * is the same close as c:
+ * 	T1 ++ T2' ++ [s', c, c', X']
+ * Args:
+ *   t (*trace.Trace): The trace to rewrite
+ *   bug (Bug): The bug to create a trace for
+ * Returns:
+ *   error: An error if the trace could not be created
+ */
+func rewriteCloseOnClosed(t *trace.Trace, bug bugs.Bug) error {
+	t.Println("Start rewriting trace for close on closed channel...")
+
+	if len(bug.TraceElement1) == 0 || bug.TraceElement1[0] == nil { // select
+		return errors.New("TraceElement1 is nil")
+	}
+	if len(bug.TraceElement2) == 0 || bug.TraceElement2[0] == nil { // close
+		return errors.New("TraceElement2 is nil")
+	}
+
+	sel, ok := (*bug.TraceElement1[0]).(*trace.TraceElementSelect)
+	if !ok {
+		return errors.New("TraceElement1 is not a select")
+	}
+	cl, ok := (*bug.TraceElement2[0]).(*trace.TraceElementChannel)
+	if !ok {
+		return errors.New("TraceElement2 is not a channel operation")
+	}
+
+	t1 := sel.GetTSort() // select
+	t2 := cl.GetTSort()  // close
+
+	if t1 < t2 {
+		return errors.New("Select is before close")
+	}
+
+	pos, err := secondClosePos(t, sel, cl)
+	if err != nil {
+		return err
+	}
+
+	// remove T3 and s -> T1 ++ [c] ++ T2
+	t.ShortenTrace(t1, false)
+
+	// transform T2 to T2' -> T1 ++ T2' ++ [c]
+	t.RemoveLater(bug.TraceElement2[0], t2)
+
+	// T1 ++ T2' ++ [s', c, c']
+	err = sel.SetChosenDefault()
+	if err != nil {
+		return err
+	}
+	sel.SetT(t1)
+	t.AddElementToTrace(sel)
+	cl.SetT(t1 + 1)
+	err = t.AddTraceElementChannel(sel.GetRoutine(), strconv.Itoa(t1+2), strconv.Itoa(t1+2),
+		strconv.Itoa(cl.GetID()), "C", "f", "0", strconv.Itoa(cl.GetQSize()), pos)
+	if err != nil {
+		return err
+	}
+
+	// add a stop marker -> T1 ++ T2' ++ [s', c, c', X']
+	t.AddTraceElementReplay(t1+3, exitCloseClose)
+
+	return nil
+}
+
+/*
+ * Get the position of the close, that a select with a default case runs
+ * if it chooses the default case
+ * Args:
+ *   t (*trace.Trace): The trace
+ *   sel (*trace.TraceElementSelect): The select
+ *   cl (*trace.TraceElementChannel): The close of the other routine
+ * Returns:
+ *   string: The position of the close in the default case of sel
+ *   error: An error if the position could not be determined
+ */
+func secondClosePos(t *trace.Trace, sel *trace.TraceElementSelect, cl *trace.TraceElementChannel) (string, error) {
+	i := strings.LastIndex(sel.GetPos(), ":")
+	if i == -1 {
+		return "", errors.New("Invalid position of the select: " + sel.GetPos())
+	}
+	file := sel.GetPos()[:i]
+	line, err := strconv.Atoi(sel.GetPos()[i+1:])
+	if err != nil {
+		return "", err
+	}
+
+	lineClose, err := utils.FindCloseInDefaultCase(file, line)
+	if err == nil {
+		return file + ":" + strconv.Itoa(lineClose), nil
+	}
+	if err == utils.ErrNoCloseInDefaultCase {
+		return "", err
+	}
+
+	// without the code, c is the same close, if the routine of c chose the
+	// default case of the same select before c
+	for _, elem := range t.GetTraceFromId(cl.GetRoutine()) {
+		if elem.GetTSort() >= cl.GetTSort() {
+			break
+		}
+		if guard, ok := elem.(*trace.TraceElementSelect); ok &&
+			guard.GetPos() == sel.GetPos() && guard.GetChosenDefault() {
+			return cl.GetPos(), nil
+		}
+	}
+
+	return "", errors.New("Could not determine the close in the default case of the select at " + sel.GetPos())
+}
@@ -0,0 +1,84 @@
+package rewriter
+
+import (
+	"analyzer/bugs"
+	"analyzer/trace"
+	"errors"
+	"math"
+)
+
+/*
+ * Create a new trace for two concurrent receives on the same channel, where
+ * the receives are swapped, so that the other routine receives the message.
+ * Let r1 be the earlier receive, s its partner, r2 the later receive, X' a
+ * stop marker and T1, T2, T3 partial traces.
+ * The trace before the rewrite looks as follows:
+ * 	T1 ++ [s, r1] ++ T2 ++ [r2] ++ T3
+ * We remove r1 and move r2 directly after s and its other HB-earlier elements.
+ * All elements, that are concurrent or HB-later than r2 and after s are
+ * moved after r2. Because r1 and r2 are concurrent, this includes the elements
+ * of the routine of r1 after r1:
+ * 	T1 ++ [s] ++ T2' ++ [r2, X'] ++ T3'
+ * In the replay, the partner of a receive is determined by its oId. r2, or
+ * the chosen case if r2 is a select, therefore gets the oId of r1.
+ * Args:
+ *   t (*trace.Trace): The trace to rewrite
+ *   bug (Bug): The bug to create a trace for
+ * Returns:
+ *   error: An error if the trace could not be created
+ */
+func rewriteConcurrentRecv(t *trace.Trace, bug bugs.Bug) error {
+	t.Println("Start rewriting trace for concurrent receive...")
+
+	if len(bug.TraceElement1) == 0 || bug.TraceElement1[0] == nil {
+		return errors.New("TraceElement1 is nil")
+	}
+	if len(bug.TraceElement2) == 0 || bug.TraceElement2[0] == nil {
+		return errors.New("TraceElement2 is nil")
+	}
+
+	r1 := bug.TraceElement1[0]
+	r2 := bug.TraceElement2[0]
+	if (*r1).GetTSort() > (*r2).GetTSort() {
+		r1, r2 = r2, r1
+	}
+
+	if (*r1).GetTSort() == math.MaxInt || (*r2).GetTSort() == math.MaxInt {
+		return errors.New("One of the receives did not finish. Cannot rewrite trace.")
+	}
+
+	// get the partner s of r1
+	var partner *trace.TraceElementChannel
+	switch r := (*r1).(type) {
+	case *trace.TraceElementChannel:
+		partner = r.GetPartner()
+	case *trace.TraceElementSelect:
+		partner = r.GetPartner()
+	}
+
+	start := (*r1).GetTPre()
+	if partner != nil {
+		start = partner.GetTSort()
+
+		// r2 takes the place of r1 as the partner of s
+		switch r := (*r2).(type) {
+		case *trace.TraceElementChannel:
+			r.SetOID(partner.GetOID())
+		case *trace.TraceElementSelect:
+			if err := r.SetChosenOID(partner.GetOID()); err != nil {
+				return err
+			}
+		}
+	}
+
+	// remove r1 -> T1 ++ [s] ++ T2 ++ [r2] ++ T3
+	t.RemoveElementFromTrace((*r1).GetTID())
+
+	// move r2 after s -> T1 ++ [s] ++ T2' ++ [r2] ++ T3'
+	t.MoveAfterTime(r2, start)
+
+	// add a stop marker -> T1 ++ [s] ++ T2' ++ [r2, X'] ++ T3'
+	t.AddTraceElementReplay((*r2).GetTSort()+1, exitConcurrentRecv)
+
+	return nil
+}
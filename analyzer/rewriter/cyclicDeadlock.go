@@ -52,7 +52,7 @@ import (
  * end()
  */
 
-func rewriteCyclicDeadlock(bug bugs.Bug) error {
+func rewriteCyclicDeadlock(t *trace.Trace, bug bugs.Bug) error {
 	firstTime := -1
 	lastTime := -1
 
@@ -72,7 +72,7 @@ func rewriteCyclicDeadlock(bug bugs.Bug) error {
 	}
 
 	// remove tail after lastTime
-	trace.ShortenTrace(lastTime, true)
+	t.ShortenTrace(lastTime, true)
 
 	routinesInCycle := make(map[int]struct{})
 
@@ -93,7 +93,7 @@ func rewriteCyclicDeadlock(bug bugs.Bug) error {
 			}
 
 			// shift the routine of elem1 so that elem 2 is before elem1
-			res := trace.ShiftRoutine((*elem1).GetRoutine(), (*elem1).GetTPre(), (*elem2).GetTPre()-(*elem1).GetTPre()+1)
+			res := t.ShiftRoutine((*elem1).GetRoutine(), (*elem1).GetTPre(), (*elem2).GetTPre()-(*elem1).GetTPre()+1)
 
 			if res {
 				found = true
@@ -105,7 +105,7 @@ func rewriteCyclicDeadlock(bug bugs.Bug) error {
 		}
 	}
 
-	currentTrace := trace.GetTraces()
+	currentTrace := t.GetTraces()
 	lastTime = -1
 
 	for routine := range routinesInCycle {
@@ -115,7 +115,7 @@ func rewriteCyclicDeadlock(bug bugs.Bug) error {
 			switch elem := elem.(type) {
 			case *trace.TraceElementMutex:
 				if (*elem).IsLock() {
-					trace.ShortenRoutineIndex(routine, i, true)
+					t.ShortenRoutineIndex(routine, i, true)
 					if lastTime == -1 || (*elem).GetTSort() > lastTime {
 						lastTime = (*elem).GetTSort()
 					}
@@ -129,7 +129,7 @@ func rewriteCyclicDeadlock(bug bugs.Bug) error {
 	}
 
 	// add start and end signal
-	trace.AddTraceElementReplay(lastTime+1, exitCodeCyclic)
+	t.AddTraceElementReplay(lastTime+1, exitCodeCyclic)
 
 	return nil
 }
@@ -27,11 +27,12 @@ import (
 /*
  * Rewrite a trace where a leaking unbuffered channel/select with possible partner was found.
  * Args:
+ *   t (*trace.Trace): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteUnbufChanLeak(bug bugs.Bug) error {
+func rewriteUnbufChanLeak(t *trace.Trace, bug bugs.Bug) error {
 	// check if one or both of the bug elements are select
 	t1Sel := false
 	t2Sel := false
@@ -45,24 +46,25 @@ func rewriteUnbufChanLeak(bug bugs.Bug) error {
 	}
 
 	if !t1Sel && !t2Sel { // both are channel operations
-		return rewriteUnbufChanLeakChanChan(bug)
+		return rewriteUnbufChanLeakChanChan(t, bug)
 	} else if !t1Sel && t2Sel { // first is channel operation, second is select
-		return rewriteUnbufChanLeakChanSel(bug)
+		return rewriteUnbufChanLeakChanSel(t, bug)
 	} else if t1Sel && !t2Sel { // first is select, second is channel operation
-		return rewriteUnbufChanLeakSelChan(bug)
+		return rewriteUnbufChanLeakSelChan(t, bug)
 	} // both are select
-	return rewriteUnbufChanLeakSelSel(bug)
+	return rewriteUnbufChanLeakSelSel(t, bug)
 }
 
 /*
  * Rewrite a trace where a leaking unbuffered channel/select with possible partner was found
  * if both elements are channel operations.
  * Args:
+ *   t (*trace.Trace): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteUnbufChanLeakChanChan(bug bugs.Bug) error {
+func rewriteUnbufChanLeakChanChan(t *trace.Trace, bug bugs.Bug) error {
 	stuck := (*bug.TraceElement1[0]).(*trace.TraceElementChannel)
 	possiblePartner := (*bug.TraceElement2[0]).(*trace.TraceElementChannel)
 	possiblePartnerPartner := possiblePartner.GetPartner()
@@ -79,39 +81,39 @@ func rewriteUnbufChanLeakChanChan(bug bugs.Bug) error {
 
 	// remove the potential partner partner from the trace
 	if possiblePartnerPartner != nil {
-		trace.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
+		t.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
 	}
 
 	// T = T1 ++ [f] ++ T2 ++ T3 ++ [e]
 
 	if stuck.Operation() == trace.Recv { // Case 3
-		trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
+		t.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
 
 		// T = T1 ++ [f] ++ T2' ++ T3' ++ [e]
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 
 		// add replay signals
-		trace.AddTraceElementReplay(stuck.GetTSort()+1, exitCodeLeakUnbuf)
+		t.AddTraceElementReplay(stuck.GetTSort()+1, exitCodeLeakUnbuf)
 
 	} else { // Case 4
 		if possiblePartnerPartner != nil {
-			trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartnerPartner.GetTSort()) // bug.TraceElement1[0] = stuck
+			t.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartnerPartner.GetTSort()) // bug.TraceElement1[0] = stuck
 		} else {
-			trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], 0) // bug.TraceElement1[0] = stuck
+			t.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], 0) // bug.TraceElement1[0] = stuck
 		}
 
 		// T = T1 ++ T2' ++ T3' ++ [e] ++ T4 ++ [f]
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 		// and T4 = [h in T4 | h >= e]
 
-		trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement2[0], stuck.GetTSort()) // bug.TraceElement2[0] = possiblePartner
+		t.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement2[0], stuck.GetTSort()) // bug.TraceElement2[0] = possiblePartner
 
 		// T = T1 ++ T2' ++ T3' ++ [e] ++ T4' ++ [f]
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 		// and T4' = [h in T4 | h >= e and h < f]
 
 		// add replay signal
-		trace.AddTraceElementReplay(possiblePartner.GetTSort()+1, exitCodeLeakUnbuf)
+		t.AddTraceElementReplay(possiblePartner.GetTSort()+1, exitCodeLeakUnbuf)
 	}
 
 	return nil
@@ -121,11 +123,12 @@ func rewriteUnbufChanLeakChanChan(bug bugs.Bug) error {
  * Rewrite a trace where a leaking unbuffered channel/select with possible partner was found
  * if both elements are channel operations.
  * Args:
+ *   t (*trace.Trace): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteUnbufChanLeakChanSel(bug bugs.Bug) error {
+func rewriteUnbufChanLeakChanSel(t *trace.Trace, bug bugs.Bug) error {
 	stuck := (*bug.TraceElement1[0]).(*trace.TraceElementChannel)
 	possiblePartner := (*bug.TraceElement2[0]).(*trace.TraceElementSelect)
 	possiblePartnerPartner := possiblePartner.GetPartner()
@@ -142,39 +145,39 @@ func rewriteUnbufChanLeakChanSel(bug bugs.Bug) error {
 
 	// remove the potential partner partner from the trace
 	if possiblePartnerPartner != nil {
-		trace.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
+		t.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
 	}
 
 	// T = T1 ++ [f] ++ T2 ++ T3 ++ [e]
 
 	if stuck.Operation() == trace.Recv { // Case 3
-		trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
+		t.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
 
 		// T = T1 ++ [f] ++ T2' ++ T3' ++ [e]
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 
 		// add replay signal
-		trace.AddTraceElementReplay(stuck.GetTSort()+1, exitCodeLeakUnbuf)
+		t.AddTraceElementReplay(stuck.GetTSort()+1, exitCodeLeakUnbuf)
 
 	} else { // Case 4
 		if possiblePartnerPartner != nil {
-			trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartnerPartner.GetTSort()) // bug.TraceElement1[0] = stuck
+			t.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartnerPartner.GetTSort()) // bug.TraceElement1[0] = stuck
 		} else {
-			trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], 0) // bug.TraceElement1[0] = stuck
+			t.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], 0) // bug.TraceElement1[0] = stuck
 		}
 
 		// T = T1 ++ T2' ++ T3' ++ [e] ++ T4 ++ [f]
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 		// and T4 = [h in T4 | h >= e]
 
-		trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement2[0], stuck.GetTSort()) // bug.TraceElement2[0] = possiblePartner
+		t.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement2[0], stuck.GetTSort()) // bug.TraceElement2[0] = possiblePartner
 
 		// T = T1 ++ T2' ++ T3' ++ [e] ++ T4' ++ [f]
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 		// and T4' = [h in T4 | h >= e and h < f]
 
 		// add replay signal
-		trace.AddTraceElementReplay(possiblePartner.GetTSort()+1, exitCodeLeakUnbuf)
+		t.AddTraceElementReplay(possiblePartner.GetTSort()+1, exitCodeLeakUnbuf)
 	}
 
 	return nil
@@ -184,11 +187,12 @@ func rewriteUnbufChanLeakChanSel(bug bugs.Bug) error {
  * Rewrite a trace where a leaking unbuffered channel/select with possible partner was found
  * if both elements are channel operations.
  * Args:
+ *   t (*trace.Trace): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteUnbufChanLeakSelChan(bug bugs.Bug) error {
+func rewriteUnbufChanLeakSelChan(t *trace.Trace, bug bugs.Bug) error {
 	stuck := (*bug.TraceElement1[0]).(*trace.TraceElementSelect)
 	possiblePartner := (*bug.TraceElement2[0]).(*trace.TraceElementChannel)
 	possiblePartnerPartner := possiblePartner.GetPartner()
@@ -205,38 +209,38 @@ func rewriteUnbufChanLeakSelChan(bug bugs.Bug) error {
 
 	// remove the potential partner partner from the trace
 	if possiblePartnerPartner != nil {
-		trace.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
+		t.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
 	}
 
 	// T = T1 ++ [f] ++ T2 ++ T3 ++ [e]
 
 	if possiblePartner.Operation() == trace.Recv {
 		if possiblePartnerPartner != nil {
-			trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartnerPartner.GetTSort()) // bug.TraceElement1[0] = stuck
+			t.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartnerPartner.GetTSort()) // bug.TraceElement1[0] = stuck
 		} else {
-			trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], 0) // bug.TraceElement1[0] = stuck
+			t.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], 0) // bug.TraceElement1[0] = stuck
 		}
 
 		// T = T1 ++ T2' ++ T3' ++ [e] ++ T4 ++ [f]
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 		// and T4 = [h in T4 | h >= e]
 
-		trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement2[0], stuck.GetTSort()) // bug.TraceElement2[0] = possiblePartner
+		t.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement2[0], stuck.GetTSort()) // bug.TraceElement2[0] = possiblePartner
 
 		// T = T1 ++ T2' ++ T3' ++ [e] ++ T4' ++ [f]
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 		// and T4' = [h in T4 | h >= e and h < f]
 		// add replay signals
-		trace.AddTraceElementReplay(possiblePartner.GetTSort()+1, exitCodeLeakUnbuf)
+		t.AddTraceElementReplay(possiblePartner.GetTSort()+1, exitCodeLeakUnbuf)
 
 	} else { // Case 3
-		trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
+		t.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
 
 		// T = T1 ++ [f] ++ T2' ++ T3' ++ [e]
 		// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 
 		// add replay signal
-		trace.AddTraceElementReplay(stuck.GetTSort()+1, exitCodeLeakUnbuf)
+		t.AddTraceElementReplay(stuck.GetTSort()+1, exitCodeLeakUnbuf)
 
 	}
 
@@ -247,11 +251,12 @@ func rewriteUnbufChanLeakSelChan(bug bugs.Bug) error {
  * Rewrite a trace where a leaking unbuffered channel/select with possible partner was found
  * if both elements are channel operations.
  * Args:
+ *   t (*trace.Trace): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteUnbufChanLeakSelSel(bug bugs.Bug) error {
+func rewriteUnbufChanLeakSelSel(t *trace.Trace, bug bugs.Bug) error {
 	stuck := (*bug.TraceElement1[0]).(*trace.TraceElementSelect)
 	possiblePartner := (*bug.TraceElement2[0]).(*trace.TraceElementSelect)
 	possiblePartnerPartner := possiblePartner.GetPartner()
@@ -268,7 +273,7 @@ func rewriteUnbufChanLeakSelSel(bug bugs.Bug) error {
 
 	// remove the potential partner partner from the trace
 	if possiblePartnerPartner != nil {
-		trace.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
+		t.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
 	}
 
 	// find communication
@@ -285,31 +290,31 @@ func rewriteUnbufChanLeakSelSel(bug bugs.Bug) error {
 			// T = T1 ++ [f] ++ T2 ++ T3 ++ [e]
 
 			if c.Operation() == trace.Recv { // Case 3
-				trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
+				t.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
 
 				// T = T1 ++ [f] ++ T2' ++ T3' ++ [e]
 				// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 
 				// add replay signal
-				trace.AddTraceElementReplay(stuck.GetTSort()+1, exitCodeLeakUnbuf)
+				t.AddTraceElementReplay(stuck.GetTSort()+1, exitCodeLeakUnbuf)
 				return nil
 			}
 
 			// Case 4
-			trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
+			t.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement1[0], possiblePartner.GetTSort()) // bug.TraceElement1[0] = stuck
 
 			// T = T1 ++ T2' ++ T3' ++ [e] ++ T4 ++ [f]
 			// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 			// and T4 = [h in T4 | h >= e]
 
-			trace.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement2[0], stuck.GetTSort()) // bug.TraceElement2[0] = possiblePartner
+			t.ShiftConcurrentOrAfterToAfterStartingFromElement(bug.TraceElement2[0], stuck.GetTSort()) // bug.TraceElement2[0] = possiblePartner
 
 			// T = T1 ++ T2' ++ T3' ++ [e] ++ T4' ++ [f]
 			// where T2' = [h in T2 | h < e] and T3' = [h in T3 | h < e]
 			// and T4' = [h in T4 | h >= e and h < f]
 
 			// add replay signals
-			trace.AddTraceElementReplay(possiblePartner.GetTSort()+1, exitCodeLeakUnbuf)
+			t.AddTraceElementReplay(possiblePartner.GetTSort()+1, exitCodeLeakUnbuf)
 
 			return nil
 		}
@@ -321,11 +326,12 @@ func rewriteUnbufChanLeakSelSel(bug bugs.Bug) error {
 /*
  * Rewrite a trace for a leaking buffered channel
  * Args:
+ *   t (*trace.Trace): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteBufChanLeak(bug bugs.Bug) error {
+func rewriteBufChanLeak(t *trace.Trace, bug bugs.Bug) error {
 	stuck := (*bug.TraceElement1[0])
 	possiblePartner := (*bug.TraceElement2[0])
 	var possiblePartnerPartner *trace.TraceElementChannel
@@ -348,20 +354,20 @@ func rewriteBufChanLeak(bug bugs.Bug) error {
 
 	// T = T1 ++ [g] ++ T2 ++ [e]
 	if possiblePartnerPartner != nil {
-		trace.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
+		t.RemoveElementFromTrace(possiblePartnerPartner.GetTID())
 	}
 
 	// T = T1 ++ T2 ++ [e]
 
-	trace.ShiftConcurrentOrAfterToAfterStartingFromElement(&stuck, possiblePartnerPartner.GetTSort())
+	t.ShiftConcurrentOrAfterToAfterStartingFromElement(&stuck, possiblePartnerPartner.GetTSort())
 
 	// T = T1 ++ T2' ++ [e]
 	// where T2' = [ h | h in T2 and h <HB e]
 
 	if possiblePartner.GetTSort() < stuck.GetTSort() {
-		trace.AddTraceElementReplay(stuck.GetTSort()+1, exitCodeLeakBuf)
+		t.AddTraceElementReplay(stuck.GetTSort()+1, exitCodeLeakBuf)
 	} else {
-		trace.AddTraceElementReplay(possiblePartner.GetTSort()+1, exitCodeLeakBuf)
+		t.AddTraceElementReplay(possiblePartner.GetTSort()+1, exitCodeLeakBuf)
 	}
 
 	return nil
@@ -385,12 +391,13 @@ func rewriteBufChanLeak(bug bugs.Bug) error {
  * are before (HB) l, X_s is the start and X_e is the stop signal, that releases the program from the
  * guided replay.
  * Args:
+ *   t (*trace.Trace): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteMutexLeak(bug bugs.Bug) error {
-	println("Start rewriting trace for mutex leak...")
+func rewriteMutexLeak(t *trace.Trace, bug bugs.Bug) error {
+	t.Println("Start rewriting trace for mutex leak...")
 
 	// get l and l'
 	lockOp := (*bug.TraceElement1[0]).(*trace.TraceElementMutex)
@@ -402,17 +409,17 @@ func rewriteMutexLeak(bug bugs.Bug) error {
 	}
 
 	// remove T_3 -> T_1 + [l'] + T_2 + [l]
-	trace.ShortenTrace(lockOp.GetTSort(), true)
+	t.ShortenTrace(lockOp.GetTSort(), true)
 
 	// remove all elements, that are concurrent with l. This includes l'
 	// -> T_1' + T_2' + [l]
-	trace.RemoveConcurrent(bug.TraceElement1[0], 0)
+	t.RemoveConcurrent(bug.TraceElement1[0], 0)
 
 	// set tpost of l to non zero
 	lockOp.SetT(lockOp.GetTPre())
 
 	// add the start and stop signal after l -> T_1' + T_2' + [X_s, l, X_e]
-	trace.AddTraceElementReplay(lockOp.GetTPre()+1, exitCodeLeakMutex)
+	t.AddTraceElementReplay(lockOp.GetTPre()+1, exitCodeLeakMutex)
 
 	return nil
 }
@@ -423,20 +430,21 @@ func rewriteMutexLeak(bug bugs.Bug) error {
 /*
  * Rewrite a trace where a leaking waitgroup was found.
  * Args:
+ *   t (*trace.Trace): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteWaitGroupLeak(bug bugs.Bug) error {
-	println("Start rewriting trace for waitgroup leak...")
+func rewriteWaitGroupLeak(t *trace.Trace, bug bugs.Bug) error {
+	t.Println("Start rewriting trace for waitgroup leak...")
 
 	wait := bug.TraceElement1[0]
 
-	trace.ShiftConcurrentOrAfterToAfter(wait)
+	t.ShiftConcurrentOrAfterToAfter(wait)
 
-	trace.AddTraceElementReplay((*wait).GetTPre()+1, exitCodeLeakWG)
+	t.AddTraceElementReplay((*wait).GetTPre()+1, exitCodeLeakWG)
 
-	nrAdd, nrDone := trace.GetNrAddDoneBeforeTime((*wait).GetID(), (*wait).GetTSort())
+	nrAdd, nrDone := t.GetNrAddDoneBeforeTime((*wait).GetID(), (*wait).GetTSort())
 
 	if nrAdd != nrDone {
 		return errors.New("The waitgroup is not balanced. Cannot rewrite trace.")
@@ -451,18 +459,19 @@ func rewriteWaitGroupLeak(bug bugs.Bug) error {
 /*
  * Rewrite a trace where a leaking cond was found.
  * Args:
+ *   t (*trace.Trace): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   error: An error if the trace could not be created
  */
-func rewriteCondLeak(bug bugs.Bug) error {
-	println("Start rewriting trace for cond leak...")
+func rewriteCondLeak(t *trace.Trace, bug bugs.Bug) error {
+	t.Println("Start rewriting trace for cond leak...")
 
 	couldRewrite := false
 
 	wait := bug.TraceElement1[0]
 
-	res := trace.GetConcurrentWaitgroups(wait)
+	res := t.GetConcurrentWaitgroups(wait)
 
 	// possible signals to release the wait
 	if len(res["signal"]) > 0 {
@@ -471,7 +480,7 @@ func rewriteCondLeak(bug bugs.Bug) error {
 		(*wait).SetT((*wait).GetTPre())
 
 		// move the signal after the wait
-		trace.ShiftConcurrentOrAfterToAfter(wait)
+		t.ShiftConcurrentOrAfterToAfter(wait)
 
 		// TODO: Problem: locks create a happens before relation -> currently only works with -c
 	}
@@ -479,12 +488,12 @@ func rewriteCondLeak(bug bugs.Bug) error {
 	// possible broadcasts to release the wait
 	for _, broad := range res["broadcast"] {
 		couldRewrite = true
-		trace.ShiftConcurrentToBefore(broad)
+		t.ShiftConcurrentToBefore(broad)
 	}
 
 	(*wait).SetT((*wait).GetTPre())
 
-	trace.AddTraceElementReplay((*wait).GetTPre()+1, exitCodeLeakCond)
+	t.AddTraceElementReplay((*wait).GetTPre()+1, exitCodeLeakCond)
 
 	if couldRewrite {
 		return nil
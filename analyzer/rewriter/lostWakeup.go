@@ -0,0 +1,59 @@
+package rewriter
+
+import (
+	"analyzer/bugs"
+	"analyzer/trace"
+	"errors"
+)
+
+/*
+ * Create a new trace for a lost wakeup on a conditional variable.
+ * Let s be the signal or broadcast, that was executed while no wait was
+ * waiting, w the concurrent wait, X' a stop marker and T1, T2, T3 partial
+ * traces.
+ * The trace before the rewrite looks as follows:
+ * 	T1 ++ [s] ++ T2 ++ [w] ++ T3
+ * where w is sorted by its tpost. We move w to its tpre, so that it directly
+ * follows all elements, that were executed before the wait started, and
+ * remove all elements after w:
+ * 	T1 ++ [s] ++ T2' ++ [w, X']
+ * The signal is therefore executed before the wait. If w starts waiting and
+ * the next element is the stop marker, the wakeup was lost and the replay ends
+ * with the exit code of the stop marker.
+ * Args:
+ *   t (*trace.Trace): The trace to rewrite
+ *   bug (Bug): The bug to create a trace for
+ * Returns:
+ *   error: An error if the trace could not be created
+ */
+func rewriteLostWakeup(t *trace.Trace, bug bugs.Bug) error {
+	t.Println("Start rewriting trace for lost wakeup...")
+
+	if len(bug.TraceElement1) == 0 || bug.TraceElement1[0] == nil {
+		return errors.New("TraceElement1 is nil")
+	}
+	if len(bug.TraceElement2) == 0 || bug.TraceElement2[0] == nil {
+		return errors.New("TraceElement2 is nil")
+	}
+
+	signal := bug.TraceElement1[0]
+	wait := bug.TraceElement2[0]
+
+	tWait := (*wait).GetTPre()
+	if tWait < (*signal).GetTPre() {
+		return errors.New("The wait started before the signal. Cannot rewrite trace.")
+	}
+
+	// remove w and T3 -> T1 ++ [s] ++ T2'
+	t.ShortenTrace(tWait, false)
+	t.RemoveElementFromTrace((*wait).GetTID())
+
+	// T1 ++ [s] ++ T2' ++ [w]
+	(*wait).SetT(tWait)
+	t.AddElementToTrace(*wait)
+
+	// add a stop marker -> T1 ++ [s] ++ T2' ++ [w, X']
+	t.AddTraceElementReplay(tWait+1, exitLostWakeup)
+
+	return nil
+}
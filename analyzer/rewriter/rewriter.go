@@ -8,32 +8,41 @@ import (
 )
 
 const (
-	exitCodeNone           = -1
-	exitCodeStuckFinish    = 10
-	exitCodeStuckWaitElem  = 11
-	exitCodeStuckNoElem    = 12
-	exitCodeElemEmptyTrace = 13
-	exitCodeLeakUnbuf      = 20
-	exitCodeLeakBuf        = 21
-	exitCodeLeakMutex      = 22
-	exitCodeLeakCond       = 23
-	exitCodeLeakWG         = 24
-	exitSendClose          = 30
-	exitRecvClose          = 31
-	exitNegativeWG         = 32
-	exitCodeCyclic         = 41
+	exitCodeNone             = -1
+	exitCodeStuckFinish      = 10
+	exitCodeStuckWaitElem    = 11
+	exitCodeStuckNoElem      = 12
+	exitCodeElemEmptyTrace   = 13
+	exitCodeReplayTimeout    = 14
+	exitCodeLeakUnbuf        = 20
+	exitCodeLeakBuf          = 21
+	exitCodeLeakMutex        = 22
+	exitCodeLeakCond         = 23
+	exitCodeLeakWG           = 24
+	exitSendClose            = 30
+	exitRecvClose            = 31
+	exitNegativeWG           = 32
+	exitCloseClose           = 33
+	exitConcurrentRecv       = 34
+	exitSelectWithoutPartner = 35
+	exitAddConcurrentWait    = 36
+	exitWaitGroupReuse       = 37
+	exitLostWakeup           = 38
+	exitAtomicityViolation   = 39
+	exitCodeCyclic           = 41
 )
 
 /*
  * Create a new trace from the given bug
  * Args:
+ *   t (*trace.Trace): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  * Returns:
  *   bool: true if rewrite was needed, false otherwise (e.g. actual bug, warning)
  *
  *   error: An error if the trace could not be created
  */
-func RewriteTrace(bug bugs.Bug) (rewriteNeeded bool, code int, err error) {
+func RewriteTrace(t *trace.Trace, bug bugs.Bug) (rewriteNeeded bool, code int, err error) {
 	rewriteNeeded = false
 	code = exitCodeNone
 	switch bug.Type {
@@ -44,37 +53,71 @@ func RewriteTrace(bug bugs.Bug) (rewriteNeeded bool, code int, err error) {
 	case bugs.ACloseOnClosed:
 		err = errors.New("Only actual close on close can be detected. Therefor no rewrite is needed.")
 	case bugs.AConcurrentRecv:
-		err = errors.New("Rewriting trace for concurrent receive is not possible")
+		code = exitConcurrentRecv
+		rewriteNeeded = true
+		err = rewriteConcurrentRecv(t, bug)
 	case bugs.ASelCaseWithoutPartner:
-		err = errors.New("Rewriting trace for select without partner is not possible")
+		code = exitSelectWithoutPartner
+		rewriteNeeded = true
+		err = rewriteSelectWithoutPartner(t, bug)
+	case bugs.APartialDeadlock:
+		err = errors.New("Actual partial deadlock in trace. Therefore no rewrite is needed.")
+	case bugs.ARecursiveDo:
+		err = errors.New("Actual recursive Do in trace. Therefore no rewrite is needed.")
+	case bugs.ADoPanic:
+		err = errors.New("Actual panic in Do in trace. Therefore no rewrite is needed.")
 	case bugs.PSendOnClosed:
 		code = exitSendClose
 		rewriteNeeded = true
-		err = rewriteClosedChannel(bug, exitSendClose)
+		err = rewriteClosedChannel(t, bug, exitSendClose)
 	case bugs.PRecvOnClosed:
 		code = exitRecvClose
 		rewriteNeeded = true
-		err = rewriteClosedChannel(bug, exitRecvClose)
+		err = rewriteClosedChannel(t, bug, exitRecvClose)
 	case bugs.PNegWG:
 		code = exitNegativeWG
 		rewriteNeeded = true
-		err = rewriteWaitGroup(bug)
+		err = rewriteWaitGroup(t, bug)
+	case bugs.PCloseOnClosed:
+		code = exitCloseClose
+		rewriteNeeded = true
+		err = rewriteCloseOnClosed(t, bug)
+	case bugs.PAddConcurrentWait:
+		code = exitAddConcurrentWait
+		rewriteNeeded = true
+		err = rewriteAddConcurrentWait(t, bug)
+	case bugs.PWaitGroupReuse:
+		code = exitWaitGroupReuse
+		rewriteNeeded = true
+		err = rewriteWaitGroupReuse(t, bug)
+	case bugs.PLostWakeup:
+		code = exitLostWakeup
+		rewriteNeeded = true
+		err = rewriteLostWakeup(t, bug)
+	case bugs.PAtomicityViolation:
+		code = exitAtomicityViolation
+		rewriteNeeded = true
+		err = rewriteAtomicityViolation(t, bug)
+	case bugs.PDataRace:
+		err = errors.New("Memory accesses are not ordered by the replay. Therefore the trace for a data race is not rewritten.")
+	case bugs.WBlockInCriticalSection:
+		err = errors.New("A blocking operation in a critical section is a warning. Therefore no rewrite is needed.")
 	// case bugs.MixedDeadlock:
 	// 	err = errors.New("Rewriting trace for mixed deadlock is not implemented yet")
 	// case bugs.CyclicDeadlock:
 	// 	rewriteNeeded = true
-	// err = rewriteCyclicDeadlock(bug)
+	// err = rewriteCyclicDeadlock(t, bug)
 
 	case bugs.LUnbufferedWith:
 		code = exitCodeLeakUnbuf
 		rewriteNeeded = true
-		err = rewriteUnbufChanLeak(bug)
+		err = rewriteUnbufChanLeak(t, bug)
 	case bugs.LUnbufferedWithout:
 		err = errors.New("No possible partner for stuck channel found. Cannot rewrite trace.")
 	case bugs.LBufferedWith:
 		code = exitCodeLeakBuf
 		rewriteNeeded = true
-		err = rewriteBufChanLeak(bug)
+		err = rewriteBufChanLeak(t, bug)
 	case bugs.LBufferedWithout:
 		err = errors.New("No possible partner for stuck channel found. Cannot rewrite trace.")
 	case bugs.LNilChan:
@@ -84,12 +127,12 @@ func RewriteTrace(bug bugs.Bug) (rewriteNeeded bool, code int, err error) {
 		rewriteNeeded = true
 		switch b := (*bug.TraceElement2[0]).(type) {
 		case *trace.TraceElementSelect:
-			err = rewriteUnbufChanLeak(bug)
+			err = rewriteUnbufChanLeak(t, bug)
 		case *trace.TraceElementChannel:
 			if b.IsBuffered() {
-				err = rewriteBufChanLeak(bug)
+				err = rewriteBufChanLeak(t, bug)
 			} else {
-				err = rewriteUnbufChanLeak(bug)
+				err = rewriteUnbufChanLeak(t, bug)
 			}
 		default:
 			rewriteNeeded = false
@@ -97,25 +140,26 @@ func RewriteTrace(bug bugs.Bug) (rewriteNeeded bool, code int, err error) {
 			err = errors.New("For the given bug type no trace rewriting is possible")
 		}
 	case bugs.LSelectWithout:
-		code = exitCodeNone
-		err = errors.New("No possible partner for stuck select found. Cannot rewrite trace.")
+		code = exitSelectWithoutPartner
+		rewriteNeeded = true
+		err = rewriteSelectWithoutPartner(t, bug)
 	case bugs.LMutex:
 		rewriteNeeded = true
 		code = exitCodeLeakMutex
-		err = rewriteMutexLeak(bug)
+		err = rewriteMutexLeak(t, bug)
 	case bugs.LWaitGroup:
 		rewriteNeeded = true
 		code = exitCodeLeakWG
-		err = rewriteWaitGroupLeak(bug)
+		err = rewriteWaitGroupLeak(t, bug)
 	case bugs.LCond:
 		rewriteNeeded = true
 		code = exitCodeLeakCond
-		err = rewriteCondLeak(bug)
+		err = rewriteCondLeak(t, bug)
 	default:
 		err = errors.New("For the given bug type no trace rewriting is implemented")
 	}
 	if rewriteNeeded && err != nil {
-		println("Error rewriting trace")
+		t.Println("Error rewriting trace")
 	}
 	return rewriteNeeded, code, err
 }
@@ -0,0 +1,71 @@
+package rewriter
+
+import (
+	"analyzer/bugs"
+	"analyzer/trace"
+	"errors"
+)
+
+/*
+ * Create a new trace for a select with a case without a possible partner.
+ * The select is forced to execute the case without partner, to confirm, that
+ * the select blocks. This is used for select cases without partner and for
+ * leaking selects without partner. Let s be the select, X' a stop marker and
+ * T1, T2 partial traces.
+ * The trace before the rewrite looks as follows:
+ * 	T1 ++ [s] ++ T2
+ * We remove s and T2 and add s', the select with the case without partner as
+ * the chosen case:
+ * 	T1 ++ [s', X']
+ * When the select blocks on the case and the next element is the stop marker,
+ * the replay ends with the exit code of the stop marker.
+ * Args:
+ *   t (*trace.Trace): The trace to rewrite
+ *   bug (Bug): The bug to create a trace for
+ * Returns:
+ *   error: An error if the trace could not be created
+ */
+func rewriteSelectWithoutPartner(t *trace.Trace, bug bugs.Bug) error {
+	t.Println("Start rewriting trace for select without partner...")
+
+	if len(bug.TraceElement1) == 0 || bug.TraceElement1[0] == nil {
+		return errors.New("TraceElement1 is nil")
+	}
+
+	sel, ok := (*bug.TraceElement1[0]).(*trace.TraceElementSelect)
+	if !ok {
+		return errors.New("TraceElement1 is not a select")
+	}
+
+	if sel.ContainsDefault() {
+		return errors.New("The select contains a default case and cannot block. Cannot rewrite trace.")
+	}
+
+	// the case without partner, for a leak all cases are without partner
+	chanID, send := 0, false
+	if len(bug.SelectCases) != 0 {
+		chanID, send = bug.SelectCases[0].ObjID, bug.SelectCases[0].Send
+	} else if len(sel.GetCases()) != 0 {
+		chanID, send = sel.GetCases()[0].GetID(), sel.GetCases()[0].Operation() == trace.Send
+	} else {
+		return errors.New("The select does not contain any cases")
+	}
+
+	tSel := sel.GetTPre()
+
+	// remove s and T2 -> T1
+	t.ShortenTrace(tSel, false)
+
+	// T1 ++ [s']
+	sel.SetT(tSel)
+	err := sel.SetCase(chanID, send)
+	if err != nil {
+		return err
+	}
+	t.AddElementToTrace(sel)
+
+	// add a stop marker -> T1 ++ [s', X']
+	t.AddTraceElementReplay(tSel+1, exitSelectWithoutPartner)
+
+	return nil
+}
@@ -3,15 +3,18 @@ package rewriter
 import (
 	"analyzer/bugs"
 	"analyzer/trace"
+	"errors"
+	"math"
 )
 
 /*
  * Create a new trace for a negative wait group counter (done before add)
  * Args:
+ *   t (*trace.Trace): The trace to rewrite
  *   bug (Bug): The bug to create a trace for
  */
-func rewriteWaitGroup(bug bugs.Bug) error {
-	println("Start rewriting trace for negative waitgroup counter...")
+func rewriteWaitGroup(t *trace.Trace, bug bugs.Bug) error {
+	t.Println("Start rewriting trace for negative waitgroup counter...")
 
 	minTime := -1
 	maxTime := -1
@@ -19,7 +22,7 @@ func rewriteWaitGroup(bug bugs.Bug) error {
 	for i := range bug.TraceElement1 {
 		elem2 := bug.TraceElement2[i] // done
 
-		trace.ShiftConcurrentOrAfterToAfter(elem2)
+		t.ShiftConcurrentOrAfterToAfter(elem2)
 
 		if minTime == -1 || (*elem2).GetTPre() < minTime {
 			minTime = (*elem2).GetTPre()
@@ -32,8 +35,106 @@ func rewriteWaitGroup(bug bugs.Bug) error {
 
 	// add start and end
 	if !(minTime == -1 && maxTime == -1) {
-		trace.AddTraceElementReplay(maxTime+1, exitNegativeWG)
+		t.AddTraceElementReplay(maxTime+1, exitNegativeWG)
 	}
 
 	return nil
 }
+
+/*
+ * Create a new trace for an add, that is concurrent with a wait while the
+ * counter may be zero. Let a be the add, w the wait, X' a stop marker and
+ * T1, T2, T3 partial traces.
+ * The trace before the rewrite looks as follows:
+ * 	T1 ++ [a] ++ T2 ++ [w] ++ T3
+ * We remove a and all elements, that are HB-later than a or w. The remaining
+ * elements T' are HB-before or concurrent to w. w is moved after them:
+ * 	T' ++ [w, X']
+ * If the counter is zero, w returns without waiting for a and the replay ends
+ * with the exit code of the stop marker.
+ * Args:
+ *   t (*trace.Trace): The trace to rewrite
+ *   bug (Bug): The bug to create a trace for
+ * Returns:
+ *   error: An error if the trace could not be created
+ */
+func rewriteAddConcurrentWait(t *trace.Trace, bug bugs.Bug) error {
+	t.Println("Start rewriting trace for add concurrent with wait...")
+
+	if len(bug.TraceElement1) == 0 || bug.TraceElement1[0] == nil {
+		return errors.New("TraceElement1 is nil")
+	}
+	if len(bug.TraceElement2) == 0 || bug.TraceElement2[0] == nil {
+		return errors.New("TraceElement2 is nil")
+	}
+
+	add := bug.TraceElement1[0]
+	wait := bug.TraceElement2[0]
+
+	// remove a, w and all elements HB-later than a or w -> T'
+	t.RemoveLater(wait, 0)
+	t.RemoveLater(add, 0)
+	t.RemoveElementFromTrace((*add).GetTID())
+	t.RemoveElementFromTrace((*wait).GetTID())
+
+	// T' ++ [w, X']
+	latest := t.GetLatestTSort()
+	(*wait).SetT(latest + 1)
+	t.AddElementToTrace(*wait)
+	t.AddTraceElementReplay(latest+2, exitAddConcurrentWait)
+
+	return nil
+}
+
+/*
+ * Create a new trace for a wait group, that is reused by an add before a
+ * previous wait has returned. Let a be the add, w the wait, X' a stop marker
+ * and T1, T2, T3 partial traces.
+ * The trace before the rewrite looks as follows:
+ * 	T1 ++ [w] ++ T2 ++ [a] ++ T3
+ * We remove all elements, that are HB-later than w or a. w is released at its
+ * tPre, so that it waits while the counter is decreased to zero. a is moved
+ * after all remaining elements T':
+ * 	T' ++ [a, X']
+ * w is woken up by the last done. The replay lets w wait after it was woken
+ * up until a has increased the counter, so that w finds the reused wait group
+ * and ends the replay with the exit code of the stop marker.
+ * Args:
+ *   t (*trace.Trace): The trace to rewrite
+ *   bug (Bug): The bug to create a trace for
+ * Returns:
+ *   error: An error if the trace could not be created
+ */
+func rewriteWaitGroupReuse(t *trace.Trace, bug bugs.Bug) error {
+	t.Println("Start rewriting trace for reuse of wait group...")
+
+	if len(bug.TraceElement1) == 0 || bug.TraceElement1[0] == nil {
+		return errors.New("TraceElement1 is nil")
+	}
+	if len(bug.TraceElement2) == 0 || bug.TraceElement2[0] == nil {
+		return errors.New("TraceElement2 is nil")
+	}
+
+	add := bug.TraceElement1[0]
+	wait := bug.TraceElement2[0]
+
+	if (*wait).GetTSort() == math.MaxInt {
+		return errors.New("The wait did not return. Cannot rewrite trace.")
+	}
+
+	// remove all elements HB-later than w or a, keep a -> T' ++ [a]
+	t.RemoveLater(wait, 0)
+	t.RemoveLater(add, 0)
+	t.RemoveElementFromTrace((*add).GetTID())
+
+	// release w at its tPre
+	(*wait).SetT((*wait).GetTPre())
+
+	// T' ++ [a, X']
+	latest := t.GetLatestTSort()
+	(*add).SetT(latest + 1)
+	t.AddElementToTrace(*add)
+	t.AddTraceElementReplay(latest+2, exitWaitGroupReuse)
+
+	return nil
+}
@@ -205,7 +205,8 @@ func parseTraceToCSV(tracePath string, statsPath string) error {
 			return nil
 		}
 
-		if filepath.Base(path) == "times.log" {
+		// ignore logs, that are not traces, e.g. times.log or routineLeaks.log
+		if _, err := getRoutineFromFileName(filepath.Base(path)); err != nil {
 			return nil
 		}
 		return parseTraceFile(path, res, known)
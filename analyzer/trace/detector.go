@@ -0,0 +1,98 @@
+package trace
+
+import (
+	"analyzer/clock"
+	"analyzer/logging"
+)
+
+/*
+ * Detector is a custom analysis that is run together with the built-in
+ * analysis scenarios. While the trace is traversed, the callback for the type
+ * of each element is called after the vector clocks have been updated for the
+ * element. The vector clocks passed to the callbacks are the current happens
+ * before vector clocks of all routines and must not be changed by the detector.
+ * After all elements have been processed, Finish is called.
+ * A detector should embed DetectorBase, so that it only needs to implement
+ * the callbacks it is interested in.
+ */
+type Detector interface {
+	// Name of the detector, used as the key in the analysis cases
+	Name() string
+
+	// Called once before the trace is traversed
+	Init(results *logging.Results, numberOfRoutines int)
+
+	Atomic(at *TraceElementAtomic, vc map[int]clock.VectorClock)
+	Channel(ch *TraceElementChannel, vc map[int]clock.VectorClock)
+	Mutex(mu *TraceElementMutex, vc map[int]clock.VectorClock)
+	Fork(fo *TraceElementFork, vc map[int]clock.VectorClock)
+	Select(se *TraceElementSelect, vc map[int]clock.VectorClock)
+	Wait(wa *TraceElementWait, vc map[int]clock.VectorClock)
+	Once(on *TraceElementOnce, vc map[int]clock.VectorClock)
+	Cond(co *TraceElementCond, vc map[int]clock.VectorClock)
+	Memory(me *TraceElementMemory, vc map[int]clock.VectorClock)
+	Panic(pa *TraceElementPanic, vc map[int]clock.VectorClock)
+
+	// Called once after all elements have been processed
+	Finish()
+}
+
+/*
+ * DetectorBase implements all callbacks of Detector without any effect
+ * Fields:
+ *   Results (*logging.Results): The results, the found bugs should be added to
+ *   NumberOfRoutines (int): The number of routines in the trace
+ */
+type DetectorBase struct {
+	Results          *logging.Results
+	NumberOfRoutines int
+}
+
+func (d *DetectorBase) Init(results *logging.Results, numberOfRoutines int) {
+	d.Results = results
+	d.NumberOfRoutines = numberOfRoutines
+}
+
+func (d *DetectorBase) Atomic(at *TraceElementAtomic, vc map[int]clock.VectorClock)   {}
+func (d *DetectorBase) Channel(ch *TraceElementChannel, vc map[int]clock.VectorClock) {}
+func (d *DetectorBase) Mutex(mu *TraceElementMutex, vc map[int]clock.VectorClock)     {}
+func (d *DetectorBase) Fork(fo *TraceElementFork, vc map[int]clock.VectorClock)       {}
+func (d *DetectorBase) Select(se *TraceElementSelect, vc map[int]clock.VectorClock)   {}
+func (d *DetectorBase) Wait(wa *TraceElementWait, vc map[int]clock.VectorClock)       {}
+func (d *DetectorBase) Once(on *TraceElementOnce, vc map[int]clock.VectorClock)       {}
+func (d *DetectorBase) Cond(co *TraceElementCond, vc map[int]clock.VectorClock)       {}
+func (d *DetectorBase) Memory(me *TraceElementMemory, vc map[int]clock.VectorClock)   {}
+func (d *DetectorBase) Panic(pa *TraceElementPanic, vc map[int]clock.VectorClock)     {}
+func (d *DetectorBase) Finish()                                                       {}
+
+/*
+ * Call the callback of all detectors for the given element
+ * Args:
+ *   elem (TraceElement): The element
+ */
+func (t *Trace) runDetectors(elem TraceElement) {
+	for _, d := range t.detectors {
+		switch e := elem.(type) {
+		case *TraceElementAtomic:
+			d.Atomic(e, t.currentVCHb)
+		case *TraceElementChannel:
+			d.Channel(e, t.currentVCHb)
+		case *TraceElementMutex:
+			d.Mutex(e, t.currentVCHb)
+		case *TraceElementFork:
+			d.Fork(e, t.currentVCHb)
+		case *TraceElementSelect:
+			d.Select(e, t.currentVCHb)
+		case *TraceElementWait:
+			d.Wait(e, t.currentVCHb)
+		case *TraceElementOnce:
+			d.Once(e, t.currentVCHb)
+		case *TraceElementCond:
+			d.Cond(e, t.currentVCHb)
+		case *TraceElementMemory:
+			d.Memory(e, t.currentVCHb)
+		case *TraceElementPanic:
+			d.Panic(e, t.currentVCHb)
+		}
+	}
+}
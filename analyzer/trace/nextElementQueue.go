@@ -0,0 +1,42 @@
+package trace
+
+/*
+ * Element in the queue of the next elements of each routine
+ * Fields:
+ *   routine (int): The routine of the element
+ *   index (int): The index of the element in the routine trace
+ *   tSort (int): The tSort of the element
+ */
+type nextElement struct {
+	routine int
+	index   int
+	tSort   int
+}
+
+/*
+ * Min heap of the next elements of each routine, ordered by tSort.
+ * Elements with the same tSort are ordered by their routine to get a
+ * deterministic order. Implements heap.Interface.
+ */
+type nextElementQueue []nextElement
+
+func (q nextElementQueue) Len() int { return len(q) }
+func (q nextElementQueue) Less(i, j int) bool {
+	if q[i].tSort != q[j].tSort {
+		return q[i].tSort < q[j].tSort
+	}
+	return q[i].routine < q[j].routine
+}
+func (q nextElementQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *nextElementQueue) Push(x any) {
+	*q = append(*q, x.(nextElement))
+}
+
+func (q *nextElementQueue) Pop() any {
+	old := *q
+	n := len(old)
+	elem := old[n-1]
+	*q = old[:n-1]
+	return elem
+}
@@ -0,0 +1,121 @@
+package trace
+
+import (
+	"analyzer/analysis"
+	"sort"
+)
+
+/*
+ * Check for misuses of once at the end of the trace.
+ * A Do, that did not return, is either blocked or the function of the Do
+ * panicked. If the same routine later started another Do on the same once,
+ * that did not return either, the function called Do on its own once. The
+ * inner Do waits for the outer Do to finish, which is a guaranteed deadlock.
+ * Otherwise, if the routine panicked after the start of the Do, the panic
+ * happened in the function of the Do. The once is nevertheless marked as
+ * done, so all later calls of Do return without executing the function and
+ * may use a value, that was only partially initialized.
+ * This relies on the post of the once not being deferred in sync/once.go. The
+ * post of a Do, whose function panicked, is therefore never recorded, even if
+ * the panic is recovered and the routine continues.
+ */
+func (t *Trace) checkForOnceMisuse() {
+	routines := make([]int, 0, len(t.traces))
+	for routine := range t.traces {
+		routines = append(routines, routine)
+	}
+	sort.Ints(routines)
+
+	for _, routine := range routines {
+		stuck := make([]*TraceElementOnce, 0)
+		panics := make([]*TraceElementPanic, 0)
+		for _, elem := range t.traces[routine] {
+			switch e := elem.(type) {
+			case *TraceElementOnce:
+				if e.tPost == 0 {
+					stuck = append(stuck, e)
+				}
+			case *TraceElementPanic:
+				panics = append(panics, e)
+			}
+		}
+
+		if len(stuck) == 0 {
+			continue
+		}
+
+		sort.Slice(stuck, func(i, j int) bool {
+			return stuck[i].tPre < stuck[j].tPre
+		})
+
+		inner := make(map[*TraceElementOnce]bool)
+		for i, do := range stuck {
+			if inner[do] {
+				continue
+			}
+
+			var recursive *TraceElementOnce
+			for _, other := range stuck[i+1:] {
+				if other.id == do.id {
+					recursive = other
+					break
+				}
+			}
+
+			if recursive != nil {
+				inner[recursive] = true
+				t.analysis.LogRecursiveDo(
+					analysis.OnceElem{Routine: routine, ID: do.id, TID: do.tID, ObjType: "OE"},
+					analysis.OnceElem{Routine: routine, ID: recursive.id, TID: recursive.tID, ObjType: "OB"})
+				continue
+			}
+
+			var pa *TraceElementPanic
+			for _, p := range panics {
+				if p.tPost > do.tPre && (pa == nil || p.tPost < pa.tPost) {
+					pa = p
+				}
+			}
+			if pa == nil {
+				continue
+			}
+
+			later := make([]analysis.OnceElem, 0)
+			for _, elem := range t.getLaterDo(do.id, pa.tPost) {
+				later = append(later, analysis.OnceElem{
+					Routine: elem.routine, ID: elem.id, TID: elem.tID, ObjType: "ON"})
+			}
+
+			t.analysis.LogDoPanic(
+				analysis.OnceElem{Routine: routine, ID: do.id, TID: do.tID, ObjType: "OE"},
+				analysis.OnceElem{Routine: routine, ID: 0, TID: pa.GetTID(), ObjType: "PA"},
+				later)
+		}
+	}
+}
+
+/*
+ * Get the calls of Do on a once, that started after the given time and
+ * returned without executing their function
+ * Args:
+ *   id (int): The id of the once
+ *   time (int): The time after which the calls started
+ * Returns:
+ *   []*TraceElementOnce: The calls sorted by their tpre
+ */
+func (t *Trace) getLaterDo(id int, time int) []*TraceElementOnce {
+	res := make([]*TraceElementOnce, 0)
+	for _, trace := range t.traces {
+		for _, elem := range trace {
+			if on, ok := elem.(*TraceElementOnce); ok && on.id == id &&
+				on.tPre > time && on.tPost != 0 && !on.suc {
+				res = append(res, on)
+			}
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].tPre < res[j].tPre
+	})
+	return res
+}
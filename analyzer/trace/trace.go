@@ -5,32 +5,99 @@ import (
 	"analyzer/clock"
 	"analyzer/logging"
 	"analyzer/utils"
+	"container/heap"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
 )
 
-var (
-	traces map[int][]TraceElement = make(map[int][]TraceElement)
+/*
+ * Trace contains the trace of one program run and the state of its analysis.
+ * Each trace is independent of all other traces, so that multiple traces can
+ * be analyzed or rewritten at the same time.
+ */
+type Trace struct {
+	traces map[int][]TraceElement
 
 	// current happens before vector clocks
-	currentVCHb = make(map[int]clock.VectorClock)
+	currentVCHb map[int]clock.VectorClock
 
 	// current must happens before vector clocks
-	currentVCWmhb = make(map[int]clock.VectorClock)
+	currentVCWmhb map[int]clock.VectorClock
 
 	// channel without partner
-	channelWithoutPartner = make(map[int]map[int]*TraceElementChannel) // id -> opId -> element
+	channelWithoutPartner map[int]map[int]*TraceElementChannel // id -> opId -> element
 
-	currentIndex     = make(map[int]int)
-	numberOfRoutines = 0
+	currentIndex     map[int]int
+	nextElements     nextElementQueue
+	numberOfRoutines int
 	fifo             bool
 	result           string
 
 	analysisCases map[string]bool
-)
+
+	// state of the analysis
+	analysis *analysis.State
+
+	// additional detectors
+	detectors []Detector
+
+	// partner of an unbuffered channel operation, that has been processed
+	// together with the current element
+	processedPartner TraceElement
+
+	// buffered receive operations that wait for their send
+	waitingReceive []*TraceElementChannel
+	maxOpID        map[int]int
+
+	// mutex operations, for which no partner has been found yet
+	mutexNoPartner []*TraceElementMutex
+
+	// elements of the trace grouped by their object id, nil if not built
+	elementsByID map[int][]TraceElement
+
+	// where messages of operations on the trace, e.g. the rewrite, are
+	// written to, nil for the standard error
+	output io.Writer
+
+	// the trace was imported from runtime/trace and only contains the
+	// operations, that blocked a routine
+	imported bool
+}
+
+/*
+ * Create a new, empty trace
+ * Returns:
+ *   *Trace: The new trace
+ */
+func NewTrace() *Trace {
+	t := &Trace{
+		traces: make(map[int][]TraceElement),
+	}
+	t.resetAnalysisState()
+	return t
+}
+
+/*
+ * Reset the state of the analysis
+ */
+func (t *Trace) resetAnalysisState() {
+	t.currentVCHb = make(map[int]clock.VectorClock)
+	t.currentVCWmhb = make(map[int]clock.VectorClock)
+	t.channelWithoutPartner = make(map[int]map[int]*TraceElementChannel)
+	t.currentIndex = make(map[int]int)
+	t.nextElements = make(nextElementQueue, 0)
+	t.waitingReceive = make([]*TraceElementChannel, 0)
+	t.maxOpID = make(map[int]int)
+	t.mutexNoPartner = make([]*TraceElementMutex, 0)
+	t.result = ""
+	t.processedPartner = nil
+}
 
 /*
 * Add an element to the trace
@@ -40,9 +107,10 @@ var (
 * Returns:
 *   error: An error if the routine does not exist
  */
-func AddElementToTrace(element TraceElement) error {
+func (t *Trace) AddElementToTrace(element TraceElement) error {
 	routine := element.GetRoutine()
-	traces[routine] = append(traces[routine], element)
+	t.traces[routine] = append(t.traces[routine], element)
+	t.elementsByID = nil
 	return nil
 }
 
@@ -51,8 +119,9 @@ func AddElementToTrace(element TraceElement) error {
 * Args:
 *   routine (int): The routine id
  */
-func AddEmptyRoutine(routine int) {
-	traces[routine] = make([]TraceElement, 0)
+func (t *Trace) AddEmptyRoutine(routine int) {
+	t.traces[routine] = make([]TraceElement, 0)
+	t.elementsByID = nil
 }
 
 /*
@@ -81,9 +150,9 @@ func sortTrace(trace []TraceElement) []TraceElement {
 /*
  * Sort all traces by tpost
  */
-func Sort() {
-	for routine, trace := range traces {
-		traces[routine] = sortTrace(trace)
+func (t *Trace) Sort() {
+	for routine, trace := range t.traces {
+		t.traces[routine] = sortTrace(trace)
 	}
 }
 
@@ -92,8 +161,8 @@ func Sort() {
  * Returns:
  *   map[int][]traceElement: The traces
  */
-func GetTraces() *map[int][]TraceElement {
-	return &traces
+func (t *Trace) GetTraces() *map[int][]TraceElement {
+	return &t.traces
 }
 
 /*
@@ -103,8 +172,8 @@ func GetTraces() *map[int][]TraceElement {
  * Returns:
  *   []traceElement: The trace of the routine
  */
-func GetTraceFromId(id int) []TraceElement {
-	return traces[id]
+func (t *Trace) GetTraceFromId(id int) []TraceElement {
+	return t.traces[id]
 }
 
 /*
@@ -116,15 +185,15 @@ func GetTraceFromId(id int) []TraceElement {
  *   *TraceElement: The element
  *   error: An error if the element does not exist
  */
-func GetTraceElementFromTID(tID string) (*TraceElement, error) {
+func (t *Trace) GetTraceElementFromTID(tID string) (*TraceElement, error) {
 	if tID == "" {
 		return nil, errors.New("tID is empty")
 	}
 
-	for routine, trace := range traces {
+	for routine, trace := range t.traces {
 		for index, elem := range trace {
 			if elem.GetTID() == tID {
-				return &traces[routine][index], nil
+				return &t.traces[routine][index], nil
 			}
 		}
 	}
@@ -140,7 +209,7 @@ func GetTraceElementFromTID(tID string) (*TraceElement, error) {
  *   *TraceElement: The element
  *   error: An error if the element does not exist
  */
-func GetTraceElementFromBugArg(bugArg string) (*TraceElement, error) {
+func (t *Trace) GetTraceElementFromBugArg(bugArg string) (*TraceElement, error) {
 	splitArg := strings.Split(bugArg, ":")
 
 	if splitArg[0] != "T" {
@@ -161,9 +230,9 @@ func GetTraceElementFromBugArg(bugArg string) (*TraceElement, error) {
 		return nil, errors.New("Could not parse tPre from bug argument: " + bugArg)
 	}
 
-	for index, elem := range traces[routine] {
+	for index, elem := range t.traces[routine] {
 		if elem.GetTPre() == tPre {
-			return &traces[routine][index], nil
+			return &t.traces[routine][index], nil
 		}
 	}
 
@@ -176,19 +245,20 @@ func GetTraceElementFromBugArg(bugArg string) (*TraceElement, error) {
  *   time (int): The time to shorten the trace to
  *   incl (bool): True if an element with the same time should stay included in the trace
  */
-func ShortenTrace(time int, incl bool) {
-	for routine, trace := range traces {
+func (t *Trace) ShortenTrace(time int, incl bool) {
+	for routine, trace := range t.traces {
 		for index, elem := range trace {
 			if incl && elem.GetTSort() > time {
-				traces[routine] = traces[routine][:index]
+				t.traces[routine] = t.traces[routine][:index]
 				break
 			}
 			if !incl && elem.GetTSort() >= time {
-				traces[routine] = traces[routine][:index]
+				t.traces[routine] = t.traces[routine][:index]
 				break
 			}
 		}
 	}
+	t.elementsByID = nil
 }
 
 /*
@@ -196,15 +266,16 @@ func ShortenTrace(time int, incl bool) {
  * Args:
  *   tID (string): The tID of the element to remove
  */
-func RemoveElementFromTrace(tID string) {
-	for routine, trace := range traces {
+func (t *Trace) RemoveElementFromTrace(tID string) {
+	for routine, trace := range t.traces {
 		for index, elem := range trace {
 			if elem.GetTID() == tID {
-				traces[routine] = append(traces[routine][:index], traces[routine][index+1:]...)
+				t.traces[routine] = append(t.traces[routine][:index], t.traces[routine][index+1:]...)
 				break
 			}
 		}
 	}
+	t.elementsByID = nil
 }
 
 /*
@@ -213,21 +284,23 @@ func RemoveElementFromTrace(tID string) {
  *   routine (int): The routine to shorten
  *   time (int): The time to shorten the trace to
  */
-func ShortenRoutine(routine int, time int) {
-	for index, elem := range traces[routine] {
+func (t *Trace) ShortenRoutine(routine int, time int) {
+	for index, elem := range t.traces[routine] {
 		if elem.GetTSort() >= time {
-			traces[routine] = traces[routine][:index]
+			t.traces[routine] = t.traces[routine][:index]
 			break
 		}
 	}
+	t.elementsByID = nil
 }
 
-func ShortenRoutineIndex(routine int, index int, incl bool) {
+func (t *Trace) ShortenRoutineIndex(routine int, index int, incl bool) {
 	if incl {
-		traces[routine] = traces[routine][:index+1]
+		t.traces[routine] = t.traces[routine][:index+1]
 	} else {
-		traces[routine] = traces[routine][:index]
+		t.traces[routine] = t.traces[routine][:index]
 	}
+	t.elementsByID = nil
 }
 
 /*
@@ -236,18 +309,18 @@ func ShortenRoutineIndex(routine int, index int, incl bool) {
  *   element1 (traceElement): The first element
  *   element2 (traceElement): The second element
  */
-func SwitchTimer(element1 *TraceElement, element2 *TraceElement) {
+func (t *Trace) SwitchTimer(element1 *TraceElement, element2 *TraceElement) {
 	routine1 := (*element1).GetRoutine()
 	routine2 := (*element2).GetRoutine()
 	tSort1 := (*element1).GetTSort()
-	for index, elem := range traces[routine1] {
+	for index, elem := range t.traces[routine1] {
 		if elem.GetTSort() == (*element1).GetTSort() {
-			traces[routine1][index].SetT((*element2).GetTSort())
+			t.traces[routine1][index].SetT((*element2).GetTSort())
 		}
 	}
-	for index, elem := range traces[routine2] {
+	for index, elem := range t.traces[routine2] {
 		if elem.GetTSort() == (*element2).GetTSort() {
-			traces[routine2][index].SetT(tSort1)
+			t.traces[routine2][index].SetT(tSort1)
 			break
 		}
 	}
@@ -290,8 +363,8 @@ func contains(slice []int, elem int) bool {
  * Args:
  *   n (int): The number of routines
  */
-func SetNumberOfRoutines(n int) {
-	numberOfRoutines = n
+func (t *Trace) SetNumberOfRoutines(n int) {
+	t.numberOfRoutines = n
 }
 
 /*
@@ -302,52 +375,80 @@ func SetNumberOfRoutines(n int) {
 *   ignoreCriticalSections (bool): True to ignore critical sections when updating
 *   	vector clocks
 *   analysisCasesMap (map[string]bool): The analysis cases to run
+*   results (*logging.Results): The results, the found bugs are added to
+*   detectors ([]Detector): Additional detectors to run
  */
-func RunAnalysis(assumeFifo bool, ignoreCriticalSections bool, analysisCasesMap map[string]bool) string {
+func (t *Trace) RunAnalysis(assumeFifo bool, ignoreCriticalSections bool, analysisCasesMap map[string]bool,
+	results *logging.Results, detectors []Detector) string {
 
 	logging.Debug("Analyze the trace...", logging.INFO)
 
-	fifo = assumeFifo
+	t.resetAnalysisState()
+
+	t.fifo = assumeFifo
+
+	t.analysisCases = analysisCasesMap
+	if t.imported {
+		// operations that did not block are missing in an imported trace,
+		// a missing partner is therefore no indication of a bug
+		t.analysisCases = make(map[string]bool, len(analysisCasesMap))
+		for c, run := range analysisCasesMap {
+			t.analysisCases[c] = run
+		}
+		t.analysisCases["selectWithoutPartner"] = false
+	}
+	t.analysis = analysis.NewState(t.analysisCases, results)
 
-	analysisCases = analysisCasesMap
-	analysis.InitAnalysis(analysisCases)
+	t.detectors = detectors
+	for _, d := range t.detectors {
+		d.Init(results, t.numberOfRoutines)
+	}
 
-	for i := 1; i <= numberOfRoutines; i++ {
-		currentVCHb[i] = clock.NewVectorClock(numberOfRoutines)
-		currentVCWmhb[i] = clock.NewVectorClock(numberOfRoutines)
+	for i := 1; i <= t.numberOfRoutines; i++ {
+		t.currentVCHb[i] = clock.NewVectorClock(t.numberOfRoutines)
+		t.currentVCWmhb[i] = clock.NewVectorClock(t.numberOfRoutines)
 	}
 
-	currentVCHb[1] = currentVCHb[1].Inc(1)
-	currentVCWmhb[1] = currentVCWmhb[1].Inc(1)
+	t.currentVCHb[1] = t.currentVCHb[1].Inc(1)
+	t.currentVCWmhb[1] = t.currentVCWmhb[1].Inc(1)
 
-	for elem := getNextElement(); elem != nil; elem = getNextElement() {
+	t.initNextElements()
+
+	for elem := t.getNextElement(); elem != nil; elem = t.getNextElement() {
 		switch e := elem.(type) {
 		case *TraceElementAtomic:
 			logging.Debug("Update vector clock for atomic operation "+e.ToString()+
 				" for routine "+strconv.Itoa(e.GetRoutine()), logging.DEBUG)
+			var vcPre clock.VectorClock
+			if t.analysisCases["atomicityViolation"] && e.opA != LoadOp {
+				vcPre = t.currentVCHb[e.routine].Copy()
+			}
 			if ignoreCriticalSections {
-				e.updateVectorClockAlt()
+				e.updateVectorClockAlt(t)
 			} else {
-				e.updateVectorClock()
+				e.updateVectorClock(t)
+			}
+			if t.analysisCases["atomicityViolation"] {
+				t.analysis.UpdateAtomicCheckThenAct(e.routine, e.id, e.GetTID(), e.GetObjType(), vcPre, e.vc)
 			}
 		case *TraceElementChannel:
 			logging.Debug("Update vector clock for channel operation "+e.ToString()+
 				" for routine "+strconv.Itoa(e.GetRoutine()), logging.DEBUG)
-			e.updateVectorClock()
+			e.updateVectorClock(t)
 		case *TraceElementMutex:
 			if ignoreCriticalSections {
 				logging.Debug("Ignore critical section "+e.ToString()+
 					" for routine "+strconv.Itoa(e.GetRoutine()), logging.DEBUG)
-				e.updateVectorClockAlt()
+				e.updateVectorClockAlt(t)
 			} else {
 				logging.Debug("Update vector clock for mutex operation "+e.ToString()+
 					" for routine "+strconv.Itoa(e.GetRoutine()), logging.DEBUG)
-				e.updateVectorClock()
+				e.updateVectorClock(t)
 			}
 		case *TraceElementFork:
 			logging.Debug("Update vector clock for routine operation "+e.ToString()+
 				" for routine "+strconv.Itoa(e.GetRoutine()), logging.DEBUG)
-			e.updateVectorClock()
+			e.updateVectorClock(t)
 		case *TraceElementSelect:
 			logging.Debug("Update vector clock for select operation "+e.ToString()+
 				" for routine "+strconv.Itoa(e.GetRoutine()), logging.DEBUG)
@@ -364,34 +465,59 @@ func RunAnalysis(assumeFifo bool, ignoreCriticalSections bool, analysisCasesMap
 					opTypes = append(opTypes, 1)
 				}
 			}
-			e.updateVectorClock()
+			e.updateVectorClock(t)
 		case *TraceElementWait:
 			logging.Debug("Update vector clock for go operation "+e.ToString()+
 				" for routine "+strconv.Itoa(e.GetRoutine()), logging.DEBUG)
-			e.updateVectorClock()
+			e.updateVectorClock(t)
 		case *TraceElementCond:
 			logging.Debug("Update vector clock for cond operation "+e.ToString()+
 				" for routine "+strconv.Itoa(e.GetRoutine()), logging.DEBUG)
-			e.updateVectorClock()
+			e.updateVectorClock(t)
+		case *TraceElementMemory:
+			logging.Debug("Update vector clock for memory access "+e.ToString()+
+				" for routine "+strconv.Itoa(e.GetRoutine()), logging.DEBUG)
+			e.updateVectorClock(t)
+			if t.analysisCases["dataRace"] {
+				t.analysis.CheckForDataRace(e.routine, e.addr, e.gc, e.GetTID(), e.GetObjType(), e.tPost, e.vc)
+			}
+		case *TraceElementPanic:
+			logging.Debug("Update vector clock for panic "+e.ToString()+
+				" for routine "+strconv.Itoa(e.GetRoutine()), logging.DEBUG)
+			e.updateVectorClock(t)
+		}
+
+		// the synchronizations of each routine separate its memory accesses
+		// into segments
+		if t.analysisCases["dataRace"] && elem.getTpost() != 0 {
+			if _, ok := elem.(*TraceElementMemory); !ok {
+				t.analysis.UpdateSyncTime(elem.GetRoutine(), elem.GetTSort())
+			}
 		}
 
 		// check for leak
-		if analysisCases["leak"] && elem.getTpost() == 0 {
+		if t.analysisCases["leak"] && elem.getTpost() == 0 {
 			switch e := elem.(type) {
 			case *TraceElementChannel:
+				if t.imported {
+					break
+				}
 				switch e.opC {
 				case Send:
-					analysis.CheckForLeakChannelStuck(elem.GetRoutine(), elem.GetID(),
-						currentVCHb[e.routine], elem.GetTID(), 0, e.qSize != 0)
+					t.analysis.CheckForLeakChannelStuck(elem.GetRoutine(), elem.GetID(),
+						t.currentVCHb[e.routine], elem.GetTID(), 0, e.qSize != 0)
 				case Recv:
-					analysis.CheckForLeakChannelStuck(elem.GetRoutine(), elem.GetID(),
-						currentVCHb[e.routine], elem.GetTID(), 1, e.qSize != 0)
+					t.analysis.CheckForLeakChannelStuck(elem.GetRoutine(), elem.GetID(),
+						t.currentVCHb[e.routine], elem.GetTID(), 1, e.qSize != 0)
 				}
 			case *TraceElementMutex:
-				analysis.CheckForLeakMutex(elem.GetRoutine(), elem.GetID(), elem.GetTID(), int(e.opM))
+				t.analysis.CheckForLeakMutex(elem.GetRoutine(), elem.GetID(), elem.GetTID(), int(e.opM))
 			case *TraceElementWait:
-				analysis.CheckForLeakWait(elem.GetRoutine(), elem.GetID(), elem.GetTID())
+				t.analysis.CheckForLeakWait(elem.GetRoutine(), elem.GetID(), elem.GetTID())
 			case *TraceElementSelect:
+				if t.imported {
+					break
+				}
 				cases := e.GetCases()
 				ids := make([]int, 0)
 				buffered := make([]bool, 0)
@@ -408,33 +534,72 @@ func RunAnalysis(assumeFifo bool, ignoreCriticalSections bool, analysisCasesMap
 						buffered = append(buffered, c.IsBuffered())
 					}
 				}
-				analysis.CheckForLeakSelectStuck(elem.GetRoutine(), ids, buffered, currentVCHb[e.routine], e.tID, opTypes, e.tPre, e.id)
+				t.analysis.CheckForLeakSelectStuck(elem.GetRoutine(), ids, buffered, t.currentVCHb[e.routine], e.tID, opTypes, e.tPre, e.id)
 			case *TraceElementCond:
-				analysis.CheckForLeakCond(elem.GetRoutine(), elem.GetID(), elem.GetTID())
+				t.analysis.CheckForLeakCond(elem.GetRoutine(), elem.GetID(), elem.GetTID())
+			}
+		}
+
+		if t.analysisCases["blockingInCriticalSection"] {
+			t.checkForBlockingInCriticalSection(elem)
+			if t.processedPartner != nil {
+				t.checkForBlockingInCriticalSection(t.processedPartner)
 			}
 		}
 
+		t.runDetectors(elem)
+
+		// the partner of an unbuffered channel operation is processed together
+		// with the operation and is therefore not returned by getNextElement
+		if t.processedPartner != nil {
+			t.runDetectors(t.processedPartner)
+			t.processedPartner = nil
+		}
+	}
+
+	if t.analysisCases["selectWithoutPartner"] {
+		t.rerunCheckForSelectCaseWithoutPartnerChannel()
+		t.analysis.CheckForSelectCaseWithoutPartner()
+	}
+
+	if t.analysisCases["leak"] {
+		t.analysis.CheckForLeak()
+	}
+
+	if t.analysisCases["partialDeadlock"] {
+		t.checkForPartialDeadlock()
+	}
+
+	if t.analysisCases["onceMisuse"] {
+		t.checkForOnceMisuse()
+	}
+
+	if t.analysisCases["doneBeforeAdd"] {
+		t.analysis.CheckForDoneBeforeAdd()
 	}
 
-	if analysisCases["selectWithoutPartner"] {
-		rerunCheckForSelectCaseWithoutPartnerChannel()
-		analysis.CheckForSelectCaseWithoutPartner()
+	if t.analysisCases["lostWakeup"] {
+		t.analysis.CheckForLostWakeup()
 	}
 
-	if analysisCases["leak"] {
-		analysis.CheckForLeak()
+	if t.analysisCases["atomicityViolation"] {
+		t.analysis.CheckForAtomicityViolation()
 	}
 
-	if analysisCases["doneBeforeAdd"] {
-		analysis.CheckForDoneBeforeAdd()
+	if t.analysisCases["blockingInCriticalSection"] {
+		t.analysis.LogBlockingInCriticalSection()
 	}
 
-	if analysisCases["cyclicDeadlock"] {
-		analysis.CheckForCyclicDeadlock()
+	if t.analysisCases["cyclicDeadlock"] {
+		t.analysis.CheckForCyclicDeadlock()
+	}
+
+	for _, d := range t.detectors {
+		d.Finish()
 	}
 
 	logging.Debug("Analysis completed", logging.INFO)
-	return result
+	return t.result
 }
 
 /*
@@ -442,53 +607,141 @@ func RunAnalysis(assumeFifo bool, ignoreCriticalSections bool, analysisCasesMap
  * is needed to find potential communication partners for not executed
  * select cases, if the select was executed after the channel
  */
-func rerunCheckForSelectCaseWithoutPartnerChannel() {
-	for _, trace := range traces {
+func (t *Trace) rerunCheckForSelectCaseWithoutPartnerChannel() {
+	for _, trace := range t.traces {
 		for _, elem := range trace {
 			if e, ok := elem.(*TraceElementChannel); ok {
-				analysis.CheckForSelectCaseWithoutPartnerChannel(e.GetID(), e.GetVC(),
+				t.analysis.CheckForSelectCaseWithoutPartnerChannel(e.GetID(), e.GetVC(),
 					e.GetTID(), e.Operation() == Send, e.IsBuffered())
 			}
 		}
 	}
 }
 
-func getNextElement() TraceElement {
-	// find the local trace, where the element on which currentIndex points to
-	// has the smallest tpost
-	var minTSort = -1
-	var minRoutine = -1
-	for routine, trace := range traces {
-		// no more elements in the routine trace
-		if currentIndex[routine] == -1 {
-			continue
+/*
+ * Check if the element is a blocking operation, that is executed while the
+ * routine holds locks. Blocking operations are channel sends and receives,
+ * selects without default, wait group waits and cond waits.
+ * Args:
+ *   elem (TraceElement): The element to check
+ */
+func (t *Trace) checkForBlockingInCriticalSection(elem TraceElement) {
+	objType := ""
+	switch e := elem.(type) {
+	case *TraceElementChannel:
+		switch e.opC {
+		case Send:
+			objType = "CS"
+		case Recv:
+			objType = "CR"
+		default:
+			return
 		}
-		// ignore non executed operations
-		if trace[currentIndex[routine]].GetTSort() == 0 {
-			continue
+	case *TraceElementSelect:
+		if e.containsDefault {
+			return
+		}
+		objType = "SS"
+	case *TraceElementWait:
+		if e.opW != WaitOp {
+			return
 		}
-		if minTSort == -1 || trace[currentIndex[routine]].GetTSort() < minTSort {
-			minTSort = trace[currentIndex[routine]].GetTSort()
-			minRoutine = routine
+		objType = "WW"
+	case *TraceElementCond:
+		if e.opC != WaitCondOp {
+			return
 		}
+		objType = "NW"
+	default:
+		return
 	}
 
-	// all elements have been processed
-	if minRoutine == -1 {
-		return nil
+	t.analysis.CheckForBlockingInCriticalSection(elem.GetRoutine(), elem.GetID(), elem.GetTID(),
+		objType, elem.GetTPre(), elem.getTpost())
+}
+
+/*
+ * Initialize the index of all routines and the queue of the next elements
+ */
+func (t *Trace) initNextElements() {
+	t.nextElements = make(nextElementQueue, 0, len(t.traces))
+	for routine, trace := range t.traces {
+		if len(trace) == 0 {
+			t.currentIndex[routine] = -1
+			continue
+		}
+		t.currentIndex[routine] = 0
+		t.pushNextElement(routine)
+	}
+	heap.Init(&t.nextElements)
+}
+
+/*
+ * Add the element on which currentIndex of the routine points to, to the
+ * queue of the next elements
+ * Args:
+ *   routine (int): The routine
+ */
+func (t *Trace) pushNextElement(routine int) {
+	index := t.currentIndex[routine]
+	if index == -1 {
+		return
+	}
+
+	// ignore non executed operations
+	tSort := t.traces[routine][index].GetTSort()
+	if tSort == 0 {
+		return
+	}
+
+	heap.Push(&t.nextElements, nextElement{routine: routine, index: index, tSort: tSort})
+}
+
+/*
+ * Get the next element in the trace, i.e. the element with the smallest tSort
+ * of all elements on which currentIndex points to
+ * Returns:
+ *   TraceElement: The next element, nil if all elements have been processed
+ */
+func (t *Trace) getNextElement() TraceElement {
+	for t.nextElements.Len() > 0 {
+		next := heap.Pop(&t.nextElements).(nextElement)
+
+		// the index of the routine has been advanced by the partner of an
+		// unbuffered channel operation
+		if t.currentIndex[next.routine] != next.index {
+			t.pushNextElement(next.routine)
+			continue
+		}
+
+		// return the element and increase the index
+		element := t.traces[next.routine][next.index]
+		t.increaseIndex(next.routine)
+		t.pushNextElement(next.routine)
+
+		return element
 	}
 
-	// return the element and increase the index
-	element := traces[minRoutine][currentIndex[minRoutine]]
-	increaseIndex(minRoutine)
+	// all elements have been processed
+	return nil
+}
 
-	return element
+func (t *Trace) increaseIndex(routine int) {
+	t.currentIndex[routine]++
+	if t.currentIndex[routine] >= len(t.traces[routine]) {
+		t.currentIndex[routine] = -1
+	}
 }
 
-func increaseIndex(routine int) {
-	currentIndex[routine]++
-	if currentIndex[routine] >= len(traces[routine]) {
-		currentIndex[routine] = -1
+/*
+ * Group the elements of the trace by their object id
+ */
+func (t *Trace) buildElementsByID() {
+	t.elementsByID = make(map[int][]TraceElement)
+	for _, trace := range t.traces {
+		for _, elem := range trace {
+			t.elementsByID[elem.GetID()] = append(t.elementsByID[elem.GetID()], elem)
+		}
 	}
 }
 
@@ -502,23 +755,23 @@ func increaseIndex(routine int) {
  *   int: The number of add operations
  *   int: The number of done operations
  */
-func GetNrAddDoneBeforeTime(wgID int, waitTime int) (int, int) {
+func (t *Trace) GetNrAddDoneBeforeTime(wgID int, waitTime int) (int, int) {
 	nrAdd := 0
 	nrDone := 0
 
-	for _, trace := range traces {
-		for _, elem := range trace {
-			switch e := elem.(type) {
-			case *TraceElementWait:
-				if e.GetID() == wgID {
-					if e.GetTPre() < waitTime {
-						delta := e.GetDelta()
-						if delta > 0 {
-							nrAdd++
-						} else if delta < 0 {
-							nrDone++
-						}
-					}
+	if t.elementsByID == nil {
+		t.buildElementsByID()
+	}
+
+	for _, elem := range t.elementsByID[wgID] {
+		switch e := elem.(type) {
+		case *TraceElementWait:
+			if e.GetTPre() < waitTime {
+				delta := e.GetDelta()
+				if delta > 0 {
+					nrAdd++
+				} else if delta < 0 {
+					nrDone++
 				}
 			}
 		}
@@ -536,15 +789,15 @@ func GetNrAddDoneBeforeTime(wgID int, waitTime int) (int, int) {
  *   startTPre (int): The time to start shifting
  *   shift (int): The shift
  */
-func ShiftTrace(startTPre int, shift int) bool {
+func (t *Trace) ShiftTrace(startTPre int, shift int) bool {
 	if shift <= 0 {
 		return false
 	}
 
-	for routine, trace := range traces {
+	for routine, trace := range t.traces {
 		for index, elem := range trace {
 			if elem.GetTPre() >= startTPre {
-				traces[routine][index].SetTWithoutNotExecuted(elem.GetTSort() + shift)
+				t.traces[routine][index].SetTWithoutNotExecuted(elem.GetTSort() + shift)
 			}
 		}
 	}
@@ -558,11 +811,11 @@ func ShiftTrace(startTPre int, shift int) bool {
  * Args:
  *   element (traceElement): The element
  */
-func ShiftConcurrentOrAfterToAfter(element *TraceElement) {
+func (t *Trace) ShiftConcurrentOrAfterToAfter(element *TraceElement) {
 	elemsToShift := make([]TraceElement, 0)
 	minTime := -1
 
-	for _, trace := range traces {
+	for _, trace := range t.traces {
 		for _, elem := range trace {
 			if elem.GetTID() == (*element).GetTID() {
 				continue
@@ -593,12 +846,12 @@ func ShiftConcurrentOrAfterToAfter(element *TraceElement) {
  *   element (traceElement): The element
  *   start (traceElement): The time to start shifting (not including)
  */
-func ShiftConcurrentOrAfterToAfterStartingFromElement(element *TraceElement, start int) {
+func (t *Trace) ShiftConcurrentOrAfterToAfterStartingFromElement(element *TraceElement, start int) {
 	elemsToShift := make([]TraceElement, 0)
 	minTime := -1
 	maxNotMoved := 0
 
-	for _, trace := range traces {
+	for _, trace := range t.traces {
 		for _, elem := range trace {
 			if elem.GetTID() == (*element).GetTID() {
 				continue
@@ -637,8 +890,64 @@ func ShiftConcurrentOrAfterToAfterStartingFromElement(element *TraceElement, sta
  * Args:
  *   element (traceElement): The element
  */
-func ShiftConcurrentToBefore(element *TraceElement) {
-	ShiftConcurrentOrAfterToAfterStartingFromElement(element, 0)
+func (t *Trace) ShiftConcurrentToBefore(element *TraceElement) {
+	t.ShiftConcurrentOrAfterToAfterStartingFromElement(element, 0)
+}
+
+/*
+ * Move the element directly after start and all elements, that are HB-before
+ * the element. All elements after start, that are concurrent or HB-later than
+ * the element, are moved after the element without changing their order.
+ * The time step directly after the element is left free, e.g. for a stop marker.
+ * Different to ShiftConcurrentOrAfterToAfterStartingFromElement, the tSort of
+ * the elements is used.
+ * Args:
+ *   element (traceElement): The element
+ *   start (int): The time after which the element is moved
+ */
+func (t *Trace) MoveAfterTime(element *TraceElement, start int) {
+	elemsToShift := make([]TraceElement, 0)
+	minTime := -1
+	maxNotMoved := start
+
+	for _, trace := range t.traces {
+		for _, elem := range trace {
+			if elem.GetTID() == (*element).GetTID() {
+				continue
+			}
+
+			if clock.GetHappensBefore(elem.GetVC(), (*element).GetVC()) == clock.Before {
+				if elem.GetTSort() != math.MaxInt && elem.GetTSort() > maxNotMoved {
+					maxNotMoved = elem.GetTSort()
+				}
+				continue
+			}
+
+			if elem.GetTSort() <= start {
+				continue
+			}
+
+			elemsToShift = append(elemsToShift, elem)
+			if elem.GetTSort() != math.MaxInt && (minTime == -1 || elem.GetTSort() < minTime) {
+				minTime = elem.GetTSort()
+			}
+		}
+	}
+
+	(*element).SetT(maxNotMoved + 1)
+
+	if minTime == -1 {
+		minTime = maxNotMoved
+	}
+	distance := (*element).GetTSort() - minTime + 2
+
+	for _, elem := range elemsToShift {
+		if elem.GetTSort() == math.MaxInt {
+			elem.SetTWithoutNotExecuted(max(elem.GetTPre()+distance, (*element).GetTSort()+2))
+		} else {
+			elem.SetTWithoutNotExecuted(elem.GetTSort() + distance)
+		}
+	}
 }
 
 /*
@@ -646,8 +955,8 @@ func ShiftConcurrentToBefore(element *TraceElement) {
  * Args:
  *   element (traceElement): The element
  */
-func RemoveConcurrent(element *TraceElement, tmin int) {
-	for routine, trace := range traces {
+func (t *Trace) RemoveConcurrent(element *TraceElement, tmin int) {
+	for routine, trace := range t.traces {
 		result := make([]TraceElement, 0)
 		for _, elem := range trace {
 			if elem.GetTSort() < tmin {
@@ -664,8 +973,50 @@ func RemoveConcurrent(element *TraceElement, tmin int) {
 				result = append(result, elem)
 			}
 		}
-		traces[routine] = result
+		t.traces[routine] = result
+	}
+	t.elementsByID = nil
+}
+
+/*
+ * Remove all elements that are HB-later than the element and have time greater or equal to tmin
+ * Args:
+ *   element (traceElement): The element
+ *   tmin (int): The time to start removing
+ */
+func (t *Trace) RemoveLater(element *TraceElement, tmin int) {
+	for routine, trace := range t.traces {
+		result := make([]TraceElement, 0)
+		for _, elem := range trace {
+			if elem.GetTSort() < tmin || elem.GetTID() == (*element).GetTID() {
+				result = append(result, elem)
+				continue
+			}
+
+			if clock.GetHappensBefore((*element).GetVC(), elem.GetVC()) != clock.Before {
+				result = append(result, elem)
+			}
+		}
+		t.traces[routine] = result
 	}
+	t.elementsByID = nil
+}
+
+/*
+ * Get the latest tSort of all executed elements in the trace
+ * Returns:
+ *   int: The latest tSort, 0 if the trace does not contain executed elements
+ */
+func (t *Trace) GetLatestTSort() int {
+	latest := 0
+	for _, trace := range t.traces {
+		for _, elem := range trace {
+			if elem.GetTSort() != math.MaxInt && elem.GetTSort() > latest {
+				latest = elem.GetTSort()
+			}
+		}
+	}
+	return latest
 }
 
 /*
@@ -675,9 +1026,9 @@ func RemoveConcurrent(element *TraceElement, tmin int) {
  * Returns:
  *   map[int]traceElement: The earliest concurrent element for each routine
  */
-func GetConcurrentEarliest(element *TraceElement) map[int]*TraceElement {
+func (t *Trace) GetConcurrentEarliest(element *TraceElement) map[int]*TraceElement {
 	concurrent := make(map[int]*TraceElement)
-	for routine, trace := range traces {
+	for routine, trace := range t.traces {
 		for _, elem := range trace {
 			if elem.GetTID() == (*element).GetTID() {
 				continue
@@ -702,14 +1053,14 @@ func GetConcurrentEarliest(element *TraceElement) map[int]*TraceElement {
  *   bool: True if the shift was successful, false otherwise (shift <= 0)
  * TODO: is this allowed or will it create problems?
  */
-func ShiftRoutine(routine int, startTSort int, shift int) bool {
+func (t *Trace) ShiftRoutine(routine int, startTSort int, shift int) bool {
 	if shift <= 0 {
 		return false
 	}
 
-	for index, elem := range traces[routine] {
+	for index, elem := range t.traces[routine] {
 		if elem.GetTPre() >= startTSort {
-			traces[routine][index].SetTWithoutNotExecuted(elem.GetTSort() + shift)
+			t.traces[routine][index].SetTWithoutNotExecuted(elem.GetTSort() + shift)
 		}
 	}
 
@@ -724,17 +1075,17 @@ func ShiftRoutine(routine int, startTSort int, shift int) bool {
  * Returns:
  *  map[int][]TraceElement: The partial trace
  */
-func GetPartialTrace(startTime int, endTime int) map[int][]*TraceElement {
+func (t *Trace) GetPartialTrace(startTime int, endTime int) map[int][]*TraceElement {
 	result := make(map[int][]*TraceElement)
 	println("\n\n")
-	for routine, trace := range traces {
+	for routine, trace := range t.traces {
 		for index, elem := range trace {
 			if _, ok := result[routine]; !ok {
 				result[routine] = make([]*TraceElement, 0)
 			}
 			time := elem.GetTSort()
 			if time >= startTime && time <= endTime {
-				result[routine] = append(result[routine], &traces[routine][index])
+				result[routine] = append(result[routine], &t.traces[routine][index])
 			}
 		}
 	}
@@ -749,8 +1100,113 @@ func GetPartialTrace(startTime int, endTime int) map[int][]*TraceElement {
  * Returns:
  *   map[int][]traceElement: The copy of the trace
  */
-func CopyCurrentTrace() map[int][]TraceElement {
-	return CopyTrace(traces)
+func (t *Trace) CopyCurrentTrace() map[int][]TraceElement {
+	return CopyTrace(t.traces)
+}
+
+/*
+ * Mark the trace as imported from runtime/trace. The analyses, that need all
+ * possible partners of an operation, are not run on imported traces.
+ * Args:
+ *   imported (bool): True if the trace was imported
+ */
+func (t *Trace) SetImported(imported bool) {
+	t.imported = imported
+}
+
+/*
+ * Set where the messages of operations on the trace, e.g. of the rewrite,
+ * are written to. Traces, that are rewritten at the same time, can use
+ * separate writers, so that their messages do not interleave.
+ * Args:
+ *   w (io.Writer): The writer, nil for the standard error
+ */
+func (t *Trace) SetOutput(w io.Writer) {
+	t.output = w
+}
+
+/*
+ * Print a message of an operation on the trace, followed by a newline
+ * Args:
+ *   a (...any): The values to print, separated by spaces
+ */
+func (t *Trace) Println(a ...any) {
+	w := t.output
+	if w == nil {
+		w = os.Stderr
+	}
+	fmt.Fprintln(w, a...)
+}
+
+/*
+ * Create an independent copy of the trace. The state of the analysis and the
+ * output are not copied.
+ * Returns:
+ *   *Trace: The copy of the trace
+ */
+func (t *Trace) Copy() *Trace {
+	res := NewTrace()
+	res.traces = CopyTrace(t.traces)
+	res.numberOfRoutines = t.numberOfRoutines
+	res.imported = t.imported
+	relinkCopy(t.traces, res.traces)
+	return res
+}
+
+/*
+ * Let the partner and select references of a copied trace point to the
+ * elements of the copy instead of the original trace, so that changes to
+ * the copy do not affect the original trace or other copies
+ * Args:
+ *   original (map[int][]TraceElement): The original trace
+ *   copyTrace (map[int][]TraceElement): The copy of the original trace
+ */
+func relinkCopy(original map[int][]TraceElement, copyTrace map[int][]TraceElement) {
+	channels := make(map[*TraceElementChannel]*TraceElementChannel)
+	selects := make(map[*TraceElementSelect]*TraceElementSelect)
+
+	for routine, trace := range original {
+		for i, elem := range trace {
+			switch e := elem.(type) {
+			case *TraceElementChannel:
+				channels[e] = copyTrace[routine][i].(*TraceElementChannel)
+			case *TraceElementSelect:
+				se := copyTrace[routine][i].(*TraceElementSelect)
+				selects[e] = se
+				for j := range e.cases {
+					channels[&e.cases[j]] = &se.cases[j]
+				}
+				channels[&e.chosenCase] = &se.chosenCase
+			}
+		}
+	}
+
+	relinkChannel := func(ch *TraceElementChannel) {
+		if ch.partner != nil {
+			if partner, ok := channels[ch.partner]; ok {
+				ch.partner = partner
+			}
+		}
+		if ch.sel != nil {
+			if sel, ok := selects[ch.sel]; ok {
+				ch.sel = sel
+			}
+		}
+	}
+
+	// contains the cases of the selects
+	for _, ch := range channels {
+		relinkChannel(ch)
+	}
+}
+
+/*
+ * Get the number of routines
+ * Returns:
+ *   int: The number of routines
+ */
+func (t *Trace) GetNumberOfRoutines() int {
+	return t.numberOfRoutines
 }
 
 /*
@@ -781,8 +1237,9 @@ func copyTraceRoutine(trace []TraceElement) []TraceElement {
  * Args:
  *   trace (map[int][]traceElement): The trace
  */
-func SetTrace(trace map[int][]TraceElement) {
-	traces = CopyTrace(trace)
+func (t *Trace) SetTrace(trace map[int][]TraceElement) {
+	t.traces = CopyTrace(trace)
+	t.elementsByID = nil
 }
 
 /*
@@ -791,13 +1248,13 @@ func SetTrace(trace map[int][]TraceElement) {
 *   types: types of the elements to print. If empty, all elements will be printed
 *   clocks: if true, the clocks will be printed
  */
-func PrintTrace(types []string, clocks bool) {
+func (t *Trace) PrintTrace(types []string, clocks bool) {
 	elements := make([]struct {
 		string
 		int
 		clock.VectorClock
 	}, 0)
-	for _, tra := range traces {
+	for _, tra := range t.traces {
 		for _, elem := range tra {
 			elemStr := elem.ToString()
 			if len(types) == 0 || utils.Contains(types, elemStr[0:1]) {
@@ -1,7 +1,6 @@
 package trace
 
 import (
-	"analyzer/analysis"
 	"analyzer/clock"
 	"analyzer/logging"
 	"errors"
@@ -27,12 +26,14 @@ const (
  *   tpost (int): The timestamp of the event
  *   id (int): The id of the atomic variable
  *   operation (int, enum): The operation on the atomic variable
+ *   pos (string): The position of the atomic operation in the code, empty if not recorded
  */
 type TraceElementAtomic struct {
 	routine int
 	tPost   int
 	id      int
 	opA     opAtomic
+	pos     string
 	vc      clock.VectorClock
 }
 
@@ -44,9 +45,10 @@ type TraceElementAtomic struct {
  *   tpost (string): The timestamp of the event
  *   id (string): The id of the atomic variable
  *   operation (string): The operation on the atomic variable
+ *   pos (string): The position of the atomic operation in the code, empty if not recorded
  */
-func AddTraceElementAtomic(routine int, tpost string,
-	id string, operation string) error {
+func (t *Trace) AddTraceElementAtomic(routine int, tpost string,
+	id string, operation string, pos string) error {
 	tPostInt, err := strconv.Atoi(tpost)
 	if err != nil {
 		return errors.New("tpost is not an integer")
@@ -78,9 +80,10 @@ func AddTraceElementAtomic(routine int, tpost string,
 		tPost:   tPostInt,
 		id:      idInt,
 		opA:     opAInt,
+		pos:     pos,
 	}
 
-	return AddElementToTrace(&elem)
+	return t.AddElementToTrace(&elem)
 }
 
 // MARK: Getter
@@ -131,21 +134,47 @@ func (at *TraceElementAtomic) GetTSort() int {
 }
 
 /*
- * Get the position of the operation. For atomic elements, the position is always empty
+ * Get the position of the operation. For traces recorded without the
+ * position of atomic operations, the position is empty
  * Returns:
  *   string: The file of the element
  */
 func (at *TraceElementAtomic) GetPos() string {
-	return ""
+	return at.pos
 }
 
 /*
- * Get the tID of the element. For atomic elements, the tID is always empty
+ * Get the tID of the element. If the position of the element was not
+ * recorded, the tID is A@[tpost]
  * Returns:
  *   string: The tID of the element
  */
 func (at *TraceElementAtomic) GetTID() string {
-	return "A@" + strconv.Itoa(at.tPost)
+	if at.pos == "" {
+		return "A@" + strconv.Itoa(at.tPost)
+	}
+	return at.pos + "@" + strconv.Itoa(at.tPost)
+}
+
+/*
+ * Get the type of the operation as used in the analysis results, e.g. AL
+ * Returns:
+ *   string: The type of the operation
+ */
+func (at *TraceElementAtomic) GetObjType() string {
+	switch at.opA {
+	case LoadOp:
+		return "AL"
+	case StoreOp:
+		return "AS"
+	case AddOp:
+		return "AA"
+	case SwapOp:
+		return "AW"
+	case CompSwapOp:
+		return "AC"
+	}
+	return "AU"
 }
 
 /*
@@ -226,6 +255,10 @@ func (at *TraceElementAtomic) ToString() string {
 		res += "U"
 	}
 
+	if at.pos != "" {
+		res += "," + at.pos
+	}
+
 	return res
 }
 
@@ -234,37 +267,37 @@ func (at *TraceElementAtomic) ToString() string {
 /*
  * Update and calculate the vector clock of the element
  */
-func (at *TraceElementAtomic) updateVectorClock() {
+func (at *TraceElementAtomic) updateVectorClock(t *Trace) {
 	switch at.opA {
 	case LoadOp:
-		analysis.Read(at.routine, at.id, currentVCHb, true)
+		t.analysis.Read(at.routine, at.id, t.currentVCHb, true)
 	case StoreOp, AddOp:
-		analysis.Write(at.routine, at.id, currentVCHb)
+		t.analysis.Write(at.routine, at.id, t.currentVCHb)
 	case SwapOp, CompSwapOp:
-		analysis.Swap(at.routine, at.id, currentVCHb, true)
+		t.analysis.Swap(at.routine, at.id, t.currentVCHb, true)
 	default:
 		err := "Unknown operation: " + at.ToString()
 		logging.Debug(err, logging.ERROR)
 	}
-	at.vc = currentVCHb[at.routine].Copy()
+	at.vc = t.currentVCHb[at.routine].Copy()
 }
 
 /*
  * Update and calculate the vector clock of the element
  */
-func (at *TraceElementAtomic) updateVectorClockAlt() {
+func (at *TraceElementAtomic) updateVectorClockAlt(t *Trace) {
 	switch at.opA {
 	case LoadOp:
-		analysis.Read(at.routine, at.id, currentVCHb, false)
+		t.analysis.Read(at.routine, at.id, t.currentVCHb, false)
 	case StoreOp, AddOp:
-		analysis.Write(at.routine, at.id, currentVCHb)
+		t.analysis.Write(at.routine, at.id, t.currentVCHb)
 	case SwapOp, CompSwapOp:
-		analysis.Swap(at.routine, at.id, currentVCHb, false)
+		t.analysis.Swap(at.routine, at.id, t.currentVCHb, false)
 	default:
 		err := "Unknown operation: " + at.ToString()
 		logging.Debug(err, logging.ERROR)
 	}
-	at.vc = currentVCHb[at.routine].Copy()
+	at.vc = t.currentVCHb[at.routine].Copy()
 }
 
 // MARK: Copy
@@ -280,6 +313,7 @@ func (at *TraceElementAtomic) Copy() TraceElement {
 		tPost:   at.tPost,
 		id:      at.id,
 		opA:     at.opA,
+		pos:     at.pos,
 		vc:      at.vc.Copy(),
 	}
 }
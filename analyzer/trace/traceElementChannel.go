@@ -19,9 +19,6 @@ const (
 	Close
 )
 
-var waitingReceive = make([]*TraceElementChannel, 0)
-var maxOpID = make(map[int]int)
-
 /*
 * TraceElementChannel is a trace element for a channel
 * MARK: Struct
@@ -72,7 +69,7 @@ type TraceElementChannel struct {
 *   pos (string): The position of the channel operation in the code
 *   tID (string): The id of the trace element, contains the position and the tpre
  */
-func AddTraceElementChannel(routine int, tPre string,
+func (t *Trace) AddTraceElementChannel(routine int, tPre string,
 	tPost string, id string, opC string, cl string, oID string, qSize string,
 	pos string) error {
 
@@ -138,20 +135,20 @@ func AddTraceElementChannel(routine int, tPre string,
 
 	// check if partner was already processed, otherwise add to channelWithoutPartner
 	if tPostInt != 0 {
-		if _, ok := channelWithoutPartner[idInt][oIDInt]; ok {
-			elem.partner = channelWithoutPartner[idInt][oIDInt]
-			channelWithoutPartner[idInt][oIDInt].partner = &elem
-			delete(channelWithoutPartner[idInt], oIDInt)
+		if _, ok := t.channelWithoutPartner[idInt][oIDInt]; ok {
+			elem.partner = t.channelWithoutPartner[idInt][oIDInt]
+			t.channelWithoutPartner[idInt][oIDInt].partner = &elem
+			delete(t.channelWithoutPartner[idInt], oIDInt)
 		} else {
-			if _, ok := channelWithoutPartner[idInt]; !ok {
-				channelWithoutPartner[idInt] = make(map[int]*TraceElementChannel)
+			if _, ok := t.channelWithoutPartner[idInt]; !ok {
+				t.channelWithoutPartner[idInt] = make(map[int]*TraceElementChannel)
 			}
 
-			channelWithoutPartner[idInt][oIDInt] = &elem
+			t.channelWithoutPartner[idInt][oIDInt] = &elem
 		}
 	}
 
-	return AddElementToTrace(&elem)
+	return t.AddElementToTrace(&elem)
 }
 
 // MARK: Getter
@@ -240,6 +237,24 @@ func (ch *TraceElementChannel) IsBuffered() bool {
 	return ch.qSize != 0
 }
 
+/*
+ * Get the size of the channel buffer
+ * Returns:
+ *   int: The size of the buffer, 0 for unbuffered channels
+ */
+func (ch *TraceElementChannel) GetQSize() int {
+	return ch.qSize
+}
+
+/*
+ * Get if the operation finished because the channel was closed
+ * Returns:
+ *   bool: true if the operation finished because of a close
+ */
+func (ch *TraceElementChannel) IsClosed() bool {
+	return ch.cl
+}
+
 /*
  * Get the type of the operation
  * Returns:
@@ -435,7 +450,11 @@ func (ch *TraceElementChannel) toStringSep(sep string, pos bool) string {
 		panic("Unknown channel operation" + strconv.Itoa(int(ch.opC)))
 	}
 
-	res += sep + "f"
+	if ch.cl {
+		res += sep + "t"
+	} else {
+		res += sep + "f"
+	}
 
 	res += sep + strconv.Itoa(ch.oID)
 	res += sep + strconv.Itoa(ch.qSize)
@@ -449,21 +468,21 @@ func (ch *TraceElementChannel) toStringSep(sep string, pos bool) string {
  * Update and calculate the vector clock of the element
  * MARK: Vector Clock
  */
-func (ch *TraceElementChannel) updateVectorClock() {
+func (ch *TraceElementChannel) updateVectorClock(t *Trace) {
 	// hold back receive operations, until the send operation is processed
-	for _, elem := range waitingReceive {
-		if elem.oID <= maxOpID[ch.id] {
-			waitingReceive = waitingReceive[1:]
-			elem.updateVectorClock()
+	for _, elem := range t.waitingReceive {
+		if elem.oID <= t.maxOpID[ch.id] {
+			t.waitingReceive = t.waitingReceive[1:]
+			elem.updateVectorClock(t)
 		}
 	}
 	if ch.IsBuffered() && ch.tPost != 0 {
 		if ch.opC == Send {
-			maxOpID[ch.id] = ch.oID
+			t.maxOpID[ch.id] = ch.oID
 		} else if ch.opC == Recv {
 			logging.Debug("Holding back", logging.INFO)
-			if ch.oID > maxOpID[ch.id] && !ch.cl {
-				waitingReceive = append(waitingReceive, ch)
+			if ch.oID > t.maxOpID[ch.id] && !ch.cl {
+				t.waitingReceive = append(t.waitingReceive, ch)
 				return
 			}
 		}
@@ -472,50 +491,52 @@ func (ch *TraceElementChannel) updateVectorClock() {
 	if !ch.IsBuffered() { // unbuffered channel
 		switch ch.opC {
 		case Send:
-			partner := ch.findPartner()
+			partner := ch.findPartner(t)
 			if partner != -1 {
 				logging.Debug("Update vector clock of channel operation: "+
-					traces[partner][currentIndex[partner]].ToString(),
+					t.traces[partner][t.currentIndex[partner]].ToString(),
 					logging.DEBUG)
-				pos := traces[partner][currentIndex[partner]].(*TraceElementChannel).tID
-				analysis.Unbuffered(ch.routine, partner, ch.id, ch.tID,
-					pos, currentVCHb, ch.tPost)
+				pos := t.traces[partner][t.currentIndex[partner]].(*TraceElementChannel).tID
+				t.analysis.Unbuffered(ch.routine, partner, ch.id, ch.tID,
+					pos, t.currentVCHb, ch.tPost)
 				// advance index of receive routine, send routine is already advanced
-				increaseIndex(partner)
+				t.processedPartner = t.traces[partner][t.currentIndex[partner]]
+				t.increaseIndex(partner)
 			} else {
 				if ch.cl { // recv on closed channel
 					logging.Debug("Update vector clock of channel operation: "+
 						ch.ToString(), logging.DEBUG)
-					analysis.SendC(ch.routine, ch.id, ch.tID)
+					t.analysis.SendC(ch.routine, ch.id, ch.tID)
 				} else {
 					logging.Debug("Could not find partner for "+ch.tID, logging.INFO)
-					analysis.StuckChan(ch.routine, currentVCHb)
+					analysis.StuckChan(ch.routine, t.currentVCHb)
 				}
 			}
 
 		case Recv: // should not occur, but better save than sorry
-			partner := ch.findPartner()
+			partner := ch.findPartner(t)
 			if partner != -1 {
 				logging.Debug("Update vector clock of channel operation: "+
-					traces[partner][currentIndex[partner]].ToString(), logging.DEBUG)
-				tID := traces[partner][currentIndex[partner]].(*TraceElementChannel).tID
-				analysis.Unbuffered(partner, ch.routine, ch.id, tID,
-					ch.tID, currentVCHb, ch.tPost)
+					t.traces[partner][t.currentIndex[partner]].ToString(), logging.DEBUG)
+				tID := t.traces[partner][t.currentIndex[partner]].(*TraceElementChannel).tID
+				t.analysis.Unbuffered(partner, ch.routine, ch.id, tID,
+					ch.tID, t.currentVCHb, ch.tPost)
 				// advance index of receive routine, send routine is already advanced
-				increaseIndex(partner)
+				t.processedPartner = t.traces[partner][t.currentIndex[partner]]
+				t.increaseIndex(partner)
 			} else {
 				if ch.cl { // recv on closed channel
 					logging.Debug("Update vector clock of channel operation: "+
 						ch.ToString(), logging.DEBUG)
-					analysis.RecvC(ch.routine, ch.id, ch.tID,
-						currentVCHb, ch.tPost, false)
+					t.analysis.RecvC(ch.routine, ch.id, ch.tID,
+						t.currentVCHb, ch.tPost, false)
 				} else {
 					logging.Debug("Could not find partner for "+ch.tID, logging.INFO)
-					analysis.StuckChan(ch.routine, currentVCHb)
+					analysis.StuckChan(ch.routine, t.currentVCHb)
 				}
 			}
 		case Close:
-			analysis.Close(ch.routine, ch.id, ch.tID, currentVCHb, ch.tPost, ch.IsBuffered())
+			t.analysis.Close(ch.routine, ch.id, ch.tID, t.currentVCHb, ch.tPost, ch.IsBuffered())
 		default:
 			err := "Unknown operation: " + ch.ToString()
 			logging.Debug(err, logging.ERROR)
@@ -525,32 +546,32 @@ func (ch *TraceElementChannel) updateVectorClock() {
 		case Send:
 			logging.Debug("Update vector clock of channel operation: "+
 				ch.ToString(), logging.DEBUG)
-			analysis.Send(ch.routine, ch.id, ch.oID, ch.qSize, ch.tID,
-				currentVCHb, fifo, ch.tPost)
+			t.analysis.Send(ch.routine, ch.id, ch.oID, ch.qSize, ch.tID,
+				t.currentVCHb, t.fifo, ch.tPost)
 		case Recv:
 			if ch.cl { // recv on closed channel
 				logging.Debug("Update vector clock of channel operation: "+
 					ch.ToString(), logging.DEBUG)
-				analysis.RecvC(ch.routine, ch.id, ch.tID, currentVCHb, ch.tPost, true)
+				t.analysis.RecvC(ch.routine, ch.id, ch.tID, t.currentVCHb, ch.tPost, true)
 			} else {
 				logging.Debug("Update vector clock of channel operation: "+
 					ch.ToString(), logging.DEBUG)
-				analysis.Recv(ch.routine, ch.id, ch.oID, ch.qSize, ch.tID,
-					currentVCHb, fifo, ch.tPost)
+				t.analysis.Recv(ch.routine, ch.id, ch.oID, ch.qSize, ch.tID,
+					t.currentVCHb, t.fifo, ch.tPost)
 			}
 		case Close:
 			logging.Debug("Update vector clock of channel operation: "+
 				ch.ToString(), logging.DEBUG)
-			analysis.Close(ch.routine, ch.id, ch.tID, currentVCHb, ch.tPost, ch.IsBuffered())
+			t.analysis.Close(ch.routine, ch.id, ch.tID, t.currentVCHb, ch.tPost, ch.IsBuffered())
 		default:
 			err := "Unknown operation: " + ch.ToString()
 			logging.Debug(err, logging.ERROR)
 		}
 	}
 
-	ch.vc = currentVCHb[ch.routine].Copy()
+	ch.vc = t.currentVCHb[ch.routine].Copy()
 	if ch.partner != nil {
-		ch.partner.vc = currentVCHb[ch.partner.routine].Copy()
+		ch.partner.vc = t.currentVCHb[ch.partner.routine].Copy()
 	}
 }
 
@@ -560,20 +581,20 @@ func (ch *TraceElementChannel) updateVectorClock() {
  * Returns:
  *   int: The routine id of the partner, -1 if no partner was found
  */
-func (ch *TraceElementChannel) findPartner() int {
+func (ch *TraceElementChannel) findPartner(t *Trace) int {
 	// return -1 if closed by channel
 	if ch.cl {
 		return -1
 	}
 
-	for routine, trace := range traces {
-		if currentIndex[routine] == -1 {
+	for routine, trace := range t.traces {
+		if t.currentIndex[routine] == -1 {
 			continue
 		}
 		// if routine == ch.routine {
 		// 	continue
 		// }
-		elem := trace[currentIndex[routine]]
+		elem := trace[t.currentIndex[routine]]
 		switch e := elem.(type) {
 		case *TraceElementChannel:
 			if e.id == ch.id && e.oID == ch.oID {
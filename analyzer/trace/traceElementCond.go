@@ -1,7 +1,6 @@
 package trace
 
 import (
-	"analyzer/analysis"
 	"analyzer/clock"
 	"errors"
 	"math"
@@ -51,7 +50,7 @@ type TraceElementCond struct {
  *   pos (string): The position of the condition variable operation in the code
  *   tID (string): The id of the trace element, contains the position and the tpre
  */
-func AddTraceElementCond(routine int, tPre string, tPost string, id string, opN string, pos string) error {
+func (t *Trace) AddTraceElementCond(routine int, tPre string, tPost string, id string, opN string, pos string) error {
 	tPreInt, err := strconv.Atoi(tPre)
 	if err != nil {
 		return errors.New("tpre is not an integer")
@@ -88,7 +87,7 @@ func AddTraceElementCond(routine int, tPre string, tPost string, id string, opN
 		tID:     tIDStr,
 	}
 
-	return AddElementToTrace(&elem)
+	return t.AddElementToTrace(&elem)
 }
 
 // MARK: Getter
@@ -191,12 +190,12 @@ func (co *TraceElementCond) GetVC() clock.VectorClock {
  * Returns:
  *   []*traceElement: The concurrent elements
  */
-func GetConcurrentWaitgroups(element *TraceElement) map[string][]*TraceElement {
+func (t *Trace) GetConcurrentWaitgroups(element *TraceElement) map[string][]*TraceElement {
 	res := make(map[string][]*TraceElement)
 	res["broadcast"] = make([]*TraceElement, 0)
 	res["signal"] = make([]*TraceElement, 0)
 	res["wait"] = make([]*TraceElement, 0)
-	for _, trace := range traces {
+	for _, trace := range t.traces {
 		for _, elem := range trace {
 			switch elem.(type) {
 			case *TraceElementCond:
@@ -312,17 +311,17 @@ func (co *TraceElementCond) ToString() string {
  * Update the vector clock of the trace and element
  * MARK: VectorClock
  */
-func (co *TraceElementCond) updateVectorClock() {
+func (co *TraceElementCond) updateVectorClock(t *Trace) {
 	switch co.opC {
 	case WaitCondOp:
-		analysis.CondWait(co.id, co.routine, currentVCHb, co.tPost == 0)
+		t.analysis.CondWait(co.id, co.routine, co.tID, co.tPre, co.tPost, t.currentVCHb)
 	case SignalOp:
-		analysis.CondSignal(co.id, co.routine, currentVCHb)
+		t.analysis.CondSignal(co.id, co.routine, co.tID, co.tPre, t.currentVCHb)
 	case BroadcastOp:
-		analysis.CondBroadcast(co.id, co.routine, currentVCHb)
+		t.analysis.CondBroadcast(co.id, co.routine, co.tID, co.tPre, t.currentVCHb)
 	}
 
-	co.vc = currentVCHb[co.routine].Copy()
+	co.vc = t.currentVCHb[co.routine].Copy()
 }
 
 // MARK: Copy
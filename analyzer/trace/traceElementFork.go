@@ -35,7 +35,7 @@ type TraceElementFork struct {
  *   id (string): The id of the new routine
  *   pos (string): The position of the trace element in the file
  */
-func AddTraceElementFork(routine int, tPost string, id string, pos string) error {
+func (t *Trace) AddTraceElementFork(routine int, tPost string, id string, pos string) error {
 	tPostInt, err := strconv.Atoi(tPost)
 	if err != nil {
 		return errors.New("tpre is not an integer")
@@ -55,7 +55,7 @@ func AddTraceElementFork(routine int, tPost string, id string, pos string) error
 		pos:     pos,
 		tID:     tIDStr,
 	}
-	return AddElementToTrace(&elem)
+	return t.AddElementToTrace(&elem)
 }
 
 // MARK Getter
@@ -190,10 +190,10 @@ func (fo *TraceElementFork) ToString() string {
  * Update and calculate the vector clock of the element
  * MARK: VectorClock
  */
-func (fo *TraceElementFork) updateVectorClock() {
-	analysis.Fork(fo.routine, fo.id, currentVCHb, currentVCWmhb)
+func (fo *TraceElementFork) updateVectorClock(t *Trace) {
+	analysis.Fork(fo.routine, fo.id, t.currentVCHb, t.currentVCWmhb)
 
-	fo.vc = currentVCHb[fo.routine].Copy()
+	fo.vc = t.currentVCHb[fo.routine].Copy()
 }
 
 /*
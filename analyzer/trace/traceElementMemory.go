@@ -0,0 +1,275 @@
+package trace
+
+import (
+	"analyzer/clock"
+	"errors"
+	"strconv"
+)
+
+// enum for operation
+type opMemory int
+
+const (
+	ReadMemOp opMemory = iota
+	WriteMemOp
+)
+
+/*
+ * Struct to save a read or write on memory in the trace. Memory accesses are
+ * only recorded for selected packages of programs built with -race.
+ * MARK: Struct
+ * Fields:
+ *   routine (int): The routine id
+ *   tPost (int): The timestamp of the event
+ *   addr (int): The address of the accessed memory
+ *   opM (int, enum): The operation on the memory
+ *   gc (int): The number of completed gc cycles at the access
+ *   pos (string): The position of the access in the code
+ *   vc (VectorClock): The vector clock of the routine after the access
+ */
+type TraceElementMemory struct {
+	routine int
+	tPost   int
+	addr    int
+	opM     opMemory
+	gc      int
+	pos     string
+	vc      clock.VectorClock
+}
+
+/*
+ * Create a new memory access trace element
+ * MARK: New
+ * Args:
+ *   routine (int): The routine id
+ *   tpost (string): The timestamp of the event
+ *   addr (string): The address of the accessed memory
+ *   operation (string): The operation on the memory
+ *   gc (string): The number of completed gc cycles at the access
+ *   pos (string): The position of the access in the code
+ */
+func (t *Trace) AddTraceElementMemory(routine int, tpost string,
+	addr string, operation string, gc string, pos string) error {
+	tPostInt, err := strconv.Atoi(tpost)
+	if err != nil {
+		return errors.New("tpost is not an integer")
+	}
+
+	addrInt, err := strconv.Atoi(addr)
+	if err != nil {
+		return errors.New("addr is not an integer")
+	}
+
+	var opMInt opMemory
+	switch operation {
+	case "R":
+		opMInt = ReadMemOp
+	case "W":
+		opMInt = WriteMemOp
+	default:
+		return errors.New("operation is not a valid operation")
+	}
+
+	gcInt, err := strconv.Atoi(gc)
+	if err != nil {
+		return errors.New("gc is not an integer")
+	}
+
+	elem := TraceElementMemory{
+		routine: routine,
+		tPost:   tPostInt,
+		addr:    addrInt,
+		opM:     opMInt,
+		gc:      gcInt,
+		pos:     pos,
+	}
+
+	return t.AddElementToTrace(&elem)
+}
+
+// MARK: Getter
+
+/*
+ * Get the id of the element. For memory accesses, the id is the address
+ * Returns:
+ *   int: The id of the element
+ */
+func (me *TraceElementMemory) GetID() int {
+	return me.addr
+}
+
+/*
+ * Get the routine of the element
+ * Returns:
+ *   int: The routine of the element
+ */
+func (me *TraceElementMemory) GetRoutine() int {
+	return me.routine
+}
+
+/*
+ * Get the tpost of the element. For memory accesses, tpre and tpost are the same
+ * Returns:
+ *   int: The tpost of the element
+ */
+func (me *TraceElementMemory) GetTPre() int {
+	return me.tPost
+}
+
+/*
+ * Get the tpost of the element. For memory accesses, tpre and tpost are the same
+ * Returns:
+ *   int: The tpost of the element
+ */
+func (me *TraceElementMemory) getTpost() int {
+	return me.tPost
+}
+
+/*
+ * Get the timer, that is used for the sorting of the trace
+ * Returns:
+ *   int: The timer of the element
+ */
+func (me *TraceElementMemory) GetTSort() int {
+	return me.tPost
+}
+
+/*
+ * Get the position of the operation.
+ * Returns:
+ *   string: The file of the element
+ */
+func (me *TraceElementMemory) GetPos() string {
+	return me.pos
+}
+
+/*
+ * Get the tID of the element.
+ * Returns:
+ *   string: The tID of the element
+ */
+func (me *TraceElementMemory) GetTID() string {
+	return me.pos + "@" + strconv.Itoa(me.tPost)
+}
+
+/*
+ * Get the type of the operation as used in the analysis results, e.g. DR
+ * Returns:
+ *   string: The type of the operation
+ */
+func (me *TraceElementMemory) GetObjType() string {
+	if me.opM == WriteMemOp {
+		return "DW"
+	}
+	return "DR"
+}
+
+/*
+ * Check if the access is a write
+ * Returns:
+ *   bool: true if the access is a write, false if it is a read
+ */
+func (me *TraceElementMemory) IsWrite() bool {
+	return me.opM == WriteMemOp
+}
+
+/*
+ * Get the vector clock of the element
+ * Returns:
+ *   VectorClock: The vector clock of the element
+ */
+func (me *TraceElementMemory) GetVC() clock.VectorClock {
+	return me.vc
+}
+
+// MARK: Setter
+
+/*
+ * Set the tPre and tPost of the element
+ * Args:
+ *   time (int): The tPre and tPost of the element
+ */
+func (me *TraceElementMemory) SetT(time int) {
+	me.tPost = time
+}
+
+/*
+ * Set the tpre of the element.
+ * Args:
+ *   tPre (int): The tpost of the element
+ */
+func (me *TraceElementMemory) SetTPre(tPre int) {
+	me.tPost = tPre
+}
+
+/*
+ * Set the timer, that is used for the sorting of the trace
+ * Args:
+ *   tSort (int): The timer of the element
+ */
+func (me *TraceElementMemory) SetTSort(tSort int) {
+	me.tPost = tSort
+}
+
+/*
+ * Set the timer, that is used for the sorting of the trace, only if the original
+ * value was not 0
+ * Args:
+ *   tSort (int): The timer of the element
+ */
+func (me *TraceElementMemory) SetTWithoutNotExecuted(tSort int) {
+	if me.tPost != 0 {
+		me.tPost = tSort
+	}
+}
+
+// MARK: ToString
+
+/*
+ * Get the simple string representation of the element.
+ * Returns:
+ *   string: The simple string representation of the element
+ */
+func (me *TraceElementMemory) ToString() string {
+	res := "D," + strconv.Itoa(me.tPost) + "," + strconv.Itoa(me.addr) + ","
+
+	if me.opM == WriteMemOp {
+		res += "W"
+	} else {
+		res += "R"
+	}
+
+	return res + "," + strconv.Itoa(me.gc) + "," + me.pos
+}
+
+// MARK: Vector Clock
+
+/*
+ * Update and calculate the vector clock of the element. A memory access does
+ * not synchronize with other routines. The clock of the routine is
+ * incremented, so that the access is not ordered before the operations of
+ * routines, that synchronized with the routine before the access.
+ */
+func (me *TraceElementMemory) updateVectorClock(t *Trace) {
+	t.currentVCHb[me.routine] = t.currentVCHb[me.routine].Inc(me.routine)
+	me.vc = t.currentVCHb[me.routine].Copy()
+}
+
+// MARK: Copy
+
+/*
+ * Copy the memory access element
+ * Returns:
+ *   TraceElement: The copy of the element
+ */
+func (me *TraceElementMemory) Copy() TraceElement {
+	return &TraceElementMemory{
+		routine: me.routine,
+		tPost:   me.tPost,
+		addr:    me.addr,
+		opM:     me.opM,
+		gc:      me.gc,
+		pos:     me.pos,
+		vc:      me.vc.Copy(),
+	}
+}
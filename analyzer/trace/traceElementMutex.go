@@ -5,7 +5,6 @@ import (
 	"math"
 	"strconv"
 
-	"analyzer/analysis"
 	"analyzer/clock"
 	"analyzer/logging"
 )
@@ -64,7 +63,7 @@ type TraceElementMutex struct {
  *   suc (string): Whether the operation was successful (only for trylock else always true)
  *   pos (string): The position of the mutex operation in the code
  */
-func AddTraceElementMutex(routine int, tPre string,
+func (t *Trace) AddTraceElementMutex(routine int, tPre string,
 	tPost string, id string, rw string, opM string, suc string,
 	pos string) error {
 	tPreInt, err := strconv.Atoi(tPre)
@@ -124,7 +123,7 @@ func AddTraceElementMutex(routine int, tPre string,
 		tID:     tIDStr,
 	}
 
-	return AddElementToTrace(&elem)
+	return t.AddElementToTrace(&elem)
 }
 
 // MARK: Getter
@@ -205,6 +204,15 @@ func (mu *TraceElementMutex) GetOperation() OpMutex {
 	return mu.opM
 }
 
+/*
+ * Get if the operation was successful, only false for a failed try(r)lock
+ * Returns:
+ *   bool: If the operation was successful
+ */
+func (mu *TraceElementMutex) IsSuc() bool {
+	return mu.suc
+}
+
 /*
  * Get if the element is a lock operation
  * Returns:
@@ -311,59 +319,56 @@ func (mu *TraceElementMutex) ToString() string {
 	return res
 }
 
-// mutex operations, for which no partner has been found yet
-var mutexNoPartner []*TraceElementMutex
-
 /*
  * Update the vector clock of the trace and element
  * MARK: VectorClock
  */
-func (mu *TraceElementMutex) updateVectorClock() {
+func (mu *TraceElementMutex) updateVectorClock(t *Trace) {
 	switch mu.opM {
 	case LockOp:
-		analysis.Lock(mu.routine, mu.id, currentVCHb, currentVCWmhb, mu.tID, mu.tPost)
-		if analysisCases["cyclicDeadlock"] {
-			analysis.AnalysisCyclickDeadlockMutexLock(mu.id, mu.tID, mu.routine, mu.rw, false, currentVCWmhb[mu.routine], mu.tPost)
+		t.analysis.Lock(mu.routine, mu.id, t.currentVCHb, t.currentVCWmhb, mu.tID, mu.tPost)
+		if t.analysisCases["cyclicDeadlock"] {
+			t.analysis.AnalysisCyclickDeadlockMutexLock(mu.id, mu.tID, mu.routine, mu.rw, false, t.currentVCWmhb[mu.routine], mu.tPost)
 		}
 	case RLockOp:
-		analysis.RLock(mu.routine, mu.id, currentVCHb, currentVCWmhb, mu.tID, mu.tPost)
-		if analysisCases["cyclicDeadlock"] {
-			analysis.AnalysisCyclickDeadlockMutexLock(mu.id, mu.tID, mu.routine, mu.rw, true, currentVCWmhb[mu.routine], mu.tPost)
+		t.analysis.RLock(mu.routine, mu.id, t.currentVCHb, t.currentVCWmhb, mu.tID, mu.tPost)
+		if t.analysisCases["cyclicDeadlock"] {
+			t.analysis.AnalysisCyclickDeadlockMutexLock(mu.id, mu.tID, mu.routine, mu.rw, true, t.currentVCWmhb[mu.routine], mu.tPost)
 		}
 	case TryLockOp:
 		if mu.suc {
-			analysis.Lock(mu.routine, mu.id, currentVCHb, currentVCWmhb, mu.tID, mu.tPost)
-			if analysisCases["cyclicDeadlock"] {
-				analysis.AnalysisCyclickDeadlockMutexLock(mu.id, mu.tID, mu.routine, mu.rw, false, currentVCWmhb[mu.routine], mu.tPost)
+			t.analysis.Lock(mu.routine, mu.id, t.currentVCHb, t.currentVCWmhb, mu.tID, mu.tPost)
+			if t.analysisCases["cyclicDeadlock"] {
+				t.analysis.AnalysisCyclickDeadlockMutexLock(mu.id, mu.tID, mu.routine, mu.rw, false, t.currentVCWmhb[mu.routine], mu.tPost)
 			}
 		}
 	case TryRLockOp:
 		if mu.suc {
-			analysis.RLock(mu.routine, mu.id, currentVCHb, currentVCWmhb, mu.tID, mu.tPost)
-			if analysisCases["cyclicDeadlock"] {
-				analysis.AnalysisCyclickDeadlockMutexLock(mu.id, mu.tID, mu.routine, mu.rw, true, currentVCWmhb[mu.routine], mu.tPost)
+			t.analysis.RLock(mu.routine, mu.id, t.currentVCHb, t.currentVCWmhb, mu.tID, mu.tPost)
+			if t.analysisCases["cyclicDeadlock"] {
+				t.analysis.AnalysisCyclickDeadlockMutexLock(mu.id, mu.tID, mu.routine, mu.rw, true, t.currentVCWmhb[mu.routine], mu.tPost)
 			}
 		}
 	case UnlockOp:
-		analysis.Unlock(mu.routine, mu.id, currentVCHb, mu.tPost)
-		if analysisCases["cyclicDeadlock"] {
-			analysis.AnalysisCyclicDeadlockMutexUnLock(mu.id, mu.routine, mu.tPost)
+		t.analysis.Unlock(mu.routine, mu.id, t.currentVCHb, mu.tPost)
+		if t.analysisCases["cyclicDeadlock"] {
+			t.analysis.AnalysisCyclicDeadlockMutexUnLock(mu.id, mu.routine, mu.tPost)
 		}
 	case RUnlockOp:
-		analysis.RUnlock(mu.routine, mu.id, currentVCHb, mu.tPost)
-		if analysisCases["cyclicDeadlock"] {
-			analysis.AnalysisCyclicDeadlockMutexUnLock(mu.id, mu.routine, mu.tPost)
+		t.analysis.RUnlock(mu.routine, mu.id, t.currentVCHb, mu.tPost)
+		if t.analysisCases["cyclicDeadlock"] {
+			t.analysis.AnalysisCyclicDeadlockMutexUnLock(mu.id, mu.routine, mu.tPost)
 		}
 	default:
 		err := "Unknown mutex operation: " + mu.ToString()
 		logging.Debug(err, logging.ERROR)
 	}
-	mu.vc = currentVCHb[mu.routine].Copy()
+	mu.vc = t.currentVCHb[mu.routine].Copy()
 }
 
-func (mu *TraceElementMutex) updateVectorClockAlt() {
-	currentVCHb[mu.routine] = currentVCHb[mu.routine].Inc(mu.routine)
-	mu.vc = currentVCHb[mu.routine].Copy()
+func (mu *TraceElementMutex) updateVectorClockAlt(t *Trace) {
+	t.currentVCHb[mu.routine] = t.currentVCHb[mu.routine].Inc(mu.routine)
+	mu.vc = t.currentVCHb[mu.routine].Copy()
 }
 
 /*
@@ -5,7 +5,6 @@ import (
 	"math"
 	"strconv"
 
-	"analyzer/analysis"
 	"analyzer/clock"
 )
 
@@ -43,7 +42,7 @@ type TraceElementOnce struct {
  *   suc (string): Whether the operation was successful (only for trylock else always true)
  *   pos (string): The position of the mutex operation in the code
  */
-func AddTraceElementOnce(routine int, tPre string,
+func (t *Trace) AddTraceElementOnce(routine int, tPre string,
 	tPost string, id string, suc string, pos string) error {
 	tPreInt, err := strconv.Atoi(tPre)
 	if err != nil {
@@ -77,7 +76,7 @@ func AddTraceElementOnce(routine int, tPre string,
 		tID:     tIDStr,
 	}
 
-	return AddElementToTrace(&elem)
+	return t.AddElementToTrace(&elem)
 }
 
 // MARK: Getter
@@ -149,6 +148,15 @@ func (on *TraceElementOnce) GetTID() string {
 	return on.tID
 }
 
+/*
+ * Check if the function in the once was executed by this do
+ * Returns:
+ *   bool: true if the function was executed
+ */
+func (on *TraceElementOnce) IsSuc() bool {
+	return on.suc
+}
+
 /*
  * Get the vector clock of the element
  * Returns:
@@ -229,14 +237,14 @@ func (on *TraceElementOnce) ToString() string {
  * Update the vector clock of the trace and element
  * MARK: VectorClock
  */
-func (on *TraceElementOnce) updateVectorClock() {
+func (on *TraceElementOnce) updateVectorClock(t *Trace) {
 	if on.suc {
-		analysis.DoSuc(on.routine, on.id, currentVCHb)
+		t.analysis.DoSuc(on.routine, on.id, t.currentVCHb)
 	} else {
-		analysis.DoFail(on.routine, on.id, currentVCHb)
+		t.analysis.DoFail(on.routine, on.id, t.currentVCHb)
 	}
 
-	on.vc = currentVCHb[on.routine].Copy()
+	on.vc = t.currentVCHb[on.routine].Copy()
 }
 
 /*
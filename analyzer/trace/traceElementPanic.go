@@ -0,0 +1,198 @@
+package trace
+
+import (
+	"analyzer/clock"
+	"errors"
+	"strconv"
+)
+
+/*
+ * Struct to save a panic in the trace
+ * MARK: Struct
+ * Fields:
+ *   routine (int): The routine id
+ *   tPost (int): The timestamp of the panic
+ *   pos (string): The position of the panic in the code
+ *   vc (VectorClock): The vector clock of the routine at the panic
+ */
+type TraceElementPanic struct {
+	routine int
+	tPost   int
+	pos     string
+	vc      clock.VectorClock
+}
+
+/*
+ * Create a new panic trace element
+ * MARK: New
+ * Args:
+ *   routine (int): The routine id
+ *   tpost (string): The timestamp of the panic
+ *   pos (string): The position of the panic in the code
+ */
+func (t *Trace) AddTraceElementPanic(routine int, tpost string, pos string) error {
+	tPostInt, err := strconv.Atoi(tpost)
+	if err != nil {
+		return errors.New("tpost is not an integer")
+	}
+
+	elem := TraceElementPanic{
+		routine: routine,
+		tPost:   tPostInt,
+		pos:     pos,
+	}
+
+	return t.AddElementToTrace(&elem)
+}
+
+// MARK: Getter
+
+/*
+ * Get the id of the element. A panic has no object, the id is always 0
+ * Returns:
+ *   int: The id of the element
+ */
+func (pa *TraceElementPanic) GetID() int {
+	return 0
+}
+
+/*
+ * Get the routine of the element
+ * Returns:
+ *   int: The routine of the element
+ */
+func (pa *TraceElementPanic) GetRoutine() int {
+	return pa.routine
+}
+
+/*
+ * Get the tpost of the element. For panics, tpre and tpost are the same
+ * Returns:
+ *   int: The tpost of the element
+ */
+func (pa *TraceElementPanic) GetTPre() int {
+	return pa.tPost
+}
+
+/*
+ * Get the tpost of the element. For panics, tpre and tpost are the same
+ * Returns:
+ *   int: The tpost of the element
+ */
+func (pa *TraceElementPanic) getTpost() int {
+	return pa.tPost
+}
+
+/*
+ * Get the timer, that is used for the sorting of the trace
+ * Returns:
+ *   int: The timer of the element
+ */
+func (pa *TraceElementPanic) GetTSort() int {
+	return pa.tPost
+}
+
+/*
+ * Get the position of the panic.
+ * Returns:
+ *   string: The file of the element
+ */
+func (pa *TraceElementPanic) GetPos() string {
+	return pa.pos
+}
+
+/*
+ * Get the tID of the element.
+ * Returns:
+ *   string: The tID of the element
+ */
+func (pa *TraceElementPanic) GetTID() string {
+	return pa.pos + "@" + strconv.Itoa(pa.tPost)
+}
+
+/*
+ * Get the vector clock of the element
+ * Returns:
+ *   VectorClock: The vector clock of the element
+ */
+func (pa *TraceElementPanic) GetVC() clock.VectorClock {
+	return pa.vc
+}
+
+// MARK: Setter
+
+/*
+ * Set the tPre and tPost of the element
+ * Args:
+ *   time (int): The tPre and tPost of the element
+ */
+func (pa *TraceElementPanic) SetT(time int) {
+	pa.tPost = time
+}
+
+/*
+ * Set the tpre of the element.
+ * Args:
+ *   tPre (int): The tpost of the element
+ */
+func (pa *TraceElementPanic) SetTPre(tPre int) {
+	pa.tPost = tPre
+}
+
+/*
+ * Set the timer, that is used for the sorting of the trace
+ * Args:
+ *   tSort (int): The timer of the element
+ */
+func (pa *TraceElementPanic) SetTSort(tSort int) {
+	pa.tPost = tSort
+}
+
+/*
+ * Set the timer, that is used for the sorting of the trace, only if the original
+ * value was not 0
+ * Args:
+ *   tSort (int): The timer of the element
+ */
+func (pa *TraceElementPanic) SetTWithoutNotExecuted(tSort int) {
+	if pa.tPost != 0 {
+		pa.tPost = tSort
+	}
+}
+
+// MARK: ToString
+
+/*
+ * Get the simple string representation of the element.
+ * Returns:
+ *   string: The simple string representation of the element
+ */
+func (pa *TraceElementPanic) ToString() string {
+	return "P," + strconv.Itoa(pa.tPost) + "," + pa.pos
+}
+
+// MARK: Vector Clock
+
+/*
+ * Store the vector clock of the element. A panic does not synchronize with
+ * other routines.
+ */
+func (pa *TraceElementPanic) updateVectorClock(t *Trace) {
+	pa.vc = t.currentVCHb[pa.routine].Copy()
+}
+
+// MARK: Copy
+
+/*
+ * Copy the panic element
+ * Returns:
+ *   TraceElement: The copy of the element
+ */
+func (pa *TraceElementPanic) Copy() TraceElement {
+	return &TraceElementPanic{
+		routine: pa.routine,
+		tPost:   pa.tPost,
+		pos:     pa.pos,
+		vc:      pa.vc.Copy(),
+	}
+}
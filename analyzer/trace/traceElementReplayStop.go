@@ -20,16 +20,16 @@ type TraceElementReplay struct {
  * Create a new atomic trace element
  * MARK: New
  * Args:
- *   t (string): The timestamp of the event
+ *   tPost (string): The timestamp of the event
  *   exitCode (int): The exit code of the event
  */
-func AddTraceElementReplay(t int, exitCode int) error {
+func (t *Trace) AddTraceElementReplay(tPost int, exitCode int) error {
 	elem := TraceElementReplay{
-		tPost:    t,
+		tPost:    tPost,
 		exitCode: exitCode,
 	}
 
-	return AddElementToTrace(&elem)
+	return t.AddElementToTrace(&elem)
 }
 
 // MARK: Getter
@@ -78,6 +78,15 @@ func (at *TraceElementReplay) GetTSort() int {
 	return at.tPost
 }
 
+/*
+ * Get the exit code of the replay stop element
+ * Returns:
+ *   int: The exit code
+ */
+func (at *TraceElementReplay) GetExitCode() int {
+	return at.exitCode
+}
+
 /*
  * Get the position of the operation.
  * Returns:
@@ -164,7 +173,7 @@ func (at *TraceElementReplay) ToString() string {
  * Update and calculate the vector clock of the element
  * MARK: VectorClock
  */
-func (at *TraceElementReplay) updateVectorClock() {
+func (at *TraceElementReplay) updateVectorClock(t *Trace) {
 	// nothing to do
 }
 
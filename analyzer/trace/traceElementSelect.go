@@ -52,7 +52,7 @@ type TraceElementSelect struct {
  *   chosenIndex (string): The internal index of chosen case
  *   pos (string): The position of the select statement in the code
  */
-func AddTraceElementSelect(routine int, tPre string,
+func (t *Trace) AddTraceElementSelect(routine int, tPre string,
 	tPost string, id string, cases string, chosenIndex string, pos string) error {
 
 	tPreInt, err := strconv.Atoi(tPre)
@@ -176,20 +176,20 @@ func AddTraceElementSelect(routine int, tPre string,
 	if tPostInt != 0 {
 		id := elem.chosenCase.id
 		oID := elem.chosenCase.oID
-		if _, ok := channelWithoutPartner[id][oID]; ok {
-			elem.chosenCase.partner = channelWithoutPartner[id][oID]
-			channelWithoutPartner[elem.chosenCase.id][oID].partner = &elem.chosenCase
-			delete(channelWithoutPartner[id], oID)
+		if _, ok := t.channelWithoutPartner[id][oID]; ok {
+			elem.chosenCase.partner = t.channelWithoutPartner[id][oID]
+			t.channelWithoutPartner[elem.chosenCase.id][oID].partner = &elem.chosenCase
+			delete(t.channelWithoutPartner[id], oID)
 		} else {
-			if _, ok := channelWithoutPartner[id]; !ok {
-				channelWithoutPartner[id] = make(map[int]*TraceElementChannel)
+			if _, ok := t.channelWithoutPartner[id]; !ok {
+				t.channelWithoutPartner[id] = make(map[int]*TraceElementChannel)
 			}
 
-			channelWithoutPartner[id][oID] = &elem.chosenCase
+			t.channelWithoutPartner[id][oID] = &elem.chosenCase
 		}
 	}
 
-	return AddElementToTrace(&elem)
+	return t.AddElementToTrace(&elem)
 }
 
 // MARK: Getter
@@ -291,6 +291,36 @@ func (se *TraceElementSelect) GetPartner() *TraceElementChannel {
 	return nil
 }
 
+/*
+ * Check if the select contains a default case
+ * Returns:
+ *   bool: true if the select contains a default case
+ */
+func (se *TraceElementSelect) ContainsDefault() bool {
+	return se.containsDefault
+}
+
+/*
+ * Check if the default case of the select was chosen
+ * Returns:
+ *   bool: true if the default case was chosen
+ */
+func (se *TraceElementSelect) GetChosenDefault() bool {
+	return se.chosenDefault
+}
+
+/*
+ * Get the executed case of the select
+ * Returns:
+ *   *TraceElementChannel: the chosen case, nil if the default case was chosen or the select did not finish
+ */
+func (se *TraceElementSelect) GetChosenCase() *TraceElementChannel {
+	if se.chosenDefault || se.chosenCase.tPost == 0 {
+		return nil
+	}
+	return &se.chosenCase
+}
+
 // MARK: Setter
 
 /*
@@ -407,6 +437,83 @@ func (se *TraceElementSelect) SetTWithoutNotExecuted2(tSort int) {
 	}
 }
 
+/*
+ * Set the default case as the chosen case of the select
+ * Returns:
+ *   error: An error if the select does not contain a default case
+ */
+func (se *TraceElementSelect) SetChosenDefault() error {
+	if !se.containsDefault {
+		return errors.New("Select does not contain a default case")
+	}
+
+	for i := range se.cases {
+		se.cases[i].tPost = 0
+		se.cases[i].cl = false
+	}
+
+	se.chosenCase = TraceElementChannel{}
+	se.chosenIndex = -1
+	se.chosenDefault = true
+	return nil
+}
+
+/*
+ * Set the case with the given channel and direction as the chosen case of
+ * the select. If the select did not finish, its tpost is set to its tpre
+ * Args:
+ *   chanID (int): The id of the channel of the case, -1 for a nil channel
+ *   send (bool): true for a send case, false for a receive case
+ * Returns:
+ *   error: An error if the select does not contain the case
+ */
+func (se *TraceElementSelect) SetCase(chanID int, send bool) error {
+	op := Recv
+	if send {
+		op = Send
+	}
+
+	for i, c := range se.cases {
+		if c.id != chanID || c.opC != op {
+			continue
+		}
+
+		if se.tPost == 0 {
+			se.tPost = se.tPre
+		}
+
+		for j := range se.cases {
+			se.cases[j].tPost = 0
+			se.cases[j].cl = false
+		}
+		se.cases[i].tPost = se.tPost
+
+		se.chosenCase = se.cases[i]
+		se.chosenIndex = i
+		se.chosenDefault = false
+		return nil
+	}
+
+	return errors.New("Select does not contain a case for the channel " + strconv.Itoa(chanID))
+}
+
+/*
+ * Set the oID of the chosen case of the select
+ * Args:
+ *   oID (int): The oID of the communication of the chosen case
+ * Returns:
+ *   error: An error if no channel case was chosen
+ */
+func (se *TraceElementSelect) SetChosenOID(oID int) error {
+	if se.chosenIndex < 0 || se.chosenIndex >= len(se.cases) {
+		return errors.New("No case of the select was chosen")
+	}
+
+	se.cases[se.chosenIndex].oID = oID
+	se.chosenCase.oID = oID
+	return nil
+}
+
 /*
  * Get the simple string representation of the element
  * MARK: ToString
@@ -447,17 +554,36 @@ func (se *TraceElementSelect) ToString() string {
  * Update and calculate the vector clock of the select element.
  * MARK: VectorClock
  */
-func (se *TraceElementSelect) updateVectorClock() {
+func (se *TraceElementSelect) updateVectorClock(t *Trace) {
 	leak := se.chosenDefault || se.tPost == 0
 
+	// a select with default is often used to check if a channel has already
+	// been closed before closing it
+	checkClose := t.analysisCases["closeOnClosed"] && se.containsDefault
+	if checkClose && se.chosenDefault {
+		ids := make([]int, 0)
+		for _, c := range se.cases {
+			if c.opC == Recv {
+				ids = append(ids, c.id)
+			}
+		}
+		t.analysis.SelectDefault(se.routine, ids, se.tID)
+	}
+	checkClose = checkClose && !leak && se.chosenCase.opC == Recv && se.chosenCase.cl
+	vcBefore := t.currentVCHb[se.routine].Copy()
+
 	if leak {
-		currentVCHb[se.routine] = currentVCHb[se.routine].Inc(se.routine)
+		t.currentVCHb[se.routine] = t.currentVCHb[se.routine].Inc(se.routine)
 	} else {
 		// update the vector clock
-		se.chosenCase.updateVectorClock()
+		se.chosenCase.updateVectorClock(t)
+	}
+
+	if checkClose {
+		t.analysis.CheckForPossibleCloseOnClosed(se.routine, se.chosenCase.id, se.tID, vcBefore)
 	}
 
-	if analysisCases["selectWithoutPartner"] {
+	if t.analysisCases["selectWithoutPartner"] {
 		// check for select case without partner
 		ids := make([]int, 0)
 		buffered := make([]bool, 0)
@@ -468,12 +594,12 @@ func (se *TraceElementSelect) updateVectorClock() {
 			sendInfo = append(sendInfo, c.opC == Send)
 		}
 
-		analysis.CheckForSelectCaseWithoutPartnerSelect(se.routine, se.id, ids, buffered, sendInfo,
-			currentVCHb[se.routine], se.tID, se.chosenIndex)
+		t.analysis.CheckForSelectCaseWithoutPartnerSelect(se.routine, se.id, ids, buffered, sendInfo,
+			t.currentVCHb[se.routine], se.tID, se.chosenIndex)
 	}
 
 	if leak {
-		se.vc = currentVCHb[se.routine].Copy()
+		se.vc = t.currentVCHb[se.routine].Copy()
 	} else {
 		se.vc = se.chosenCase.vc.Copy()
 	}
@@ -481,15 +607,15 @@ func (se *TraceElementSelect) updateVectorClock() {
 	for _, c := range se.cases {
 		c.vc = se.vc.Copy()
 		if c.opC == Send {
-			analysis.SetChannelAsLastSend(c.id, se.routine, c.vc, c.tID)
+			t.analysis.SetChannelAsLastSend(c.id, se.routine, c.vc, c.tID)
 		} else if c.opC == Recv {
-			analysis.SetChannelAsLastReceive(c.id, se.routine, c.vc, c.tID)
+			t.analysis.SetChannelAsLastReceive(c.id, se.routine, c.vc, c.tID)
 		}
 	}
 
-	if analysisCases["leak"] {
+	if t.analysisCases["leak"] {
 		for _, c := range se.cases {
-			analysis.CheckForLeakChannelRun(se.routine, c.id,
+			t.analysis.CheckForLeakChannelRun(se.routine, c.id,
 				analysis.VectorClockTID{
 					Vc:      se.vc.Copy(),
 					TID:     se.tID,
@@ -1,7 +1,6 @@
 package trace
 
 import (
-	"analyzer/analysis"
 	"analyzer/clock"
 	"analyzer/logging"
 	"errors"
@@ -56,7 +55,7 @@ type TraceElementWait struct {
  *   val (string): The value of the wait group
  *   pos (string): The position of the wait group in the code
  */
-func AddTraceElementWait(routine int, tpre string,
+func (t *Trace) AddTraceElementWait(routine int, tpre string,
 	tpost string, id string, opW string, delta string, val string,
 	pos string) error {
 	tpre_int, err := strconv.Atoi(tpre)
@@ -103,7 +102,7 @@ func AddTraceElementWait(routine int, tpre string,
 		tID:     pos + "@" + tpre,
 	}
 
-	return AddElementToTrace(&elem)
+	return t.AddElementToTrace(&elem)
 }
 
 // MARK: Getter
@@ -270,18 +269,18 @@ func (wa *TraceElementWait) ToString() string {
  * Update and calculate the vector clock of the element
  * MARK: VectorClock
  */
-func (wa *TraceElementWait) updateVectorClock() {
+func (wa *TraceElementWait) updateVectorClock(t *Trace) {
 	switch wa.opW {
 	case ChangeOp:
-		analysis.Change(wa.routine, wa.id, wa.delta, wa.tID, currentVCHb)
+		t.analysis.Change(wa.routine, wa.id, wa.delta, wa.tID, t.currentVCHb)
 	case WaitOp:
-		analysis.Wait(wa.routine, wa.id, wa.tID, currentVCHb, wa.getTpost() != 0)
+		t.analysis.Wait(wa.routine, wa.id, wa.tID, t.currentVCHb, wa.getTpost() != 0)
 	default:
 		err := "Unknown operation on wait group: " + wa.ToString()
 		logging.Debug(err, logging.ERROR)
 	}
 
-	wa.vc = currentVCHb[wa.routine].Copy()
+	wa.vc = t.currentVCHb[wa.routine].Copy()
 }
 
 /*
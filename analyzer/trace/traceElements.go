@@ -16,7 +16,7 @@ type TraceElement interface {
 	GetPos() string
 	GetTID() string
 	ToString() string
-	updateVectorClock()
+	updateVectorClock(t *Trace)
 	GetVC() clock.VectorClock
 	Copy() TraceElement
 }
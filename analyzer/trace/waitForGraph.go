@@ -0,0 +1,362 @@
+package trace
+
+import (
+	"analyzer/analysis"
+	"sort"
+)
+
+/*
+ * Check for partial deadlocks at the end of the trace.
+ * For each routine, that is stuck at the end of the trace, the routines that
+ * could unblock it are determined. A routine R waits for a routine R', if R'
+ * executed an operation on the object R is blocked on, that can release R
+ * (e.g. a receive for a blocked send, the unlock of a held mutex or a done
+ * for a blocked wait). Only edges to routines, that are stuck themselves, are
+ * added. A stuck routine without such an edge, e.g. a receive on a channel
+ * no routine has ever sent on, is not part of a partial deadlock and stays
+ * reported as a leak. Each strongly connected component of this wait-for
+ * graph, that contains a cycle, is a set of routines that block each other
+ * and is reported as a partial deadlock.
+ */
+func (t *Trace) checkForPartialDeadlock() {
+	stuck := make(map[int]TraceElement)
+	for routine, trace := range t.traces {
+		if len(trace) == 0 {
+			continue
+		}
+		elem := trace[len(trace)-1]
+		if elem.getTpost() == 0 && stuckObjType(elem) != "" {
+			stuck[routine] = elem
+		}
+	}
+
+	if len(stuck) == 0 {
+		return
+	}
+
+	unblockers := t.getUnblockers()
+
+	graph := make(map[int][]int)
+	for routine, elem := range stuck {
+		for waitFor := range unblockers.waitFor(elem) {
+			if waitFor == routine {
+				// only a mutex can be blocked by its own routine
+				if _, ok := elem.(*TraceElementMutex); !ok {
+					continue
+				}
+			}
+			if _, ok := stuck[waitFor]; ok {
+				graph[routine] = append(graph[routine], waitFor)
+			}
+		}
+		sort.Ints(graph[routine])
+	}
+
+	for _, component := range stronglyConnectedComponents(graph) {
+		if len(component) == 1 && !containsInt(graph[component[0]], component[0]) {
+			continue
+		}
+
+		elems := make([]analysis.StuckElem, 0, len(component))
+		for _, routine := range component {
+			elem := stuck[routine]
+			elems = append(elems, analysis.StuckElem{
+				Routine: routine,
+				ID:      elem.GetID(),
+				TID:     elem.GetTID(),
+				ObjType: stuckObjType(elem),
+			})
+		}
+
+		t.analysis.LogPartialDeadlock(elems)
+	}
+}
+
+/*
+ * Get the type of an element, if it can block forever
+ * Args:
+ *   elem (TraceElement): The element
+ * Returns:
+ *   string: The type of the element, e.g. CS, or "" if the element cannot
+ *     be part of a partial deadlock
+ */
+func stuckObjType(elem TraceElement) string {
+	switch e := elem.(type) {
+	case *TraceElementChannel:
+		if e.id == -1 {
+			return ""
+		}
+		switch e.opC {
+		case Send:
+			return "CS"
+		case Recv:
+			return "CR"
+		}
+	case *TraceElementSelect:
+		if !e.containsDefault {
+			return "SS"
+		}
+	case *TraceElementMutex:
+		switch e.opM {
+		case LockOp:
+			return "ML"
+		case RLockOp:
+			return "MR"
+		}
+	case *TraceElementWait:
+		if e.opW == WaitOp {
+			return "WW"
+		}
+	case *TraceElementCond:
+		if e.opC == WaitCondOp {
+			return "NW"
+		}
+	}
+	return ""
+}
+
+/*
+ * The routines, that can unblock an operation on an object
+ * Fields:
+ *   send (map[int]map[int]bool): channel id -> routines with a send on the channel
+ *   recv (map[int]map[int]bool): channel id -> routines with a receive on the channel
+ *   close (map[int]map[int]bool): channel id -> routines with a close of the channel
+ *   done (map[int]map[int]bool): wait group id -> routines with a done on the wait group
+ *   release (map[int]map[int]bool): cond id -> routines with a signal or broadcast
+ *   lock (map[int]map[int]int): mutex id -> routines holding the mutex as a writer
+ *   rLock (map[int]map[int]int): mutex id -> routines holding the mutex as a reader
+ */
+type unblockers struct {
+	send    map[int]map[int]bool
+	recv    map[int]map[int]bool
+	close   map[int]map[int]bool
+	done    map[int]map[int]bool
+	release map[int]map[int]bool
+	lock    map[int]map[int]int
+	rLock   map[int]map[int]int
+}
+
+/*
+ * Collect the routines, that can unblock operations on each object
+ * Returns:
+ *   unblockers: The routines that can unblock an operation
+ */
+func (t *Trace) getUnblockers() unblockers {
+	u := unblockers{
+		send:    make(map[int]map[int]bool),
+		recv:    make(map[int]map[int]bool),
+		close:   make(map[int]map[int]bool),
+		done:    make(map[int]map[int]bool),
+		release: make(map[int]map[int]bool),
+		lock:    make(map[int]map[int]int),
+		rLock:   make(map[int]map[int]int),
+	}
+
+	for routine, trace := range t.traces {
+		for _, elem := range trace {
+			switch e := elem.(type) {
+			case *TraceElementChannel:
+				u.addChannel(routine, e)
+			case *TraceElementSelect:
+				for _, c := range e.GetCases() {
+					u.addChannel(routine, &c)
+				}
+			case *TraceElementMutex:
+				if e.tPost == 0 || !e.suc {
+					continue
+				}
+				switch e.opM {
+				case LockOp, TryLockOp:
+					addCount(u.lock, e.id, routine, 1)
+				case RLockOp, TryRLockOp:
+					addCount(u.rLock, e.id, routine, 1)
+				case UnlockOp:
+					addCount(u.lock, e.id, routine, -1)
+				case RUnlockOp:
+					addCount(u.rLock, e.id, routine, -1)
+				}
+			case *TraceElementWait:
+				if e.opW == ChangeOp && e.delta < 0 {
+					addRoutine(u.done, e.id, routine)
+				}
+			case *TraceElementCond:
+				if e.opC == SignalOp || e.opC == BroadcastOp {
+					addRoutine(u.release, e.id, routine)
+				}
+			}
+		}
+	}
+
+	return u
+}
+
+/*
+ * Add a channel operation to the unblockers
+ * Args:
+ *   routine (int): The routine of the operation
+ *   ch (*TraceElementChannel): The channel operation
+ */
+func (u *unblockers) addChannel(routine int, ch *TraceElementChannel) {
+	switch ch.opC {
+	case Send:
+		addRoutine(u.send, ch.id, routine)
+	case Recv:
+		addRoutine(u.recv, ch.id, routine)
+	case Close:
+		addRoutine(u.close, ch.id, routine)
+	}
+}
+
+/*
+ * Get the routines, that can unblock a stuck element
+ * Args:
+ *   elem (TraceElement): The stuck element
+ * Returns:
+ *   map[int]bool: The routines that can unblock the element
+ */
+func (u *unblockers) waitFor(elem TraceElement) map[int]bool {
+	res := make(map[int]bool)
+
+	switch e := elem.(type) {
+	case *TraceElementChannel:
+		u.waitForChannel(e, res)
+	case *TraceElementSelect:
+		for _, c := range e.GetCases() {
+			u.waitForChannel(&c, res)
+		}
+	case *TraceElementMutex:
+		for routine, count := range u.lock[e.id] {
+			if count > 0 {
+				res[routine] = true
+			}
+		}
+		if e.opM == LockOp {
+			for routine, count := range u.rLock[e.id] {
+				if count > 0 {
+					res[routine] = true
+				}
+			}
+		}
+	case *TraceElementWait:
+		for routine := range u.done[e.id] {
+			res[routine] = true
+		}
+	case *TraceElementCond:
+		for routine := range u.release[e.id] {
+			res[routine] = true
+		}
+	}
+
+	return res
+}
+
+/*
+ * Add the routines, that can unblock a channel operation, to res
+ * Args:
+ *   ch (*TraceElementChannel): The channel operation
+ *   res (map[int]bool): The set of routines to add to
+ */
+func (u *unblockers) waitForChannel(ch *TraceElementChannel, res map[int]bool) {
+	switch ch.opC {
+	case Send:
+		for routine := range u.recv[ch.id] {
+			res[routine] = true
+		}
+	case Recv:
+		for routine := range u.send[ch.id] {
+			res[routine] = true
+		}
+		for routine := range u.close[ch.id] {
+			res[routine] = true
+		}
+	}
+}
+
+func addRoutine(m map[int]map[int]bool, id int, routine int) {
+	if _, ok := m[id]; !ok {
+		m[id] = make(map[int]bool)
+	}
+	m[id][routine] = true
+}
+
+func addCount(m map[int]map[int]int, id int, routine int, n int) {
+	if _, ok := m[id]; !ok {
+		m[id] = make(map[int]int)
+	}
+	m[id][routine] += n
+}
+
+func containsInt(list []int, val int) bool {
+	for _, elem := range list {
+		if elem == val {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Get the strongly connected components of a graph with the algorithm of
+ * Tarjan. The routines in each component are sorted.
+ * Args:
+ *   graph (map[int][]int): The graph as adjacency lists
+ * Returns:
+ *   [][]int: The strongly connected components
+ */
+func stronglyConnectedComponents(graph map[int][]int) [][]int {
+	nodes := make([]int, 0, len(graph))
+	for node := range graph {
+		nodes = append(nodes, node)
+	}
+	sort.Ints(nodes)
+
+	index := make(map[int]int)
+	lowLink := make(map[int]int)
+	onStack := make(map[int]bool)
+	stack := make([]int, 0)
+	res := make([][]int, 0)
+	counter := 0
+
+	var strongConnect func(v int)
+	strongConnect = func(v int) {
+		index[v] = counter
+		lowLink[v] = counter
+		counter++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range graph[v] {
+			if _, ok := index[w]; !ok {
+				strongConnect(w)
+				lowLink[v] = min(lowLink[v], lowLink[w])
+			} else if onStack[w] {
+				lowLink[v] = min(lowLink[v], index[w])
+			}
+		}
+
+		if lowLink[v] != index[v] {
+			return
+		}
+
+		component := make([]int, 0)
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component = append(component, w)
+			if w == v {
+				break
+			}
+		}
+		sort.Ints(component)
+		res = append(res, component)
+	}
+
+	for _, node := range nodes {
+		if _, ok := index[node]; !ok {
+			strongConnect(node)
+		}
+	}
+
+	return res
+}
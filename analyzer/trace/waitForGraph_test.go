@@ -0,0 +1,89 @@
+package trace
+
+import (
+	"strings"
+	"testing"
+
+	"analyzer/logging"
+)
+
+/*
+ * Routine 2 sends on a channel while holding a mutex. Routine 1 receives the
+ * message and then holds the mutex, while it waits for a second message.
+ * Routine 2 is blocked on the mutex before it can send again. Both must be
+ * reported as one partial deadlock instead of separate leaks.
+ */
+func TestPartialDeadlockMutexChannel(t *testing.T) {
+	tr := NewTrace()
+
+	add := func(err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	add(tr.AddTraceElementFork(1, "2", "2", "/a/main.go:20"))
+	add(tr.AddTraceElementMutex(2, "3", "4", "5", "-", "L", "t", "/a/main.go:10"))
+	add(tr.AddTraceElementChannel(2, "5", "7", "7", "S", "f", "1", "0", "/a/main.go:11"))
+	add(tr.AddTraceElementChannel(1, "6", "7", "7", "R", "f", "1", "0", "/a/main.go:21"))
+	add(tr.AddTraceElementMutex(2, "8", "9", "5", "-", "U", "t", "/a/main.go:12"))
+	add(tr.AddTraceElementMutex(1, "10", "11", "5", "-", "L", "t", "/a/main.go:22"))
+	add(tr.AddTraceElementMutex(2, "12", "0", "5", "-", "L", "t", "/a/main.go:10"))
+	add(tr.AddTraceElementChannel(1, "13", "0", "7", "R", "f", "0", "0", "/a/main.go:23"))
+	tr.Sort()
+	tr.SetNumberOfRoutines(2)
+
+	results := logging.NewResults()
+	tr.RunAnalysis(false, false, map[string]bool{"leak": true, "partialDeadlock": true}, results, nil)
+
+	found := results.GetResultsMachine(false)
+	if len(found) != 1 || !strings.HasPrefix(found[0], string(logging.APartialDeadlock)+",") {
+		t.Fatalf("expected one partial deadlock, got %v", found)
+	}
+	for _, pos := range []string{"/a/main.go:10", "/a/main.go:23"} {
+		if !strings.Contains(found[0], pos) {
+			t.Errorf("partial deadlock does not contain %s: %s", pos, found[0])
+		}
+	}
+}
+
+/*
+ * Routine 1 holds a mutex and is blocked on a receive on a channel, on which
+ * no routine ever sends. Routine 2 is blocked on the mutex. Routine 1 does
+ * not wait for routine 2, so both must be reported as leaks and not as a
+ * partial deadlock.
+ */
+func TestPartialDeadlockReceiveWithoutSender(t *testing.T) {
+	tr := NewTrace()
+
+	add := func(err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	add(tr.AddTraceElementFork(1, "2", "2", "/a/main.go:20"))
+	add(tr.AddTraceElementMutex(1, "3", "4", "5", "-", "L", "t", "/a/main.go:21"))
+	add(tr.AddTraceElementChannel(1, "5", "0", "7", "R", "f", "0", "0", "/a/main.go:22"))
+	add(tr.AddTraceElementMutex(2, "6", "0", "5", "-", "L", "t", "/a/main.go:10"))
+	tr.Sort()
+	tr.SetNumberOfRoutines(2)
+
+	results := logging.NewResults()
+	tr.RunAnalysis(false, false, map[string]bool{"leak": true, "partialDeadlock": true}, results, nil)
+
+	found := results.GetResultsMachine(false)
+	if len(found) != 2 {
+		t.Fatalf("expected two leaks, got %v", found)
+	}
+	for _, res := range found {
+		if strings.HasPrefix(res, string(logging.APartialDeadlock)+",") {
+			t.Errorf("unexpected partial deadlock: %s", res)
+		}
+	}
+	for _, pos := range []string{"/a/main.go:22", "/a/main.go:10"} {
+		if !strings.Contains(strings.Join(found, "\n"), pos) {
+			t.Errorf("no leak for %s: %v", pos, found)
+		}
+	}
+}
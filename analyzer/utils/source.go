@@ -0,0 +1,332 @@
+package utils
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strconv"
+	"sync"
+)
+
+type parsedFile struct {
+	fset *token.FileSet
+	file *ast.File
+	err  error
+}
+
+// parsed source files, the analyses check many elements in the same files
+var parsedFiles = make(map[string]parsedFile)
+var parsedFilesLock sync.Mutex
+
+/*
+ * Parse a source file. Each file is only parsed once.
+ * Args:
+ *   file (string): the file
+ * Returns:
+ *   *token.FileSet: the file set of the file
+ *   *ast.File: the parsed file
+ *   error: error if the file could not be parsed
+ */
+func parseSourceFile(file string) (*token.FileSet, *ast.File, error) {
+	parsedFilesLock.Lock()
+	defer parsedFilesLock.Unlock()
+
+	if res, ok := parsedFiles[file]; ok {
+		return res.fset, res.file, res.err
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, 0)
+	parsedFiles[file] = parsedFile{fset, f, err}
+	return fset, f, err
+}
+
+// error if the select was found, but its default case does not close the
+// channel of a receive case
+var ErrNoCloseInDefaultCase = errors.New("No close of the received channel in the default case of the select")
+
+/*
+ * Get the line of the close in the default case of a select in the program.
+ * Only closes of a channel, that is received from in one of the cases of the
+ * select, are found, e.g.
+ * 	select {
+ * 	case <-c:
+ * 	default:
+ * 		close(c)
+ * 	}
+ * The channels are compared by their expressions in the code. Closes in
+ * called functions or in function literals are not found.
+ * Args:
+ *   file (string): the file of the select
+ *   line (int): the line of the select
+ * Returns:
+ *   int: the line of the first matching close in the default case
+ *   error: ErrNoCloseInDefaultCase if the select does not contain such a
+ *     close, another error if the file could not be parsed or does not
+ *     contain a select at the line
+ */
+func FindCloseInDefaultCase(file string, line int) (int, error) {
+	fset, f, err := parseSourceFile(file)
+	if err != nil {
+		return 0, err
+	}
+
+	found := false
+	res := 0
+	ast.Inspect(f, func(n ast.Node) bool {
+		if found {
+			return false
+		}
+
+		sel, ok := n.(*ast.SelectStmt)
+		if !ok || fset.Position(sel.Pos()).Line != line {
+			return true
+		}
+		found = true
+
+		// channels of the receive cases
+		received := make(map[string]struct{})
+		for _, stmt := range sel.Body.List {
+			clause, ok := stmt.(*ast.CommClause)
+			if !ok || clause.Comm == nil {
+				continue
+			}
+			var expr ast.Expr
+			switch comm := clause.Comm.(type) {
+			case *ast.ExprStmt:
+				expr = comm.X
+			case *ast.AssignStmt:
+				if len(comm.Rhs) == 1 {
+					expr = comm.Rhs[0]
+				}
+			}
+			if recv, ok := expr.(*ast.UnaryExpr); ok && recv.Op == token.ARROW {
+				received[types.ExprString(recv.X)] = struct{}{}
+			}
+		}
+
+		for _, stmt := range sel.Body.List {
+			clause, ok := stmt.(*ast.CommClause)
+			if !ok || clause.Comm != nil { // not the default case
+				continue
+			}
+
+			for _, s := range clause.Body {
+				ast.Inspect(s, func(n ast.Node) bool {
+					if res != 0 {
+						return false
+					}
+					if _, ok := n.(*ast.FuncLit); ok {
+						return false
+					}
+					call, ok := n.(*ast.CallExpr)
+					if !ok || len(call.Args) != 1 {
+						return true
+					}
+					if ident, ok := call.Fun.(*ast.Ident); ok && ident.Name == "close" {
+						if _, ok := received[types.ExprString(call.Args[0])]; ok {
+							res = fset.Position(call.Pos()).Line
+							return false
+						}
+					}
+					return true
+				})
+			}
+		}
+
+		return false
+	})
+
+	if !found {
+		return 0, errors.New("No select at " + file + ":" + strconv.Itoa(line))
+	}
+	if res == 0 {
+		return 0, ErrNoCloseInDefaultCase
+	}
+
+	return res, nil
+}
+
+/*
+ * Check if a write on an atomic variable depends on a load of the same
+ * routine, i.e. if the load and the write form a check-then-act sequence.
+ * This is the case, if both are in the same function and
+ *   - the write uses the result of the load directly, e.g. a.Store(a.Load()+1)
+ *   - the load is in the condition of an if, for or switch statement and
+ *     the write is in its body, or after it, if the body leaves the function
+ *     or loop
+ *   - the result of the load is assigned to a variable, that is used in the
+ *     arguments of the write or in such a condition
+ * Args:
+ *   file (string): the file of the load and the write
+ *   lineLoad (int): the line of the load
+ *   lineWrite (int): the line of the write
+ * Returns:
+ *   bool: true if the write depends on the load
+ *   error: error if the file could not be parsed
+ */
+func AtomicWriteDependsOnLoad(file string, lineLoad int, lineWrite int) (bool, error) {
+	fset, f, err := parseSourceFile(file)
+	if err != nil {
+		return false, err
+	}
+
+	containsLine := func(n ast.Node, line int) bool {
+		if n == nil {
+			return false
+		}
+		return fset.Position(n.Pos()).Line <= line && line <= fset.Position(n.End()).Line
+	}
+
+	// innermost function, that contains both operations
+	var body *ast.BlockStmt
+	ast.Inspect(f, func(n ast.Node) bool {
+		var b *ast.BlockStmt
+		switch fn := n.(type) {
+		case *ast.FuncDecl:
+			b = fn.Body
+		case *ast.FuncLit:
+			b = fn.Body
+		}
+		if b != nil && containsLine(b, lineLoad) && containsLine(b, lineWrite) {
+			body = b
+		}
+		return true
+	})
+	if body == nil {
+		return false, nil
+	}
+
+	if lineLoad == lineWrite {
+		return true, nil
+	}
+
+	// variables, that are assigned the result of the load
+	vars := make(map[string]struct{})
+	addVars := func(lhs []ast.Expr, rhs []ast.Expr) {
+		for _, r := range rhs {
+			if !containsLine(r, lineLoad) {
+				continue
+			}
+			for _, l := range lhs {
+				if ident, ok := l.(*ast.Ident); ok && ident.Name != "_" {
+					vars[ident.Name] = struct{}{}
+				}
+			}
+		}
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch s := n.(type) {
+		case *ast.AssignStmt:
+			addVars(s.Lhs, s.Rhs)
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, 0, len(s.Names))
+			for _, name := range s.Names {
+				lhs = append(lhs, name)
+			}
+			addVars(lhs, s.Values)
+		}
+		return true
+	})
+
+	// check if a node contains the load or uses one of the variables
+	usesLoad := func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		if containsLine(n, lineLoad) {
+			return true
+		}
+		found := false
+		ast.Inspect(n, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok {
+				if _, ok := vars[ident.Name]; ok {
+					found = true
+				}
+			}
+			return !found
+		})
+		return found
+	}
+
+	// check if a block ends with a statement, that leaves the function or loop
+	leaves := func(b *ast.BlockStmt) bool {
+		if b == nil || len(b.List) == 0 {
+			return false
+		}
+		switch b.List[len(b.List)-1].(type) {
+		case *ast.ReturnStmt, *ast.BranchStmt:
+			return true
+		}
+		return false
+	}
+
+	// check if a statement with the given header guards the write
+	guards := func(n ast.Node, header []ast.Node, b *ast.BlockStmt, alt ast.Node) bool {
+		dependent := false
+		for _, h := range header {
+			if usesLoad(h) {
+				dependent = true
+			}
+		}
+		if !dependent {
+			return false
+		}
+		if containsLine(b, lineWrite) || containsLine(alt, lineWrite) {
+			return true
+		}
+		return leaves(b) && fset.Position(n.End()).Line < lineWrite
+	}
+
+	res := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if res {
+			return false
+		}
+
+		switch s := n.(type) {
+		case *ast.IfStmt:
+			var alt ast.Node
+			if s.Else != nil {
+				alt = s.Else
+			}
+			var init ast.Node
+			if s.Init != nil {
+				init = s.Init
+			}
+			res = guards(s, []ast.Node{init, s.Cond}, s.Body, alt)
+		case *ast.ForStmt:
+			var init, cond ast.Node
+			if s.Init != nil {
+				init = s.Init
+			}
+			if s.Cond != nil {
+				cond = s.Cond
+			}
+			res = guards(s, []ast.Node{init, cond}, s.Body, nil)
+		case *ast.SwitchStmt:
+			var init, tag ast.Node
+			if s.Init != nil {
+				init = s.Init
+			}
+			if s.Tag != nil {
+				tag = s.Tag
+			}
+			res = guards(s, []ast.Node{init, tag}, s.Body, nil)
+		case *ast.CallExpr:
+			if fset.Position(s.Pos()).Line != lineWrite {
+				return true
+			}
+			for _, arg := range s.Args {
+				if usesLoad(arg) {
+					res = true
+				}
+			}
+		}
+		return !res
+	})
+
+	return res, nil
+}
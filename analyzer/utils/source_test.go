@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const sourceSelectClose = `package main
+
+func main() {
+	done := make(chan int)
+	other := make(chan int)
+
+	select {
+	case <-done:
+	default:
+		close(done)
+	}
+
+	select {
+	case <-done:
+	default:
+		close(other)
+	}
+
+	select {
+	case v, ok := <-done:
+		println(v, ok)
+	default:
+		println("closing")
+		close(done)
+	}
+}
+`
+
+/*
+ * Write a source file for a test
+ * Args:
+ *   t (*testing.T): the test
+ *   content (string): the content of the file
+ * Returns:
+ *   string: the path of the file
+ */
+func writeSource(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+/*
+ * The close in the default case must close the received channel
+ */
+func TestFindCloseInDefaultCase(t *testing.T) {
+	file := writeSource(t, sourceSelectClose)
+
+	tests := []struct {
+		line    int
+		res     int
+		wantErr error
+	}{
+		{7, 10, nil},
+		{13, 0, ErrNoCloseInDefaultCase}, // closes a different channel
+		{19, 24, nil},
+	}
+
+	for _, test := range tests {
+		res, err := FindCloseInDefaultCase(file, test.line)
+		if err != test.wantErr || res != test.res {
+			t.Errorf("select at line %d: got %d, %v, expected %d, %v", test.line, res, err, test.res, test.wantErr)
+		}
+	}
+
+	if _, err := FindCloseInDefaultCase(file, 3); err == nil || err == ErrNoCloseInDefaultCase {
+		t.Errorf("expected error for a line without select, got %v", err)
+	}
+}
+
+const sourceAtomic = `package main
+
+import "sync/atomic"
+
+func f(a *atomic.Int32) {
+	if a.Load() == 0 {
+		a.Store(1)
+	}
+	v := a.Load()
+	a.Store(v + 1)
+	a.Store(a.Load() + 1)
+	x := a.Load()
+	println(x)
+	a.Store(5)
+	if a.Load() != 0 {
+		return
+	}
+	a.Store(3)
+}
+`
+
+/*
+ * A write only forms a check-then-act sequence with a load, if it depends on
+ * the loaded value
+ */
+func TestAtomicWriteDependsOnLoad(t *testing.T) {
+	file := writeSource(t, sourceAtomic)
+
+	tests := []struct {
+		lineLoad  int
+		lineWrite int
+		res       bool
+	}{
+		{6, 7, true},    // write in the body of the check
+		{9, 10, true},   // write uses the loaded variable
+		{11, 11, true},  // write uses the load directly
+		{12, 14, false}, // loaded variable is not used by the write
+		{15, 18, true},  // check leaves the function before the write
+	}
+
+	for _, test := range tests {
+		res, err := AtomicWriteDependsOnLoad(file, test.lineLoad, test.lineWrite)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res != test.res {
+			t.Errorf("load at %d, write at %d: got %t, expected %t", test.lineLoad, test.lineWrite, res, test.res)
+		}
+	}
+}
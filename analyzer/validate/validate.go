@@ -0,0 +1,680 @@
+// Package validate checks trace folders for corrupt or truncated traces
+// without building the trace, so that broken traces can be found before
+// they are analyzed.
+package validate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+ * Problem describes one error found in a trace
+ * Fields:
+ *   File (string): path of the trace file, or of the trace folder
+ *   Element (int): index of the element in the file (1 based), 0 if the problem is not bound to an element
+ *   Message (string): description of the problem
+ */
+type Problem struct {
+	File    string
+	Element int
+	Message string
+}
+
+/*
+ * Get the problem as a string
+ * Returns:
+ *   string: the problem as a string
+ */
+func (p Problem) String() string {
+	if p.Element == 0 {
+		return p.File + ": " + p.Message
+	}
+	return p.File + ": element " + strconv.Itoa(p.Element) + ": " + p.Message
+}
+
+/*
+ * Information about an element, that is needed for the checks between
+ * elements and routines
+ * Fields:
+ *   file (string): path of the trace file
+ *   index (int): index of the element in the file (1 based)
+ *   tPre (int): tPre of the element
+ *   tPost (int): tPost of the element
+ *   id (int): id of the channel or mutex
+ *   op (string): operation of the element
+ *   cl (bool): for channels, true if the operation was finished because of a close
+ *   oID (int): for channels, the communication id
+ *   qSize (int): for channels, the size of the buffer
+ */
+type elementInfo struct {
+	file  string
+	index int
+	tPre  int
+	tPost int
+	id    int
+	op    string
+	cl    bool
+	oID   int
+	qSize int
+}
+
+/*
+ * Collected information of one trace folder
+ * Fields:
+ *   problems ([]Problem): the found problems
+ *   routines (map[int]bool): routines for which a trace file exists
+ *   spawns ([]elementInfo): spawn elements
+ *   channels ([]elementInfo): executed channel operations, including chosen select cases
+ *   mutexes ([]elementInfo): mutex operations
+ */
+type folderInfo struct {
+	problems []Problem
+	routines map[int]bool
+	spawns   []elementInfo
+	channels []elementInfo
+	mutexes  []elementInfo
+}
+
+/*
+ * Validate all trace folders in the given path. If the path itself contains
+ * trace files, only this folder is checked, otherwise all subfolders
+ * containing trace files are checked.
+ * Args:
+ *   path (string): path to the trace folder or a folder containing trace folders
+ * Returns:
+ *   []Problem: the problems found in all folders
+ *   int: the number of checked trace folders
+ *   error: error if the path could not be read
+ */
+func Validate(path string) ([]Problem, int, error) {
+	folders := make([]string, 0)
+	err := filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		isTrace, err := containsTraceFiles(p)
+		if err != nil {
+			return err
+		}
+		if isTrace {
+			folders = append(folders, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(folders) == 0 {
+		return nil, 0, errors.New("No trace folder found in " + path)
+	}
+
+	problems := make([]Problem, 0)
+	for _, folder := range folders {
+		res, err := ValidateFolder(folder)
+		if err != nil {
+			return problems, len(folders), err
+		}
+		problems = append(problems, res...)
+	}
+
+	return problems, len(folders), nil
+}
+
+/*
+ * Validate one trace folder
+ * Args:
+ *   path (string): path to the trace folder
+ * Returns:
+ *   []Problem: the found problems
+ *   error: error if the folder could not be read
+ */
+func ValidateFolder(path string) ([]Problem, error) {
+	files, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info := folderInfo{
+		problems: make([]Problem, 0),
+		routines: make(map[int]bool),
+		spawns:   make([]elementInfo, 0),
+		channels: make([]elementInfo, 0),
+		mutexes:  make([]elementInfo, 0),
+	}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		routine, ok := getRoutineFromFileName(file.Name())
+		if !ok {
+			continue
+		}
+		info.routines[routine] = true
+
+		filePath := filepath.Join(path, file.Name())
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			info.problems = append(info.problems, Problem{filePath, 0, err.Error()})
+			continue
+		}
+
+		validateFile(&info, filePath, string(content))
+	}
+
+	checkSpawns(&info, path)
+	checkChannels(&info)
+	checkMutexes(&info)
+
+	sort.SliceStable(info.problems, func(i, j int) bool {
+		if info.problems[i].File != info.problems[j].File {
+			return info.problems[i].File < info.problems[j].File
+		}
+		return info.problems[i].Element < info.problems[j].Element
+	})
+
+	return info.problems, nil
+}
+
+/*
+ * Check if a folder contains trace files
+ * Args:
+ *   path (string): path to the folder
+ * Returns:
+ *   bool: true if the folder contains at least one trace file
+ *   error: error if the folder could not be read
+ */
+func containsTraceFiles(path string) (bool, error) {
+	files, err := os.ReadDir(path)
+	if err != nil {
+		return false, err
+	}
+
+	for _, file := range files {
+		if _, ok := getRoutineFromFileName(file.Name()); ok && !file.IsDir() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+/*
+ * Get the routine id from the name of a trace file
+ * Args:
+ *   fileName (string): the name of the file, e.g. trace_1.log
+ * Returns:
+ *   int: the routine id
+ *   bool: false if the file is not a trace file
+ */
+func getRoutineFromFileName(fileName string) (int, bool) {
+	if !strings.HasPrefix(fileName, "trace_") || !strings.HasSuffix(fileName, ".log") {
+		return 0, false
+	}
+
+	routine, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(fileName, "trace_"), ".log"))
+	if err != nil {
+		return 0, false
+	}
+	return routine, true
+}
+
+/*
+ * Validate the content of one trace file
+ * Args:
+ *   info (*folderInfo): the collected information of the folder
+ *   file (string): path of the file
+ *   content (string): content of the file
+ */
+func validateFile(info *folderInfo, file string, content string) {
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return
+	}
+
+	if strings.Contains(content, "\n") {
+		info.problems = append(info.problems, Problem{file, 0, "Trace file contains more than one line"})
+		content = content[:strings.Index(content, "\n")]
+	}
+
+	for i, element := range strings.Split(content, ";") {
+		errs := validateElement(info, file, i+1, element)
+		for _, err := range errs {
+			info.problems = append(info.problems, Problem{file, i + 1, err})
+		}
+	}
+}
+
+// number of fields for each element type
+var numberFields = map[string]int{
+	"A": 4,
+	"D": 6,
+	"G": 4,
+	"M": 8,
+	"P": 3,
+	"W": 8,
+	"C": 9,
+	"S": 7,
+	"O": 6,
+	"N": 6,
+	"X": 3,
+}
+
+/*
+ * Validate one element and collect the information needed for the checks
+ * between elements
+ * Args:
+ *   info (*folderInfo): the collected information of the folder
+ *   file (string): path of the file
+ *   index (int): index of the element in the file (1 based)
+ *   element (string): the element
+ * Returns:
+ *   []string: the problems of the element
+ */
+func validateElement(info *folderInfo, file string, index int, element string) []string {
+	if element == "" {
+		return []string{"Empty element"}
+	}
+
+	fields := strings.Split(element, ",")
+	n, ok := numberFields[fields[0]]
+	if !ok {
+		return []string{"Unknown element type " + fields[0] + " in " + element}
+	}
+
+	// the position of atomic operations is optional
+	if fields[0] == "A" && len(fields) == n+1 {
+		n++
+	}
+
+	if len(fields) != n {
+		return []string{"Element " + element + " has " + strconv.Itoa(len(fields)) +
+			" fields, expected " + strconv.Itoa(n)}
+	}
+
+	v := elementValidator{fields: fields, problems: make([]string, 0)}
+	elem := elementInfo{file: file, index: index}
+
+	switch fields[0] {
+	case "A":
+		elem.tPre = v.nat(1, "tpre")
+		v.nat(2, "addr")
+		v.oneOf(3, "opA", "L", "S", "A", "W", "C", "U")
+		if len(fields) > 4 {
+			v.pos(4)
+		}
+		return v.problems
+	case "D":
+		elem.tPre = v.nat(1, "tpre")
+		v.nat(2, "addr")
+		v.oneOf(3, "opD", "R", "W")
+		v.nat(4, "gc")
+		v.pos(5)
+		return v.problems
+	case "P":
+		elem.tPost = v.nat(1, "tpost")
+		v.pos(2)
+		return v.problems
+	case "G":
+		elem.tPre = v.nat(1, "tpre")
+		elem.id = v.nat(2, "id")
+		v.pos(3)
+		if len(v.problems) == 0 {
+			info.spawns = append(info.spawns, elem)
+		}
+		return v.problems
+	case "X":
+		v.nat(1, "tpre")
+		v.nat(2, "ec")
+		return v.problems
+	}
+
+	elem.tPre = v.nat(1, "tpre")
+	elem.tPost = v.nat(2, "tpost")
+
+	switch fields[0] {
+	case "M":
+		elem.id = v.nat(3, "id")
+		v.oneOf(4, "rw", "R", "-")
+		elem.op = v.oneOf(5, "opM", "L", "R", "T", "Y", "U", "N")
+		suc := v.oneOf(6, "suc", "t", "f")
+		v.pos(7)
+		if len(v.problems) == 0 && suc == "t" {
+			info.mutexes = append(info.mutexes, elem)
+		}
+	case "W":
+		v.nat(3, "id")
+		v.oneOf(4, "opW", "A", "W")
+		v.integer(5, "delta")
+		v.nat(6, "val")
+		v.pos(7)
+	case "C":
+		elem.id = v.channelID(3)
+		elem.op = v.oneOf(4, "opC", "S", "R", "C")
+		elem.cl = v.oneOf(5, "cl", "t", "f") == "t"
+		elem.oID = v.nat(6, "oId")
+		elem.qSize = v.nat(7, "qSize")
+		v.pos(8)
+		if len(v.problems) == 0 && elem.tPost != 0 {
+			info.channels = append(info.channels, elem)
+		}
+	case "S":
+		v.nat(3, "id")
+		v.integer(5, "selIndex")
+		v.pos(6)
+		cases := v.selectCases(4, elem)
+		if len(v.problems) == 0 && elem.tPost != 0 {
+			info.channels = append(info.channels, cases...)
+		}
+	case "O":
+		v.nat(3, "id")
+		v.oneOf(4, "suco", "t", "f")
+		v.pos(5)
+	case "N":
+		v.nat(3, "id")
+		v.oneOf(4, "opN", "W", "S", "B")
+		v.pos(5)
+	}
+
+	// checked last, so that the element is still used for the checks between elements
+	if elem.tPost != 0 && elem.tPre > elem.tPost {
+		v.problems = append(v.problems, "tpre "+fields[1]+" is greater than tpost "+fields[2])
+	}
+
+	return v.problems
+}
+
+/*
+ * Helper to check the fields of an element
+ * Fields:
+ *   fields ([]string): the fields of the element
+ *   problems ([]string): the found problems
+ */
+type elementValidator struct {
+	fields   []string
+	problems []string
+}
+
+/*
+ * Check that a field is an integer
+ * Args:
+ *   i (int): index of the field
+ *   name (string): name of the field
+ * Returns:
+ *   int: the value of the field, 0 if it is invalid
+ */
+func (v *elementValidator) integer(i int, name string) int {
+	value, err := strconv.Atoi(v.fields[i])
+	if err != nil {
+		v.problems = append(v.problems, name+" is not an integer: "+v.fields[i])
+		return 0
+	}
+	return value
+}
+
+/*
+ * Check that a field is a natural number
+ * Args:
+ *   i (int): index of the field
+ *   name (string): name of the field
+ * Returns:
+ *   int: the value of the field, 0 if it is invalid
+ */
+func (v *elementValidator) nat(i int, name string) int {
+	value, err := strconv.Atoi(v.fields[i])
+	if err != nil || value < 0 {
+		v.problems = append(v.problems, name+" is not a natural number: "+v.fields[i])
+		return 0
+	}
+	return value
+}
+
+/*
+ * Check that a field is one of the given values
+ * Args:
+ *   i (int): index of the field
+ *   name (string): name of the field
+ *   values (...string): the allowed values
+ * Returns:
+ *   string: the value of the field
+ */
+func (v *elementValidator) oneOf(i int, name string, values ...string) string {
+	for _, value := range values {
+		if v.fields[i] == value {
+			return value
+		}
+	}
+	v.problems = append(v.problems, "Invalid "+name+": "+v.fields[i])
+	return v.fields[i]
+}
+
+/*
+ * Check that a field is a channel id, a natural number or *
+ * Args:
+ *   i (int): index of the field
+ * Returns:
+ *   int: the id, -1 for a nil channel
+ */
+func (v *elementValidator) channelID(i int) int {
+	if v.fields[i] == "*" {
+		return -1
+	}
+	return v.nat(i, "id")
+}
+
+/*
+ * Check that a field is a position of the form file:line
+ * Args:
+ *   i (int): index of the field
+ */
+func (v *elementValidator) pos(i int) {
+	pos := v.fields[i]
+	sep := strings.LastIndex(pos, ":")
+	if sep <= 0 {
+		v.problems = append(v.problems, "Invalid pos: "+pos)
+		return
+	}
+	if line, err := strconv.Atoi(pos[sep+1:]); err != nil || line < 0 {
+		v.problems = append(v.problems, "Invalid line in pos: "+pos)
+	}
+}
+
+/*
+ * Check the cases of a select
+ * Args:
+ *   i (int): index of the field containing the cases
+ *   sel (elementInfo): the select element
+ * Returns:
+ *   []elementInfo: the executed channel cases
+ */
+func (v *elementValidator) selectCases(i int, sel elementInfo) []elementInfo {
+	res := make([]elementInfo, 0)
+
+	if v.fields[i] == "" {
+		v.problems = append(v.problems, "Select without cases")
+		return res
+	}
+
+	numberChosen := 0
+	for j, c := range strings.Split(v.fields[i], "~") {
+		if c == "d" {
+			continue
+		}
+		if c == "D" {
+			numberChosen++
+			continue
+		}
+
+		caseFields := strings.Split(c, ".")
+		if len(caseFields) != 8 || caseFields[0] != "C" {
+			v.problems = append(v.problems, "Invalid select case "+strconv.Itoa(j+1)+": "+c)
+			continue
+		}
+
+		cv := elementValidator{fields: caseFields, problems: make([]string, 0)}
+		elem := elementInfo{file: sel.file, index: sel.index}
+		elem.tPre = cv.nat(1, "tpre")
+		elem.tPost = cv.nat(2, "tpost")
+		elem.id = cv.channelID(3)
+		elem.op = cv.oneOf(4, "opC", "S", "R")
+		elem.cl = cv.oneOf(5, "cl", "t", "f") == "t"
+		elem.oID = cv.nat(6, "oId")
+		elem.qSize = cv.nat(7, "qSize")
+
+		for _, p := range cv.problems {
+			v.problems = append(v.problems, "Select case "+strconv.Itoa(j+1)+": "+p)
+		}
+
+		if elem.tPost != 0 {
+			numberChosen++
+			res = append(res, elem)
+		}
+	}
+
+	if sel.tPost != 0 && numberChosen != 1 {
+		v.problems = append(v.problems, "Executed select has "+strconv.Itoa(numberChosen)+
+			" chosen cases, expected 1")
+	}
+
+	return res
+}
+
+/*
+ * Check that all spawned routines have a trace file
+ * Args:
+ *   info (*folderInfo): the collected information of the folder
+ *   path (string): path of the trace folder
+ */
+func checkSpawns(info *folderInfo, path string) {
+	for _, spawn := range info.spawns {
+		if !info.routines[spawn.id] {
+			info.problems = append(info.problems, Problem{spawn.file, spawn.index,
+				"Spawned routine " + strconv.Itoa(spawn.id) + " has no trace file trace_" +
+					strconv.Itoa(spawn.id) + ".log in " + path})
+		}
+	}
+}
+
+/*
+ * Check that the executed send and receive operations on each channel have
+ * matching partners. For unbuffered channels, each send must have a receive
+ * with the same oId and the other way around. For buffered channels, a send
+ * may stay in the buffer, so only the receives need a matching send.
+ * Args:
+ *   info (*folderInfo): the collected information of the folder
+ */
+func checkChannels(info *folderInfo) {
+	sends := make(map[int]map[int]elementInfo) // id -> oId -> send
+	recvs := make(map[int]map[int]elementInfo) // id -> oId -> recv
+
+	for _, ch := range info.channels {
+		if ch.id == -1 || ch.op == "C" || ch.cl {
+			continue
+		}
+
+		ops := sends
+		if ch.op == "R" {
+			ops = recvs
+		}
+
+		if _, ok := ops[ch.id]; !ok {
+			ops[ch.id] = make(map[int]elementInfo)
+		}
+
+		if other, ok := ops[ch.id][ch.oID]; ok {
+			info.problems = append(info.problems, Problem{ch.file, ch.index,
+				"oId " + strconv.Itoa(ch.oID) + " on channel " + strconv.Itoa(ch.id) +
+					" is already used by element " + strconv.Itoa(other.index) + " in " + other.file})
+			continue
+		}
+		ops[ch.id][ch.oID] = ch
+	}
+
+	for id, ops := range recvs {
+		for oID, recv := range ops {
+			if _, ok := sends[id][oID]; !ok {
+				info.problems = append(info.problems, Problem{recv.file, recv.index,
+					"Receive on channel " + strconv.Itoa(id) + " with oId " + strconv.Itoa(oID) +
+						" has no matching send"})
+			}
+		}
+	}
+
+	for id, ops := range sends {
+		for oID, send := range ops {
+			if send.qSize != 0 {
+				continue
+			}
+			if _, ok := recvs[id][oID]; !ok {
+				info.problems = append(info.problems, Problem{send.file, send.index,
+					"Send on unbuffered channel " + strconv.Itoa(id) + " with oId " +
+						strconv.Itoa(oID) + " has no matching receive"})
+			}
+		}
+	}
+}
+
+/*
+ * Check that the successful lock and unlock operations on each mutex are
+ * balanced. An unlock must release a lock held at this time and a lock
+ * can only be acquired if the mutex is not locked. A mutex that is still
+ * locked at the end of the trace is not a problem, because the program may
+ * have ended while the lock was held.
+ * Args:
+ *   info (*folderInfo): the collected information of the folder
+ */
+func checkMutexes(info *folderInfo) {
+	// blocked operations did not change the state of the mutex
+	executed := make([]elementInfo, 0)
+	for _, mu := range info.mutexes {
+		if mu.tPost != 0 {
+			executed = append(executed, mu)
+		}
+	}
+
+	sort.SliceStable(executed, func(i, j int) bool {
+		return executed[i].tPost < executed[j].tPost
+	})
+
+	locked := make(map[int]bool)
+	readers := make(map[int]int)
+
+	for _, mu := range executed {
+		switch mu.op {
+		case "L", "T":
+			if locked[mu.id] || readers[mu.id] > 0 {
+				info.problems = append(info.problems, Problem{mu.file, mu.index,
+					"Lock of mutex " + strconv.Itoa(mu.id) + " while it is already locked"})
+			}
+			locked[mu.id] = true
+		case "R", "Y":
+			if locked[mu.id] {
+				info.problems = append(info.problems, Problem{mu.file, mu.index,
+					"RLock of mutex " + strconv.Itoa(mu.id) + " while it is locked"})
+			}
+			readers[mu.id]++
+		case "U":
+			if !locked[mu.id] {
+				info.problems = append(info.problems, Problem{mu.file, mu.index,
+					"Unlock of mutex " + strconv.Itoa(mu.id) + ", that is not locked"})
+			}
+			locked[mu.id] = false
+		case "N":
+			if readers[mu.id] == 0 {
+				info.problems = append(info.problems, Problem{mu.file, mu.index,
+					"RUnlock of mutex " + strconv.Itoa(mu.id) + ", that is not read locked"})
+				continue
+			}
+			readers[mu.id]--
+		}
+	}
+}
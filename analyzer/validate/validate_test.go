@@ -0,0 +1,175 @@
+package validate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+/*
+ * Check the problems found for single elements of each element type
+ */
+func TestValidateElement(t *testing.T) {
+	tests := []struct {
+		name     string
+		element  string
+		problems []string
+	}{
+		{"empty", "", []string{"Empty element"}},
+		{"unknown type", "Q,1,2", []string{"Unknown element type Q in Q,1,2"}},
+
+		{"atomic", "A,2,3,L", nil},
+		{"atomic with pos", "A,2,3,L,main.go:5", nil},
+		{"atomic fields", "A,2,3", []string{"Element A,2,3 has 3 fields, expected 4"}},
+		{"atomic op", "A,2,3,X", []string{"Invalid opA: X"}},
+
+		{"memory", "D,2,3,W,0,main.go:5", nil},
+		{"memory fields", "D,2,3,W,main.go:5", []string{"Element D,2,3,W,main.go:5 has 5 fields, expected 6"}},
+		{"memory gc", "D,2,3,R,-1,main.go:5", []string{"gc is not a natural number: -1"}},
+		{"memory op", "D,2,3,X,0,main.go:5", []string{"Invalid opD: X"}},
+
+		{"panic", "P,4,main.go:5", nil},
+		{"panic tpost", "P,a,main.go:5", []string{"tpost is not a natural number: a"}},
+		{"panic pos", "P,4,main.go", []string{"Invalid pos: main.go"}},
+
+		{"spawn", "G,2,3,main.go:5", nil},
+		{"spawn id", "G,2,x,main.go:5", []string{"id is not a natural number: x"}},
+
+		{"replay", "X,2,0", nil},
+		{"replay ec", "X,2,e", []string{"ec is not a natural number: e"}},
+
+		{"mutex", "M,2,3,4,-,L,t,main.go:5", nil},
+		{"mutex rw", "M,2,3,4,X,L,t,main.go:5", []string{"Invalid rw: X"}},
+		{"mutex op", "M,2,3,4,R,Q,t,main.go:5", []string{"Invalid opM: Q"}},
+		{"mutex suc", "M,2,3,4,R,L,x,main.go:5", []string{"Invalid suc: x"}},
+		{"mutex tpre after tpost", "M,5,3,4,-,L,t,main.go:5", []string{"tpre 5 is greater than tpost 3"}},
+
+		{"wait group", "W,2,3,4,A,-1,0,main.go:5", nil},
+		{"wait group op", "W,2,3,4,X,1,0,main.go:5", []string{"Invalid opW: X"}},
+		{"wait group delta", "W,2,3,4,A,x,0,main.go:5", []string{"delta is not an integer: x"}},
+		{"wait group val", "W,2,3,4,A,1,-1,main.go:5", []string{"val is not a natural number: -1"}},
+
+		{"channel", "C,2,3,4,S,f,1,0,main.go:5", nil},
+		{"nil channel", "C,2,0,*,R,f,0,0,main.go:5", nil},
+		{"channel op", "C,2,3,4,X,f,1,0,main.go:5", []string{"Invalid opC: X"}},
+		{"channel cl", "C,2,3,4,S,x,1,0,main.go:5", []string{"Invalid cl: x"}},
+		{"channel oId", "C,2,3,4,S,f,x,0,main.go:5", []string{"oId is not a natural number: x"}},
+		{"channel qSize", "C,2,3,4,S,f,1,-2,main.go:5", []string{"qSize is not a natural number: -2"}},
+		{"channel line", "C,2,3,4,S,f,1,0,main.go:x", []string{"Invalid line in pos: main.go:x"}},
+
+		{"select", "S,2,3,4,C.2.3.5.R.f.1.0~d,0,main.go:5", nil},
+		{"select default", "S,2,3,4,C.2.0.5.R.f.0.0~D,1,main.go:5", nil},
+		{"select without cases", "S,2,3,4,,0,main.go:5", []string{"Select without cases"}},
+		{"select case", "S,2,3,4,C.2.3,0,main.go:5", []string{"Invalid select case 1: C.2.3",
+			"Executed select has 0 chosen cases, expected 1"}},
+		{"select case field", "S,2,3,4,C.2.3.5.X.f.1.0,0,main.go:5", []string{"Select case 1: Invalid opC: X"}},
+		{"select two chosen", "S,2,3,4,C.2.3.5.R.f.1.0~D,0,main.go:5",
+			[]string{"Executed select has 2 chosen cases, expected 1"}},
+
+		{"once", "O,2,3,4,t,main.go:5", nil},
+		{"once suc", "O,2,3,4,x,main.go:5", []string{"Invalid suco: x"}},
+
+		{"cond", "N,2,3,4,W,main.go:5", nil},
+		{"cond op", "N,2,3,4,X,main.go:5", []string{"Invalid opN: X"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			info := &folderInfo{routines: make(map[int]bool)}
+			problems := validateElement(info, "trace_1.log", 1, test.element)
+
+			if len(problems) == 0 && len(test.problems) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(problems, test.problems) {
+				t.Errorf("validateElement(%q) = %q, expected %q", test.element, problems, test.problems)
+			}
+		})
+	}
+}
+
+/*
+ * Check the problems found between the elements of a trace folder
+ */
+func TestValidateFolder(t *testing.T) {
+	tests := []struct {
+		name     string
+		traces   map[string]string
+		problems []string
+	}{
+		{"valid", map[string]string{
+			"trace_1.log": "G,1,2,main.go:3;C,2,4,5,S,f,1,0,main.go:4;M,6,7,8,-,L,t,main.go:5;M,9,10,8,-,U,t,main.go:6",
+			"trace_2.log": "C,3,4,5,R,f,1,0,main.go:10",
+		}, nil},
+		{"missing routine", map[string]string{
+			"trace_1.log": "G,1,2,main.go:3",
+		}, []string{"trace_1.log: element 1: Spawned routine 2 has no trace file trace_2.log in "}},
+		{"receive without send", map[string]string{
+			"trace_1.log": "C,2,4,5,R,f,1,0,main.go:4",
+		}, []string{"trace_1.log: element 1: Receive on channel 5 with oId 1 has no matching send"}},
+		{"send without receive", map[string]string{
+			"trace_1.log": "C,2,4,5,S,f,1,0,main.go:4",
+		}, []string{"trace_1.log: element 1: Send on unbuffered channel 5 with oId 1 has no matching receive"}},
+		{"double unlock", map[string]string{
+			"trace_1.log": "M,2,3,8,-,L,t,main.go:5;M,4,5,8,-,U,t,main.go:6;M,6,7,8,-,U,t,main.go:7",
+		}, []string{"trace_1.log: element 3: Unlock of mutex 8, that is not locked"}},
+		{"two lines", map[string]string{
+			"trace_1.log": "G,1,2,main.go:3\nG,1,2,main.go:3",
+			"trace_2.log": "",
+		}, []string{"trace_1.log: Trace file contains more than one line"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range test.traces {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			problems, err := ValidateFolder(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res := make([]string, 0, len(problems))
+			for _, p := range problems {
+				rel, err := filepath.Rel(dir, p.File)
+				if err != nil {
+					t.Fatal(err)
+				}
+				p.File = rel
+				str := p.String()
+				if len(test.problems) != 0 {
+					// the path of the folder is part of some messages
+					str = trimSuffixPath(str, dir)
+				}
+				res = append(res, str)
+			}
+
+			if len(res) == 0 && len(test.problems) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(res, test.problems) {
+				t.Errorf("ValidateFolder = %q, expected %q", res, test.problems)
+			}
+		})
+	}
+}
+
+/*
+ * Remove the path of the trace folder at the end of a problem
+ * Args:
+ *   str (string): the problem
+ *   dir (string): the path of the trace folder
+ * Returns:
+ *   string: the problem without the path
+ */
+func trimSuffixPath(str string, dir string) string {
+	if len(str) >= len(dir) && str[len(str)-len(dir):] == dir {
+		return str[:len(str)-len(dir)]
+	}
+	return str
+}
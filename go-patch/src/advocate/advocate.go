@@ -2,6 +2,8 @@ package advocate
 
 import (
 	"bufio"
+	"encoding/json"
+	"fmt"
 	"math"
 	"os"
 	"os/signal"
@@ -21,6 +23,21 @@ var tracePathRecorded = "advocateTrace"
 var advocateStartTimer time.Time // start time of the program
 var advocateReplayStartTime time.Time
 
+// spawn positions of the recorded routines, read from the G elements
+var spawnPositions = make(map[int]string) // routine id -> position
+var spawnPositionsLock sync.Mutex
+
+// time Finish waits for spawned routines to return, before they are
+// reported as unfinished, if FailOnRoutineLeak was called
+const unfinishedRoutinesTimeout = time.Second
+const unfinishedRoutinesMinBackoff = time.Millisecond
+const unfinishedRoutinesMaxBackoff = 100 * time.Millisecond
+
+// routines, that were spawned while recording and not finished in Finish
+var routineLeaks = make(map[string][]int) // spawn position -> routine ids
+var waitForUnfinishedRoutines = false     // set by FailOnRoutineLeak
+var routineLeaksLock sync.Mutex
+
 /*
  * Write the trace of the program to a file.
  * The trace is written in the file named file_name.
@@ -28,11 +45,17 @@ var advocateReplayStartTime time.Time
  */
 func Finish() {
 	runEndTime := time.Now()
+	unfinished := getUnfinishedRoutines()
 	runtime.DisableTrace()
+	runtime.DisableExploration()
 
-	writeToTraceFiles()
+	spawnPositions = make(map[int]string)
+	writeToTraceFiles(tracePathRecorded)
 	// deleteEmptyFiles()
 
+	collectRoutineLeaks(unfinished)
+	writeRoutineLeaks(tracePathRecorded)
+
 	traceEndTime := time.Now()
 
 	progTime := runEndTime.Sub(advocateStartTimer).Seconds()
@@ -44,12 +67,46 @@ func Finish() {
 	writeTime("ExecutionTotal", totalTime)
 }
 
+/*
+ * Get the routines, that were spawned while recording and have not returned.
+ * A routine, that has already executed its last operation, e.g. the Done of
+ * a wait group, may not have returned yet. If FailOnRoutineLeak was called,
+ * the check is therefore repeated with an increasing backoff, until all
+ * routines have returned or the timeout is reached. The routine calling
+ * Finish sleeps in between.
+ * Returns:
+ * 	[]uint64: the ids of the unfinished routines
+ */
+func getUnfinishedRoutines() []uint64 {
+	routineLeaksLock.Lock()
+	wait := waitForUnfinishedRoutines
+	routineLeaksLock.Unlock()
+
+	deadline := time.Now().Add(unfinishedRoutinesTimeout)
+	backoff := unfinishedRoutinesMinBackoff
+
+	for {
+		unfinished := runtime.GetUnfinishedRoutines()
+		if !wait || len(unfinished) == 0 || time.Now().After(deadline) {
+			return unfinished
+		}
+
+		time.Sleep(backoff)
+		if backoff < unfinishedRoutinesMaxBackoff {
+			backoff *= 2
+		}
+	}
+}
+
 /*
  * WaitForReplayFinish waits for the replay to finish.
  */
 func WaitForReplayFinish() {
 	if r := recover(); r != nil {
 		println("Replay failed.")
+		replayPanicMessage = fmt.Sprint(r)
+		finishReplay(runtime.ExitCodePanic)
+		runtime.ExitReplayWithCode(runtime.ExitCodePanic)
 	}
 
 	runtime.WaitForReplayFinish()
@@ -58,6 +115,8 @@ func WaitForReplayFinish() {
 
 	writeTime("ReplayRuntime", replayRuntime)
 
+	finishReplay(runtime.ExitCodeDefault)
+
 	runtime.ExitReplayWithCode(runtime.ExitCodeDefault)
 }
 
@@ -106,17 +165,19 @@ func toGB(bytes uint64) float64 {
 // }
 
 /*
- * Write the trace to a set of files. The traces are written into a folder
- * with name trace. For each routine, a file is created. The file is named
+ * Write the trace to a set of files. The traces are written into the given
+ * folder. For each routine, a file is created. The file is named
  * trace_routineId.log. The trace of the routine is written into the file.
+ * Args:
+ * 	- path: The folder the trace files are written into
  */
-func writeToTraceFiles() {
+func writeToTraceFiles(path string) {
 	numRout := runtime.GetNumberOfRoutines()
 	var wg sync.WaitGroup
 	for i := 1; i <= numRout; i++ {
 		// write the trace to the file
 		wg.Add(1)
-		go writeToTraceFile(i, &wg)
+		go writeToTraceFile(i, path, &wg)
 	}
 
 	wg.Wait()
@@ -128,8 +189,9 @@ func writeToTraceFiles() {
  * The trace is written in the format of advocate.
  * Args:
  * 	- routine: The id of the routine
+ * 	- path: The folder the trace file is written into
  */
-func writeToTraceFile(routine int, wg *sync.WaitGroup) {
+func writeToTraceFile(routine int, path string, wg *sync.WaitGroup) {
 	// create the file if it does not exist and open it
 	defer wg.Done()
 
@@ -137,7 +199,7 @@ func writeToTraceFile(routine int, wg *sync.WaitGroup) {
 	// 	return
 	// }
 
-	fileName := "advocateTrace/trace_" + strconv.Itoa(routine) + ".log"
+	fileName := path + "/trace_" + strconv.Itoa(routine) + ".log"
 
 	file, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
@@ -157,9 +219,171 @@ func writeToTraceFile(routine int, wg *sync.WaitGroup) {
 		if _, err := file.WriteString(trace); err != nil {
 			panic(err)
 		}
+		storeSpawnPositions(trace)
 	}
 }
 
+/*
+ * Store the positions of the G elements in a part of a trace
+ * Args:
+ * 	- trace: part of the trace, the elements are separated by ;
+ */
+func storeSpawnPositions(trace string) {
+	for _, elem := range strings.Split(trace, ";") {
+		if !strings.HasPrefix(elem, "G,") {
+			continue
+		}
+
+		fields := strings.Split(elem, ",") // G,[tpre],[id],[pos]
+		if len(fields) != 4 {
+			continue
+		}
+
+		id, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+
+		spawnPositionsLock.Lock()
+		spawnPositions[id] = fields[3]
+		spawnPositionsLock.Unlock()
+	}
+}
+
+// ============== Routine leaks =================
+
+/*
+ * Collect the routines, that were spawned while recording and have not
+ * finished, grouped by the position they were spawned at. Routines without
+ * a G element, e.g. routines spawned before the recording was started, are
+ * ignored.
+ * Args:
+ * 	- unfinished: ids of the routines, that have not finished
+ */
+func collectRoutineLeaks(unfinished []uint64) {
+	routineLeaksLock.Lock()
+	defer routineLeaksLock.Unlock()
+
+	routineLeaks = make(map[string][]int)
+
+	for _, id := range unfinished {
+		pos, ok := spawnPositions[int(id)]
+		if !ok {
+			continue
+		}
+		routineLeaks[pos] = append(routineLeaks[pos], int(id))
+	}
+
+	for _, ids := range routineLeaks {
+		sort.Ints(ids)
+	}
+}
+
+/*
+ * Write the leaking routines into routineLeaks.log in the trace folder.
+ * Each line contains the spawn position and the ids of the routines spawned
+ * there, e.g. example_test.go:12,3;5
+ * If no routine leaked, no file is created.
+ * Args:
+ * 	- path: The trace folder
+ */
+func writeRoutineLeaks(path string) {
+	leaks := GetRoutineLeaks()
+	if len(leaks) == 0 {
+		return
+	}
+
+	res := ""
+	for _, pos := range routineLeakPositions(leaks) {
+		ids := make([]string, 0, len(leaks[pos]))
+		for _, id := range leaks[pos] {
+			ids = append(ids, strconv.Itoa(id))
+		}
+		res += pos + "," + strings.Join(ids, ";") + "\n"
+	}
+
+	if err := os.WriteFile(path+"/routineLeaks.log", []byte(res), 0644); err != nil {
+		println("Could not write routine leaks: " + err.Error())
+	}
+}
+
+/*
+ * Get the spawn positions of the leaking routines in sorted order
+ * Args:
+ * 	- leaks: spawn position -> ids of the leaking routines
+ * Returns:
+ * 	- []string: The spawn positions
+ */
+func routineLeakPositions(leaks map[string][]int) []string {
+	positions := make([]string, 0, len(leaks))
+	for pos := range leaks {
+		positions = append(positions, pos)
+	}
+	sort.Strings(positions)
+	return positions
+}
+
+/*
+ * GetRoutineLeaks returns the routines, that were spawned while recording and
+ * had not finished when Finish was called, grouped by their spawn position.
+ * Returns:
+ * 	- map[string][]int: spawn position -> ids of the routines
+ */
+func GetRoutineLeaks() map[string][]int {
+	routineLeaksLock.Lock()
+	defer routineLeaksLock.Unlock()
+
+	res := make(map[string][]int, len(routineLeaks))
+	for pos, ids := range routineLeaks {
+		res[pos] = append([]int(nil), ids...)
+	}
+	return res
+}
+
+/*
+ * TB is the part of testing.TB, that is used by FailOnRoutineLeak
+ */
+type TB interface {
+	Helper()
+	Cleanup(func())
+	Errorf(format string, args ...any)
+}
+
+/*
+ * FailOnRoutineLeak lets the test fail, if a routine, that was spawned while
+ * recording, has not finished when Finish is called. Finish then waits up to
+ * one second for the spawned routines to return. The check is run in a
+ * cleanup function of the test. If Finish has not been called before, e.g.
+ * because it was not deferred in the test, it is called by the cleanup.
+ * Call after InitTracing:
+ *
+ * 	advocate.InitTracing(0)
+ * 	advocate.FailOnRoutineLeak(t)
+ * 	defer advocate.Finish()
+ *
+ * Args:
+ * 	- t: The test
+ */
+func FailOnRoutineLeak(t TB) {
+	t.Helper()
+
+	routineLeaksLock.Lock()
+	waitForUnfinishedRoutines = true
+	routineLeaksLock.Unlock()
+
+	t.Cleanup(func() {
+		if !runtime.GetAdvocateDisabled() {
+			Finish()
+		}
+
+		leaks := GetRoutineLeaks()
+		for _, pos := range routineLeakPositions(leaks) {
+			ids := leaks[pos]
+			t.Errorf("found %d unfinished routine(s) spawned at %s: %v", len(ids), pos, ids)
+		}
+	})
+}
+
 func writeTime(name string, time float64) error {
 	path := tracePathRecorded + "/times.log"
 
@@ -268,10 +492,92 @@ func InitTracing(size int) {
 	runtime.InitAdvocate(size)
 }
 
+/*
+ * EnableMemoryRecording additionally records the reads and writes on memory
+ * in the given packages. The recorded accesses are used by the analyzer to
+ * predict data races. The accesses are reported by the race instrumentation,
+ * the program must therefore be built with -race. The recording is only
+ * implemented for amd64. Call after InitTracing.
+ * Args:
+ * 	- packages: import paths of the packages whose accesses are recorded
+ */
+func EnableMemoryRecording(packages ...string) {
+	if !runtime.AdvocateEnableMemoryRecording(packages) {
+		println("Memory accesses are only recorded if the program is built with -race on amd64")
+	}
+}
+
+/*
+ * EnableAtomicPositions additionally records the position of atomic
+ * operations. The positions are needed by the analyzer to predict atomicity
+ * violations. Getting the position walks the stack for every atomic
+ * operation, it is therefore disabled by default. Call after InitTracing.
+ */
+func EnableAtomicPositions() {
+	runtime.AdvocateEnableAtomicPositions()
+}
+
+// ============== Exploration =================
+
+var tracePathExploration = "advocateExploration"
+
+/*
+ * InitExploration initializes a run of the schedule exploration.
+ * The program is executed with random, seeded priorities for the routines
+ * (probabilistic concurrency testing) and the run is recorded like a normal
+ * run into advocateExploration/seed_<seed>. The seed is read from the
+ * environment variable ADVOCATE_EXPLORATION_SEED. If it is not set, a seed
+ * is created from the current time. To reproduce a run, either rerun the
+ * program with the same seed or replay the recorded trace.
+ * The number of steps, from which the priority change points are chosen,
+ * can be set with ADVOCATE_EXPLORATION_STEPS (default 1000).
+ * Args:
+ * 	- depth: The bug depth, the number of priority change points is depth-1
+ */
+func InitExploration(depth int) {
+	seed := uint64(time.Now().UnixNano())
+	if seedStr := os.Getenv("ADVOCATE_EXPLORATION_SEED"); seedStr != "" {
+		s, err := strconv.ParseUint(seedStr, 10, 64)
+		if err != nil {
+			panic("Invalid exploration seed " + seedStr)
+		}
+		seed = s
+	}
+
+	steps := 1000
+	if stepsStr := os.Getenv("ADVOCATE_EXPLORATION_STEPS"); stepsStr != "" {
+		s, err := strconv.Atoi(stepsStr)
+		if err != nil {
+			panic("Invalid number of exploration steps " + stepsStr)
+		}
+		steps = s
+	}
+
+	err := os.MkdirAll(tracePathExploration, 0755)
+	if err != nil {
+		panic(err)
+	}
+
+	tracePathRecorded = tracePathExploration + "/seed_" + strconv.FormatUint(seed, 10)
+	InitTracing(0)
+
+	// store the parameters of the run next to the trace to reproduce it
+	info := strconv.FormatUint(seed, 10) + "#" + strconv.Itoa(depth) + "#" + strconv.Itoa(steps)
+	err = os.WriteFile(tracePathRecorded+"/exploration_info.log", []byte(info), 0644)
+	if err != nil {
+		panic(err)
+	}
+
+	runtime.EnableExploration(seed, depth, steps)
+}
+
 // ============== Reading =================
 
 var timeout = false
 var tracePathRewritten = "rewritten_trace_"
+var tracePathReplayRecorded = "replay_trace"
+var replayFinished sync.Once
+var replayPanicMessage = ""
 
 /*
  * Read the trace from the trace folder.
@@ -297,6 +603,12 @@ func EnableReplay(index int, exitCode bool) {
 		tracePathRewritten = tracePathRewritten + strconv.Itoa(index)
 	}
 
+	// the trace folder can be overwritten, e.g. by the trace minimization
+	// of the analyzer
+	if path := os.Getenv("ADVOCATE_REPLAY_TRACE"); path != "" {
+		tracePathRewritten = strings.TrimSuffix(path, "/")
+	}
+
 	// if trace folder does not exist, panic
 	if _, err := os.Stat(tracePathRewritten); os.IsNotExist(err) {
 		panic("Trace folder " + tracePathRewritten + " does not exist.")
@@ -316,12 +628,9 @@ func EnableReplay(index int, exitCode bool) {
 			continue
 		}
 
-		if file.Name() == "times.log" {
-			continue
-		}
-
-		// if the file is a log file, read the trace
-		if strings.HasSuffix(file.Name(), ".log") && file.Name() != "rewrite_info.log" {
+		// only the trace_[routine].log files contain traces, the folder can
+		// also contain other logs, e.g. times.log or routineLeaks.log
+		if strings.HasPrefix(file.Name(), "trace_") && strings.HasSuffix(file.Name(), ".log") {
 			routineID, trace := readTraceFile(tracePathRewritten + "/" + file.Name())
 			runtime.AddReplayTrace(uint64(routineID), trace)
 		}
@@ -329,16 +638,222 @@ func EnableReplay(index int, exitCode bool) {
 
 	writeTime("ReplayTrace", time.Now().Sub(advocateStartTimer).Seconds())
 
+	initReplayRecording()
+
+	setReplayTimeouts()
+
 	advocateReplayStartTime = time.Now()
 
 	runtime.EnableReplay(timeout)
 }
 
-func EnableReplayWithTimeout(index int, exitCode bool) {
+/*
+ * ReplayTimeouts contains the wall clock timeouts of the replay.
+ * A timeout of 0 keeps the default value, a negative timeout disables the check.
+ * Fields:
+ * 	- Element: Max wait time of a single operation for its turn (default 20s)
+ * 	- NoOperation: Max time in which no traced operation is executed (default 20s)
+ * 	- Finish: Max wait time for the remaining operations after the main routine finished (default 20s)
+ * 	- Total: Max time for the whole replay (default no limit)
+ */
+type ReplayTimeouts struct {
+	Element     time.Duration
+	NoOperation time.Duration
+	Finish      time.Duration
+	Total       time.Duration
+}
+
+var replayTimeouts ReplayTimeouts
+
+/*
+ * EnableReplayWithTimeout enables the replay like EnableReplay, but the replay
+ * is canceled if one of the timeouts is reached. The timeouts can be set with
+ * the optional timeouts argument or the environment variables
+ * ADVOCATE_REPLAY_TIMEOUT_ELEMENT, ADVOCATE_REPLAY_TIMEOUT_NO_OPERATION,
+ * ADVOCATE_REPLAY_TIMEOUT_FINISH and ADVOCATE_REPLAY_TIMEOUT_TOTAL
+ * (e.g. "30s" or "2m"). The environment variables overwrite the given timeouts.
+ * Args:
+ * 	- index: The index of the replay case
+ * 	- exitCode: Whether the program should exit after the important replay part passed
+ * 	- timeouts: The timeouts of the replay
+ */
+func EnableReplayWithTimeout(index int, exitCode bool, timeouts ...ReplayTimeouts) {
 	timeout = true
+	if len(timeouts) > 0 {
+		replayTimeouts = timeouts[0]
+	}
 	EnableReplay(index, exitCode)
 }
 
+/*
+ * Pass the replay timeouts to the runtime. The values set by
+ * EnableReplayWithTimeout are overwritten by the environment variables.
+ */
+func setReplayTimeouts() {
+	element := getReplayTimeout("ADVOCATE_REPLAY_TIMEOUT_ELEMENT", replayTimeouts.Element)
+	noOperation := getReplayTimeout("ADVOCATE_REPLAY_TIMEOUT_NO_OPERATION", replayTimeouts.NoOperation)
+	finish := getReplayTimeout("ADVOCATE_REPLAY_TIMEOUT_FINISH", replayTimeouts.Finish)
+	total := getReplayTimeout("ADVOCATE_REPLAY_TIMEOUT_TOTAL", replayTimeouts.Total)
+
+	runtime.SetReplayTimeouts(element, noOperation, finish, total)
+}
+
+/*
+ * Get the value of a timeout for the runtime.
+ * Args:
+ * 	- env: Name of the environment variable for the timeout
+ * 	- value: The value given by the program
+ * Returns:
+ * 	The timeout in nanoseconds, -1 to keep the default value, 0 to disable the timeout
+ */
+func getReplayTimeout(env string, value time.Duration) int64 {
+	if envValue := os.Getenv(env); envValue != "" {
+		d, err := time.ParseDuration(envValue)
+		if err != nil {
+			panic("Invalid replay timeout " + env + "=" + envValue)
+		}
+		value = d
+	}
+
+	if value == 0 {
+		return -1
+	}
+
+	if value < 0 {
+		return 0
+	}
+
+	return int64(value)
+}
+
+/*
+ * Start recording the replayed execution.
+ * The replay is recorded with the same recorder as a normal run. The trace is
+ * written into the folder replay_trace inside the folder of the replayed trace,
+ * so that the analyzer can compare the executed order with the replayed trace.
+ */
+func initReplayRecording() {
+	tracePathReplayRecorded = tracePathRewritten + "/replay_trace"
+
+	err := os.RemoveAll(tracePathReplayRecorded)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			panic(err)
+		}
+	}
+
+	err = os.Mkdir(tracePathReplayRecorded, 0755)
+	if err != nil {
+		if !os.IsExist(err) {
+			panic(err)
+		}
+	}
+
+	// the replay can terminate the program directly with an exit code.
+	// Make sure, that the recorded trace is written before.
+	runtime.SetReplayExitHook(finishReplay)
+
+	runtime.InitAdvocate(0)
+}
+
+/*
+ * Stop the recording of the replay, write the recorded trace and the result
+ * of the replay.
+ * The function is called at most once, either at the end of the replay or
+ * before the replay terminates the program with an exit code.
+ * Args:
+ * 	- code: The exit code of the replay
+ */
+func finishReplay(code int) {
+	replayFinished.Do(func() {
+		replayRuntime := time.Now().Sub(advocateReplayStartTime).Seconds()
+		replayed, total, endReached := runtime.GetReplayProgress()
+
+		runtime.DisableTrace()
+
+		// the operations used to write the trace must not be replayed
+		if runtime.IsReplayEnabled() {
+			runtime.DisableReplay()
+		}
+
+		writeToTraceFiles(tracePathReplayRecorded)
+		writeIgnoredPositions(tracePathReplayRecorded)
+
+		result := replayResult{
+			ExitCode:         code,
+			ExitCodeName:     runtime.ExitCodeNames[code],
+			ExpectedExitCode: runtime.GetExpectedExitCode(),
+			ReplayEndReached: endReached,
+			ReplayedElements: replayed,
+			TotalElements:    total,
+			ReadTime:         advocateReplayStartTime.Sub(advocateStartTimer).Seconds(),
+			ReplayTime:       replayRuntime,
+			PanicMessage:     replayPanicMessage,
+		}
+		err := writeReplayResult(result)
+		if err != nil {
+			println("Could not write replay result: ", err.Error())
+		}
+	})
+}
+
+/*
+ * Write the positions, whose operations are not enforced by the replay, into
+ * ignored.log in the given folder, one position per line. The analyzer uses
+ * them to compare the replayed trace with the trace recorded during the replay.
+ * Args:
+ * 	- path: The folder of the trace recorded during the replay
+ */
+func writeIgnoredPositions(path string) {
+	content := strings.Join(runtime.AdvocateIgnoredPositions(), "\n") + "\n"
+	if err := os.WriteFile(path+"/ignored.log", []byte(content), 0644); err != nil {
+		println("Could not write ignored positions: ", err.Error())
+	}
+}
+
+/*
+ * replayResult is the machine readable result of a replay, written into
+ * replay_result.json in the folder of the replayed trace.
+ * Fields:
+ * 	- ExitCode: The exit code of the replay
+ * 	- ExitCodeName: The description of the exit code
+ * 	- ExpectedExitCode: The exit code of the replay end element in the trace
+ * 	- ReplayEndReached: Whether the replay end element in the trace was reached
+ * 	- ReplayedElements: The number of replayed trace elements
+ * 	- TotalElements: The number of elements in the replayed trace
+ * 	- ReadTime: The time in seconds to read the trace
+ * 	- ReplayTime: The time in seconds from the start of the replay until the result was written
+ * 	- PanicMessage: The message of a panic in the replay, empty if no panic occurred
+ */
+type replayResult struct {
+	ExitCode         int     `json:"exitCode"`
+	ExitCodeName     string  `json:"exitCodeName"`
+	ExpectedExitCode int     `json:"expectedExitCode"`
+	ReplayEndReached bool    `json:"replayEndReached"`
+	ReplayedElements int     `json:"replayedElements"`
+	TotalElements    int     `json:"totalElements"`
+	ReadTime         float64 `json:"readTime"`
+	ReplayTime       float64 `json:"replayTime"`
+	PanicMessage     string  `json:"panicMessage"`
+}
+
+/*
+ * Write the result of the replay into replay_result.json in the folder of
+ * the replayed trace.
+ * Args:
+ * 	- result: The result of the replay
+ * Returns:
+ * 	An error if the file could not be written
+ */
+func writeReplayResult(result replayResult) error {
+	content, err := json.MarshalIndent(result, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(tracePathRewritten+"/replay_result.json", content, 0644)
+}
+
 /*
  * Import the trace.
  * The function creates the replay data structure, that is used to replay the trace.
@@ -548,7 +1063,7 @@ func readTraceFile(fileName string) (int, runtime.AdvocateReplayTrace) {
 					if fields[2] == "0" {
 						blocked = true
 					}
-				case "A":
+				case "A", "D", "P":
 					// do nothing
 
 				default:
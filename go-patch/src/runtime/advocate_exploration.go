@@ -0,0 +1,153 @@
+// ADVOCATE-FILE-START
+
+package runtime
+
+/*
+ * Schedule exploration based on probabilistic concurrency testing (PCT).
+ * Each routine is assigned a random priority when it executes its first
+ * traced operation. At depth-1 randomly chosen steps, the priority of the
+ * routine that executes the step is lowered below all initial priorities.
+ * Before each traced operation, a routine yields the processor once for each
+ * known routine with a higher priority. This way, routines with a high priority
+ * are preferred, without the need to fully serialize the execution.
+ * All random decisions are derived from the seed, so the same seed creates
+ * the same priorities and change points. Because the go scheduler itself is
+ * not deterministic, the recorded trace of a run should be used to replay it.
+ */
+
+var explorationEnabled bool
+var explorationLock mutex
+var explorationSeed uint64
+var explorationRandState uint64
+var explorationDepth int
+var explorationStep int
+var explorationChangePoints []int
+var explorationPriorities map[uint64]int
+
+// maximum number of yields before a single operation
+var explorationMaxDelay = 10
+
+/*
+ * Enable the schedule exploration.
+ * Args:
+ * 	seed: seed for all random decisions
+ * 	depth: bug depth, the number of priority change points is depth-1
+ * 	steps: estimated number of traced operations in the run, the change
+ * 		points are chosen from [1, steps]
+ */
+func EnableExploration(seed uint64, depth int, steps int) {
+	lock(&explorationLock)
+	defer unlock(&explorationLock)
+
+	if depth < 1 {
+		depth = 1
+	}
+	if steps < 1 {
+		steps = 1
+	}
+
+	explorationSeed = seed
+	explorationRandState = seed
+	explorationDepth = depth
+	explorationStep = 0
+	explorationPriorities = make(map[uint64]int)
+
+	explorationChangePoints = make([]int, depth-1)
+	for i := range explorationChangePoints {
+		explorationChangePoints[i] = int(explorationRandom()%uint64(steps)) + 1
+	}
+
+	explorationEnabled = true
+	println("Exploration enabled with seed ", seed)
+}
+
+/*
+ * Disable the schedule exploration.
+ */
+func DisableExploration() {
+	lock(&explorationLock)
+	defer unlock(&explorationLock)
+
+	explorationEnabled = false
+}
+
+/*
+ * Get if the schedule exploration is enabled
+ * Return:
+ * 	bool: true if the exploration is enabled, false otherwise
+ */
+func IsExplorationEnabled() bool {
+	return explorationEnabled
+}
+
+/*
+ * Get the next pseudo random number (splitmix64).
+ * Must be called with explorationLock held.
+ * Return:
+ * 	uint64: the random number
+ */
+func explorationRandom() uint64 {
+	explorationRandState += 0x9e3779b97f4a7c15
+	z := explorationRandState
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+/*
+ * Called before each traced operation if the exploration is enabled.
+ * Update the priority of the current routine and delay it depending on the
+ * number of routines with a higher priority.
+ */
+func explorationPoint() {
+	routine := GetRoutineID()
+
+	lock(&explorationLock)
+	if !explorationEnabled {
+		unlock(&explorationLock)
+		return
+	}
+
+	explorationStep++
+
+	priority, ok := explorationPriorities[routine]
+	if !ok {
+		// initial priorities are always higher than the changed priorities
+		priority = explorationDepth + int(explorationRandom()%1000000)
+	}
+
+	// lower the priority at the change points
+	for i, changePoint := range explorationChangePoints {
+		if changePoint == explorationStep {
+			priority = explorationDepth - 1 - i
+		}
+	}
+	explorationPriorities[routine] = priority
+
+	delay := 0
+	for _, p := range explorationPriorities {
+		if p > priority {
+			delay++
+		}
+	}
+	unlock(&explorationLock)
+
+	// the spawn operation is executed on the system stack and can not yield
+	gp := getg()
+	if gp != gp.m.curg {
+		return
+	}
+
+	// some hooks are called while a runtime lock is held, e.g. the close of a
+	// channel holds the lock of the channel. Yielding with a held lock makes
+	// the scheduler throw, the operation is therefore not delayed
+	if gp.m.locks > 0 {
+		return
+	}
+
+	for i := 0; i < delay && i < explorationMaxDelay; i++ {
+		Gosched()
+	}
+}
+
+// ADVOCATE-FILE-END
@@ -1,21 +1,29 @@
 package runtime
 
 const (
-	ExitCodeDefault        = 0
-	ExitCodePanic          = 3
-	ExitCodeStuckFinish    = 10
-	ExitCodeStuckWaitElem  = 11
-	ExitCodeStuckNoElem    = 12
-	ExitCodeElemEmptyTrace = 13
-	ExitCodeLeakUnbuf      = 20
-	ExitCodeLeakBuf        = 21
-	ExitCodeLeakMutex      = 22
-	ExitCodeLeakCond       = 23
-	ExitCodeLeakWG         = 24
-	ExitCodeSendClose      = 30
-	ExitCodeRecvClose      = 31
-	ExitCodeNegativeWG     = 32
-	ExitCodeCyclic         = 41
+	ExitCodeDefault           = 0
+	ExitCodePanic             = 3
+	ExitCodeStuckFinish       = 10
+	ExitCodeStuckWaitElem     = 11
+	ExitCodeStuckNoElem       = 12
+	ExitCodeElemEmptyTrace    = 13
+	ExitCodeReplayTimeout     = 14
+	ExitCodeLeakUnbuf         = 20
+	ExitCodeLeakBuf           = 21
+	ExitCodeLeakMutex         = 22
+	ExitCodeLeakCond          = 23
+	ExitCodeLeakWG            = 24
+	ExitCodeSendClose         = 30
+	ExitCodeRecvClose         = 31
+	ExitCodeNegativeWG        = 32
+	ExitCodeCloseClose        = 33
+	ExitCodeConcurrentRecv    = 34
+	ExitCodeSelectNoPartner   = 35
+	ExitCodeAddConcurrentWait = 36
+	ExitCodeWGReuse           = 37
+	ExitCodeLostWakeup        = 38
+	ExitCodeAtomicity         = 39
+	ExitCodeCyclic            = 41
 )
 
 var ExitCodeNames = map[int]string{
@@ -23,8 +31,9 @@ var ExitCodeNames = map[int]string{
 	3:  "The program panicked unexpectedly",
 	10: "Replay Stuck: Long wait time for finishing replay",
 	11: "Replay Stuck: Long wait time for running element",
-	12: "Replay Stuck: No traced operation has been executed for approx. 20s",
+	12: "Replay Stuck: No traced operation has been executed for a long time",
 	13: "The program tried to execute an operation, although all elements in the trace have already been executed.",
+	14: "Replay Stuck: The replay exceeded the maximum replay time",
 	20: "Leak: Leaking unbuffered channel or select was unstuck",
 	21: "Leak: Leaking buffered channel was unstuck",
 	22: "Leak: Leaking Mutex was unstuck",
@@ -33,6 +42,13 @@ var ExitCodeNames = map[int]string{
 	30: "Send on close",
 	31: "Receive on close",
 	32: "Negative WaitGroup counter",
+	33: "Close on close",
+	34: "Concurrent receive: the order of the receives was swapped",
+	35: "Select case without partner: select blocked on the case",
+	36: "Add concurrent with Wait: Wait returned before the Add",
+	37: "WaitGroup reused before previous Wait has returned",
+	38: "Lost wakeup: Cond Wait started after the Signal",
+	39: "Atomicity violation: rewritten trace replayed, order of the atomics not enforced",
 }
 
 /*
@@ -141,14 +157,24 @@ var numberElementsInTrace int
 var traceElementPositions = make(map[string][]int) // file -> []line
 
 // timeout
+// All timeouts are wall clock times in nanoseconds. A timeout of 0 disables
+// the corresponding check.
 var timeoutLock mutex
-var timeoutCounterGlobal = 0
-var timeoutMessageCycle = 1000 // approx. 20s
+var lastReplayOperationTime int64 // time of the last executed replay element
+var replayStartTime int64
 var timeOutCancel = false
+var replayTimeoutElement int64 = 20e9     // max wait time for a single element
+var replayTimeoutNoOperation int64 = 20e9 // max time without any executed element
+var replayTimeoutFinish int64 = 20e9      // max wait time after the main routine finished
+var replayTimeoutTotal int64 = 0          // max time for the whole replay
 
 // exit code
 var replayExitCode bool
 var expectedExitCode int
+var replayEndReached bool
+
+// function that is called before the replay terminates the program
+var replayExitHook func(code int)
 
 /*
  * Add a replay trace to the replay data.
@@ -201,6 +227,11 @@ func (t AdvocateReplayTrace) Print() {
 func EnableReplay(timeout bool) {
 	timeOutCancel = timeout
 
+	replayStartTime = nanotime()
+	lock(&timeoutLock)
+	lastReplayOperationTime = replayStartTime
+	unlock(&timeoutLock)
+
 	// run a background routine to check for timeout if no operation is executed
 	go checkForTimeoutNoOperation()
 
@@ -208,6 +239,30 @@ func EnableReplay(timeout bool) {
 	println("Replay enabled")
 }
 
+/*
+ * Set the timeouts of the replay. All timeouts are given in nanoseconds.
+ * A negative value keeps the current value, 0 disables the check.
+ * Arguments:
+ * 	element: max wait time for a single element before the replay is considered stuck
+ * 	noOperation: max time without any executed element
+ * 	finish: max wait time for the remaining elements after the main routine finished
+ * 	total: max time for the whole replay
+ */
+func SetReplayTimeouts(element, noOperation, finish, total int64) {
+	if element >= 0 {
+		replayTimeoutElement = element
+	}
+	if noOperation >= 0 {
+		replayTimeoutNoOperation = noOperation
+	}
+	if finish >= 0 {
+		replayTimeoutFinish = finish
+	}
+	if total >= 0 {
+		replayTimeoutTotal = total
+	}
+}
+
 /*
  * Disable the replay. This is called when a stop character in the trace is
  * encountered.
@@ -226,9 +281,9 @@ func DisableReplay() {
  * the program to terminate before the trace is finished.
  */
 func WaitForReplayFinish() {
-	timeoutCounter := 0
+	waitStart := nanotime()
+	lastWarning := waitStart
 	for {
-		timeoutCounter++
 		lock(&replayDoneLock)
 		if replayDone >= numberElementsInTrace {
 			unlock(&replayDoneLock)
@@ -241,10 +296,12 @@ func WaitForReplayFinish() {
 		}
 
 		// check for timeout
-		if timeoutCounter%timeoutMessageCycle == 0 {
+		now := nanotime()
+		if replayTimeoutFinish > 0 && now-lastWarning >= replayTimeoutFinish {
+			lastWarning = now
 			ExitReplayWithCode(ExitCodeStuckFinish)
 
-			waitTime := intToString(int(10 * timeoutCounter / timeoutMessageCycle))
+			waitTime := intToString(int((now - waitStart) / 1e9))
 			warningMessage := "\nReplayWarning: Long wait time for finishing replay."
 			warningMessage += "The main routine has already finished approx. "
 			warningMessage += waitTime
@@ -285,7 +342,7 @@ func IsReplayEnabled() bool {
  * 	chan ReplayElement: channel to receive the next replay element
  */
 func WaitForReplay(op Operation, skip int) (bool, bool, ReplayElement) {
-	if !replayEnabled {
+	if !replayEnabled && !explorationEnabled {
 		return false, false, ReplayElement{}
 	}
 
@@ -346,6 +403,10 @@ func WaitForReplay(op Operation, skip int) (bool, bool, ReplayElement) {
  */
 func WaitForReplayPath(op Operation, file string, line int) (bool, bool, ReplayElement) {
 	if !replayEnabled {
+		// the replay hook points are also used for the schedule exploration
+		if explorationEnabled && !AdvocateIgnoreReplay(op, file, line) {
+			explorationPoint()
+		}
 		return false, false, ReplayElement{}
 	}
 
@@ -354,7 +415,7 @@ func WaitForReplayPath(op Operation, file string, line int) (bool, bool, ReplayE
 	}
 
 	// println("Wait: ", op.ToString(), file, line)
-	timeoutCounter := 0
+	waitStart := nanotime()
 	for {
 		if !replayEnabled { // check again if disabled by command
 			return false, false, ReplayElement{}
@@ -370,6 +431,7 @@ func WaitForReplayPath(op Operation, file string, line int) (bool, bool, ReplayE
 
 		// disable the replay, if the next operation is the disable replay operation
 		if next.Op == OperationReplayEnd {
+			replayEndReached = true
 			ExitReplayWithCode(next.Line)
 
 			println("Stop Character Found. Disable Replay.")
@@ -378,7 +440,6 @@ func WaitForReplayPath(op Operation, file string, line int) (bool, bool, ReplayE
 			return false, false, ReplayElement{}
 		}
 
-		timeoutCounter++
 		// all elements in the trace have been executed
 		if nextRoutine == -1 {
 			println("The program tried to execute an operation, although all elements in the trace have already been executed.\nDisable Replay")
@@ -396,7 +457,9 @@ func WaitForReplayPath(op Operation, file string, line int) (bool, bool, ReplayE
 			if (next.Op != op && !correctSelect(next.Op, op)) ||
 				next.File != file || next.Line != line {
 
-				checkForTimeout(timeoutCounter, file, line)
+				if checkForTimeout(waitStart, file, line) {
+					waitStart = nanotime()
+				}
 				slowExecution()
 				continue
 			}
@@ -406,7 +469,7 @@ func WaitForReplayPath(op Operation, file string, line int) (bool, bool, ReplayE
 		foundReplayElement(nextRoutine)
 
 		lock(&timeoutLock)
-		timeoutCounterGlobal = 0 // reset the global timeout counter
+		lastReplayOperationTime = nanotime() // reset the global timeout
 		unlock(&timeoutLock)
 
 		lock(&replayDoneLock)
@@ -418,20 +481,22 @@ func WaitForReplayPath(op Operation, file string, line int) (bool, bool, ReplayE
 }
 
 /*
- * At specified timeoutCounter values, do some checks or messages.
- * At the first timeout, check if the position is in the trace.
- * At following timeouts, print a warning message.
- * At the last timeout, panic.
+ * If an operation waits longer than the element timeout for its turn,
+ * print a warning message and exit or panic if set.
  * Args:
- * 	timeoutCounter: the current timeout counter
+ * 	waitStart: time at which the operation started waiting
  * 	file: file in which the operation is executed
  * 	line: line number of the operation
  * Return:
- * 	bool: false
+ * 	bool: true if the timeout was reached, false otherwise
  */
-func checkForTimeout(timeoutCounter int, file string, line int) {
-	if !replayEnabled {
-		return
+func checkForTimeout(waitStart int64, file string, line int) bool {
+	if !replayEnabled || replayTimeoutElement <= 0 {
+		return false
+	}
+
+	if nanotime()-waitStart < replayTimeoutElement {
+		return false
 	}
 
 	messageCauses := "Possible causes are:\n"
@@ -440,42 +505,26 @@ func checkForTimeout(timeoutCounter int, file string, line int) {
 	messageCauses += "    - The program execution path depends on the order of not tracked operations\n"
 	messageCauses += "    - The program execution depends on outside input, that was not exactly reproduced\n"
 
-	if timeoutCounter == 500 { // ca. 10s
-		// res := isPositionInTrace(file, line)
-		// if !res {
-		// 	errorMessage := "ReplayError: Program tried to execute an operation that is not in the trace:\n"
-		// 	errorMessage += "    File: " + file + "\n"
-		// 	errorMessage += "    Line: " + intToString(line) + "\n"
-		// 	errorMessage += "This means, that the program replay was not successful.\n"
-		// 	errorMessage += messageCauses
-		// 	errorMessage += "If you suspect, that one of these causes is the reason for the error, you can try to change the program to avoid the problem.\n"
-		// 	errorMessage += "If this is not possible, you can try to rerun the replay, hoping the error does not occur again.\n"
-		// 	errorMessage += "If this is not possible or does not work, the program replay is currently not possible.\n\n"
-
-		// 	panic(errorMessage)
-		// }
-		// } else
-		if timeoutCounter%timeoutMessageCycle == 0 { // approx. every 20s
-			warningMessage := "\nReplayWarning: Long wait time\n"
-			warningMessage += "The following operation is taking a long time to execute:\n"
-			warningMessage += "    File: " + file + "\n"
-			warningMessage += "    Line: " + intToString(line) + "\n"
-			warningMessage += "This can be caused by a stuck replay.\n"
-			warningMessage += messageCauses
-			warningMessage += "If you believe, the program is still running, you can continue to wait.\n"
-			warningMessage += "If you believe, the program is stuck, you can cancel the program.\n"
-			warningMessage += "If you suspect, that one of these causes is the reason for the long wait time, you can try to change the program to avoid the problem.\n"
-			warningMessage += "If the problem persist, this message will be repeated.\n\n"
+	warningMessage := "\nReplayWarning: Long wait time\n"
+	warningMessage += "The following operation is taking a long time to execute:\n"
+	warningMessage += "    File: " + file + "\n"
+	warningMessage += "    Line: " + intToString(line) + "\n"
+	warningMessage += "This can be caused by a stuck replay.\n"
+	warningMessage += messageCauses
+	warningMessage += "If you believe, the program is still running, you can continue to wait.\n"
+	warningMessage += "If you believe, the program is stuck, you can cancel the program.\n"
+	warningMessage += "If you suspect, that one of these causes is the reason for the long wait time, you can try to change the program to avoid the problem.\n"
+	warningMessage += "If the problem persist, this message will be repeated.\n\n"
 
-			println(warningMessage)
+	println(warningMessage)
 
-			ExitReplayWithCode(ExitCodeStuckWaitElem)
+	ExitReplayWithCode(ExitCodeStuckWaitElem)
 
-			if timeOutCancel {
-				panic("ReplayError: Replay stuck")
-			}
-		}
+	if timeOutCancel {
+		panic("ReplayError: Replay stuck")
 	}
+
+	return true
 }
 
 func checkForTimeoutNoOperation() {
@@ -483,7 +532,6 @@ func checkForTimeoutNoOperation() {
 		return
 	}
 
-	waitTime := 800 // approx. 20s
 	warningMessage := "No traced operation has been executed for a long time.\n"
 	warningMessage += "This can be caused by a stuck replay.\n"
 	warningMessage += "Possible causes are:\n"
@@ -496,17 +544,34 @@ func checkForTimeoutNoOperation() {
 	warningMessage += "If you suspect, that one of these causes is the reason for the long wait time, you can try to change the program to avoid the problem.\n"
 	warningMessage += "If the problem persist, this message will be repeated.\n\n"
 
+	lastTotalWarning := replayStartTime
 	for {
-		lock(&timeoutLock)
-		timeoutCounterGlobal++
-		timeoutCounter := timeoutCounterGlobal
-		unlock(&timeoutLock)
-
 		if !replayEnabled {
 			break
 		}
 
-		if timeoutCounter%waitTime == 0 {
+		now := nanotime()
+
+		if replayTimeoutTotal > 0 && now-lastTotalWarning >= replayTimeoutTotal {
+			lastTotalWarning = now
+			message := "\nReplayWarning: The replay exceeded the maximum replay time of "
+			message += intToString(int(replayTimeoutTotal/1e9)) + "s\n"
+
+			println(message)
+			ExitReplayWithCode(ExitCodeReplayTimeout)
+			if timeOutCancel {
+				panic("ReplayError: Replay timeout")
+			}
+		}
+
+		lock(&timeoutLock)
+		noOperationTime := now - lastReplayOperationTime
+		if replayTimeoutNoOperation > 0 && noOperationTime >= replayTimeoutNoOperation {
+			lastReplayOperationTime = now // repeat the message after the next timeout
+		}
+		unlock(&timeoutLock)
+
+		if replayTimeoutNoOperation > 0 && noOperationTime >= replayTimeoutNoOperation {
 			message := "\nReplayWarning: Long wait time\n"
 			message += warningMessage
 
@@ -601,6 +666,7 @@ func IsNextElementReplayEnd(code int, runExit bool, overwrite bool) bool {
 	_, next := getNextReplayElement()
 
 	if overwrite && code == expectedExitCode {
+		replayEndReached = true
 		ExitReplayWithCode(code)
 		return true
 	}
@@ -609,6 +675,8 @@ func IsNextElementReplayEnd(code int, runExit bool, overwrite bool) bool {
 		return false
 	}
 
+	replayEndReached = true
+
 	if runExit {
 		ExitReplayWithCode(code)
 	}
@@ -616,6 +684,39 @@ func IsNextElementReplayEnd(code int, runExit bool, overwrite bool) bool {
 	return true
 }
 
+/*
+ * Wait until the next element in the trace is a replay end element with the
+ * given code and the condition is true. This lets the operations of the trace
+ * run at a point of the program, that is not an operation itself, e.g. after
+ * a waiting routine was woken up. It only waits, if the replay expects the
+ * given exit code, and at most for the element timeout.
+ * Args:
+ * 	code: the code of the replay end element
+ * 	cond: the condition, nil if only the next element is checked
+ * Return:
+ * 	bool: true if the next element is the replay end element and the condition is true
+ */
+func WaitForReplayEnd(code int, cond func() bool) bool {
+	if !replayEnabled || expectedExitCode != code {
+		return false
+	}
+
+	waitStart := nanotime()
+	for replayEnabled {
+		_, next := getNextReplayElement()
+		if next.Op == OperationReplayEnd && next.Line == code && (cond == nil || cond()) {
+			return true
+		}
+
+		if replayTimeoutElement > 0 && nanotime()-waitStart >= replayTimeoutElement {
+			return false
+		}
+		slowExecution()
+	}
+
+	return false
+}
+
 func foundReplayElement(routine int) {
 	lock(&replayLock)
 	defer unlock(&replayLock)
@@ -632,9 +733,41 @@ func SetExpectedExitCode(code int) {
 	expectedExitCode = code
 }
 
+func GetExpectedExitCode() int {
+	return expectedExitCode
+}
+
+/*
+ * Get the progress of the replay.
+ * Return:
+ * 	int: number of replayed elements
+ * 	int: number of elements in the trace
+ * 	bool: true if the replay end element of the trace was reached, false otherwise
+ */
+func GetReplayProgress() (int, int, bool) {
+	lock(&replayDoneLock)
+	defer unlock(&replayDoneLock)
+
+	return replayDone, numberElementsInTrace, replayEndReached
+}
+
+/*
+ * Set a function that is called before the replay terminates the program with
+ * an exit code. This is used to write the trace recorded during the replay
+ * and the result of the replay.
+ * Args:
+ * 	hook: function to call with the exit code before the exit
+ */
+func SetReplayExitHook(hook func(code int)) {
+	replayExitHook = hook
+}
+
 func ExitReplayWithCode(code int) {
 	if replayExitCode {
 		println("Exit Replay with code ", code, ExitCodeNames[code])
+		if replayExitHook != nil {
+			replayExitHook(code)
+		}
 		exit(int32(code))
 	}
 }
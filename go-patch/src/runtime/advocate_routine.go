@@ -18,12 +18,19 @@ var atomicRecordingDisabled = false
  * id: the id of the routine
  * G: the g struct of the routine
  * Trace: the trace of the routine
+ * Atomics: the atomic operations of the routine
+ * Memory: the memory accesses of the routine
+ * finished: true if the routine has returned
+ * recorded: true if the spawn of the routine was recorded
  */
 type AdvocateRoutine struct {
-	id      uint64
-	G       *g
-	Trace   []string
-	Atomics []string
+	id       uint64
+	G        *g
+	Trace    []string
+	Atomics  []string
+	Memory   []string
+	finished bool
+	recorded bool
 	// lock    *mutex
 	newEvents []string
 }
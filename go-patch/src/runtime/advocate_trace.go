@@ -157,6 +157,10 @@ func getTpre(elem string) int {
  * 	index of the element in the trace
  */
 func insertIntoTrace(elem string, atomic bool) int {
+	if advocateMemoryFlushNeeded() {
+		advocateMemoryFlush(false)
+	}
+
 	if atomic {
 		currentGoRoutine().addAtomicToTrace(elem)
 		return -1
@@ -184,7 +188,8 @@ func TraceToStringByID(id uint64) (string, bool) {
 	lock(&AdvocateRoutinesLock)
 	defer unlock(&AdvocateRoutinesLock)
 	if routine, ok := AdvocateRoutines[id]; ok {
-		return traceToString(&routine.Trace, &routine.Atomics), true
+		trace := mergeMemoryIntoTrace(routine.Trace, routine.Memory)
+		return traceToString(&trace, &routine.Atomics), true
 	}
 	return "", false
 }
@@ -200,7 +205,7 @@ func TraceIsEmptyByRoutine(routine int) bool {
 	lock(&AdvocateRoutinesLock)
 	defer unlock(&AdvocateRoutinesLock)
 	if routine, ok := AdvocateRoutines[uint64(routine)]; ok {
-		return len(routine.Trace) == 0
+		return len(routine.Trace) == 0 && len(routine.Memory) == 0
 	}
 	return true
 }
@@ -220,28 +225,29 @@ func TraceToStringByIDChannel(id int, c chan<- string) {
 	if routine, ok := AdvocateRoutines[uint64(id)]; ok {
 		unlock(&AdvocateRoutinesLock)
 		res := ""
+		trace := mergeMemoryIntoTrace(routine.Trace, routine.Memory)
 
 		if !atomicRecordingDisabled {
 			traceIndex := 0
 			atomicIndex := 0
 
 			// merge trace and atomics based on the time
-			for i := 0; i < len(routine.Trace)+len(routine.Atomics); i++ {
+			for i := 0; i < len(trace)+len(routine.Atomics); i++ {
 				if i != 0 {
 					res += ";"
 				}
-				if traceIndex < len(routine.Trace) && atomicIndex < len(routine.Atomics) {
-					traceTime := getTpre(routine.Trace[traceIndex])
+				if traceIndex < len(trace) && atomicIndex < len(routine.Atomics) {
+					traceTime := getTpre(trace[traceIndex])
 					atomicTime := getTpre(routine.Atomics[atomicIndex])
 					if traceTime < atomicTime {
-						res += (routine.Trace)[traceIndex]
+						res += trace[traceIndex]
 						traceIndex++
 					} else {
 						res += addAtomicInfo(routine.Atomics[atomicIndex])
 						atomicIndex++
 					}
-				} else if traceIndex < len(routine.Trace) {
-					res += (routine.Trace)[traceIndex]
+				} else if traceIndex < len(trace) {
+					res += trace[traceIndex]
 					traceIndex++
 				} else {
 					res += addAtomicInfo(routine.Atomics[atomicIndex])
@@ -371,6 +377,9 @@ func InitAdvocate(size int) {
 func DisableTrace() {
 	at.AdvocateAtomicUnlink()
 	advocateDisabled = true
+	if advocateMemoryBuffer != nil {
+		AdvocateDisableMemoryRecording()
+	}
 }
 
 /*
@@ -414,74 +423,125 @@ func DeleteTrace() {
 // ====================== Ignore =========================
 
 /*
- * Some operations, like garbage collection and internal operations, can
- * cause the replay to get stuck or are not needed.
- * For this reason, we ignore them.
+ * advocateIgnoredPos is a position, whose operations are ignored by the
+ * recording and the replay
+ * Fields:
+ * 	ops: operations that are ignored at the position
+ * 	file: suffix of the file of the position
+ * 	lines: lines of the position, all lines of the file if empty
+ */
+type advocateIgnoredPos struct {
+	ops   []Operation
+	file  string
+	lines []int
+}
+
+// Some operations, like garbage collection and internal operations, can
+// cause the replay to get stuck or are not needed. For this reason, we
+// ignore them. The table is written next to the trace recorded during a
+// replay (see AdvocateIgnoredPositions), so that the analyzer can use the
+// same positions. Lines must be updated, if the files are changed.
+var advocateIgnored = []advocateIgnoredPos{
+	// internal
+	{file: "advocate/advocate.go"},
+	{file: "advocate/advocate_replay.go"},
+	{file: "advocate/advocate_routine.go"},
+	{file: "advocate/advocate_trace.go"},
+	{file: "advocate/advocate_utile.go"},
+	{file: "advocate/advocate_atomic.go"},
+	// {file: "testing/testing.go"},
+	{file: "syscall/env_unix.go"},
+	{file: "runtime/signal_unix.go"},
+	// garbage collection can cause the replay to get stuck
+	{ops: []Operation{OperationSpawn}, file: "runtime/mgc.go", lines: []int{1215}},
+	// mutex operations in the once can cause the replay to get stuck,
+	// if the once was called by the poll/fd_poll_runtime.go init.
+	{ops: []Operation{OperationMutexLock, OperationMutexUnlock}, file: "sync/once.go",
+		lines: []int{116, 117, 122, 126}},
+	// pools
+	{ops: []Operation{OperationMutexLock, OperationMutexUnlock}, file: "sync/pool.go",
+		lines: []int{226, 243}},
+	// mutex in rwmutex
+	// {ops: []Operation{OperationMutexLock, OperationMutexUnlock}, file: "sync/rwmutex.go",
+	// 	lines: []int{270, 396}},
+	// once operations in the poll/fd_poll_runtime.go init can cause the replay to get stuck.
+	{ops: []Operation{OperationOnce}, file: "internal/poll/fd_poll_runtime.go", lines: []int{40}},
+}
+
+// positions, that are only ignored by the replay
+var advocateIgnoredReplay = []advocateIgnoredPos{
+	{file: "time/sleep.go"},
+}
+
+/*
+ * Check if an operation is at an ignored position
  * Arguments:
+ * 	ignored: the ignored positions
  * 	operation: operation that is about to be executed
  * 	file: file in which the operation is executed
  * 	line: line number of the operation
  * Return:
- * 	bool: true if the operation should be ignored, false otherwise
+ * 	bool: true if the operation is at one of the positions, false otherwise
  */
-func AdvocateIgnore(operation Operation, file string, line int) bool {
-	if hasSuffix(file, "advocate/advocate.go") ||
-		hasSuffix(file, "advocate/advocate_replay.go") ||
-		hasSuffix(file, "advocate/advocate_routine.go") ||
-		hasSuffix(file, "advocate/advocate_trace.go") ||
-		hasSuffix(file, "advocate/advocate_utile.go") ||
-		hasSuffix(file, "advocate/advocate_atomic.go") { // internal
-		return true
-	}
-
-	// if hasSuffix(file, "testing/testing.go") {
-	// 	return true
-	// }
-
-	if hasSuffix(file, "syscall/env_unix.go") {
-		return true
-	}
-
-	if hasSuffix(file, "runtime/signal_unix.go") {
-		return true
-	}
-
-	switch operation {
-	case OperationSpawn:
-		// garbage collection can cause the replay to get stuck
-		if hasSuffix(file, "runtime/mgc.go") && line == 1215 {
-			return true
-		}
-	case OperationMutexLock, OperationMutexUnlock:
-		// mutex operations in the once can cause the replay to get stuck,
-		// if the once was called by the poll/fd_poll_runtime.go init.
-		if hasSuffix(file, "sync/once.go") && (line == 116 || line == 117 ||
-			line == 122 || line == 126) {
-			return true
+func isIgnoredPos(ignored []advocateIgnoredPos, operation Operation, file string, line int) bool {
+	for _, pos := range ignored {
+		if !hasSuffix(file, pos.file) {
+			continue
 		}
-		// pools
-		if hasSuffix(file, "sync/pool.go") && (line == 226 || line == 243) {
-			return true
+
+		if len(pos.ops) != 0 && !containsOperation(pos.ops, operation) {
+			continue
 		}
-		// mutex in rwmutex
-		// if hasSuffix(file, "sync/rwmutex.go") && (line == 270 || line == 396) {
-		// 	return true
-		// }
-	case OperationOnce:
-		// once operations in the poll/fd_poll_runtime.go init can cause the replay to get stuck.
-		if hasSuffix(file, "internal/poll/fd_poll_runtime.go") && line == 40 {
+
+		if len(pos.lines) == 0 || containsInt(pos.lines, line) {
 			return true
 		}
 	}
 	return false
 }
 
+/*
+ * Some operations, like garbage collection and internal operations, can
+ * cause the replay to get stuck or are not needed.
+ * For this reason, we ignore them.
+ * Arguments:
+ * 	operation: operation that is about to be executed
+ * 	file: file in which the operation is executed
+ * 	line: line number of the operation
+ * Return:
+ * 	bool: true if the operation should be ignored, false otherwise
+ */
+func AdvocateIgnore(operation Operation, file string, line int) bool {
+	return isIgnoredPos(advocateIgnored, operation, file, line)
+}
+
 func AdvocateIgnoreReplay(operation Operation, file string, line int) bool {
-	if hasSuffix(file, "time/sleep.go") {
+	if isIgnoredPos(advocateIgnoredReplay, operation, file, line) {
 		return true
 	}
 
 	return AdvocateIgnore(operation, file, line)
 }
 
+/*
+ * Get the positions, whose operations are not enforced by the replay.
+ * Return:
+ * 	[]string: the positions in the form file for a whole file or file:line
+ */
+func AdvocateIgnoredPositions() []string {
+	res := make([]string, 0)
+	for _, ignored := range [][]advocateIgnoredPos{advocateIgnored, advocateIgnoredReplay} {
+		for _, pos := range ignored {
+			if len(pos.lines) == 0 {
+				res = append(res, pos.file)
+				continue
+			}
+			for _, line := range pos.lines {
+				res = append(res, pos.file+":"+intToString(line))
+			}
+		}
+	}
+	return res
+}
+
 // ADVOCATE-FILE-END
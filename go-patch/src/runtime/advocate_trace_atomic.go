@@ -2,6 +2,19 @@ package runtime
 
 import at "runtime/internal/atomic"
 
+// the position of atomic operations is only recorded, if it was enabled
+// with AdvocateEnableAtomicPositions, because getting it is expensive
+var advocateAtomicPositions = false
+
+/*
+ * AdvocateEnableAtomicPositions enables the recording of the position of
+ * atomic operations. The position is needed by the analyzer to detect
+ * atomicity violations.
+ */
+func AdvocateEnableAtomicPositions() {
+	advocateAtomicPositions = true
+}
+
 /*
  * Add an atomic operation to the trace
  * Args:
@@ -12,10 +25,46 @@ func AdvocateAtomicPre(index uint64) {
 
 	elem := "A," + uint64ToString(timer) + "," + uint64ToString(index) + ",-"
 
+	if advocateAtomicPositions {
+		if file, line := atomicCaller(); file != "" {
+			elem += "," + file + ":" + intToString(line)
+		}
+	}
+
 	// // elem := advocateAtomicElement{index: index, timer: timer}
 	insertIntoTrace(elem, true)
 }
 
+/*
+ * Get the position of an atomic operation in the program code. The frames
+ * of the recording and of the implementation of the atomic operations are
+ * skipped. The stack is only walked once.
+ * Return:
+ * 	file of the atomic operation, empty if it could not be determined
+ * 	line of the atomic operation
+ */
+func atomicCaller() (string, int) {
+	// The atomic operation is reported by a send on the internal channel in
+	// runtime/internal/atomic/advocate_atomic.go. Skip atomicCaller,
+	// AdvocateAtomicPre, AdvocateChanSendPre, chansend and chansend1. The
+	// following frames in runtime/internal/atomic and sync/atomic are skipped
+	// by their file.
+	var pcs [16]uintptr
+	n := callers(5, pcs[:])
+
+	frames := CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if frame.File != "" && !containsString(frame.File, "runtime/internal/atomic/") &&
+			!containsString(frame.File, "sync/atomic/") {
+			return frame.File, frame.Line
+		}
+		if !more {
+			return "", 0
+		}
+	}
+}
+
 /*
  * Update the atomic operation in the trace after received
  * Args:
@@ -38,7 +87,7 @@ func AdvocateAtomicPost(atomic at.AtomicElem) {
  * 	the atomic operation with the id and operation
  */
 func addAtomicInfo(elem string) string {
-	split := splitStringAtCommas(elem, []int{2, 3}) // A,[tpre] - id - operation
+	split := splitStringAtCommas(elem, []int{2, 3, 4}) // A,[tpre] - id - operation - pos
 	if split[2] != "-" {
 		return elem
 	}
@@ -0,0 +1,294 @@
+package runtime
+
+import "runtime/internal/atomic"
+
+// memory accesses are only recorded for programs built with -race, if the
+// recording was enabled with AdvocateEnableMemoryRecording
+var advocateMemoryRecording = false
+var advocateMemoryPackages []string
+
+// number of accesses, that can be stored before they are converted into
+// trace elements. If both buffers are full, further accesses are dropped.
+const advocateMemoryBufferSize = 1 << 16
+
+/*
+ * advocateMemoryElem is a recorded memory access, that has not been
+ * converted into a trace element yet
+ * Fields:
+ * 	routine: id of the routine, that executed the access
+ * 	timer: value of the global counter at the access
+ * 	addr: address of the access
+ * 	pc: return address into the function, that executed the access
+ * 	write: true for a write, false for a read
+ * 	gc: number of completed gc cycles at the access
+ */
+type advocateMemoryElem struct {
+	routine uint64
+	timer   uint64
+	addr    uintptr
+	pc      uintptr
+	write   bool
+	gc      uint32
+}
+
+// The accesses are recorded from the race instrumentation, where the
+// routine must not allocate. They are therefore stored in a preallocated
+// buffer. When it is half full, it is swapped with the spare buffer by the
+// next recorded operation of any routine, which converts the accesses into
+// trace elements.
+var advocateMemoryBuffer []advocateMemoryElem
+var advocateMemorySpare []advocateMemoryElem
+var advocateMemoryCount int
+var advocateMemoryDropped uint64
+var advocateMemoryLock mutex
+var advocateMemoryFlushing atomic.Uint32
+
+/*
+ * AdvocateEnableMemoryRecording enables the recording of reads and writes
+ * on memory. The accesses are reported by the race instrumentation of the
+ * compiler, the program must therefore be built with -race. To keep the trace
+ * small, only accesses in functions of the given packages are recorded.
+ * Args:
+ * 	packages: import paths of the packages whose accesses are recorded
+ * Return:
+ * 	true if the recording was enabled, false if the program was not built with
+ * 	-race or the architecture is not amd64
+ */
+func AdvocateEnableMemoryRecording(packages []string) bool {
+	// the calls of advocateMemoryAccess are only added in race_amd64.s
+	if !raceenabled || GOARCH != "amd64" {
+		return false
+	}
+
+	lock(&advocateMemoryLock)
+	if advocateMemoryBuffer == nil {
+		advocateMemoryBuffer = make([]advocateMemoryElem, advocateMemoryBufferSize)
+		advocateMemorySpare = make([]advocateMemoryElem, advocateMemoryBufferSize)
+	}
+	unlock(&advocateMemoryLock)
+
+	advocateMemoryPackages = packages
+	advocateMemoryRecording = len(packages) != 0
+	return advocateMemoryRecording
+}
+
+/*
+ * AdvocateDisableMemoryRecording disables the recording of memory accesses
+ * and adds the stored accesses to the traces of their routines
+ */
+func AdvocateDisableMemoryRecording() {
+	advocateMemoryRecording = false
+	advocateMemoryFlush(true)
+}
+
+/*
+ * Record a read or write on one memory location. Called from raceread and
+ * racewrite before the access is passed on to the race detector. The caller
+ * is a nosplit assembly function, that is called from arbitrary points of
+ * the instrumented code. The access is therefore only stored in the
+ * preallocated buffer on the system stack and nothing is allocated.
+ * Args:
+ * 	addr: address of the access
+ * 	pc: return address into the function that executed the access
+ * 	write: true for a write, false for a read
+ * Return:
+ * 	addr, so that the address is still in its register after the call
+ */
+//go:nosplit
+func advocateMemoryAccess(addr uintptr, pc uintptr, write bool) uintptr {
+	if advocateDisabled || !advocateMemoryRecording {
+		return addr
+	}
+
+	// Variables on the stack of a routine are never shared with other
+	// routines, because variables used by multiple routines escape to the
+	// heap. Their addresses are reused by later frames and by routines, that
+	// get the stack after the routine terminated. Recording them would only
+	// create false data races.
+	gp := getg()
+	if gp.stack.lo <= addr && addr < gp.stack.hi {
+		return addr
+	}
+
+	if gp.goInfo == nil {
+		return addr
+	}
+	routine := gp.goInfo.id
+
+	systemstack(func() {
+		advocateMemoryRecord(routine, addr, pc, write)
+	})
+	return addr
+}
+
+/*
+ * Record a read or write on a range of memory. The access is recorded as an
+ * access on the first address of the range.
+ * Args:
+ * 	addr: first address of the access
+ * 	size: size of the range
+ * 	pc: return address into the function that executed the access
+ * 	write: true for a write, false for a read
+ * Return:
+ * 	addr and size, so that they are still in their registers after the call
+ */
+//go:nosplit
+func advocateMemoryAccessRange(addr uintptr, size uintptr, pc uintptr, write bool) (uintptr, uintptr) {
+	advocateMemoryAccess(addr, pc, write)
+	return addr, size
+}
+
+/*
+ * Store a memory access in the buffer, if it was executed in one of the
+ * selected packages. Must not allocate.
+ * Args:
+ * 	routine: id of the routine, that executed the access
+ * 	addr: address of the access
+ * 	pc: return address into the function that executed the access
+ * 	write: true for a write, false for a read
+ */
+//go:systemstack
+func advocateMemoryRecord(routine uint64, addr uintptr, pc uintptr, write bool) {
+	f := findfunc(pc)
+	if !f.valid() || !advocateMemoryRecordedFunc(funcname(f)) {
+		return
+	}
+
+	lock(&advocateMemoryLock)
+	if advocateMemoryCount < len(advocateMemoryBuffer) {
+		// the timer is taken while holding the lock, so that the accesses
+		// in the buffer are sorted by their time
+		advocateMemoryBuffer[advocateMemoryCount] = advocateMemoryElem{
+			routine: routine,
+			timer:   GetNextTimeStep(),
+			addr:    addr,
+			pc:      pc,
+			write:   write,
+			gc:      work.cycles.Load(),
+		}
+		advocateMemoryCount++
+	} else {
+		advocateMemoryDropped++
+	}
+	unlock(&advocateMemoryLock)
+}
+
+/*
+ * Check if accesses in a function are recorded
+ * Args:
+ * 	name: full name of the function, e.g. example.com/pkg.(*T).f
+ * Return:
+ * 	true if the function is in one of the selected packages
+ */
+func advocateMemoryRecordedFunc(name string) bool {
+	for _, pkg := range advocateMemoryPackages {
+		if len(name) > len(pkg) && name[:len(pkg)] == pkg && name[len(pkg)] == '.' {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Check if the buffer of the memory accesses should be flushed
+ * Return:
+ * 	true if the buffer is at least half full
+ */
+func advocateMemoryFlushNeeded() bool {
+	return advocateMemoryBuffer != nil && advocateMemoryCount >= advocateMemoryBufferSize/2
+}
+
+/*
+ * Convert the recorded memory accesses into trace elements and add them to
+ * the memory accesses of their routines. The filled buffer is swapped with
+ * the spare buffer, so that no allocation is done while holding
+ * advocateMemoryLock, which the recording takes on the system stack.
+ * Args:
+ * 	wait: if true, wait for a running flush to finish, otherwise return
+ * 		if another routine is already flushing
+ */
+func advocateMemoryFlush(wait bool) {
+	for !advocateMemoryFlushing.CompareAndSwap(0, 1) {
+		if !wait {
+			return
+		}
+		Gosched()
+	}
+	defer advocateMemoryFlushing.Store(0)
+
+	lock(&advocateMemoryLock)
+	full := advocateMemoryBuffer[:advocateMemoryCount]
+	advocateMemoryBuffer, advocateMemorySpare = advocateMemorySpare, advocateMemoryBuffer
+	advocateMemoryCount = 0
+	dropped := advocateMemoryDropped
+	advocateMemoryDropped = 0
+	unlock(&advocateMemoryLock)
+
+	if dropped != 0 {
+		println("ADVOCATE: dropped", dropped, "memory accesses, because the buffer was full")
+	}
+
+	var routine *AdvocateRoutine
+	for _, elem := range full {
+		if routine == nil || routine.id != elem.routine {
+			lock(&AdvocateRoutinesLock)
+			routine = AdvocateRoutines[elem.routine]
+			unlock(&AdvocateRoutinesLock)
+			if routine == nil {
+				continue
+			}
+		}
+
+		routine.Memory = append(routine.Memory, advocateMemoryElemToString(elem))
+	}
+}
+
+/*
+ * Get the trace element of a memory access
+ * Args:
+ * 	elem: the memory access
+ * Return:
+ * 	the trace element
+ */
+func advocateMemoryElemToString(elem advocateMemoryElem) string {
+	file, line := "", int32(0)
+	if f := findfunc(elem.pc); f.valid() {
+		file, line = funcline1(f, elem.pc-1, false)
+	}
+
+	op := "R"
+	if elem.write {
+		op = "W"
+	}
+
+	return "D," + uint64ToString(elem.timer) + "," + uint64ToString(uint64(elem.addr)) + "," +
+		op + "," + uint64ToString(uint64(elem.gc)) + "," + file + ":" + int32ToString(line)
+}
+
+/*
+ * Merge the trace of a routine with its memory accesses by their time
+ * Args:
+ * 	trace: the trace of the routine
+ * 	memory: the memory accesses of the routine
+ * Return:
+ * 	the merged trace
+ */
+func mergeMemoryIntoTrace(trace []string, memory []string) []string {
+	if len(memory) == 0 {
+		return trace
+	}
+
+	res := make([]string, 0, len(trace)+len(memory))
+	i, j := 0, 0
+	for i < len(trace) && j < len(memory) {
+		if getTpre(trace[i]) < getTpre(memory[j]) {
+			res = append(res, trace[i])
+			i++
+		} else {
+			res = append(res, memory[j])
+			j++
+		}
+	}
+	res = append(res, trace[i:]...)
+	return append(res, memory[j:]...)
+}
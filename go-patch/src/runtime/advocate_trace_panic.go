@@ -0,0 +1,44 @@
+package runtime
+
+/*
+ * AdvocatePanic adds a panic to the trace. The position is the position of
+ * the call of panic, or, for run-time errors like a nil pointer dereference,
+ * the position of the failing operation.
+ */
+func AdvocatePanic() {
+	if advocateDisabled {
+		return
+	}
+
+	file, line := panicCaller()
+	if file == "" {
+		return
+	}
+
+	timer := GetNextTimeStep()
+
+	elem := "P," + uint64ToString(timer) + "," + file + ":" + intToString(line)
+
+	insertIntoTrace(elem, false)
+}
+
+/*
+ * Get the position of a panic in the program code. The frames of the
+ * runtime, e.g. of gopanic or panicmem, are skipped.
+ * Return:
+ * 	file of the panic, empty if it could not be determined
+ * 	line of the panic
+ */
+func panicCaller() (string, int) {
+	// panicCaller, AdvocatePanic, gopanic
+	for skip := 3; ; skip++ {
+		_, file, line, ok := Caller(skip)
+		if !ok {
+			return "", 0
+		}
+
+		if !containsString(file, "src/runtime/") {
+			return file, line
+		}
+	}
+}
@@ -15,3 +15,38 @@ func AdvocateSpawnCaller(callerRoutine *AdvocateRoutine, newID uint64, file stri
 
 	callerRoutine.addToTrace(elem)
 }
+
+/*
+ * AdvocateRoutineEnd marks the current routine as finished. The g of the
+ * routine can be reused for new routines afterwards.
+ */
+func AdvocateRoutineEnd() {
+	gp := getg()
+	if gp.goInfo != nil {
+		gp.goInfo.finished = true
+	}
+}
+
+/*
+ * GetUnfinishedRoutines returns the ids of all routines, that were spawned
+ * while recording and have not returned yet. System routines of the runtime
+ * and the calling routine are not included.
+ * Return:
+ * 	ids of the routines, that have not returned
+ */
+func GetUnfinishedRoutines() []uint64 {
+	self := getg().goInfo
+
+	lock(&AdvocateRoutinesLock)
+	defer unlock(&AdvocateRoutinesLock)
+
+	res := make([]uint64, 0)
+	for id, routine := range AdvocateRoutines {
+		if routine == self || !routine.recorded || routine.finished ||
+			routine.G == nil || isSystemGoroutine(routine.G, false) {
+			continue
+		}
+		res = append(res, id)
+	}
+	return res
+}
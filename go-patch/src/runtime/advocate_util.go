@@ -291,6 +291,40 @@ func containsInt(list []int, elem int) bool {
 	return false
 }
 
+/*
+ * Check if a list of operations contains an operation
+ * Args:
+ * 	list: list of operations
+ * 	elem: operation to check
+ * Return:
+ * 	true if the list contains the operation, false otherwise
+ */
+func containsOperation(list []Operation, elem Operation) bool {
+	for _, e := range list {
+		if e == elem {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Check if a string contains a substring
+ * Args:
+ * 	s: string to check
+ * 	sub: substring to search for
+ * Return:
+ * 	true if s contains sub, false otherwise
+ */
+func containsString(s, sub string) bool {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		if s[i:i+len(sub)] == sub {
+			return true
+		}
+	}
+	return false
+}
+
 /*
  * Slow down the execution of the program
  */
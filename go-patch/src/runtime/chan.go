@@ -497,19 +497,32 @@ func closechan(c *hchan) {
 		panic(plainError("close of nil channel"))
 	}
 
+	// ADVOCATE-CHANGE-START
+	// The replay wait must be done before the channel lock is taken, because
+	// the wait can end the replay, which parks the routine.
+	enabled := false
+	if !c.advocateIgnore {
+		enabled, _, _ = WaitForReplay(OperationChannelClose, 2)
+	}
+	// ADVOCATE-CHANGE-END
+
 	lock(&c.lock)
 
 	// ADVOCATE-CHANGE-START
 	// AdvocateChanClose is called when a channel is closed. It creates a close event
 	// in the trace.
 	if !c.advocateIgnore {
-		_, _, _ = WaitForReplay(OperationChannelClose, 2)
 		AdvocateChanClose(c.id, c.dataqsiz)
 	}
 	// ADVOCATE-CHANGE-END
 
 	if c.closed != 0 {
 		unlock(&c.lock)
+		// ADVOCATE-CHANGE-START
+		if enabled {
+			IsNextElementReplayEnd(ExitCodeCloseClose, true, false)
+		}
+		// ADVOCATE-CHANGE-END
 		panic(plainError("close of closed channel"))
 	}
 
@@ -761,6 +761,10 @@ func gopanic(e any) {
 		throw("panic holding locks")
 	}
 
+	// ADVOCATE-CHANGE-START
+	AdvocatePanic()
+	// ADVOCATE-CHANGE-END
+
 	var p _panic
 	p.arg = e
 
@@ -4167,6 +4167,9 @@ func goexit1() {
 	if raceenabled {
 		racegoend()
 	}
+	// ADVOCATE-CHANGE-START
+	AdvocateRoutineEnd()
+	// ADVOCATE-CHANGE-END
 	trace := traceAcquire()
 	if trace.ok() {
 		trace.GoEnd()
@@ -4883,16 +4886,19 @@ func newproc(fn *funcval) {
 	}
 	file, line := funcline(f, tracepc)
 
+	// the replay wait can end the replay, which parks the routine, so it
+	// must not be done on the system stack
+	_, _, _ = WaitForReplayPath(OperationSpawn, file, int(line))
 	// ADVOCATE-CHANGE-END
 
 	systemstack(func() {
 		newg := newproc1(fn, gp, pc)
 
 		// ADVOCATE-CHANGE-START
-		_, _, _ = WaitForReplayPath(OperationSpawn, file, int(line))
 		newg.goInfo = newAdvocateRoutine(newg)
 		if gp != nil && gp.goInfo != nil {
 			AdvocateSpawnCaller(gp.goInfo, newg.goInfo.id, file, line)
+			newg.goInfo.recorded = !advocateDisabled
 		}
 		// ADVOCATE-CHANGE-END
 
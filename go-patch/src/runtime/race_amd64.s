@@ -45,6 +45,14 @@
 // Defined as ABIInternal so as to avoid introducing a wrapper,
 // which would render runtime.getcallerpc ineffective.
 TEXT	runtime·raceread<ABIInternal>(SB), NOSPLIT, $0-8
+	// ADVOCATE-CHANGE-START
+	CMPB	runtime·advocateMemoryRecording(SB), $0
+	JEQ	raceread_advocate
+	MOVQ	(SP), BX
+	MOVL	$0, CX
+	CALL	runtime·advocateMemoryAccess<ABIInternal>(SB) // keeps AX
+raceread_advocate:
+	// ADVOCATE-CHANGE-END
 	MOVQ	AX, RARG1
 	MOVQ	(SP), RARG2
 	// void __tsan_read(ThreadState *thr, void *addr, void *pc);
@@ -71,6 +79,14 @@ TEXT	runtime·racereadpc(SB), NOSPLIT, $0-24
 // Defined as ABIInternal so as to avoid introducing a wrapper,
 // which would render runtime.getcallerpc ineffective.
 TEXT	runtime·racewrite<ABIInternal>(SB), NOSPLIT, $0-8
+	// ADVOCATE-CHANGE-START
+	CMPB	runtime·advocateMemoryRecording(SB), $0
+	JEQ	racewrite_advocate
+	MOVQ	(SP), BX
+	MOVL	$1, CX
+	CALL	runtime·advocateMemoryAccess<ABIInternal>(SB) // keeps AX
+racewrite_advocate:
+	// ADVOCATE-CHANGE-END
 	MOVQ	AX, RARG1
 	MOVQ	(SP), RARG2
 	// void __tsan_write(ThreadState *thr, void *addr, void *pc);
@@ -97,6 +113,14 @@ TEXT	runtime·racewritepc(SB), NOSPLIT, $0-24
 // Defined as ABIInternal so as to avoid introducing a wrapper,
 // which would render runtime.getcallerpc ineffective.
 TEXT	runtime·racereadrange<ABIInternal>(SB), NOSPLIT, $0-16
+	// ADVOCATE-CHANGE-START
+	CMPB	runtime·advocateMemoryRecording(SB), $0
+	JEQ	racereadrange_advocate
+	MOVQ	(SP), CX
+	MOVL	$0, DI
+	CALL	runtime·advocateMemoryAccessRange<ABIInternal>(SB) // keeps AX and BX
+racereadrange_advocate:
+	// ADVOCATE-CHANGE-END
 	MOVQ	AX, RARG1
 	MOVQ	BX, RARG2
 	MOVQ	(SP), RARG3
@@ -124,6 +148,14 @@ TEXT	runtime·racereadrangepc1(SB), NOSPLIT, $0-24
 // Defined as ABIInternal so as to avoid introducing a wrapper,
 // which would render runtime.getcallerpc ineffective.
 TEXT	runtime·racewriterange<ABIInternal>(SB), NOSPLIT, $0-16
+	// ADVOCATE-CHANGE-START
+	CMPB	runtime·advocateMemoryRecording(SB), $0
+	JEQ	racewriterange_advocate
+	MOVQ	(SP), CX
+	MOVL	$1, DI
+	CALL	runtime·advocateMemoryAccessRange<ABIInternal>(SB) // keeps AX and BX
+racewriterange_advocate:
+	// ADVOCATE-CHANGE-END
 	MOVQ	AX, RARG1
 	MOVQ	BX, RARG2
 	MOVQ	(SP), RARG3
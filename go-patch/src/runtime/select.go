@@ -250,7 +250,8 @@ func selectgo(cas0 *scase, order0 *uint16, pc0 *uintptr, nsends, nrecvs int, blo
 	// Here the first lock order is set. This is only needed if the select
 	// is never executed.
 	advocateIndex := AdvocateSelectPre(&scases, nsends, ncases, block, lockorder)
-	advocateRClose := false // case was chosen, because channel was closed
+	advocateRClose := false     // case was chosen, because channel was closed
+	advocateEndChecked := false // the replay end was checked before blocking
 	// ADVOCATE-CHANGE-END
 
 	// lock all the channels involved in the select
@@ -286,11 +287,21 @@ func selectgo(cas0 *scase, order0 *uint16, pc0 *uintptr, nsends, nrecvs int, blo
 		}
 	}
 
+	// ADVOCATE-CHANGE-START
+advocatePass1:
+	// ADVOCATE-CHANGE-END
 	for _, casei := range pollorder {
 		casi = int(casei)
 		cas = &scases[casi]
 		c = cas.c
 
+		// ADVOCATE-CHANGE-START
+		// only the case chosen in the trace can be selected
+		if replayEnabled && valid && casi != replayElem.SelIndex {
+			continue
+		}
+		// ADVOCATE-CHANGE-END
+
 		if casi >= nsends {
 			// ADVOCATE-CHANGE-START
 			sg = c.sendq.dequeue(replayElem)
@@ -332,6 +343,19 @@ func selectgo(cas0 *scase, order0 *uint16, pc0 *uintptr, nsends, nrecvs int, blo
 		goto retc
 	}
 
+	// ADVOCATE-CHANGE-START
+	// the select blocks on the case chosen in the trace, e.g. a case without partner.
+	// Exiting the replay is not possible while the channels are locked. A
+	// partner can arrive while they are unlocked, pass 1 is therefore repeated.
+	if replayEnabled && valid && !advocateEndChecked {
+		advocateEndChecked = true
+		selunlock(scases, lockorder)
+		IsNextElementReplayEnd(ExitCodeSelectNoPartner, true, false)
+		sellock(scases, lockorder)
+		goto advocatePass1
+	}
+	// ADVOCATE-CHANGE-END
+
 	// pass 2 - enqueue on all chans
 	gp = getg()
 	if gp.waiting != nil {
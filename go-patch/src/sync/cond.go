@@ -76,7 +76,7 @@ func (c *Cond) Wait() {
 		c.id = runtime.GetAdvocateObjectID()
 	}
 	// replay
-	_, _, _ = runtime.WaitForReplay(runtime.OperationCondWait, 2)
+	enabled, _, _ := runtime.WaitForReplay(runtime.OperationCondWait, 2)
 	//record
 	advocateIndex := runtime.AdvocateCondPre(c.id, 0)
 	defer runtime.AdvocateCondPost(advocateIndex)
@@ -84,6 +84,11 @@ func (c *Cond) Wait() {
 
 	c.checker.check()
 	t := runtime_notifyListAdd(&c.notify)
+	// ADVOCATE-CHANGE-START
+	if enabled {
+		runtime.IsNextElementReplayEnd(runtime.ExitCodeLostWakeup, true, false)
+	}
+	// ADVOCATE-CHANGE-END
 	c.L.Unlock()
 	runtime_notifyListWait(&c.notify, t)
 	c.L.Lock()
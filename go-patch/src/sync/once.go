@@ -106,7 +106,7 @@ func (o *Once) Do(f func()) {
 		println("advocate once: replay failed")
 		panic("advocate: replay failed")
 	}
-	runtime.AdvocateOncePost(index, res)
+	runtime.AdvocateOncePost(index, res) // MUST NOT BE DEFERRED, a Do whose f panicked keeps tpost 0
 	// ADVOCATE-CHANGE-END
 }
 
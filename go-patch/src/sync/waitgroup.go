@@ -98,6 +98,11 @@ func (wg *WaitGroup) Add(delta int) {
 		panic("sync: negative WaitGroup counter")
 	}
 	if w != 0 && delta > 0 && v == int32(delta) {
+		// ADVOCATE-CHANGE-START
+		if enabled {
+			runtime.IsNextElementReplayEnd(runtime.ExitCodeWGReuse, true, false)
+		}
+		// ADVOCATE-CHANGE-END
 		panic("sync: WaitGroup misuse: Add called concurrently with Wait")
 	}
 	if v > 0 || w == 0 {
@@ -169,6 +174,11 @@ func (wg *WaitGroup) Wait() {
 				race.Enable()
 				race.Acquire(unsafe.Pointer(wg))
 			}
+			// ADVOCATE-CHANGE-START
+			if enabled {
+				runtime.IsNextElementReplayEnd(runtime.ExitCodeAddConcurrentWait, true, false)
+			}
+			// ADVOCATE-CHANGE-END
 			return
 		}
 		// Increment waiters count.
@@ -181,13 +191,32 @@ func (wg *WaitGroup) Wait() {
 				race.Write(unsafe.Pointer(&wg.sema))
 			}
 			runtime_Semacquire(&wg.sema)
+			// ADVOCATE-CHANGE-START
+			// In the replay of a reuse, the add of the trace runs after the
+			// last done woke up the wait, but before the wait checks the counter
+			if enabled {
+				runtime.WaitForReplayEnd(runtime.ExitCodeWGReuse, func() bool {
+					return wg.state.Load() != 0
+				})
+			}
+			// ADVOCATE-CHANGE-END
 			if wg.state.Load() != 0 {
+				// ADVOCATE-CHANGE-START
+				if enabled {
+					runtime.IsNextElementReplayEnd(runtime.ExitCodeWGReuse, true, false)
+				}
+				// ADVOCATE-CHANGE-END
 				panic("sync: WaitGroup is reused before previous Wait has returned")
 			}
 			if race.Enabled {
 				race.Enable()
 				race.Acquire(unsafe.Pointer(wg))
 			}
+			// ADVOCATE-CHANGE-START
+			if enabled {
+				runtime.IsNextElementReplayEnd(runtime.ExitCodeAddConcurrentWait, true, false)
+			}
+			// ADVOCATE-CHANGE-END
 			return
 		}
 	}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
@@ -99,6 +100,14 @@ func getCaseReportForCode(code string, folder string) caseReport {
 	}
 	for _, file := range filteredFiles {
 		dir := filepath.Dir(file)
+
+		// prefer the result file written by the replay
+		result, err := readReplayResult(dir)
+		if err == nil {
+			toRet.actualExitCodes = append(toRet.actualExitCodes, strconv.Itoa(result.ExitCode))
+			continue
+		}
+
 		reorderFiles, err := getFiles(dir, "reorder_output.txt")
 		if err != nil {
 			fmt.Println(err)
@@ -239,6 +248,7 @@ func getActualExitCodes(filePath string) (map[string]int, error) {
 		"11",
 		"12",
 		"13",
+		"14",
 		"20",
 		"21",
 		"22",
@@ -251,11 +261,28 @@ func getActualExitCodes(filePath string) (map[string]int, error) {
 	for _, code := range exitCodes {
 		actualCodes[code] = 0
 	}
+	resultFiles, err := getFiles(filePath, "replay_result.json")
+	if err != nil {
+		return nil, err
+	}
+	dirsWithResult := make(map[string]bool)
+	for _, file := range resultFiles {
+		dir := filepath.Dir(file)
+		result, err := readReplayResult(dir)
+		if err != nil {
+			continue
+		}
+		dirsWithResult[dir] = true
+		actualCodes[strconv.Itoa(result.ExitCode)]++
+	}
 	files, err := getFiles(filePath, "reorder_output.txt")
 	if err != nil {
 		return nil, err
 	}
 	for _, file := range files {
+		if dirsWithResult[filepath.Dir(file)] {
+			continue
+		}
 		file, err := os.Open(file)
 		if err != nil {
 			return nil, err
@@ -277,6 +304,28 @@ func getActualExitCodes(filePath string) (map[string]int, error) {
 	return actualCodes, nil
 }
 
+type replayResult struct {
+	ExitCode         int     `json:"exitCode"`
+	ExitCodeName     string  `json:"exitCodeName"`
+	ExpectedExitCode int     `json:"expectedExitCode"`
+	ReplayEndReached bool    `json:"replayEndReached"`
+	ReplayedElements int     `json:"replayedElements"`
+	TotalElements    int     `json:"totalElements"`
+	ReadTime         float64 `json:"readTime"`
+	ReplayTime       float64 `json:"replayTime"`
+	PanicMessage     string  `json:"panicMessage"`
+}
+
+func readReplayResult(dir string) (replayResult, error) {
+	var result replayResult
+	content, err := os.ReadFile(filepath.Join(dir, "replay_result.json"))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(content, &result)
+	return result, err
+}
+
 func extractActualCode(s string) (int, error) {
 	re := regexp.MustCompile(`Exit Replay with code  (\d+)`)
 	match := re.FindStringSubmatch(s)
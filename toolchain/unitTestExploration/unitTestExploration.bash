@@ -0,0 +1,132 @@
+while [[ $# -gt 0 ]]; do
+	key="$1"
+	case $key in
+	-a | --advocate)
+		pathToAdvocate="$2"
+		shift
+		shift
+		;;
+	-f | --folder)
+		dir="$2"
+		shift
+		shift
+		;;
+	-m | --modulemode)
+		modulemode="$2"
+		shift
+		shift
+		;;
+	-t | --test-name)
+		testName="$2"
+		shift
+		shift
+		;;
+	-p | --package)
+		package="$2"
+		shift
+		shift
+		;;
+	-tf | --test-file)
+		file="$2"
+		shift
+		shift
+		;;
+	-r | --runs)
+		runs="$2"
+		shift
+		shift
+		;;
+	-s | --start-seed)
+		startSeed="$2"
+		shift
+		shift
+		;;
+	-d | --depth)
+		depth="$2"
+		shift
+		shift
+		;;
+	*)
+		shift
+		;;
+	esac
+done
+
+pathToPatchedGoRuntime="$pathToAdvocate/go-patch/bin/go"
+pathToGoRoot="$pathToAdvocate/go-patch"
+pathToOverheadInserter="$pathToAdvocate/toolchain/unitTestOverheadInserter/unitTestOverheadInserter"
+pathToOverheadRemover="$pathToAdvocate/toolchain/unitTestOverheadRemover/unitTestOverheadRemover"
+pathToAnalyzer="$pathToAdvocate/analyzer/analyzer"
+
+if [ -z "$pathToAdvocate" ]; then
+	echo "Path to advocate is empty"
+	exit 1
+fi
+if [ -z "$dir" ]; then
+	echo "Directory is empty"
+	exit 1
+fi
+if [ -z "$testName" ]; then
+	echo "Test name is empty"
+	exit 1
+fi
+if [ -z "$package" ]; then
+	echo "Package is empty"
+	exit 1
+fi
+if [ -z "$file" ]; then
+	echo "Test file is empty"
+	exit 1
+fi
+if [ -z "$runs" ]; then
+	runs=10
+fi
+if [ -z "$startSeed" ]; then
+	startSeed=1
+fi
+if [ -z "$depth" ]; then
+	depth=3
+fi
+
+cd "$dir"
+echo "In directory: $dir"
+export GOROOT=$pathToGoRoot
+echo "Goroot exported"
+touch advocateCommand.log
+echo "Remove Overhead just in case"
+$pathToOverheadRemover -f $file -t $testName
+echo "Add Exploration Overhead"
+echo "$pathToOverheadInserter -f $file -t $testName -e true -d $depth" >>advocateCommand.log
+$pathToOverheadInserter -f $file -t $testName -e true -d $depth >>advocateCommand.log
+if [ $? -ne 0 ]; then
+	echo "Error in adding overhead"
+	exit 1
+fi
+endSeed=$((startSeed + runs - 1))
+for seed in $(seq $startSeed $endSeed); do
+	echo "Run test with seed $seed"
+	export ADVOCATE_EXPLORATION_SEED=$seed
+	if [ "$modulemode" == "true" ]; then
+		echo "ADVOCATE_EXPLORATION_SEED=$seed $pathToPatchedGoRuntime test -count=1 -run=$testName -mod=mod ./$package" >>advocateCommand.log
+		$pathToPatchedGoRuntime test -count=1 -run=$testName -mod=mod "./$package"
+	else
+		echo "ADVOCATE_EXPLORATION_SEED=$seed $pathToPatchedGoRuntime test -count=1 -run=$testName ./$package" >>advocateCommand.log
+		$pathToPatchedGoRuntime test -count=1 -run=$testName "./$package"
+	fi
+	if [ $? -ne 0 ]; then
+		echo "Test failed with seed $seed" | tee -a advocateExploration.log
+	fi
+done
+unset ADVOCATE_EXPLORATION_SEED
+echo "Remove Overhead"
+echo "$pathToOverheadRemover -f $file -t $testName" >>advocateCommand.log
+$pathToOverheadRemover -f $file -t $testName
+for seed in $(seq $startSeed $endSeed); do
+	trace="$dir/$package/advocateExploration/seed_$seed"
+	if [ -d "$trace" ]; then
+		echo "$pathToAnalyzer -t $trace -x -r $trace/" >>advocateCommand.log
+		$pathToAnalyzer -t "$trace" -x -r "$trace/"
+	fi
+done
+
+unset GOROOT
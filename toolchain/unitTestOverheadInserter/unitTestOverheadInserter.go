@@ -14,11 +14,22 @@ func main() {
 	testName := flag.String("t", "", "name of the test")
 	replayOverheadString := flag.String("r", "false", "replay overhead")
 	replayNum := flag.String("n", "1", "replay number")
+	explorationOverheadString := flag.String("e", "false", "exploration overhead")
+	explorationDepth := flag.String("d", "3", "exploration depth")
+	leakCheckString := flag.String("l", "false", "fail the test on unfinished routines")
 	flag.Parse()
 	replayOverhead := false
 	if *replayOverheadString == "true" {
 		replayOverhead = true
 	}
+	explorationOverhead := false
+	if *explorationOverheadString == "true" {
+		explorationOverhead = true
+	}
+	leakCheck := false
+	if *leakCheckString == "true" {
+		leakCheck = true
+	}
 	if *testName == "" {
 		fmt.Println("Please provide a test name")
 		fmt.Println("Usage: go run unitTestOverheadInserter -f <file> -t <test name>")
@@ -39,7 +50,7 @@ func main() {
 		return
 	}
 
-	addOverhead(*fileName, *testName, replayOverhead, *replayNum)
+	addOverhead(*fileName, *testName, replayOverhead, *replayNum, explorationOverhead, *explorationDepth, leakCheck)
 }
 
 func testExists(testName string, fileName string) (bool, error) {
@@ -67,7 +78,8 @@ func testExists(testName string, fileName string) (bool, error) {
 	return false, nil
 }
 
-func addOverhead(fileName string, testName string, replayOverhead bool, replayNumber string) {
+func addOverhead(fileName string, testName string, replayOverhead bool, replayNumber string,
+	explorationOverhead bool, explorationDepth string, leakCheck bool) {
 	importAdded := false
 	file, err := os.OpenFile(fileName, os.O_RDWR, 0644)
 	if err != nil {
@@ -99,6 +111,17 @@ func addOverhead(fileName string, testName string, replayOverhead bool, replayNu
 	advocate.EnableReplay(%s, true)
 	defer advocate.WaitForReplayFinish()
 	// ======= Preamble End =======`, replayNumber))
+			} else if explorationOverhead {
+				lines = append(lines, fmt.Sprintf(`	// ======= Preamble Start =======
+	advocate.InitExploration(%s)
+	defer advocate.Finish()
+	// ======= Preamble End =======`, explorationDepth))
+			} else if leakCheck {
+				lines = append(lines, `	// ======= Preamble Start =======
+	advocate.InitTracing(0)
+	advocate.FailOnRoutineLeak(t)
+	defer advocate.Finish()
+	// ======= Preamble End =======`)
 			} else {
 				lines = append(lines, `	// ======= Preamble Start =======
 	advocate.InitTracing(0)